@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
@@ -41,6 +42,40 @@ const (
 const (
 	// ComputeDiscoverHashKey is the key to hash of compute discovery job based on compute templates for cell
 	ComputeDiscoverHashKey = "nova-compute-discovery"
+	// OnlineDataMigrationHashKey is the key to hash of the online data
+	// migration job of the cell
+	OnlineDataMigrationHashKey = "online-data-migration"
+	// UpgradeCheckHashKey is the key to hash of the upgrade check job of the
+	// new container images
+	UpgradeCheckHashKey = "upgrade-check"
+	// AdoptionCheckHashKey is the key to hash of the adoption check job of
+	// the existing DBs
+	AdoptionCheckHashKey = "adoption-check"
+	// UpgradeCheckOverrideAnnotation can be set to "true" on the Nova CR to
+	// deploy new container images even if the upgrade check fails with them
+	UpgradeCheckOverrideAnnotation = "nova.openstack.org/upgrade-check-override"
+	// ConfigRevisionAnnotation can be set on a nova service CR to the name of
+	// one of its config revision Secrets to deploy the service with that
+	// config instead of the one rendered from the Spec. It has no effect
+	// while the service is rolled back to its last known-good revision.
+	ConfigRevisionAnnotation = "nova.openstack.org/config-revision"
+	// ConfigRevisionOfLabel is the label of the config revision Secrets
+	// holding the name of the nova service CR the config belongs to
+	ConfigRevisionOfLabel = "nova.openstack.org/config-revision-of"
+	// ConfigRevisionNumberAnnotation is the annotation of the config revision
+	// Secrets ordering the revisions of a nova service. The revision with the
+	// highest number is the most recently deployed one.
+	ConfigRevisionNumberAnnotation = "nova.openstack.org/config-revision-number"
+	// CellForceDeleteAnnotation can be set on the Nova CR to a comma separated
+	// list of cell names to delete those cells even if they still have hosts
+	// or instance mappings of deleted instances. The compute services and
+	// host mappings of the cells are removed too. A cell that still has
+	// instances is not deleted even if forced.
+	CellForceDeleteAnnotation = "nova.openstack.org/force-cell-delete"
+	// AdoptionRecheckAnnotation can be set on the Nova CR to re-run the
+	// adoption check. Every new value of the annotation re-runs the check
+	// until it passed.
+	AdoptionRecheckAnnotation = "nova.openstack.org/adoption-recheck"
 )
 
 // NovaServiceBase contains the fields that are needed for each nova service CRD
@@ -104,6 +139,199 @@ type PasswordSelector struct {
 	PrefixMetadataCellsSecret string `json:"prefixMetadataCellsSecret"`
 }
 
+// KeystoneServiceIdentity defines the keystone region the nova services use
+// and where the nova service user and its project live in keystone
+type KeystoneServiceIdentity struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=regionOne
+	// Region - the keystone region used by the nova services to look up
+	// other services from the service catalog
+	Region string `json:"region"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Default
+	// ServiceUserDomain - the name of the keystone domain of the ServiceUser
+	ServiceUserDomain string `json:"serviceUserDomain"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=service
+	// ServiceProjectName - the name of the keystone project the ServiceUser
+	// is scoped to
+	ServiceProjectName string `json:"serviceProjectName"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=Default
+	// ServiceProjectDomain - the name of the keystone domain of the
+	// ServiceProjectName project
+	ServiceProjectDomain string `json:"serviceProjectDomain"`
+}
+
+// NovaTelemetry defines how the nova services integrate with the telemetry
+// stack that consumes the notifications emitted by nova
+type NovaTelemetry struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// Enabled - enables the instance usage audit on the computes and the
+	// instance state change notifications
+	Enabled bool `json:"enabled"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=hour
+	// +kubebuilder:validation:Pattern=`^(hour|day|month|year)(@[0-9]+)?$`
+	// InstanceUsageAuditPeriod - the time period the instance usage audit
+	// notifications are generated for. It can have an optional offset, e.g.
+	// month@15 will result in monthly audits starting on the 15th day.
+	InstanceUsageAuditPeriod string `json:"instanceUsageAuditPeriod"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=vm_and_task_state
+	// +kubebuilder:validation:Enum=vm_state;vm_and_task_state
+	// NotifyOnStateChange - defines which instance state changes trigger a
+	// notification
+	NotifyOnStateChange string `json:"notifyOnStateChange"`
+}
+
+// NovaRolloutStrategy defines how the changes of the cells are rolled out
+type NovaRolloutStrategy struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// MaxUnavailableCells - the number of cells that can be not Ready at the
+	// same time. A change is only rolled out to the next cell if less cells
+	// are not Ready. The rollout is paused when a cell fails.
+	MaxUnavailableCells int `json:"maxUnavailableCells"`
+
+	// +kubebuilder:validation:Optional
+	// CellOrder - the order the changes are rolled out to the cells. cell0 is
+	// always the first. The cells not listed here are rolled out after the
+	// listed ones in alphabetical order.
+	CellOrder []string `json:"cellOrder,omitempty"`
+}
+
+// NovaQuotaLimits defines the compute quota limits. A limit that is not set
+// is not managed by the operator. The value -1 means unlimited.
+type NovaQuotaLimits struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=-1
+	// Instances - number of servers
+	Instances *int `json:"instances,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=-1
+	// Cores - number of server cores
+	Cores *int `json:"cores,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=-1
+	// RAM - amount of server memory in MiB
+	RAM *int `json:"ram,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=-1
+	// KeyPairs - number of key pairs per user
+	KeyPairs *int `json:"keyPairs,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=-1
+	// MetadataItems - number of metadata items per server
+	MetadataItems *int `json:"metadataItems,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=-1
+	// ServerGroups - number of server groups
+	ServerGroups *int `json:"serverGroups,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum=-1
+	// ServerGroupMembers - number of servers per server group
+	ServerGroupMembers *int `json:"serverGroupMembers,omitempty"`
+}
+
+// NovaCapacity defines the instance and hypervisor capacity information
+// collected from the compute API
+type NovaCapacity struct {
+	// Instances - number of instances running on the compute hosts
+	Instances int `json:"instances"`
+
+	// ComputeServicesUp - number of nova-compute services reported as up
+	ComputeServicesUp int `json:"computeServicesUp"`
+
+	// ComputeServicesDown - number of nova-compute services reported as down
+	ComputeServicesDown int `json:"computeServicesDown"`
+
+	// ComputeServicesDisabled - number of disabled nova-compute services
+	ComputeServicesDisabled int `json:"computeServicesDisabled"`
+
+	// VCPUs - total number of vCPUs of the hypervisors
+	VCPUs int `json:"vcpus"`
+
+	// VCPUsUsed - number of vCPUs used by instances
+	VCPUsUsed int `json:"vcpusUsed"`
+
+	// MemoryMB - total RAM of the hypervisors in MB
+	MemoryMB int `json:"memoryMB"`
+
+	// MemoryMBUsed - RAM used by instances in MB
+	MemoryMBUsed int `json:"memoryMBUsed"`
+
+	// LocalDiskGB - total local disk of the hypervisors in GB
+	LocalDiskGB int `json:"localDiskGB"`
+
+	// LocalDiskGBUsed - local disk used by instances in GB
+	LocalDiskGBUsed int `json:"localDiskGBUsed"`
+
+	// CollectedAt - the time the information was collected from the compute
+	// API
+	CollectedAt metav1.Time `json:"collectedAt"`
+}
+
+// NovaCellMapping defines the cell mapping of a cell in the nova_api
+// database as reported by the cell mapping job
+type NovaCellMapping struct {
+	// UUID - the UUID of the cell
+	UUID string `json:"uuid"`
+
+	// TransportURLHost - the host of the transport URL of the cell. It is a
+	// comma separated list if the transport URL has multiple hosts.
+	TransportURLHost string `json:"transportURLHost,omitempty"`
+
+	// DatabaseHost - the host of the database connection of the cell
+	DatabaseHost string `json:"databaseHost,omitempty"`
+}
+
+// NovaServiceRevision identifies a rollout of a nova service by its
+// container image and the hash of its rendered config
+type NovaServiceRevision struct {
+	// ContainerImage - the container image of the service
+	ContainerImage string `json:"containerImage"`
+
+	// ConfigHash - the hash of every input of the service config
+	ConfigHash string `json:"configHash"`
+}
+
+// NovaRolloutStatus tracks the rollout of a nova service
+type NovaRolloutStatus struct {
+	// LastKnownGood - the last revision of the service that became ready
+	LastKnownGood *NovaServiceRevision `json:"lastKnownGood,omitempty"`
+
+	// Current - the revision that is being rolled out but not ready yet
+	Current *NovaServiceRevision `json:"current,omitempty"`
+
+	// StartTime - the time the rollout of the Current revision started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// RolledBackGeneration - the generation of the service CR that was
+	// rolled back to the LastKnownGood revision. The service stays rolled
+	// back until its Spec is changed.
+	RolledBackGeneration int64 `json:"rolledBackGeneration,omitempty"`
+}
+
+// IsRolledBack returns true if the given generation of the service is
+// rolled back to the LastKnownGood revision
+func (r NovaRolloutStatus) IsRolledBack(generation int64) bool {
+	return r.LastKnownGood != nil && r.RolledBackGeneration == generation
+}
+
 type NovaImages struct {
 	// +kubebuilder:validation:Required
 	// APIContainerImageURL
@@ -161,6 +389,46 @@ func (r *NovaCellImages) Default(defaults NovaCellDefaults) {
 	}
 }
 
+// NovaCellImageOverrides defines the container images of a single cell that
+// differ from the images defined for every cell
+type NovaCellImageOverrides struct {
+	// +kubebuilder:validation:Optional
+	// ConductorContainerImageURL
+	ConductorContainerImageURL string `json:"conductorContainerImageURL,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// MetadataContainerImageURL
+	MetadataContainerImageURL string `json:"metadataContainerImageURL,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// NoVNCContainerImageURL
+	NoVNCContainerImageURL string `json:"novncproxyContainerImageURL,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// NovaComputeContainerImageURL
+	NovaComputeContainerImageURL string `json:"computeContainerImageURL,omitempty"`
+}
+
+// Apply returns the images with the defined overrides applied
+func (r *NovaCellImageOverrides) Apply(images NovaCellImages) NovaCellImages {
+	if r == nil {
+		return images
+	}
+	if r.ConductorContainerImageURL != "" {
+		images.ConductorContainerImageURL = r.ConductorContainerImageURL
+	}
+	if r.MetadataContainerImageURL != "" {
+		images.MetadataContainerImageURL = r.MetadataContainerImageURL
+	}
+	if r.NoVNCContainerImageURL != "" {
+		images.NoVNCContainerImageURL = r.NoVNCContainerImageURL
+	}
+	if r.NovaComputeContainerImageURL != "" {
+		images.NovaComputeContainerImageURL = r.NovaComputeContainerImageURL
+	}
+	return images
+}
+
 // SetupDefaults - initializes any CRD field defaults based on environment variables (the defaulting mechanism itself is implemented via webhooks)
 func SetupDefaults() {
 	// Acquire environmental defaults and initialize NovaCell defaults with them
@@ -58,3 +58,69 @@ func matchAny(requested string, allowed []string) bool {
 	}
 	return false
 }
+
+// Validate checks that the quota limits are meaningful. Every limit needs to
+// be -1 (unlimited) or non negative and the limits need to be consistent with
+// each other.
+func (r NovaQuotaLimits) Validate(basePath *field.Path) field.ErrorList {
+	var errors field.ErrorList
+	limits := []struct {
+		name  string
+		value *int
+	}{
+		{"instances", r.Instances},
+		{"cores", r.Cores},
+		{"ram", r.RAM},
+		{"keyPairs", r.KeyPairs},
+		{"metadataItems", r.MetadataItems},
+		{"serverGroups", r.ServerGroups},
+		{"serverGroupMembers", r.ServerGroupMembers},
+	}
+	anySet := false
+	for _, limit := range limits {
+		if limit.value == nil {
+			continue
+		}
+		anySet = true
+		if *limit.value < -1 {
+			errors = append(
+				errors,
+				field.Invalid(
+					basePath.Child(limit.name), *limit.value,
+					"should be -1 (unlimited) or a non negative number"),
+			)
+		}
+	}
+	if !anySet {
+		errors = append(
+			errors,
+			field.Required(basePath, "at least one quota limit needs to be defined"),
+		)
+	}
+
+	// every server needs at least one core so a lower cores limit makes the
+	// instances limit unreachable
+	if isLimited(r.Instances) && isLimited(r.Cores) && *r.Cores < *r.Instances {
+		errors = append(
+			errors,
+			field.Invalid(
+				basePath.Child("cores"), *r.Cores,
+				fmt.Sprintf("should not be less than the instances limit %d", *r.Instances)),
+		)
+	}
+	if isLimited(r.ServerGroups) && *r.ServerGroups == 0 &&
+		r.ServerGroupMembers != nil && *r.ServerGroupMembers != 0 {
+		errors = append(
+			errors,
+			field.Invalid(
+				basePath.Child("serverGroupMembers"), *r.ServerGroupMembers,
+				"should be 0 if serverGroups is 0"),
+		)
+	}
+	return errors
+}
+
+// isLimited returns true if the quota limit is set and not unlimited
+func isLimited(limit *int) bool {
+	return limit != nil && *limit >= 0
+}
@@ -39,6 +39,14 @@ const (
 	// NovaAllCellsMQReadyCondition indicates that the message bus for each
 	// configured Cell is created successfully
 	NovaAllCellsMQReadyCondition condition.Type = "NovaAllCellsMQReady"
+	// NovaNotificationMQReadyCondition indicates that the message bus used
+	// for notifications is created successfully for the top level services
+	// and for each cell
+	NovaNotificationMQReadyCondition condition.Type = "NovaNotificationMQReady"
+	// NovaTelemetryReadyCondition indicates that the telemetry integration is
+	// enabled and every service has a notifications bus to emit to. It is a
+	// warning only so it does not affect the Ready condition.
+	NovaTelemetryReadyCondition condition.Type = "NovaTelemetryReady"
 	// NovaSchedulerReadyCondition indicates if the NovaScheduler is operational
 	NovaSchedulerReadyCondition condition.Type = "NovaSchedulerReady"
 	// NovaCellReadyCondition indicates when the given NovaCell instance is Ready
@@ -54,6 +62,56 @@ const (
 	NovaAllControlPlaneComputesReadyCondition condition.Type = "NovaAllControlPlaneComputesReady"
 	//NovaCellsDeletionCondition indicates that the NovaCells deletion is in progress
 	NovaCellsDeletionCondition condition.Type = "NovaCellsDeletion"
+	// NovaFlavorSyncedCondition indicates that the flavor in the compute API
+	// matches the NovaFlavor spec
+	NovaFlavorSyncedCondition condition.Type = "NovaFlavorSynced"
+	// NovaAggregateSyncedCondition indicates that the host aggregate in the
+	// compute API matches the NovaAggregate spec
+	NovaAggregateSyncedCondition condition.Type = "NovaAggregateSynced"
+	// NovaQuotaSyncedCondition indicates that the quota in the compute API
+	// matches the NovaQuotaClass or NovaProjectQuota spec
+	NovaQuotaSyncedCondition condition.Type = "NovaQuotaSynced"
+	// NovaHostServiceDisabledCondition indicates that the nova-compute
+	// service of the host is disabled so no new instances are scheduled to it
+	NovaHostServiceDisabledCondition condition.Type = "NovaHostServiceDisabled"
+	// NovaHostDrainedCondition indicates that every instance is moved off
+	// the host
+	NovaHostDrainedCondition condition.Type = "NovaHostDrained"
+	// NovaHostServiceForcedDownCondition indicates that the nova-compute
+	// service of the failed host is forced down so its instances can be
+	// evacuated
+	NovaHostServiceForcedDownCondition condition.Type = "NovaHostServiceForcedDown"
+	// NovaHostEvacuatedCondition indicates that every selected instance is
+	// evacuated off the failed host
+	NovaHostEvacuatedCondition condition.Type = "NovaHostEvacuated"
+	// NovaOnlineDataMigrationReadyCondition indicates that the online data
+	// migrations of the cell are completed with the current conductor image
+	NovaOnlineDataMigrationReadyCondition condition.Type = "NovaOnlineDataMigrationReady"
+	// NovaUpgradeCheckReadyCondition indicates that the nova-status upgrade
+	// check passed with the container images from the Spec so they are
+	// deployed
+	NovaUpgradeCheckReadyCondition condition.Type = "NovaUpgradeCheckReady"
+	// NovaUpgradeReadyCondition indicates that every service runs the
+	// deployed container images and no ordered upgrade is in progress
+	NovaUpgradeReadyCondition condition.Type = "NovaUpgradeReady"
+	// NovaRolloutReadyCondition indicates that the service is not rolled back
+	// to its last known-good container image and config
+	NovaRolloutReadyCondition condition.Type = "NovaRolloutReady"
+	// NovaManageCommandCompletedCondition indicates that the nova-manage
+	// command is run to completion
+	NovaManageCommandCompletedCondition condition.Type = "NovaManageCommandCompleted"
+	// NovaCellAuditCondition indicates that the last consistency audit of the
+	// cell found no inconsistency. It does not affect the Ready condition.
+	NovaCellAuditCondition condition.Type = "NovaCellAudit"
+	// NovaDatabaseBackupCondition indicates that the database backup is
+	// taken or the backups are scheduled
+	NovaDatabaseBackupCondition condition.Type = "NovaDatabaseBackup"
+	// NovaDatabaseRestoreCondition indicates that the database restore is
+	// completed
+	NovaDatabaseRestoreCondition condition.Type = "NovaDatabaseRestore"
+	// NovaAdoptionReadyCondition indicates that the existing DBs and cell
+	// mappings match the Spec so the deployment can be adopted
+	NovaAdoptionReadyCondition condition.Type = "NovaAdoptionReady"
 )
 
 // Common Messages used by API objects.
@@ -64,6 +122,12 @@ const (
 	// NovaAPIReadyErrorMessage
 	NovaAPIReadyErrorMessage = "NovaAPI error occurred %s"
 
+	// NovaAPIReadyWaitingMessage
+	NovaAPIReadyWaitingMessage = "Waiting for NovaAPI %s to become Ready"
+
+	// NovaAPIReadyMessage
+	NovaAPIReadyMessage = "NovaAPI %s is Ready"
+
 	// NovaConductorReadyInitMessage
 	NovaConductorReadyInitMessage = "NovaConductor not started"
 
@@ -121,6 +185,24 @@ const (
 	// NovaAllCellsMQReadyMessage
 	NovaAllCellsMQReadyMessage = "All message busses created successfully"
 
+	// NovaNotificationMQReadyInitMessage
+	NovaNotificationMQReadyInitMessage = "Notification message bus creation not started"
+
+	// NovaNotificationMQReadyCreatingMessage
+	NovaNotificationMQReadyCreatingMessage = "Notification message bus creation ongoing for %s"
+
+	// NovaNotificationMQReadyErrorMessage
+	NovaNotificationMQReadyErrorMessage = "Notification message bus creation failed for %s"
+
+	// NovaNotificationMQReadyMessage
+	NovaNotificationMQReadyMessage = "Notification message bus created successfully"
+
+	// NovaTelemetryReadyMessage
+	NovaTelemetryReadyMessage = "Telemetry is enabled"
+
+	// NovaTelemetryReadyNoNotificationsBusMessage
+	NovaTelemetryReadyNoNotificationsBusMessage = "Telemetry is enabled but no notifications bus is configured for %s"
+
 	// NovaSchedulerReadyInitMessage
 	NovaSchedulerReadyInitMessage = "NovaScheduler not started"
 
@@ -183,4 +265,241 @@ const (
 
 	// NovaCellsDeletionConditionReadyMessage
 	NovaCellsDeletionConditionReadyMessage = "There is no more NovaCells to delete"
+
+	// NovaCellsDeletionErrorMessage
+	NovaCellsDeletionErrorMessage = "NovaCells deletion failed: %s"
+
+	// NovaCellsDeletionBlockedMessage
+	NovaCellsDeletionBlockedMessage = "NovaCells deletion blocked as the cells are not empty: %s. " +
+		"Delete the Job of a cell to retry its deletion after the cell is emptied. " +
+		"Add the cell names to the " + CellForceDeleteAnnotation + " annotation to force the deletion " +
+		"of the cells without instances"
+
+	// NovaFlavorSyncedInitMessage
+	NovaFlavorSyncedInitMessage = "Flavor synchronization not started"
+
+	// NovaFlavorSyncedErrorMessage
+	NovaFlavorSyncedErrorMessage = "Flavor synchronization error occurred %s"
+
+	// NovaFlavorSyncedDriftMessage
+	NovaFlavorSyncedDriftMessage = "Flavor differs from the spec in fields that cannot be updated in place: %s"
+
+	// NovaFlavorSyncedMessage
+	NovaFlavorSyncedMessage = "Flavor is in sync with the compute API"
+
+	// NovaAggregateSyncedInitMessage
+	NovaAggregateSyncedInitMessage = "Aggregate synchronization not started"
+
+	// NovaAggregateSyncedErrorMessage
+	NovaAggregateSyncedErrorMessage = "Aggregate synchronization error occurred %s"
+
+	// NovaAggregateSyncedMissingHostsMessage
+	NovaAggregateSyncedMissingHostsMessage = "Waiting for hosts to be registered in the compute API: %s"
+
+	// NovaAggregateSyncedFailedHostsMessage
+	NovaAggregateSyncedFailedHostsMessage = "Failed to add hosts to the aggregate: %s"
+
+	// NovaAggregateSyncedMessage
+	NovaAggregateSyncedMessage = "Aggregate is in sync with the compute API"
+
+	// NovaQuotaSyncedInitMessage
+	NovaQuotaSyncedInitMessage = "Quota synchronization not started"
+
+	// NovaQuotaSyncedErrorMessage
+	NovaQuotaSyncedErrorMessage = "Quota synchronization error occurred %s"
+
+	// NovaQuotaSyncedMessage
+	NovaQuotaSyncedMessage = "Quota is in sync with the compute API"
+
+	// NovaHostServiceDisabledInitMessage
+	NovaHostServiceDisabledInitMessage = "Compute service is not yet disabled"
+
+	// NovaHostServiceDisabledErrorMessage
+	NovaHostServiceDisabledErrorMessage = "Compute service disable error occurred %s"
+
+	// NovaHostServiceDisabledMessage
+	NovaHostServiceDisabledMessage = "Compute service is disabled"
+
+	// NovaHostDrainedInitMessage
+	NovaHostDrainedInitMessage = "Host drain not started"
+
+	// NovaHostDrainedErrorMessage
+	NovaHostDrainedErrorMessage = "Host drain error occurred %s"
+
+	// NovaHostDrainedInProgressMessage
+	NovaHostDrainedInProgressMessage = "Host drain in progress: %d of %d instances migrated"
+
+	// NovaHostDrainedFailedMessage
+	NovaHostDrainedFailedMessage = "Failed to migrate %d instances off the host: %s"
+
+	// NovaHostDrainedMessage
+	NovaHostDrainedMessage = "Every instance is migrated off the host"
+
+	// NovaHostServiceForcedDownInitMessage
+	NovaHostServiceForcedDownInitMessage = "Compute service is not yet forced down"
+
+	// NovaHostServiceForcedDownErrorMessage
+	NovaHostServiceForcedDownErrorMessage = "Compute service force down error occurred %s"
+
+	// NovaHostServiceForcedDownMessage
+	NovaHostServiceForcedDownMessage = "Compute service is forced down"
+
+	// NovaHostServiceForcedDownServiceUpMessage
+	NovaHostServiceForcedDownServiceUpMessage = "Compute service is up, refusing to evacuate the host"
+
+	// NovaHostServiceRecoveredMessage
+	NovaHostServiceRecoveredMessage = "Compute service is reporting again, force down is unset"
+
+	// NovaHostEvacuatedInitMessage
+	NovaHostEvacuatedInitMessage = "Host evacuation not started"
+
+	// NovaHostEvacuatedErrorMessage
+	NovaHostEvacuatedErrorMessage = "Host evacuation error occurred %s"
+
+	// NovaHostEvacuatedInProgressMessage
+	NovaHostEvacuatedInProgressMessage = "Host evacuation in progress: %d of %d instances evacuated"
+
+	// NovaHostEvacuatedFailedMessage
+	NovaHostEvacuatedFailedMessage = "Failed to evacuate %d instances off the host: %s"
+
+	// NovaHostEvacuatedMessage
+	NovaHostEvacuatedMessage = "Every instance is evacuated off the host"
+
+	// NovaOnlineDataMigrationReadyInitMessage
+	NovaOnlineDataMigrationReadyInitMessage = "Online data migration not started"
+
+	// NovaOnlineDataMigrationReadyWaitingMessage
+	NovaOnlineDataMigrationReadyWaitingMessage = "Online data migration waits for the cell services to become Ready"
+
+	// NovaOnlineDataMigrationReadyRunningMessage
+	NovaOnlineDataMigrationReadyRunningMessage = "Online data migration in progress"
+
+	// NovaOnlineDataMigrationReadyErrorMessage
+	NovaOnlineDataMigrationReadyErrorMessage = "Online data migration error occurred %s"
+
+	// NovaOnlineDataMigrationReadyMessage
+	NovaOnlineDataMigrationReadyMessage = "Online data migration completed"
+
+	// NovaUpgradeCheckReadyInitMessage
+	NovaUpgradeCheckReadyInitMessage = "Upgrade check not started"
+
+	// NovaUpgradeCheckReadyRunningMessage
+	NovaUpgradeCheckReadyRunningMessage = "Upgrade check of the new container images in progress"
+
+	// NovaUpgradeCheckReadyFailedMessage
+	NovaUpgradeCheckReadyFailedMessage = "Upgrade check of the new container images failed, delete the Job to re-run it: %s"
+
+	// NovaUpgradeCheckReadyErrorMessage
+	NovaUpgradeCheckReadyErrorMessage = "Upgrade check error occurred %s"
+
+	// NovaUpgradeCheckReadyMessage
+	NovaUpgradeCheckReadyMessage = "Upgrade check passed with the container images"
+
+	// NovaUpgradeCheckReadyOverriddenMessage
+	NovaUpgradeCheckReadyOverriddenMessage = "Upgrade check is overridden by the %s annotation"
+
+	// NovaUpgradeReadyInitMessage
+	NovaUpgradeReadyInitMessage = "Upgrade not started"
+
+	// NovaUpgradeReadyInProgressMessage
+	NovaUpgradeReadyInProgressMessage = "Upgrade in progress in the %s phase"
+
+	// NovaUpgradeReadyErrorMessage
+	NovaUpgradeReadyErrorMessage = "Upgrade error occurred %s"
+
+	// NovaUpgradeReadyMessage
+	NovaUpgradeReadyMessage = "Every service runs the deployed container images"
+
+	// NovaOnlineDataMigrationReadyDeferredMessage
+	NovaOnlineDataMigrationReadyDeferredMessage = "Online data migration is deferred until every service is upgraded"
+
+	// NovaRolloutReadyInitMessage
+	NovaRolloutReadyInitMessage = "Rollout not started"
+
+	// NovaRolloutReadyRolledBackMessage
+	NovaRolloutReadyRolledBackMessage = "Rolled back to image %s and config %s as the rollout of image %s and config %s did not become ready in %d seconds"
+
+	// NovaRolloutReadyErrorMessage
+	NovaRolloutReadyErrorMessage = "Rollout error occurred %s"
+
+	// NovaRolloutReadyMessage
+	NovaRolloutReadyMessage = "Rollout is not rolled back"
+
+	// NovaManageCommandInputWaitingMessage
+	NovaManageCommandInputWaitingMessage = "Waiting for cell %s of Nova %s to be mapped"
+
+	// NovaManageCommandCompletedInitMessage
+	NovaManageCommandCompletedInitMessage = "nova-manage command not started"
+
+	// NovaManageCommandCompletedRunningMessage
+	NovaManageCommandCompletedRunningMessage = "nova-manage command is running"
+
+	// NovaManageCommandCompletedErrorMessage
+	NovaManageCommandCompletedErrorMessage = "nova-manage command error occurred %s"
+
+	// NovaManageCommandCompletedMessage
+	NovaManageCommandCompletedMessage = "nova-manage command exited with code %d"
+
+	// NovaCellAuditInitMessage
+	NovaCellAuditInitMessage = "Consistency audit has not run yet"
+
+	// NovaCellAuditErrorMessage
+	NovaCellAuditErrorMessage = "Consistency audit error occurred %s"
+
+	// NovaCellAuditInconsistentMessage
+	NovaCellAuditInconsistentMessage = "Consistency audit found %s"
+
+	// NovaCellAuditMessage
+	NovaCellAuditMessage = "Consistency audit found no inconsistency"
+
+	// NovaDatabaseInputWaitingMessage
+	NovaDatabaseInputWaitingMessage = "Waiting for %s"
+
+	// NovaDatabaseBackupInitMessage
+	NovaDatabaseBackupInitMessage = "Database backup not started"
+
+	// NovaDatabaseBackupRunningMessage
+	NovaDatabaseBackupRunningMessage = "Database backup is running"
+
+	// NovaDatabaseBackupErrorMessage
+	NovaDatabaseBackupErrorMessage = "Database backup error occurred %s"
+
+	// NovaDatabaseBackupScheduledMessage
+	NovaDatabaseBackupScheduledMessage = "Database backups are scheduled with %s"
+
+	// NovaDatabaseBackupMessage
+	NovaDatabaseBackupMessage = "Database backup completed"
+
+	// NovaDatabaseRestoreInitMessage
+	NovaDatabaseRestoreInitMessage = "Database restore not started"
+
+	// NovaDatabaseRestoreRefusedMessage
+	NovaDatabaseRestoreRefusedMessage = "Database restore refused while services are running: %s"
+
+	// NovaDatabaseRestoreRunningMessage
+	NovaDatabaseRestoreRunningMessage = "Database restore is running"
+
+	// NovaDatabaseRestoreErrorMessage
+	NovaDatabaseRestoreErrorMessage = "Database restore error occurred %s"
+
+	// NovaDatabaseRestoreMessage
+	NovaDatabaseRestoreMessage = "Database restore completed"
+
+	// NovaAdoptionReadyInitMessage
+	NovaAdoptionReadyInitMessage = "Adoption check not started"
+
+	// NovaAdoptionReadyWaitingMessage
+	NovaAdoptionReadyWaitingMessage = "Adoption check is waiting for the DBs and the message buses"
+
+	// NovaAdoptionReadyRunningMessage
+	NovaAdoptionReadyRunningMessage = "Adoption check is running"
+
+	// NovaAdoptionReadyErrorMessage
+	NovaAdoptionReadyErrorMessage = "Adoption check error occurred %s"
+
+	// NovaAdoptionReadyMismatchMessage
+	NovaAdoptionReadyMismatchMessage = "Adoption check found mismatches with the existing deployment: %s"
+
+	// NovaAdoptionReadyMessage
+	NovaAdoptionReadyMessage = "Adoption check passed"
 )
@@ -49,6 +49,57 @@ type NovaSpecCore struct {
 	// communicate.
 	APIMessageBusInstance string `json:"apiMessageBusInstance"`
 
+	// +kubebuilder:validation:Optional
+	// NotificationsBusInstance is the name of the RabbitMqCluster CR to
+	// select the Message Bus Service instance used by the Nova services to
+	// publish versioned notifications. If not defined then notifications
+	// are disabled. It can be overridden per cell in the CellTemplates.
+	NotificationsBusInstance *string `json:"notificationsBusInstance,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default={enabled: false}
+	// Telemetry - defines the integration with the telemetry stack. It
+	// needs a notifications bus to be configured via NotificationsBusInstance.
+	Telemetry NovaTelemetry `json:"telemetry"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=0
+	// StaleServiceGracePeriod - the number of seconds a nova service of a
+	// removed pod needs to be reported down before the service and the
+	// placement resource providers of its compute nodes are deleted
+	StaleServiceGracePeriod int `json:"staleServiceGracePeriod"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=auto
+	// +kubebuilder:validation:MinLength=1
+	// ComputeRPCPin - the compute RPC version the nova services are pinned
+	// to via [upgrade_levels]compute while new container images are rolled
+	// out. The default auto pins to the version of the oldest nova-compute
+	// service. It can be a release name or an explicit RPC version.
+	ComputeRPCPin string `json:"computeRPCPin"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=0
+	// RolloutDeadline - the number of seconds a new container image or
+	// config of a nova service has to become ready before the service is
+	// rolled back to its last known-good image and config. 0 disables the
+	// rollback.
+	RolloutDeadline int `json:"rolloutDeadline"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// ConfigRevisionHistoryLimit - the number of rendered config revisions
+	// kept per nova service
+	ConfigRevisionHistoryLimit int `json:"configRevisionHistoryLimit"`
+
+	// +kubebuilder:validation:Optional
+	// RolloutStrategy - if defined then the changes of the cells are rolled
+	// out cell by cell instead of to every cell at once
+	RolloutStrategy *NovaRolloutStrategy `json:"rolloutStrategy,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default={cell0: {cellDatabaseAccount: nova-cell0, hasAPIAccess: true}, cell1: {cellDatabaseAccount: nova-cell1, cellDatabaseInstance: openstack-cell1, cellMessageBusInstance: rabbitmq-cell1, hasAPIAccess: true}}
 	// Cells is a mapping of cell names to NovaCellTemplate objects defining
@@ -62,6 +113,11 @@ type NovaSpecCore struct {
 	// ServiceUser - optional username used for this service to register in keystone
 	ServiceUser string `json:"serviceUser"`
 
+	// +kubebuilder:validation:Optional
+	// KeystoneServiceIdentity defines the keystone region, and the domain
+	// and project of the ServiceUser
+	KeystoneServiceIdentity `json:",inline"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default="nova-api"
 	// APIDatabaseAccount - MariaDBAccount to use when accessing the API DB
@@ -95,6 +151,21 @@ type NovaSpecCore struct {
 	// PreserveJobs - do not delete jobs after they finished e.g. to check logs
 	PreserveJobs bool `json:"preserveJobs"`
 
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// Adoption - adopts the existing nova_api and nova_cell* DBs of a Nova
+	// deployment not managed by the operator, e.g. a TripleO deployment.
+	// Before any cell is deployed the schema versions of the DBs are
+	// verified and the existing cell mappings are matched to the
+	// CellTemplates by the name of the cell, the hosts of its message bus
+	// and DB and the name of its DB. The matched cell mappings are updated
+	// instead of creating new ones. Until the check passes it is re-run when
+	// its input changes or when the nova.openstack.org/adoption-recheck
+	// annotation is set to a new value, e.g. after the existing deployment is
+	// fixed. Cells added after the adoption completed are deployed as new
+	// cells.
+	Adoption bool `json:"adoption"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default={replicas:1}
 	// APIServiceTemplate - define the nova-api service
@@ -133,6 +204,42 @@ type NovaSpec struct {
 	NovaImages `json:",inline"`
 }
 
+// NovaUpgradePhase defines the phase of the ordered rollout of new container
+// images
+type NovaUpgradePhase string
+
+const (
+	// NovaUpgradePhaseDBSync - cell0 conductor syncs the API and cell0 DB
+	// schema with the new image
+	NovaUpgradePhaseDBSync NovaUpgradePhase = "DBSync"
+	// NovaUpgradePhaseConductors - the conductors of the other cells are
+	// upgraded one cell at a time
+	NovaUpgradePhaseConductors NovaUpgradePhase = "Conductors"
+	// NovaUpgradePhaseScheduler - the scheduler is upgraded
+	NovaUpgradePhaseScheduler NovaUpgradePhase = "Scheduler"
+	// NovaUpgradePhaseServices - the API, metadata, novncproxy and compute
+	// services are upgraded
+	NovaUpgradePhaseServices NovaUpgradePhase = "Services"
+	// NovaUpgradePhaseOnlineDataMigration - the online data migrations are
+	// run in every cell
+	NovaUpgradePhaseOnlineDataMigration NovaUpgradePhase = "OnlineDataMigration"
+	// NovaUpgradePhaseCompleted - every service runs the target images
+	NovaUpgradePhaseCompleted NovaUpgradePhase = "Completed"
+	// NovaUpgradePhaseAborted - the previous images were requested again
+	// during the upgrade so every service is moved back to them
+	NovaUpgradePhaseAborted NovaUpgradePhase = "Aborted"
+)
+
+// NovaUpgradeCheckFailure defines the result of a failed nova-status upgrade
+// check
+type NovaUpgradeCheckFailure struct {
+	// Images are the container images the upgrade check failed with
+	Images NovaImages `json:"images"`
+
+	// Report is the output of the failed upgrade check
+	Report string `json:"report"`
+}
+
 // NovaStatus defines the observed state of Nova
 type NovaStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
@@ -156,10 +263,64 @@ type NovaStatus struct {
 	// configuration.
 	RegisteredCells map[string]string `json:"registeredCells,omitempty"`
 
+	// CellMappings is a map keyed by the same cell names as RegisteredCells
+	// with a value that is the cell mapping of the given cell as reported by
+	// the last cell mapping job
+	CellMappings map[string]NovaCellMapping `json:"cellMappings,omitempty"`
+
 	// DiscoveredCells is a map keyed by cell names that have discovered all kubernetes managed
 	// computes in cell value is a hash of config from all kubernetes managed computes in cell
 	DiscoveredCells map[string]string `json:"discoveredCells,omitempty"`
 
+	// DisabledCells are the names of the cells that are disabled for
+	// scheduling in their cell mapping
+	DisabledCells []string `json:"disabledCells,omitempty"`
+
+	// AdoptionMismatches are the differences the last adoption check found
+	// between the existing DBs and the Spec. The cells are not deployed
+	// while there is a mismatch.
+	AdoptionMismatches []string `json:"adoptionMismatches,omitempty"`
+
+	// AdoptionCompleted is true once the adoption check passed and the
+	// existing cell mappings are adopted. The check is not run afterwards.
+	AdoptionCompleted bool `json:"adoptionCompleted,omitempty"`
+
+	// Capacity is the sum of the capacity information reported by the cells
+	Capacity *NovaCapacity `json:"capacity,omitempty"`
+
+	// DeployedImages are the container images every nova service is
+	// deployed with. New images from the Spec are only deployed after the
+	// nova-status upgrade check passed with them.
+	DeployedImages *NovaImages `json:"deployedImages,omitempty"`
+
+	// UpgradePhase is the phase of the current or last ordered rollout of
+	// new container images
+	UpgradePhase NovaUpgradePhase `json:"upgradePhase,omitempty"`
+
+	// PreviousImages are the container images the services ran before the
+	// current or last upgrade
+	PreviousImages *NovaImages `json:"previousImages,omitempty"`
+
+	// TargetImages are the container images the current or last upgrade
+	// rolls out
+	TargetImages *NovaImages `json:"targetImages,omitempty"`
+
+	// UpgradeCheckFailure is the result of the last failed upgrade check of
+	// the new container images. It is kept after the check Job is deleted
+	// and removed when the check passes or the images change.
+	UpgradeCheckFailure *NovaUpgradeCheckFailure `json:"upgradeCheckFailure,omitempty"`
+
+	// RolloutHeldCells are the names of the cells whose changes are held
+	// back by the cell by cell rollout
+	RolloutHeldCells []string `json:"rolloutHeldCells,omitempty"`
+
+	// RolloutPausedBy is the name of the failed cell that paused the cell by
+	// cell rollout
+	RolloutPausedBy string `json:"rolloutPausedBy,omitempty"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
 	//ObservedGeneration - the most recent generation observed for this service. If the observed generation is less than the spec generation, then the controller has not processed the latest changes.
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
@@ -199,6 +360,13 @@ func (instance Nova) IsReady() bool {
 	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
 }
 
+// IsUpgrading returns true if new container images are being rolled out
+func (instance Nova) IsUpgrading() bool {
+	return instance.Status.UpgradePhase != "" &&
+		instance.Status.UpgradePhase != NovaUpgradePhaseCompleted &&
+		instance.Status.UpgradePhase != NovaUpgradePhaseAborted
+}
+
 // RbacConditionsSet - set the conditions for the rbac object
 func (instance Nova) RbacConditionsSet(c *condition.Condition) {
 	instance.Status.Conditions.Set(c)
@@ -24,6 +24,7 @@ package v1beta1
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/google/go-cmp/cmp"
 	service "github.com/openstack-k8s-operators/lib-common/modules/common/service"
@@ -198,6 +199,10 @@ func (r *NovaSpecCore) ValidateCellTemplates(basePath *field.Path, namespace str
 			errors,
 			cell.DBPurge.Validate(cellPath.Child("dbPurge"))...)
 
+		errors = append(
+			errors,
+			cell.Audit.Validate(cellPath.Child("audit"))...)
+
 		if name == Cell0Name {
 			errors = append(
 				errors,
@@ -211,6 +216,27 @@ func (r *NovaSpecCore) ValidateCellTemplates(basePath *field.Path, namespace str
 				errors,
 				ValidateNovaComputeCell0(
 					cellPath.Child("novaComputeTemplates"), len(cell.NovaComputeTemplates))...)
+			if cell.AvailabilityZone != "" {
+				errors = append(
+					errors,
+					field.Invalid(
+						cellPath.Child("availabilityZone"), cell.AvailabilityZone,
+						"should not be defined for cell0 as it has no computes"))
+			}
+			if cell.Disabled {
+				errors = append(
+					errors,
+					field.Invalid(
+						cellPath.Child("disabled"), cell.Disabled,
+						"cell0 cannot be disabled as it is not used for scheduling"))
+			}
+			if cell.Audit.Enabled {
+				errors = append(
+					errors,
+					field.Invalid(
+						cellPath.Child("audit", "enabled"), cell.Audit.Enabled,
+						"should not be enabled for cell0 as it has no computes"))
+			}
 		}
 
 		for computeName, computeTemplate := range cell.NovaComputeTemplates {
@@ -272,6 +298,50 @@ func (r *NovaSpecCore) ValidateSchedulerServiceTemplate(basePath *field.Path, na
 	return errors
 }
 
+// ValidateCellImages returns a warning for each cell image override that
+// makes the cells run mixed images
+func (r *NovaSpec) ValidateCellImages(basePath *field.Path) admission.Warnings {
+	var warnings admission.Warnings
+
+	cellNames := make([]string, 0, len(r.CellTemplates))
+	for name := range r.CellTemplates {
+		cellNames = append(cellNames, name)
+	}
+	sort.Strings(cellNames)
+
+	services := []struct {
+		field string
+		image func(NovaCellImages) string
+	}{
+		{"conductorContainerImageURL", func(i NovaCellImages) string { return i.ConductorContainerImageURL }},
+		{"metadataContainerImageURL", func(i NovaCellImages) string { return i.MetadataContainerImageURL }},
+		{"novncproxyContainerImageURL", func(i NovaCellImages) string { return i.NoVNCContainerImageURL }},
+		{"computeContainerImageURL", func(i NovaCellImages) string { return i.NovaComputeContainerImageURL }},
+	}
+	for _, s := range services {
+		defaultImage := s.image(r.NovaCellImages)
+		cellImages := map[string]string{}
+		distinct := map[string]bool{}
+		for _, name := range cellNames {
+			image := s.image(r.CellTemplates[name].Images.Apply(r.NovaCellImages))
+			cellImages[name] = image
+			distinct[image] = true
+		}
+		if len(distinct) < 2 {
+			continue
+		}
+		for _, name := range cellNames {
+			if cellImages[name] == defaultImage {
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf(
+				"%s: the cell runs %s instead of %s so the cells run mixed images",
+				basePath.Child("cellTemplates").Key(name).Child("images").Child(s.field),
+				cellImages[name], defaultImage))
+		}
+	}
+	return warnings
+}
 
 // ValidateCreate validates the NovaSpec during the webhook invocation.
 func (r *NovaSpec) ValidateCreate(basePath *field.Path, namespace string) field.ErrorList {
@@ -286,6 +356,11 @@ func (r *NovaSpecCore) ValidateCreate(basePath *field.Path, namespace string) fi
 	errors = append(errors, r.ValidateAPIServiceTemplate(basePath, namespace)...)
 	errors = append(errors, r.ValidateSchedulerServiceTemplate(basePath, namespace)...)
 
+	if r.RolloutStrategy != nil {
+		errors = append(errors,
+			r.RolloutStrategy.Validate(basePath.Child("rolloutStrategy"), r.CellTemplates)...)
+	}
+
 	// validate TopologyRef override for top-level MetadataServiceTemplate
 	errors = append(errors,
 		r.MetadataServiceTemplate.ValidateTopology(
@@ -309,14 +384,15 @@ func (r *NovaSpecCore) ValidateCreate(basePath *field.Path, namespace string) fi
 func (r *Nova) ValidateCreate() (admission.Warnings, error) {
 	novalog.Info("validate create", "name", r.Name)
 
+	warnings := r.Spec.ValidateCellImages(field.NewPath("spec"))
 	errors := r.Spec.ValidateCreate(field.NewPath("spec"), r.Namespace)
 	if len(errors) != 0 {
 		novalog.Info("validation failed", "name", r.Name)
-		return nil, apierrors.NewInvalid(
+		return warnings, apierrors.NewInvalid(
 			schema.GroupKind{Group: "nova.openstack.org", Kind: "Nova"},
 			r.Name, errors)
 	}
-	return nil, nil
+	return warnings, nil
 }
 
 // ValidateUpdate validates the NovaSpec during the webhook invocation.
@@ -336,6 +412,11 @@ func (r *NovaSpecCore) ValidateUpdate(old NovaSpecCore, basePath *field.Path, na
 	errors = append(errors, r.ValidateAPIServiceTemplate(basePath, namespace)...)
 	errors = append(errors, r.ValidateSchedulerServiceTemplate(basePath, namespace)...)
 
+	if r.RolloutStrategy != nil {
+		errors = append(errors,
+			r.RolloutStrategy.Validate(basePath.Child("rolloutStrategy"), r.CellTemplates)...)
+	}
+
 	// validate TopologyRef override for top-level MetadataServiceTemplate
 	errors = append(errors,
 		r.MetadataServiceTemplate.ValidateTopology(
@@ -360,14 +441,15 @@ func (r *Nova) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
 
 	novalog.Info("validate update", "diff", cmp.Diff(oldNova, r))
 
+	warnings := r.Spec.ValidateCellImages(field.NewPath("spec"))
 	errors := r.Spec.ValidateUpdate(oldNova.Spec, field.NewPath("spec"), r.Namespace)
 	if len(errors) != 0 {
 		novalog.Info("validation failed", "name", r.Name)
-		return nil, apierrors.NewInvalid(
+		return warnings, apierrors.NewInvalid(
 			schema.GroupKind{Group: "nova.openstack.org", Kind: "Nova"},
 			r.Name, errors)
 	}
-	return nil, nil
+	return warnings, nil
 }
 
 // ValidateDelete implements webhook.Validator so a webhook will be registered for the type
@@ -419,3 +501,39 @@ func (r *NovaCellDBPurge) Validate(basePath *field.Path) field.ErrorList {
 	}
 	return errors
 }
+
+// Validate the field values
+func (r *NovaCellAudit) Validate(basePath *field.Path) field.ErrorList {
+	var errors field.ErrorList
+	if r.Schedule == nil {
+		return errors
+	}
+	if _, err := cron.ParseStandard(*r.Schedule); err != nil {
+		errors = append(
+			errors,
+			field.Invalid(
+				basePath.Child("schedule"), r.Schedule, err.Error()),
+		)
+	}
+	return errors
+}
+
+// Validate the cell order of the rollout strategy
+func (r *NovaRolloutStrategy) Validate(
+	basePath *field.Path, cellTemplates map[string]NovaCellTemplate,
+) field.ErrorList {
+	var errors field.ErrorList
+	seen := map[string]bool{}
+	for i, cellName := range r.CellOrder {
+		path := basePath.Child("cellOrder").Index(i)
+		if _, ok := cellTemplates[cellName]; !ok {
+			errors = append(errors, field.Invalid(
+				path, cellName, "should be the name of a cell in cellTemplates"))
+		}
+		if seen[cellName] {
+			errors = append(errors, field.Duplicate(path, cellName))
+		}
+		seen[cellName] = true
+	}
+	return errors
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NovaAggregateSpec defines the desired state of NovaAggregate
+type NovaAggregateSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=nova
+	// NovaInstance is the name of the Nova CR in the same namespace. The
+	// aggregate is managed via the compute API of this Nova deployment.
+	NovaInstance string `json:"novaInstance"`
+
+	// +kubebuilder:validation:Optional
+	// AggregateName is the name of the host aggregate in the compute API. If
+	// not provided then the name of the NovaAggregate CR is used. If an
+	// aggregate with this name already exists then it is adopted.
+	AggregateName string `json:"aggregateName,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// AvailabilityZone is the name of the availability zone the hosts of the
+	// aggregate are exposed in. If not provided then the aggregate does not
+	// define an availability zone.
+	AvailabilityZone string `json:"availabilityZone,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Metadata is the set of key value pairs defined on the aggregate, e.g.
+	// to be used by the scheduler filters. Metadata keys on the aggregate
+	// that are not listed here are removed.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Hosts is the list of compute service host names that are members of
+	// the aggregate.
+	Hosts []string `json:"hosts,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// CellName is the name of a cell of the NovaInstance. If provided then
+	// every compute host mapped to this cell is a member of the aggregate in
+	// addition to the ones listed in Hosts.
+	CellName string `json:"cellName,omitempty"`
+}
+
+// NovaAggregateStatus defines the observed state of NovaAggregate
+type NovaAggregateStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// AggregateID is the ID of the host aggregate in the compute API
+	AggregateID int `json:"aggregateID,omitempty"`
+
+	// Hosts is the list of hosts that are members of the aggregate in the
+	// compute API
+	Hosts []string `json:"hosts,omitempty"`
+
+	// MissingHosts is the list of requested hosts that are not registered as
+	// compute services in the compute API so they cannot be added to the
+	// aggregate yet
+	MissingHosts []string `json:"missingHosts,omitempty"`
+
+	// FailedHosts is the list of requested hosts the compute API refused to
+	// add to the aggregate together with the reason, e.g. because the host
+	// is already in another availability zone
+	FailedHosts []string `json:"failedHosts,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="AggregateID",type="integer",JSONPath=".status.aggregateID",description="AggregateID"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// NovaAggregate is the Schema for the novaaggregates API
+type NovaAggregate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NovaAggregateSpec   `json:"spec,omitempty"`
+	Status NovaAggregateStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NovaAggregateList contains a list of NovaAggregate
+type NovaAggregateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NovaAggregate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NovaAggregate{}, &NovaAggregateList{})
+}
+
+// GetConditions returns the list of conditions from the status
+func (s NovaAggregateStatus) GetConditions() condition.Conditions {
+	return s.Conditions
+}
+
+// IsReady returns true if the aggregate is in sync with the compute API
+func (instance NovaAggregate) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
+
+// GetAggregateName returns the name of the host aggregate in the compute API
+func (instance NovaAggregate) GetAggregateName() string {
+	if instance.Spec.AggregateName != "" {
+		return instance.Spec.AggregateName
+	}
+	return instance.Name
+}
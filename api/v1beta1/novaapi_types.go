@@ -117,6 +117,36 @@ type NovaAPISpec struct {
 	// by the service for authentication and authorization
 	KeystoneAuthURL string `json:"keystoneAuthURL"`
 
+	// +kubebuilder:validation:Optional
+	// KeystoneServiceIdentity defines the keystone region, and the domain
+	// and project of the ServiceUser
+	KeystoneServiceIdentity `json:",inline"`
+
+	// +kubebuilder:validation:Optional
+	// Telemetry - defines the integration with the telemetry stack
+	Telemetry NovaTelemetry `json:"telemetry"`
+
+	// +kubebuilder:validation:Optional
+	// ComputeRPCPin - the compute RPC version pinned via
+	// [upgrade_levels]compute. Defaults to auto if empty.
+	ComputeRPCPin string `json:"computeRPCPin,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=0
+	// RolloutDeadline - the number of seconds a new container image or
+	// config of a nova service has to become ready before the service is
+	// rolled back to its last known-good image and config. 0 disables the
+	// rollback.
+	RolloutDeadline int `json:"rolloutDeadline"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// ConfigRevisionHistoryLimit - the number of rendered config revisions
+	// kept per nova service
+	ConfigRevisionHistoryLimit int `json:"configRevisionHistoryLimit"`
+
 	// +kubebuilder:validation:Required
 	// KeystonePublicAuthURL configures the public keystone API endpoint. This
 	// can be different from KeystoneAuthURL. The service uses this value
@@ -200,6 +230,14 @@ type NovaAPIStatus struct {
 
 	// LastAppliedTopology - the last applied Topology
 	LastAppliedTopology *topologyv1.TopoRef `json:"lastAppliedTopology,omitempty"`
+
+	// Rollout - tracks the rollout of the container image and config of the
+	// service to roll it back if it does not become ready in time
+	Rollout NovaRolloutStatus `json:"rollout,omitempty"`
+
+	// ConfigRevision - the name of the config revision Secret holding the
+	// config the service is deployed with
+	ConfigRevision string `json:"configRevision,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -240,6 +278,32 @@ func (n NovaAPI) GetSecret() string {
 	return n.Spec.Secret
 }
 
+// IsReady returns true if the NovaAPI reconciled successfully
+func (instance NovaAPI) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
+
+// GetKeystoneAuthURL returns the KeystoneAuthURL from the Spec
+func (n NovaAPI) GetKeystoneAuthURL() string {
+	return n.Spec.KeystoneAuthURL
+}
+
+// GetKeystoneUser returns the Service user from the Spec
+func (n NovaAPI) GetKeystoneUser() string {
+	return n.Spec.ServiceUser
+}
+
+// GetCABundleSecretName returns the TLS CA bundle name from the Spec
+func (n NovaAPI) GetCABundleSecretName() string {
+	return n.Spec.TLS.CaBundleSecretName
+}
+
+// GetKeystoneServiceIdentity returns the keystone region, domains and project
+// of the ServiceUser from the Spec
+func (n NovaAPI) GetKeystoneServiceIdentity() KeystoneServiceIdentity {
+	return n.Spec.KeystoneServiceIdentity
+}
+
 // GetSpecTopologyRef - Returns the LastAppliedTopology Set in the Status
 func (instance *NovaAPI) GetSpecTopologyRef() *topologyv1.TopoRef {
 	return instance.Spec.TopologyRef
@@ -51,6 +51,13 @@ type NovaCellTemplate struct {
 	// communicate in this cell. For cell0 it is unused.
 	CellMessageBusInstance string `json:"cellMessageBusInstance"`
 
+	// +kubebuilder:validation:Optional
+	// NotificationsBusInstance is the name of the RabbitMqCluster CR to
+	// select the Message Bus Service instance used by the nova services in
+	// this cell to publish notifications. If defined then this takes
+	// precedence over Nova.Spec.NotificationsBusInstance for this cell.
+	NotificationsBusInstance *string `json:"notificationsBusInstance,omitempty"`
+
 	// +kubebuilder:validation:Required
 	// HasAPIAccess defines if this Cell is configured to have access to the
 	// API DB and message bus.
@@ -99,6 +106,38 @@ type NovaCellTemplate struct {
 	// +kubebuilder:validation:Optional
 	// DBPurge defines the parameters for the DB archiving and purging cron job
 	DBPurge NovaCellDBPurge `json:"dbPurge"`
+
+	// +kubebuilder:validation:Optional
+	// Audit defines the parameters for the consistency audit cron job of the
+	// cell. It cannot be enabled for cell0 as it has no computes.
+	Audit NovaCellAudit `json:"audit"`
+
+	// +kubebuilder:validation:Optional
+	// AvailabilityZone - if defined then a NovaAggregate is created for the
+	// cell that exposes every compute of the cell in this availability zone.
+	// It cannot be defined for cell0 as it has no computes.
+	AvailabilityZone string `json:"availabilityZone,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Images - overrides the container images of the services of this cell
+	// only, e.g. to canary a hotfix image in a single cell. The overridden
+	// images are deployed directly without the upgrade check and the ordered
+	// upgrade of the Nova images.
+	Images *NovaCellImageOverrides `json:"images,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// Disabled - disables the cell in its cell mapping so that the scheduler
+	// does not select the computes of the cell for new instances. The
+	// existing instances of the cell are kept. It cannot be set for cell0.
+	Disabled bool `json:"disabled"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// ScaleDownServices - scales the conductor, metadata and novncproxy
+	// services of the cell to zero while the cell is disabled. The DB and the
+	// message bus of the cell are kept intact.
+	ScaleDownServices bool `json:"scaleDownServices"`
 }
 
 // NovaCellSpec defines the desired state of NovaCell
@@ -141,6 +180,50 @@ type NovaCellSpec struct {
 	// to keystone
 	KeystoneAuthURL string `json:"keystoneAuthURL"`
 
+	// +kubebuilder:validation:Optional
+	// KeystoneServiceIdentity defines the keystone region, and the domain
+	// and project of the ServiceUser
+	KeystoneServiceIdentity `json:",inline"`
+
+	// +kubebuilder:validation:Optional
+	// Telemetry - defines the integration with the telemetry stack
+	Telemetry NovaTelemetry `json:"telemetry"`
+
+	// +kubebuilder:validation:Optional
+	// ComputeRPCPin - the compute RPC version pinned via
+	// [upgrade_levels]compute. Defaults to auto if empty.
+	ComputeRPCPin string `json:"computeRPCPin,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=0
+	// RolloutDeadline - the number of seconds a new container image or
+	// config of a nova service has to become ready before the service is
+	// rolled back to its last known-good image and config. 0 disables the
+	// rollback.
+	RolloutDeadline int `json:"rolloutDeadline"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// ConfigRevisionHistoryLimit - the number of rendered config revisions
+	// kept per nova service
+	ConfigRevisionHistoryLimit int `json:"configRevisionHistoryLimit"`
+
+	// +kubebuilder:validation:Optional
+	// OnlineDataMigrationsDeferred - the online data migrations of the cell
+	// DB are not run while it is true. Nova sets it while it rolls out new
+	// images to the services that are upgraded after the conductors.
+	OnlineDataMigrationsDeferred bool `json:"onlineDataMigrationsDeferred,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=0
+	// StaleServiceGracePeriod - the number of seconds a nova service of a
+	// removed pod needs to be reported down before the service and the
+	// placement resource providers of its compute nodes are deleted
+	StaleServiceGracePeriod int `json:"staleServiceGracePeriod"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=nova
 	// APIDatabaseAccount - MariaDBAccount to use when accessing the API DB
@@ -203,6 +286,10 @@ type NovaCellSpec struct {
 	// DBPurge defines the parameters for the DB archiving and purging cron job
 	DBPurge NovaCellDBPurge `json:"dbPurge"`
 
+	// +kubebuilder:validation:Optional
+	// Audit defines the parameters for the consistency audit cron job
+	Audit NovaCellAudit `json:"audit"`
+
 	// +kubebuilder:validation:Required
 	NovaCellImages `json:",inline"`
 
@@ -210,6 +297,15 @@ type NovaCellSpec struct {
 	// TopologyRef to apply the Topology defined by the associated CR referenced
 	// by name
 	TopologyRef *topologyv1.TopoRef `json:"topologyRef,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Disabled - the cell is disabled for scheduling in its cell mapping
+	Disabled bool `json:"disabled,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ScaleDownServices - the conductor, metadata and novncproxy services of
+	// the cell are scaled to zero while the cell is disabled
+	ScaleDownServices bool `json:"scaleDownServices,omitempty"`
 }
 
 // NovaCellDBPurge defines the parameters for the DB archiving and purging
@@ -234,6 +330,104 @@ type NovaCellDBPurge struct {
 	// PurgeAge defines the minimum age of the records in days that can be
 	// deleted from the shadow tables
 	PurgeAge *int `json:"purgeAge"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=1000
+	// +kubebuilder:validation:Minimum=1
+	// MaxRows defines the maximum number of deleted rows to archive in a
+	// single batch. The archiving is repeated until every deleted row is
+	// archived.
+	MaxRows *int `json:"maxRows"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// DryRun - only reports the number of rows that would be archived and
+	// purged without changing the DB
+	DryRun bool `json:"dryRun"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	// ArchiveTaskLog defines if the records of the task_log table are
+	// deleted as well
+	ArchiveTaskLog *bool `json:"archiveTaskLog"`
+}
+
+// NovaCellDBPurgeStatus defines the result of the runs of the DB archiving
+// and purging cron job
+type NovaCellDBPurgeStatus struct {
+	// LastSuccessTime - the time the last successful run finished
+	LastSuccessTime *metav1.Time `json:"lastSuccessTime,omitempty"`
+
+	// ArchivedRows - number of rows archived by the last successful run, or
+	// the number of rows it would archive in dry run mode
+	ArchivedRows int `json:"archivedRows"`
+
+	// PurgedRows - number of rows purged by the last successful run, or the
+	// number of rows it would purge in dry run mode
+	PurgedRows int `json:"purgedRows"`
+
+	// DryRun - the last successful run was a dry run
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// LastFailureTime - the time the last failed run finished
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+
+	// LastFailureReason - the reason of the failure of the last failed run
+	LastFailureReason string `json:"lastFailureReason,omitempty"`
+}
+
+// NovaCellAudit defines the parameters for the cron job that periodically
+// audits the consistency of the cell with placement and the host mappings
+type NovaCellAudit struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// Enabled - runs the consistency audit of the cell periodically
+	Enabled bool `json:"enabled"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default="0 1 * * *"
+	// Schedule defines when to run the audit in a cron format. By default it
+	// runs every day at 1 AM.
+	Schedule *string `json:"schedule"`
+}
+
+// NovaCellAuditStatus defines the findings of the last consistency audit of
+// the cell
+type NovaCellAuditStatus struct {
+	// LastAuditTime - the time the last audit finished
+	LastAuditTime metav1.Time `json:"lastAuditTime"`
+
+	// OrphanedAllocations - number of allocations against the compute nodes
+	// of the cell in placement that are not related to any instance or
+	// active migration
+	OrphanedAllocations int `json:"orphanedAllocations"`
+
+	// UnmappedHosts - compute hosts of the cell without a host mapping in
+	// the nova_api database
+	UnmappedHosts []string `json:"unmappedHosts,omitempty"`
+
+	// AggregatesInSync - false if the host aggregates could not be mirrored
+	// to placement
+	AggregatesInSync bool `json:"aggregatesInSync"`
+
+	// Errors - the audit steps that failed to run
+	Errors []string `json:"errors,omitempty"`
+}
+
+// IsConsistent returns true if the audit found no inconsistency
+func (s NovaCellAuditStatus) IsConsistent() bool {
+	return s.OrphanedAllocations == 0 && len(s.UnmappedHosts) == 0 &&
+		s.AggregatesInSync && len(s.Errors) == 0
+}
+
+// NovaCellHostMappingsStatus defines the compute hosts mapped to the cell in
+// the nova_api database
+type NovaCellHostMappingsStatus struct {
+	// LastReportTime - the time the hosts were last reported
+	LastReportTime metav1.Time `json:"lastReportTime"`
+
+	// Hosts - the compute hosts with a host mapping to the cell
+	Hosts []string `json:"hosts,omitempty"`
 }
 
 // NovaCellStatus defines the observed state of NovaCell
@@ -266,6 +460,35 @@ type NovaCellStatus struct {
 	// related NovaCompute CR and then remove the compute from this Status field.
 	NovaComputesStatus map[string]NovaComputeCellStatus `json:"novaComputesStatus,omitempty"`
 
+	// Capacity is the number of instances, the state of the compute services
+	// and the hypervisor resource totals and usage of the compute hosts of
+	// the cell. It is periodically collected from the compute API.
+	Capacity *NovaCapacity `json:"capacity,omitempty"`
+
+	// OnlineDataMigrationImage is the conductor container image the online
+	// data migrations of the cell were last completed with
+	OnlineDataMigrationImage string `json:"onlineDataMigrationImage,omitempty"`
+
+	// Disabled is true if the cell is disabled for scheduling. The services
+	// of the cell are scaled to zero as well if ScaleDownServices is set.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// CellMapping is the cell mapping of the cell in the nova_api database
+	// as reported by the last cell mapping job
+	CellMapping *NovaCellMapping `json:"cellMapping,omitempty"`
+
+	// Audit is the findings of the last consistency audit of the cell
+	Audit *NovaCellAuditStatus `json:"audit,omitempty"`
+
+	// HostMappings is the list of compute hosts mapped to the cell as
+	// periodically reported from the nova_api database. It covers every
+	// compute host of the cell, not just the ones deployed by NovaCompute.
+	HostMappings *NovaCellHostMappingsStatus `json:"hostMappings,omitempty"`
+
+	// DBPurge is the result of the runs of the DB archiving and purging cron
+	// job of the cell as reported by its conductor
+	DBPurge *NovaCellDBPurgeStatus `json:"dbPurge,omitempty"`
+
 	// ObservedGeneration - the most recent generation observed for this
 	// service. If the observed generation is less than the spec generation,
 	// then the controller has not processed the latest changes injected by
@@ -312,3 +535,9 @@ func (instance NovaCell) IsReady() bool {
 func (n NovaCell) GetSecret() string {
 	return n.Spec.Secret
 }
+
+// ServicesScaledDown returns true if the conductor, metadata and novncproxy
+// services of the cell need to run with zero replicas
+func (s NovaCellSpec) ServicesScaledDown() bool {
+	return s.Disabled && s.ScaleDownServices
+}
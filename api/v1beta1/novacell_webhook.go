@@ -174,6 +174,10 @@ func (r *NovaCellSpec) validate(basePath *field.Path, namespace string) field.Er
 		errors,
 		r.DBPurge.Validate(basePath.Child("dbPurge"))...,
 	)
+	errors = append(
+		errors,
+		r.Audit.Validate(basePath.Child("audit"))...,
+	)
 
 	return errors
 }
@@ -101,6 +101,28 @@ type NovaComputeSpec struct {
 	// +kubebuilder:validation:Required
 	KeystoneAuthURL string `json:"keystoneAuthURL"`
 
+	// +kubebuilder:validation:Optional
+	// KeystoneServiceIdentity defines the keystone region, and the domain
+	// and project of the ServiceUser
+	KeystoneServiceIdentity `json:",inline"`
+
+	// +kubebuilder:validation:Optional
+	// Telemetry - defines the integration with the telemetry stack
+	Telemetry NovaTelemetry `json:"telemetry"`
+
+	// +kubebuilder:validation:Optional
+	// ComputeRPCPin - the compute RPC version pinned via
+	// [upgrade_levels]compute. Defaults to auto if empty.
+	ComputeRPCPin string `json:"computeRPCPin,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=0
+	// StaleServiceGracePeriod - the number of seconds a nova service of a
+	// removed pod needs to be reported down before the service and the
+	// placement resource providers of its compute nodes are deleted
+	StaleServiceGracePeriod int `json:"staleServiceGracePeriod"`
+
 	// +kubebuilder:validation:Required
 	// NovaServiceBase specifies the generic fields of the service
 	NovaServiceBase `json:",inline"`
@@ -197,6 +219,27 @@ func (n NovaCompute) GetSecret() string {
 	return n.Spec.Secret
 }
 
+// GetKeystoneAuthURL returns the KeystoneAuthURL from the Spec
+func (n NovaCompute) GetKeystoneAuthURL() string {
+	return n.Spec.KeystoneAuthURL
+}
+
+// GetKeystoneUser returns the Service user from the Spec
+func (n NovaCompute) GetKeystoneUser() string {
+	return n.Spec.ServiceUser
+}
+
+// GetCABundleSecretName returns the TLS CA bundle name from the Spec
+func (n NovaCompute) GetCABundleSecretName() string {
+	return n.Spec.TLS.CaBundleSecretName
+}
+
+// GetKeystoneServiceIdentity returns the keystone region, domains and project
+// of the ServiceUser from the Spec
+func (n NovaCompute) GetKeystoneServiceIdentity() KeystoneServiceIdentity {
+	return n.Spec.KeystoneServiceIdentity
+}
+
 // IsReady returns true if the Cell reconciled successfully
 func (instance NovaCompute) IsReady() bool {
 	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
@@ -221,12 +264,16 @@ func NewNovaComputeSpec(
 			Resources:           computeTemplate.Resources,
 			NetworkAttachments:  computeTemplate.NetworkAttachments,
 		},
-		KeystoneAuthURL:        novaCell.KeystoneAuthURL,
-		ServiceUser:            novaCell.ServiceUser,
-		ServiceAccount:         novaCell.ServiceAccount,
-		ComputeDriver:          computeTemplate.ComputeDriver,
-		TLS:                    novaCell.TLS,
-		DefaultConfigOverwrite: computeTemplate.DefaultConfigOverwrite,
+		KeystoneAuthURL:         novaCell.KeystoneAuthURL,
+		ServiceUser:             novaCell.ServiceUser,
+		KeystoneServiceIdentity: novaCell.KeystoneServiceIdentity,
+		Telemetry:               novaCell.Telemetry,
+		ComputeRPCPin:           novaCell.ComputeRPCPin,
+		StaleServiceGracePeriod: novaCell.StaleServiceGracePeriod,
+		ServiceAccount:          novaCell.ServiceAccount,
+		ComputeDriver:           computeTemplate.ComputeDriver,
+		TLS:                     novaCell.TLS,
+		DefaultConfigOverwrite:  computeTemplate.DefaultConfigOverwrite,
 	}
 
 	if novaComputeSpec.NodeSelector == nil {
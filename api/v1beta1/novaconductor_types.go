@@ -21,6 +21,7 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 )
 
@@ -90,6 +91,44 @@ type NovaConductorSpec struct {
 	// talk to keystone
 	KeystoneAuthURL string `json:"keystoneAuthURL"`
 
+	// +kubebuilder:validation:Optional
+	// KeystoneServiceIdentity defines the keystone region, and the domain
+	// and project of the ServiceUser
+	KeystoneServiceIdentity `json:",inline"`
+
+	// +kubebuilder:validation:Optional
+	// Telemetry - defines the integration with the telemetry stack
+	Telemetry NovaTelemetry `json:"telemetry"`
+
+	// +kubebuilder:validation:Optional
+	// ComputeRPCPin - the compute RPC version pinned via
+	// [upgrade_levels]compute. Defaults to auto if empty.
+	ComputeRPCPin string `json:"computeRPCPin,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=0
+	// RolloutDeadline - the number of seconds a new container image or
+	// config of a nova service has to become ready before the service is
+	// rolled back to its last known-good image and config. 0 disables the
+	// rollback.
+	RolloutDeadline int `json:"rolloutDeadline"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// ConfigRevisionHistoryLimit - the number of rendered config revisions
+	// kept per nova service
+	ConfigRevisionHistoryLimit int `json:"configRevisionHistoryLimit"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=0
+	// StaleServiceGracePeriod - the number of seconds a nova service of a
+	// removed pod needs to be reported down before the service and the
+	// placement resource providers of its compute nodes are deleted
+	StaleServiceGracePeriod int `json:"staleServiceGracePeriod"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=nova
 	// APIDatabaseAccount - MariaDBAccount to use when accessing the API DB
@@ -164,6 +203,18 @@ type NovaConductorStatus struct {
 
 	// LastAppliedTopology - the last applied Topology
 	LastAppliedTopology *topologyv1.TopoRef `json:"lastAppliedTopology,omitempty"`
+
+	// Rollout - tracks the rollout of the container image and config of the
+	// service to roll it back if it does not become ready in time
+	Rollout NovaRolloutStatus `json:"rollout,omitempty"`
+
+	// ConfigRevision - the name of the config revision Secret holding the
+	// config the service is deployed with
+	ConfigRevision string `json:"configRevision,omitempty"`
+
+	// DBPurge - the result of the runs of the DB archiving and purging cron
+	// job
+	DBPurge *NovaCellDBPurgeStatus `json:"dbPurge,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -219,13 +270,19 @@ func NewNovaConductorSpec(
 			Resources:           novaCell.ConductorServiceTemplate.Resources,
 			NetworkAttachments:  novaCell.ConductorServiceTemplate.NetworkAttachments,
 		},
-		KeystoneAuthURL:   novaCell.KeystoneAuthURL,
-		ServiceUser:       novaCell.ServiceUser,
-		ServiceAccount:    novaCell.ServiceAccount,
-		TLS:               novaCell.TLS,
-		PreserveJobs:      novaCell.PreserveJobs,
-		MemcachedInstance: novaCell.MemcachedInstance,
-		DBPurge:           novaCell.DBPurge,
+		KeystoneAuthURL:            novaCell.KeystoneAuthURL,
+		ServiceUser:                novaCell.ServiceUser,
+		KeystoneServiceIdentity:    novaCell.KeystoneServiceIdentity,
+		Telemetry:                  novaCell.Telemetry,
+		ComputeRPCPin:              novaCell.ComputeRPCPin,
+		RolloutDeadline:            novaCell.RolloutDeadline,
+		ConfigRevisionHistoryLimit: novaCell.ConfigRevisionHistoryLimit,
+		StaleServiceGracePeriod:    novaCell.StaleServiceGracePeriod,
+		ServiceAccount:             novaCell.ServiceAccount,
+		TLS:                        novaCell.TLS,
+		PreserveJobs:               novaCell.PreserveJobs,
+		MemcachedInstance:          novaCell.MemcachedInstance,
+		DBPurge:                    novaCell.DBPurge,
 	}
 
 	if conductorSpec.NodeSelector == nil {
@@ -236,6 +293,10 @@ func NewNovaConductorSpec(
 		conductorSpec.TopologyRef = novaCell.TopologyRef
 	}
 
+	if novaCell.ServicesScaledDown() {
+		conductorSpec.Replicas = ptr.To[int32](0)
+	}
+
 	return conductorSpec
 }
 
@@ -259,6 +320,12 @@ func (n NovaConductor) GetCABundleSecretName() string {
 	return n.Spec.TLS.CaBundleSecretName
 }
 
+// GetKeystoneServiceIdentity returns the keystone region, domains and project
+// of the ServiceUser from the Spec
+func (n NovaConductor) GetKeystoneServiceIdentity() KeystoneServiceIdentity {
+	return n.Spec.KeystoneServiceIdentity
+}
+
 // GetSpecTopologyRef - Returns the LastAppliedTopology Set in the Status
 func (instance *NovaConductor) GetSpecTopologyRef() *topologyv1.TopoRef {
 	return instance.Spec.TopologyRef
@@ -0,0 +1,144 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NovaDatabaseBackupSpec defines the desired state of NovaDatabaseBackup
+type NovaDatabaseBackupSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=nova
+	// NovaInstance is the name of the Nova CR in the same namespace whose
+	// databases are backed up
+	NovaInstance string `json:"novaInstance"`
+
+	// +kubebuilder:validation:Optional
+	// Cells are the names of the cells, e.g. cell1, whose DB is backed up
+	// together with the API DB. Every cell of the Nova deployment is backed
+	// up if empty.
+	Cells []string `json:"cells,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// Schedule is the cron schedule of the backups, e.g. "0 2 * * *". The
+	// backup is taken once if not set.
+	Schedule *string `json:"schedule,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// StorageRequest is the size of the PVC the backups are written to,
+	// e.g. 10G
+	StorageRequest string `json:"storageRequest"`
+
+	// +kubebuilder:validation:Optional
+	// StorageClass is the storage class of the PVC the backups are written
+	// to. The default storage class is used if not set.
+	StorageClass string `json:"storageClass,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	// MaxBackups is the number of backups kept on the PVC. The oldest backup
+	// is removed when a new backup is taken.
+	MaxBackups int `json:"maxBackups"`
+
+	// +kubebuilder:validation:Optional
+	// ContainerImage is the image used to run mysqldump. It needs to provide
+	// the mysql client tools. Defaults to the conductor image of cell0.
+	ContainerImage string `json:"containerImage,omitempty"`
+}
+
+// NovaDatabaseBackupRecord describes a backup taken on the PVC
+type NovaDatabaseBackupRecord struct {
+	// Time is the time the backup was completed
+	Time metav1.Time `json:"time"`
+
+	// Path is the directory of the backup on the PVC
+	Path string `json:"path"`
+
+	// Cells are the names of the cells whose DB is in the backup. The API DB
+	// is always in the backup.
+	Cells []string `json:"cells"`
+
+	// SchemaVersions are the schema versions of the backed up databases,
+	// keyed by the name of the database, e.g. nova_api
+	SchemaVersions map[string]string `json:"schemaVersions,omitempty"`
+
+	// ContainerImage is the conductor image of cell0 the Nova deployment
+	// was running when the backup was taken
+	ContainerImage string `json:"containerImage"`
+}
+
+// NovaDatabaseBackupStatus defines the observed state of NovaDatabaseBackup
+type NovaDatabaseBackupStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// LastBackup is the last backup completed. This is the backup a
+	// NovaDatabaseRestore restores.
+	LastBackup *NovaDatabaseBackupRecord `json:"lastBackup,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Schedule",type="string",JSONPath=".spec.schedule",description="Schedule"
+//+kubebuilder:printcolumn:name="LastBackup",type="string",JSONPath=".status.lastBackup.time",description="LastBackup"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// NovaDatabaseBackup is the Schema for the novadatabasebackups API. It takes
+// a logical backup of the API DB and the cell DBs of a Nova deployment to a
+// PVC, either once or periodically. The PVC is deleted with the
+// NovaDatabaseBackup.
+type NovaDatabaseBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NovaDatabaseBackupSpec   `json:"spec,omitempty"`
+	Status NovaDatabaseBackupStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NovaDatabaseBackupList contains a list of NovaDatabaseBackup
+type NovaDatabaseBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NovaDatabaseBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NovaDatabaseBackup{}, &NovaDatabaseBackupList{})
+}
+
+// GetConditions returns the list of conditions from the status
+func (s NovaDatabaseBackupStatus) GetConditions() condition.Conditions {
+	return s.Conditions
+}
+
+// IsReady returns true if the backup is taken or the backups are scheduled
+func (instance NovaDatabaseBackup) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
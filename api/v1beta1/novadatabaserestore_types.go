@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NovaDatabaseRestoreSpec defines the desired state of NovaDatabaseRestore
+type NovaDatabaseRestoreSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=nova
+	// NovaInstance is the name of the Nova CR in the same namespace whose
+	// databases are restored
+	NovaInstance string `json:"novaInstance"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// BackupName is the name of the NovaDatabaseBackup in the same namespace
+	// whose last backup is restored
+	BackupName string `json:"backupName"`
+
+	// +kubebuilder:validation:Optional
+	// Cells are the names of the cells, e.g. cell1, whose DB is restored.
+	// Every cell in the backup is restored if empty.
+	Cells []string `json:"cells,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	// RestoreAPIDatabase - restores the API DB as well. The nova-api,
+	// nova-scheduler and the top level nova-metadata services and the
+	// services of cell0 and of every cell with API DB access need to be
+	// scaled down for that.
+	RestoreAPIDatabase bool `json:"restoreAPIDatabase"`
+}
+
+// NovaDatabaseRestoreStatus defines the observed state of NovaDatabaseRestore
+type NovaDatabaseRestoreStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// Databases are the names of the restored databases
+	Databases []string `json:"databases,omitempty"`
+
+	// BackupPath is the directory of the restored backup on the PVC of the
+	// NovaDatabaseBackup
+	BackupPath string `json:"backupPath,omitempty"`
+
+	// CompletionTime is the time the restore was completed
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Backup",type="string",JSONPath=".spec.backupName",description="Backup"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// NovaDatabaseRestore is the Schema for the novadatabaserestores API. It
+// restores the last backup of a NovaDatabaseBackup once. The restore is
+// refused while the services using the restored databases are running.
+type NovaDatabaseRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NovaDatabaseRestoreSpec   `json:"spec,omitempty"`
+	Status NovaDatabaseRestoreStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NovaDatabaseRestoreList contains a list of NovaDatabaseRestore
+type NovaDatabaseRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NovaDatabaseRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NovaDatabaseRestore{}, &NovaDatabaseRestoreList{})
+}
+
+// GetConditions returns the list of conditions from the status
+func (s NovaDatabaseRestoreStatus) GetConditions() condition.Conditions {
+	return s.Conditions
+}
+
+// IsReady returns true if the restore is completed
+func (instance NovaDatabaseRestore) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
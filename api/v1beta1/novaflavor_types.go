@@ -0,0 +1,134 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NovaFlavorSpec defines the desired state of NovaFlavor
+type NovaFlavorSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=nova
+	// NovaInstance is the name of the Nova CR in the same namespace. The
+	// flavor is managed via the compute API of this Nova deployment.
+	NovaInstance string `json:"novaInstance"`
+
+	// +kubebuilder:validation:Optional
+	// FlavorName is the name of the flavor in the compute API. If not
+	// provided then the name of the NovaFlavor CR is used. If a flavor with
+	// this name already exists then it is adopted.
+	FlavorName string `json:"flavorName,omitempty"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// VCPUs is the number of virtual CPUs of the flavor
+	VCPUs int `json:"vcpus"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	// RAM is the amount of memory of the flavor in MiB
+	RAM int `json:"ram"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=0
+	// +kubebuilder:validation:Minimum=0
+	// Disk is the size of the root disk of the flavor in GiB
+	Disk int `json:"disk"`
+
+	// +kubebuilder:validation:Optional
+	// ExtraSpecs is the set of key value pairs defined as the extra specs
+	// of the flavor. Extra specs on the flavor that are not listed here are
+	// removed.
+	ExtraSpecs map[string]string `json:"extraSpecs,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=true
+	// IsPublic defines the visibility of the flavor. A public flavor is
+	// visible to every project, a private one only to the projects listed in
+	// ProjectAccess.
+	IsPublic bool `json:"isPublic"`
+
+	// +kubebuilder:validation:Optional
+	// ProjectAccess is the list of project IDs that have access to the
+	// flavor. It can only be used if the flavor is not public.
+	ProjectAccess []string `json:"projectAccess,omitempty"`
+}
+
+// NovaFlavorStatus defines the observed state of NovaFlavor
+type NovaFlavorStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// FlavorID is the ID of the flavor in the compute API
+	FlavorID string `json:"flavorID,omitempty"`
+
+	// DriftedFields lists the fields of the flavor in the compute API that
+	// differ from the Spec but cannot be updated in place as the compute API
+	// treats them as immutable.
+	DriftedFields []string `json:"driftedFields,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="FlavorID",type="string",JSONPath=".status.flavorID",description="FlavorID"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// NovaFlavor is the Schema for the novaflavors API
+type NovaFlavor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NovaFlavorSpec   `json:"spec,omitempty"`
+	Status NovaFlavorStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NovaFlavorList contains a list of NovaFlavor
+type NovaFlavorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NovaFlavor `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NovaFlavor{}, &NovaFlavorList{})
+}
+
+// GetConditions returns the list of conditions from the status
+func (s NovaFlavorStatus) GetConditions() condition.Conditions {
+	return s.Conditions
+}
+
+// IsReady returns true if the flavor is in sync with the compute API
+func (instance NovaFlavor) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
+
+// GetFlavorName returns the name of the flavor in the compute API
+func (instance NovaFlavor) GetFlavorName() string {
+	if instance.Spec.FlavorName != "" {
+		return instance.Spec.FlavorName
+	}
+	return instance.Name
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NovaHostDrainInstanceState is the state of the migration of an instance
+// off the drained host
+type NovaHostDrainInstanceState string
+
+const (
+	// NovaHostDrainInstancePending - the instance is waiting for its live
+	// migration to be started
+	NovaHostDrainInstancePending NovaHostDrainInstanceState = "Pending"
+	// NovaHostDrainInstanceMigrating - the live migration of the instance is
+	// in progress
+	NovaHostDrainInstanceMigrating NovaHostDrainInstanceState = "Migrating"
+	// NovaHostDrainInstanceMigrated - the instance is moved off the host
+	NovaHostDrainInstanceMigrated NovaHostDrainInstanceState = "Migrated"
+	// NovaHostDrainInstanceFailed - the instance could not be moved off the
+	// host. It is not retried automatically.
+	NovaHostDrainInstanceFailed NovaHostDrainInstanceState = "Failed"
+)
+
+// NovaHostDrainSpec defines the desired state of NovaHostDrain
+type NovaHostDrainSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=nova
+	// NovaInstance is the name of the Nova CR in the same namespace. The host
+	// is drained via the compute API of this Nova deployment.
+	NovaInstance string `json:"novaInstance"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// Host is the name of the host of the nova-compute service to drain as
+	// it is registered in the compute API
+	Host string `json:"host"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=2
+	// +kubebuilder:validation:Minimum=1
+	// MaxConcurrentMigrations is the maximum number of live migrations
+	// started in parallel from the host
+	MaxConcurrentMigrations int `json:"maxConcurrentMigrations"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// EnableServiceOnDelete - enable the nova-compute service of the host
+	// again when the NovaHostDrain CR is deleted. If false then the service
+	// stays disabled.
+	EnableServiceOnDelete bool `json:"enableServiceOnDelete"`
+}
+
+// NovaHostDrainInstanceStatus defines the observed state of the migration
+// of an instance off the drained host
+type NovaHostDrainInstanceStatus struct {
+	// ID is the UUID of the instance
+	ID string `json:"id"`
+
+	// Name is the name of the instance
+	Name string `json:"name,omitempty"`
+
+	// State is the state of the migration of the instance
+	State NovaHostDrainInstanceState `json:"state"`
+
+	// Message is the reason of the failure if the instance could not be
+	// migrated
+	Message string `json:"message,omitempty"`
+}
+
+// NovaHostDrainStatus defines the observed state of NovaHostDrain
+type NovaHostDrainStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// ServiceID is the UUID of the nova-compute service of the host
+	ServiceID string `json:"serviceID,omitempty"`
+
+	// Instances is the list of instances found on the host and the state of
+	// their migration
+	Instances []NovaHostDrainInstanceStatus `json:"instances,omitempty"`
+
+	// MigratedCount is the number of instances moved off the host
+	MigratedCount int `json:"migratedCount,omitempty"`
+
+	// FailedCount is the number of instances failed to be moved off the
+	// host
+	FailedCount int `json:"failedCount,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Host",type="string",JSONPath=".spec.host",description="Host"
+//+kubebuilder:printcolumn:name="Migrated",type="integer",JSONPath=".status.migratedCount",description="Migrated"
+//+kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failedCount",description="Failed"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// NovaHostDrain is the Schema for the novahostdrains API
+type NovaHostDrain struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NovaHostDrainSpec   `json:"spec,omitempty"`
+	Status NovaHostDrainStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NovaHostDrainList contains a list of NovaHostDrain
+type NovaHostDrainList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NovaHostDrain `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NovaHostDrain{}, &NovaHostDrainList{})
+}
+
+// GetConditions returns the list of conditions from the status
+func (s NovaHostDrainStatus) GetConditions() condition.Conditions {
+	return s.Conditions
+}
+
+// IsReady returns true if every instance is moved off the host
+func (instance NovaHostDrain) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
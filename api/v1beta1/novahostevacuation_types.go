@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NovaHostEvacuationInstanceState is the state of the evacuation of an
+// instance off the failed host
+type NovaHostEvacuationInstanceState string
+
+const (
+	// NovaHostEvacuationInstancePending - the instance is waiting for its
+	// evacuation to be started
+	NovaHostEvacuationInstancePending NovaHostEvacuationInstanceState = "Pending"
+	// NovaHostEvacuationInstanceEvacuating - the evacuation of the instance
+	// is in progress
+	NovaHostEvacuationInstanceEvacuating NovaHostEvacuationInstanceState = "Evacuating"
+	// NovaHostEvacuationInstanceEvacuated - the instance is rebuilt on
+	// another host
+	NovaHostEvacuationInstanceEvacuated NovaHostEvacuationInstanceState = "Evacuated"
+	// NovaHostEvacuationInstanceFailed - the instance could not be evacuated.
+	// It is not retried automatically.
+	NovaHostEvacuationInstanceFailed NovaHostEvacuationInstanceState = "Failed"
+)
+
+// NovaHostEvacuationSpec defines the desired state of NovaHostEvacuation
+type NovaHostEvacuationSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=nova
+	// NovaInstance is the name of the Nova CR in the same namespace. The host
+	// is evacuated via the compute API of this Nova deployment.
+	NovaInstance string `json:"novaInstance"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// Host is the name of the host of the failed nova-compute service as it
+	// is registered in the compute API. The host is only evacuated if the
+	// compute API reports its service down.
+	Host string `json:"host"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=2
+	// +kubebuilder:validation:Minimum=1
+	// MaxConcurrentEvacuations is the maximum number of evacuations started
+	// in parallel from the host
+	MaxConcurrentEvacuations int `json:"maxConcurrentEvacuations"`
+
+	// +kubebuilder:validation:Optional
+	// ServerTags - if provided then only the instances having all of these
+	// tags are evacuated
+	ServerTags []string `json:"serverTags,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// ServerMetadata - if provided then only the instances having all of
+	// these metadata key value pairs are evacuated
+	ServerMetadata map[string]string `json:"serverMetadata,omitempty"`
+}
+
+// NovaHostEvacuationInstanceStatus defines the observed state of the
+// evacuation of an instance off the failed host
+type NovaHostEvacuationInstanceStatus struct {
+	// ID is the UUID of the instance
+	ID string `json:"id"`
+
+	// Name is the name of the instance
+	Name string `json:"name,omitempty"`
+
+	// State is the state of the evacuation of the instance
+	State NovaHostEvacuationInstanceState `json:"state"`
+
+	// Message is the reason of the failure if the instance could not be
+	// evacuated
+	Message string `json:"message,omitempty"`
+}
+
+// NovaHostEvacuationStatus defines the observed state of NovaHostEvacuation
+type NovaHostEvacuationStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// ServiceID is the UUID of the nova-compute service of the host
+	ServiceID string `json:"serviceID,omitempty"`
+
+	// ServiceUpdatedAt is the last update time of the nova-compute service
+	// after it is forced down. A later update means that the service is
+	// reporting again so the host is recovered.
+	ServiceUpdatedAt *metav1.Time `json:"serviceUpdatedAt,omitempty"`
+
+	// Recovered is true if the host came back after the evacuation and the
+	// forced down flag of its service is unset
+	Recovered bool `json:"recovered,omitempty"`
+
+	// Instances is the list of instances found on the host and the state of
+	// their evacuation
+	Instances []NovaHostEvacuationInstanceStatus `json:"instances,omitempty"`
+
+	// EvacuatedCount is the number of instances rebuilt on another host
+	EvacuatedCount int `json:"evacuatedCount,omitempty"`
+
+	// FailedCount is the number of instances failed to be evacuated
+	FailedCount int `json:"failedCount,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Host",type="string",JSONPath=".spec.host",description="Host"
+//+kubebuilder:printcolumn:name="Evacuated",type="integer",JSONPath=".status.evacuatedCount",description="Evacuated"
+//+kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failedCount",description="Failed"
+//+kubebuilder:printcolumn:name="Recovered",type="boolean",JSONPath=".status.recovered",description="Recovered"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// NovaHostEvacuation is the Schema for the novahostevacuations API
+type NovaHostEvacuation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NovaHostEvacuationSpec   `json:"spec,omitempty"`
+	Status NovaHostEvacuationStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NovaHostEvacuationList contains a list of NovaHostEvacuation
+type NovaHostEvacuationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NovaHostEvacuation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NovaHostEvacuation{}, &NovaHostEvacuationList{})
+}
+
+// GetConditions returns the list of conditions from the status
+func (s NovaHostEvacuationStatus) GetConditions() condition.Conditions {
+	return s.Conditions
+}
+
+// IsReady returns true if every instance is evacuated off the host
+func (instance NovaHostEvacuation) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
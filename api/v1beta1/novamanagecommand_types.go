@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NovaManageSubcommand is a nova-manage command and subcommand that is
+// allowed to be run via a NovaManageCommand
+// +kubebuilder:validation:Enum="placement heal_allocations";"cell_v2 verify_instance";"db archive_deleted_rows"
+type NovaManageSubcommand string
+
+const (
+	// NovaManagePlacementHealAllocations - nova-manage placement
+	// heal_allocations
+	NovaManagePlacementHealAllocations NovaManageSubcommand = "placement heal_allocations"
+	// NovaManageCellV2VerifyInstance - nova-manage cell_v2 verify_instance
+	NovaManageCellV2VerifyInstance NovaManageSubcommand = "cell_v2 verify_instance"
+	// NovaManageDBArchiveDeletedRows - nova-manage db archive_deleted_rows
+	NovaManageDBArchiveDeletedRows NovaManageSubcommand = "db archive_deleted_rows"
+)
+
+// NovaManageCommandOutputLimit is the number of bytes kept from the end of
+// the output of the command. It is the size limit of the termination
+// message of a pod.
+const NovaManageCommandOutputLimit = 4096
+
+// NovaManageCommandSpec defines the desired state of NovaManageCommand
+type NovaManageCommandSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=nova
+	// NovaInstance is the name of the Nova CR in the same namespace. The
+	// command is run with the nova-manage config this Nova deployment
+	// generates.
+	NovaInstance string `json:"novaInstance"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// CellName is the name of the cell of the Nova deployment, e.g. cell1,
+	// the command targets. The command uses the config, the cell DB and the
+	// conductor container image of this cell.
+	CellName string `json:"cellName"`
+
+	// +kubebuilder:validation:Required
+	// Command is the allow-listed nova-manage command and subcommand to run
+	Command NovaManageSubcommand `json:"command"`
+
+	// +kubebuilder:validation:Optional
+	// Args are the arguments passed to the nova-manage subcommand, e.g.
+	// ["--max-count", "100"]. They are passed as is without shell expansion.
+	Args []string `json:"args,omitempty"`
+}
+
+// NovaManageCommandStatus defines the observed state of NovaManageCommand
+type NovaManageCommandStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// Map of hashes to track e.g. job status
+	Hash map[string]string `json:"hash,omitempty"`
+
+	// ExitCode is the exit code of the nova-manage command. Its meaning is
+	// specific to the subcommand, e.g. db archive_deleted_rows exits with 1
+	// if rows were archived.
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// Output is the end of the output of the nova-manage command truncated
+	// to NovaManageCommandOutputLimit bytes
+	Output string `json:"output,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Command",type="string",JSONPath=".spec.command",description="Command"
+//+kubebuilder:printcolumn:name="Cell",type="string",JSONPath=".spec.cellName",description="Cell"
+//+kubebuilder:printcolumn:name="ExitCode",type="integer",JSONPath=".status.exitCode",description="ExitCode"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// NovaManageCommand is the Schema for the novamanagecommands API. The command
+// is run once, the NovaManageCommand needs to be re-created to run it again.
+type NovaManageCommand struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NovaManageCommandSpec   `json:"spec,omitempty"`
+	Status NovaManageCommandStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NovaManageCommandList contains a list of NovaManageCommand
+type NovaManageCommandList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NovaManageCommand `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NovaManageCommand{}, &NovaManageCommandList{})
+}
+
+// GetConditions returns the list of conditions from the status
+func (s NovaManageCommandStatus) GetConditions() condition.Conditions {
+	return s.Conditions
+}
+
+// IsReady returns true if the command is run to completion
+func (instance NovaManageCommand) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
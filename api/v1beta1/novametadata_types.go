@@ -22,6 +22,7 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 )
 
@@ -134,6 +135,36 @@ type NovaMetadataSpec struct {
 	// TODO(ksambor) Add checking if dynamic vendor data is configured
 	KeystoneAuthURL string `json:"keystoneAuthURL"`
 
+	// +kubebuilder:validation:Optional
+	// KeystoneServiceIdentity defines the keystone region, and the domain
+	// and project of the ServiceUser
+	KeystoneServiceIdentity `json:",inline"`
+
+	// +kubebuilder:validation:Optional
+	// Telemetry - defines the integration with the telemetry stack
+	Telemetry NovaTelemetry `json:"telemetry"`
+
+	// +kubebuilder:validation:Optional
+	// ComputeRPCPin - the compute RPC version pinned via
+	// [upgrade_levels]compute. Defaults to auto if empty.
+	ComputeRPCPin string `json:"computeRPCPin,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=0
+	// RolloutDeadline - the number of seconds a new container image or
+	// config of a nova service has to become ready before the service is
+	// rolled back to its last known-good image and config. 0 disables the
+	// rollback.
+	RolloutDeadline int `json:"rolloutDeadline"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// ConfigRevisionHistoryLimit - the number of rendered config revisions
+	// kept per nova service
+	ConfigRevisionHistoryLimit int `json:"configRevisionHistoryLimit"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default="nova-api"
 	// APIDatabaseAccount - MariaDBAccount to use when accessing the API DB
@@ -218,6 +249,14 @@ type NovaMetadataStatus struct {
 
 	// LastAppliedTopology - the last applied Topology
 	LastAppliedTopology *topologyv1.TopoRef `json:"lastAppliedTopology,omitempty"`
+
+	// Rollout - tracks the rollout of the container image and config of the
+	// service to roll it back if it does not become ready in time
+	Rollout NovaRolloutStatus `json:"rollout,omitempty"`
+
+	// ConfigRevision - the name of the config revision Secret holding the
+	// config the service is deployed with
+	ConfigRevision string `json:"configRevision,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -274,14 +313,19 @@ func NewNovaMetadataSpec(
 			Resources:           novaCell.MetadataServiceTemplate.Resources,
 			NetworkAttachments:  novaCell.MetadataServiceTemplate.NetworkAttachments,
 		},
-		KeystoneAuthURL:        novaCell.KeystoneAuthURL,
-		ServiceUser:            novaCell.ServiceUser,
-		ServiceAccount:         novaCell.ServiceAccount,
-		Override:               novaCell.MetadataServiceTemplate.Override,
-		TLS:                    novaCell.MetadataServiceTemplate.TLS,
-		DefaultConfigOverwrite: novaCell.MetadataServiceTemplate.DefaultConfigOverwrite,
-		MemcachedInstance:      novaCell.MemcachedInstance,
-		APITimeout:             novaCell.APITimeout,
+		KeystoneAuthURL:            novaCell.KeystoneAuthURL,
+		ServiceUser:                novaCell.ServiceUser,
+		KeystoneServiceIdentity:    novaCell.KeystoneServiceIdentity,
+		Telemetry:                  novaCell.Telemetry,
+		ComputeRPCPin:              novaCell.ComputeRPCPin,
+		RolloutDeadline:            novaCell.RolloutDeadline,
+		ConfigRevisionHistoryLimit: novaCell.ConfigRevisionHistoryLimit,
+		ServiceAccount:             novaCell.ServiceAccount,
+		Override:                   novaCell.MetadataServiceTemplate.Override,
+		TLS:                        novaCell.MetadataServiceTemplate.TLS,
+		DefaultConfigOverwrite:     novaCell.MetadataServiceTemplate.DefaultConfigOverwrite,
+		MemcachedInstance:          novaCell.MemcachedInstance,
+		APITimeout:                 novaCell.APITimeout,
 	}
 
 	if metadataSpec.NodeSelector == nil {
@@ -292,6 +336,10 @@ func NewNovaMetadataSpec(
 		metadataSpec.TopologyRef = novaCell.TopologyRef
 	}
 
+	if novaCell.ServicesScaledDown() {
+		metadataSpec.Replicas = ptr.To[int32](0)
+	}
+
 	return metadataSpec
 }
 
@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NovaProjectQuotaSpec defines the desired state of NovaProjectQuota
+type NovaProjectQuotaSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=nova
+	// NovaInstance is the name of the Nova CR in the same namespace. The
+	// project quota is managed via the compute API of this Nova deployment.
+	NovaInstance string `json:"novaInstance"`
+
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// ProjectID is the ID of the keystone project the quota applies to
+	ProjectID string `json:"projectID"`
+
+	// +kubebuilder:validation:Optional
+	// NovaQuotaLimits defines the limits of the project. The limits that are
+	// not set are inherited from the default quota class.
+	NovaQuotaLimits `json:",inline"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=false
+	// Force - apply the limits even if they are lower than the current
+	// usage of the project
+	Force bool `json:"force"`
+}
+
+// NovaProjectQuotaStatus defines the observed state of NovaProjectQuota
+type NovaProjectQuotaStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// AppliedLimits are the limits of the project as reported by the
+	// compute API
+	AppliedLimits NovaQuotaLimits `json:"appliedLimits,omitempty"`
+
+	// InUse is the current usage of the project as reported by the compute
+	// API
+	InUse NovaQuotaLimits `json:"inUse,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="ProjectID",type="string",JSONPath=".spec.projectID",description="ProjectID"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// NovaProjectQuota is the Schema for the novaprojectquotas API
+type NovaProjectQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NovaProjectQuotaSpec   `json:"spec,omitempty"`
+	Status NovaProjectQuotaStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NovaProjectQuotaList contains a list of NovaProjectQuota
+type NovaProjectQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NovaProjectQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NovaProjectQuota{}, &NovaProjectQuotaList{})
+}
+
+// GetConditions returns the list of conditions from the status
+func (s NovaProjectQuotaStatus) GetConditions() condition.Conditions {
+	return s.Conditions
+}
+
+// IsReady returns true if the project quota is in sync with the compute API
+func (instance NovaProjectQuota) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+//
+// Generated by:
+//
+// operator-sdk create webhook --group nova --version v1beta1 --kind NovaProjectQuota --programmatic-validation
+//
+
+package v1beta1
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var novaprojectquotalog = logf.Log.WithName("novaprojectquota-resource")
+
+// SetupWebhookWithManager sets up the webhook with the Manager
+func (r *NovaProjectQuota) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-nova-openstack-org-v1beta1-novaprojectquota,mutating=false,failurePolicy=fail,sideEffects=None,groups=nova.openstack.org,resources=novaprojectquotas,verbs=create;update,versions=v1beta1,name=vnovaprojectquota.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &NovaProjectQuota{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *NovaProjectQuota) ValidateCreate() (admission.Warnings, error) {
+	novaprojectquotalog.Info("validate create", "name", r.Name)
+
+	errors := r.Spec.NovaQuotaLimits.Validate(field.NewPath("spec"))
+	if len(errors) != 0 {
+		novaprojectquotalog.Info("validation failed", "name", r.Name)
+		return nil, apierrors.NewInvalid(
+			schema.GroupKind{Group: "nova.openstack.org", Kind: "NovaProjectQuota"},
+			r.Name, errors)
+	}
+	return nil, nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *NovaProjectQuota) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	novaprojectquotalog.Info("validate update", "name", r.Name)
+	basePath := field.NewPath("spec")
+
+	oldProjectQuota, ok := old.(*NovaProjectQuota)
+	if !ok || oldProjectQuota == nil {
+		return nil, apierrors.NewInternalError(fmt.Errorf("unable to convert existing object"))
+	}
+
+	errors := r.Spec.NovaQuotaLimits.Validate(basePath)
+	// changing the project would leave the quota of the old project behind
+	if r.Spec.ProjectID != oldProjectQuota.Spec.ProjectID {
+		errors = append(
+			errors,
+			field.Invalid(
+				basePath.Child("projectID"), r.Spec.ProjectID,
+				"is immutable"),
+		)
+	}
+
+	if len(errors) != 0 {
+		novaprojectquotalog.Info("validation failed", "name", r.Name)
+		return nil, apierrors.NewInvalid(
+			schema.GroupKind{Group: "nova.openstack.org", Kind: "NovaProjectQuota"},
+			r.Name, errors)
+	}
+	return nil, nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *NovaProjectQuota) ValidateDelete() (admission.Warnings, error) {
+	novaprojectquotalog.Info("validate delete", "name", r.Name)
+
+	return nil, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NovaQuotaClassSpec defines the desired state of NovaQuotaClass
+type NovaQuotaClassSpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=nova
+	// NovaInstance is the name of the Nova CR in the same namespace. The
+	// quota class is managed via the compute API of this Nova deployment.
+	NovaInstance string `json:"novaInstance"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=default
+	// QuotaClassName is the name of the quota class in the compute API. Note
+	// that the compute service only uses the "default" quota class, it
+	// defines the quota of every project without project specific quota.
+	QuotaClassName string `json:"quotaClassName"`
+
+	// +kubebuilder:validation:Optional
+	// NovaQuotaLimits defines the limits of the quota class
+	NovaQuotaLimits `json:",inline"`
+}
+
+// NovaQuotaClassStatus defines the observed state of NovaQuotaClass
+type NovaQuotaClassStatus struct {
+	// Conditions
+	Conditions condition.Conditions `json:"conditions,omitempty" optional:"true"`
+
+	// AppliedLimits are the limits of the quota class as reported by the
+	// compute API
+	AppliedLimits NovaQuotaLimits `json:"appliedLimits,omitempty"`
+
+	//ObservedGeneration - the most recent generation observed for this object.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="QuotaClass",type="string",JSONPath=".spec.quotaClassName",description="QuotaClass"
+//+kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[0].status",description="Status"
+//+kubebuilder:printcolumn:name="Message",type="string",JSONPath=".status.conditions[0].message",description="Message"
+
+// NovaQuotaClass is the Schema for the novaquotaclasses API
+type NovaQuotaClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NovaQuotaClassSpec   `json:"spec,omitempty"`
+	Status NovaQuotaClassStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// NovaQuotaClassList contains a list of NovaQuotaClass
+type NovaQuotaClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NovaQuotaClass `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&NovaQuotaClass{}, &NovaQuotaClassList{})
+}
+
+// GetConditions returns the list of conditions from the status
+func (s NovaQuotaClassStatus) GetConditions() condition.Conditions {
+	return s.Conditions
+}
+
+// IsReady returns true if the quota class is in sync with the compute API
+func (instance NovaQuotaClass) IsReady() bool {
+	return instance.Status.Conditions.IsTrue(condition.ReadyCondition)
+}
@@ -86,6 +86,44 @@ type NovaSchedulerSpec struct {
 	// talk to keystone
 	KeystoneAuthURL string `json:"keystoneAuthURL"`
 
+	// +kubebuilder:validation:Optional
+	// KeystoneServiceIdentity defines the keystone region, and the domain
+	// and project of the ServiceUser
+	KeystoneServiceIdentity `json:",inline"`
+
+	// +kubebuilder:validation:Optional
+	// Telemetry - defines the integration with the telemetry stack
+	Telemetry NovaTelemetry `json:"telemetry"`
+
+	// +kubebuilder:validation:Optional
+	// ComputeRPCPin - the compute RPC version pinned via
+	// [upgrade_levels]compute. Defaults to auto if empty.
+	ComputeRPCPin string `json:"computeRPCPin,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=600
+	// +kubebuilder:validation:Minimum=0
+	// RolloutDeadline - the number of seconds a new container image or
+	// config of a nova service has to become ready before the service is
+	// rolled back to its last known-good image and config. 0 disables the
+	// rollback.
+	RolloutDeadline int `json:"rolloutDeadline"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=10
+	// +kubebuilder:validation:Minimum=1
+	// ConfigRevisionHistoryLimit - the number of rendered config revisions
+	// kept per nova service
+	ConfigRevisionHistoryLimit int `json:"configRevisionHistoryLimit"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:default=300
+	// +kubebuilder:validation:Minimum=0
+	// StaleServiceGracePeriod - the number of seconds a nova service of a
+	// removed pod needs to be reported down before the service and the
+	// placement resource providers of its compute nodes are deleted
+	StaleServiceGracePeriod int `json:"staleServiceGracePeriod"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:default=nova-api
 	// APIDatabaseAccount - MariaDBAccount to use when accessing the API DB
@@ -154,6 +192,14 @@ type NovaSchedulerStatus struct {
 
 	// LastAppliedTopology - the last applied Topology
 	LastAppliedTopology *topologyv1.TopoRef `json:"lastAppliedTopology,omitempty"`
+
+	// Rollout - tracks the rollout of the container image and config of the
+	// service to roll it back if it does not become ready in time
+	Rollout NovaRolloutStatus `json:"rollout,omitempty"`
+
+	// ConfigRevision - the name of the config revision Secret holding the
+	// config the service is deployed with
+	ConfigRevision string `json:"configRevision,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -209,6 +255,12 @@ func (n NovaScheduler) GetCABundleSecretName() string {
 	return n.Spec.TLS.CaBundleSecretName
 }
 
+// GetKeystoneServiceIdentity returns the keystone region, domains and project
+// of the ServiceUser from the Spec
+func (n NovaScheduler) GetKeystoneServiceIdentity() KeystoneServiceIdentity {
+	return n.Spec.KeystoneServiceIdentity
+}
+
 // GetSpecTopologyRef - Returns the LastAppliedTopology Set in the Status
 func (instance *NovaScheduler) GetSpecTopologyRef() *topologyv1.TopoRef {
 	return instance.Spec.TopologyRef
@@ -49,6 +49,21 @@ func (in *APIOverrideSpec) DeepCopy() *APIOverrideSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KeystoneServiceIdentity) DeepCopyInto(out *KeystoneServiceIdentity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KeystoneServiceIdentity.
+func (in *KeystoneServiceIdentity) DeepCopy() *KeystoneServiceIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(KeystoneServiceIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MetadataOverrideSpec) DeepCopyInto(out *MetadataOverrideSpec) {
 	*out = *in
@@ -173,6 +188,8 @@ func (in *NovaAPIList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaAPISpec) DeepCopyInto(out *NovaAPISpec) {
 	*out = *in
+	out.KeystoneServiceIdentity = in.KeystoneServiceIdentity
+	out.Telemetry = in.Telemetry
 	in.NovaServiceBase.DeepCopyInto(&out.NovaServiceBase)
 	in.Override.DeepCopyInto(&out.Override)
 	if in.RegisteredCells != nil {
@@ -240,6 +257,7 @@ func (in *NovaAPIStatus) DeepCopyInto(out *NovaAPIStatus) {
 		*out = new(topologyv1beta1.TopoRef)
 		**out = **in
 	}
+	in.Rollout.DeepCopyInto(&out.Rollout)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaAPIStatus.
@@ -303,6 +321,145 @@ func (in *NovaAPITemplate) DeepCopy() *NovaAPITemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaAggregate) DeepCopyInto(out *NovaAggregate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaAggregate.
+func (in *NovaAggregate) DeepCopy() *NovaAggregate {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaAggregate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaAggregate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaAggregateList) DeepCopyInto(out *NovaAggregateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NovaAggregate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaAggregateList.
+func (in *NovaAggregateList) DeepCopy() *NovaAggregateList {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaAggregateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaAggregateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaAggregateSpec) DeepCopyInto(out *NovaAggregateSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaAggregateSpec.
+func (in *NovaAggregateSpec) DeepCopy() *NovaAggregateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaAggregateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaAggregateStatus) DeepCopyInto(out *NovaAggregateStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.MissingHosts != nil {
+		in, out := &in.MissingHosts, &out.MissingHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FailedHosts != nil {
+		in, out := &in.FailedHosts, &out.FailedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaAggregateStatus.
+func (in *NovaAggregateStatus) DeepCopy() *NovaAggregateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaAggregateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaCapacity) DeepCopyInto(out *NovaCapacity) {
+	*out = *in
+	in.CollectedAt.DeepCopyInto(&out.CollectedAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCapacity.
+func (in *NovaCapacity) DeepCopy() *NovaCapacity {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaCapacity)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaCell) DeepCopyInto(out *NovaCell) {
 	*out = *in
@@ -330,6 +487,52 @@ func (in *NovaCell) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaCellAudit) DeepCopyInto(out *NovaCellAudit) {
+	*out = *in
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCellAudit.
+func (in *NovaCellAudit) DeepCopy() *NovaCellAudit {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaCellAudit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaCellAuditStatus) DeepCopyInto(out *NovaCellAuditStatus) {
+	*out = *in
+	in.LastAuditTime.DeepCopyInto(&out.LastAuditTime)
+	if in.UnmappedHosts != nil {
+		in, out := &in.UnmappedHosts, &out.UnmappedHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCellAuditStatus.
+func (in *NovaCellAuditStatus) DeepCopy() *NovaCellAuditStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaCellAuditStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaCellDBPurge) DeepCopyInto(out *NovaCellDBPurge) {
 	*out = *in
@@ -348,6 +551,16 @@ func (in *NovaCellDBPurge) DeepCopyInto(out *NovaCellDBPurge) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.MaxRows != nil {
+		in, out := &in.MaxRows, &out.MaxRows
+		*out = new(int)
+		**out = **in
+	}
+	if in.ArchiveTaskLog != nil {
+		in, out := &in.ArchiveTaskLog, &out.ArchiveTaskLog
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCellDBPurge.
@@ -360,6 +573,29 @@ func (in *NovaCellDBPurge) DeepCopy() *NovaCellDBPurge {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaCellDBPurgeStatus) DeepCopyInto(out *NovaCellDBPurgeStatus) {
+	*out = *in
+	if in.LastSuccessTime != nil {
+		in, out := &in.LastSuccessTime, &out.LastSuccessTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCellDBPurgeStatus.
+func (in *NovaCellDBPurgeStatus) DeepCopy() *NovaCellDBPurgeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaCellDBPurgeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaCellDefaults) DeepCopyInto(out *NovaCellDefaults) {
 	*out = *in
@@ -375,6 +611,42 @@ func (in *NovaCellDefaults) DeepCopy() *NovaCellDefaults {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaCellHostMappingsStatus) DeepCopyInto(out *NovaCellHostMappingsStatus) {
+	*out = *in
+	in.LastReportTime.DeepCopyInto(&out.LastReportTime)
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCellHostMappingsStatus.
+func (in *NovaCellHostMappingsStatus) DeepCopy() *NovaCellHostMappingsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaCellHostMappingsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaCellImageOverrides) DeepCopyInto(out *NovaCellImageOverrides) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCellImageOverrides.
+func (in *NovaCellImageOverrides) DeepCopy() *NovaCellImageOverrides {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaCellImageOverrides)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaCellImages) DeepCopyInto(out *NovaCellImages) {
 	*out = *in
@@ -422,6 +694,21 @@ func (in *NovaCellList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaCellMapping) DeepCopyInto(out *NovaCellMapping) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCellMapping.
+func (in *NovaCellMapping) DeepCopy() *NovaCellMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaCellMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaCellSpec) DeepCopyInto(out *NovaCellSpec) {
 	*out = *in
@@ -436,6 +723,8 @@ func (in *NovaCellSpec) DeepCopyInto(out *NovaCellSpec) {
 			}
 		}
 	}
+	out.KeystoneServiceIdentity = in.KeystoneServiceIdentity
+	out.Telemetry = in.Telemetry
 	in.ConductorServiceTemplate.DeepCopyInto(&out.ConductorServiceTemplate)
 	in.MetadataServiceTemplate.DeepCopyInto(&out.MetadataServiceTemplate)
 	in.NoVNCProxyServiceTemplate.DeepCopyInto(&out.NoVNCProxyServiceTemplate)
@@ -448,6 +737,7 @@ func (in *NovaCellSpec) DeepCopyInto(out *NovaCellSpec) {
 	}
 	out.TLS = in.TLS
 	in.DBPurge.DeepCopyInto(&out.DBPurge)
+	in.Audit.DeepCopyInto(&out.Audit)
 	out.NovaCellImages = in.NovaCellImages
 	if in.TopologyRef != nil {
 		in, out := &in.TopologyRef, &out.TopologyRef
@@ -490,6 +780,31 @@ func (in *NovaCellStatus) DeepCopyInto(out *NovaCellStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(NovaCapacity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CellMapping != nil {
+		in, out := &in.CellMapping, &out.CellMapping
+		*out = new(NovaCellMapping)
+		**out = **in
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(NovaCellAuditStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HostMappings != nil {
+		in, out := &in.HostMappings, &out.HostMappings
+		*out = new(NovaCellHostMappingsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DBPurge != nil {
+		in, out := &in.DBPurge, &out.DBPurge
+		*out = new(NovaCellDBPurgeStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCellStatus.
@@ -505,6 +820,11 @@ func (in *NovaCellStatus) DeepCopy() *NovaCellStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaCellTemplate) DeepCopyInto(out *NovaCellTemplate) {
 	*out = *in
+	if in.NotificationsBusInstance != nil {
+		in, out := &in.NotificationsBusInstance, &out.NotificationsBusInstance
+		*out = new(string)
+		**out = **in
+	}
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
 		*out = new(map[string]string)
@@ -532,6 +852,12 @@ func (in *NovaCellTemplate) DeepCopyInto(out *NovaCellTemplate) {
 		}
 	}
 	in.DBPurge.DeepCopyInto(&out.DBPurge)
+	in.Audit.DeepCopyInto(&out.Audit)
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = new(NovaCellImageOverrides)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaCellTemplate.
@@ -636,6 +962,8 @@ func (in *NovaComputeList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaComputeSpec) DeepCopyInto(out *NovaComputeSpec) {
 	*out = *in
+	out.KeystoneServiceIdentity = in.KeystoneServiceIdentity
+	out.Telemetry = in.Telemetry
 	in.NovaServiceBase.DeepCopyInto(&out.NovaServiceBase)
 	out.TLS = in.TLS
 	if in.DefaultConfigOverwrite != nil {
@@ -833,6 +1161,8 @@ func (in *NovaConductorList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaConductorSpec) DeepCopyInto(out *NovaConductorSpec) {
 	*out = *in
+	out.KeystoneServiceIdentity = in.KeystoneServiceIdentity
+	out.Telemetry = in.Telemetry
 	in.NovaServiceBase.DeepCopyInto(&out.NovaServiceBase)
 	out.TLS = in.TLS
 	in.DBPurge.DeepCopyInto(&out.DBPurge)
@@ -886,6 +1216,12 @@ func (in *NovaConductorStatus) DeepCopyInto(out *NovaConductorStatus) {
 		*out = new(topologyv1beta1.TopoRef)
 		**out = **in
 	}
+	in.Rollout.DeepCopyInto(&out.Rollout)
+	if in.DBPurge != nil {
+		in, out := &in.DBPurge, &out.DBPurge
+		*out = new(NovaCellDBPurgeStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaConductorStatus.
@@ -941,63 +1277,58 @@ func (in *NovaConductorTemplate) DeepCopy() *NovaConductorTemplate {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaDefaults) DeepCopyInto(out *NovaDefaults) {
+func (in *NovaDatabaseBackup) DeepCopyInto(out *NovaDatabaseBackup) {
 	*out = *in
-	out.NovaCellDefaults = in.NovaCellDefaults
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDefaults.
-func (in *NovaDefaults) DeepCopy() *NovaDefaults {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDatabaseBackup.
+func (in *NovaDatabaseBackup) DeepCopy() *NovaDatabaseBackup {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaDefaults)
+	out := new(NovaDatabaseBackup)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaImages) DeepCopyInto(out *NovaImages) {
-	*out = *in
-	out.NovaCellImages = in.NovaCellImages
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaImages.
-func (in *NovaImages) DeepCopy() *NovaImages {
-	if in == nil {
-		return nil
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaDatabaseBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
 	}
-	out := new(NovaImages)
-	in.DeepCopyInto(out)
-	return out
+	return nil
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaList) DeepCopyInto(out *NovaList) {
+func (in *NovaDatabaseBackupList) DeepCopyInto(out *NovaDatabaseBackupList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]Nova, len(*in))
+		*out = make([]NovaDatabaseBackup, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaList.
-func (in *NovaList) DeepCopy() *NovaList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDatabaseBackupList.
+func (in *NovaDatabaseBackupList) DeepCopy() *NovaDatabaseBackupList {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaList)
+	out := new(NovaDatabaseBackupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NovaList) DeepCopyObject() runtime.Object {
+func (in *NovaDatabaseBackupList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1005,26 +1336,983 @@ func (in *NovaList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaMetadata) DeepCopyInto(out *NovaMetadata) {
+func (in *NovaDatabaseBackupRecord) DeepCopyInto(out *NovaDatabaseBackupRecord) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Cells != nil {
+		in, out := &in.Cells, &out.Cells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SchemaVersions != nil {
+		in, out := &in.SchemaVersions, &out.SchemaVersions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDatabaseBackupRecord.
+func (in *NovaDatabaseBackupRecord) DeepCopy() *NovaDatabaseBackupRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaDatabaseBackupRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaDatabaseBackupSpec) DeepCopyInto(out *NovaDatabaseBackupSpec) {
+	*out = *in
+	if in.Cells != nil {
+		in, out := &in.Cells, &out.Cells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Schedule != nil {
+		in, out := &in.Schedule, &out.Schedule
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDatabaseBackupSpec.
+func (in *NovaDatabaseBackupSpec) DeepCopy() *NovaDatabaseBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaDatabaseBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaDatabaseBackupStatus) DeepCopyInto(out *NovaDatabaseBackupStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastBackup != nil {
+		in, out := &in.LastBackup, &out.LastBackup
+		*out = new(NovaDatabaseBackupRecord)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDatabaseBackupStatus.
+func (in *NovaDatabaseBackupStatus) DeepCopy() *NovaDatabaseBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaDatabaseBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaDatabaseRestore) DeepCopyInto(out *NovaDatabaseRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDatabaseRestore.
+func (in *NovaDatabaseRestore) DeepCopy() *NovaDatabaseRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaDatabaseRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaDatabaseRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaDatabaseRestoreList) DeepCopyInto(out *NovaDatabaseRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NovaDatabaseRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDatabaseRestoreList.
+func (in *NovaDatabaseRestoreList) DeepCopy() *NovaDatabaseRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaDatabaseRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaDatabaseRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaDatabaseRestoreSpec) DeepCopyInto(out *NovaDatabaseRestoreSpec) {
+	*out = *in
+	if in.Cells != nil {
+		in, out := &in.Cells, &out.Cells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDatabaseRestoreSpec.
+func (in *NovaDatabaseRestoreSpec) DeepCopy() *NovaDatabaseRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaDatabaseRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaDatabaseRestoreStatus) DeepCopyInto(out *NovaDatabaseRestoreStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDatabaseRestoreStatus.
+func (in *NovaDatabaseRestoreStatus) DeepCopy() *NovaDatabaseRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaDatabaseRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaDefaults) DeepCopyInto(out *NovaDefaults) {
+	*out = *in
+	out.NovaCellDefaults = in.NovaCellDefaults
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaDefaults.
+func (in *NovaDefaults) DeepCopy() *NovaDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaFlavor) DeepCopyInto(out *NovaFlavor) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaFlavor.
+func (in *NovaFlavor) DeepCopy() *NovaFlavor {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaFlavor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaFlavor) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaFlavorList) DeepCopyInto(out *NovaFlavorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NovaFlavor, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaFlavorList.
+func (in *NovaFlavorList) DeepCopy() *NovaFlavorList {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaFlavorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaFlavorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaFlavorSpec) DeepCopyInto(out *NovaFlavorSpec) {
+	*out = *in
+	if in.ExtraSpecs != nil {
+		in, out := &in.ExtraSpecs, &out.ExtraSpecs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ProjectAccess != nil {
+		in, out := &in.ProjectAccess, &out.ProjectAccess
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaFlavorSpec.
+func (in *NovaFlavorSpec) DeepCopy() *NovaFlavorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaFlavorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaFlavorStatus) DeepCopyInto(out *NovaFlavorStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DriftedFields != nil {
+		in, out := &in.DriftedFields, &out.DriftedFields
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaFlavorStatus.
+func (in *NovaFlavorStatus) DeepCopy() *NovaFlavorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaFlavorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostDrain) DeepCopyInto(out *NovaHostDrain) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostDrain.
+func (in *NovaHostDrain) DeepCopy() *NovaHostDrain {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostDrain)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaHostDrain) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostDrainInstanceStatus) DeepCopyInto(out *NovaHostDrainInstanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostDrainInstanceStatus.
+func (in *NovaHostDrainInstanceStatus) DeepCopy() *NovaHostDrainInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostDrainInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostDrainList) DeepCopyInto(out *NovaHostDrainList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NovaHostDrain, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostDrainList.
+func (in *NovaHostDrainList) DeepCopy() *NovaHostDrainList {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostDrainList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaHostDrainList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostDrainSpec) DeepCopyInto(out *NovaHostDrainSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostDrainSpec.
+func (in *NovaHostDrainSpec) DeepCopy() *NovaHostDrainSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostDrainSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostDrainStatus) DeepCopyInto(out *NovaHostDrainStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]NovaHostDrainInstanceStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostDrainStatus.
+func (in *NovaHostDrainStatus) DeepCopy() *NovaHostDrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostDrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostEvacuation) DeepCopyInto(out *NovaHostEvacuation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostEvacuation.
+func (in *NovaHostEvacuation) DeepCopy() *NovaHostEvacuation {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostEvacuation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaHostEvacuation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostEvacuationInstanceStatus) DeepCopyInto(out *NovaHostEvacuationInstanceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostEvacuationInstanceStatus.
+func (in *NovaHostEvacuationInstanceStatus) DeepCopy() *NovaHostEvacuationInstanceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostEvacuationInstanceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostEvacuationList) DeepCopyInto(out *NovaHostEvacuationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NovaHostEvacuation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostEvacuationList.
+func (in *NovaHostEvacuationList) DeepCopy() *NovaHostEvacuationList {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostEvacuationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaHostEvacuationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostEvacuationSpec) DeepCopyInto(out *NovaHostEvacuationSpec) {
+	*out = *in
+	if in.ServerTags != nil {
+		in, out := &in.ServerTags, &out.ServerTags
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServerMetadata != nil {
+		in, out := &in.ServerMetadata, &out.ServerMetadata
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostEvacuationSpec.
+func (in *NovaHostEvacuationSpec) DeepCopy() *NovaHostEvacuationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostEvacuationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaHostEvacuationStatus) DeepCopyInto(out *NovaHostEvacuationStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ServiceUpdatedAt != nil {
+		in, out := &in.ServiceUpdatedAt, &out.ServiceUpdatedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = make([]NovaHostEvacuationInstanceStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaHostEvacuationStatus.
+func (in *NovaHostEvacuationStatus) DeepCopy() *NovaHostEvacuationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaHostEvacuationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaImages) DeepCopyInto(out *NovaImages) {
+	*out = *in
+	out.NovaCellImages = in.NovaCellImages
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaImages.
+func (in *NovaImages) DeepCopy() *NovaImages {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaImages)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaList) DeepCopyInto(out *NovaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]Nova, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaList.
+func (in *NovaList) DeepCopy() *NovaList {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaManageCommand) DeepCopyInto(out *NovaManageCommand) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaManageCommand.
+func (in *NovaManageCommand) DeepCopy() *NovaManageCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaManageCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaManageCommand) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaManageCommandList) DeepCopyInto(out *NovaManageCommandList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NovaManageCommand, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaManageCommandList.
+func (in *NovaManageCommandList) DeepCopy() *NovaManageCommandList {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaManageCommandList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaManageCommandList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaManageCommandSpec) DeepCopyInto(out *NovaManageCommandSpec) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaManageCommandSpec.
+func (in *NovaManageCommandSpec) DeepCopy() *NovaManageCommandSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaManageCommandSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaManageCommandStatus) DeepCopyInto(out *NovaManageCommandStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ExitCode != nil {
+		in, out := &in.ExitCode, &out.ExitCode
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaManageCommandStatus.
+func (in *NovaManageCommandStatus) DeepCopy() *NovaManageCommandStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaManageCommandStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaMetadata) DeepCopyInto(out *NovaMetadata) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadata.
+func (in *NovaMetadata) DeepCopy() *NovaMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaMetadata) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaMetadataDefaults) DeepCopyInto(out *NovaMetadataDefaults) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataDefaults.
+func (in *NovaMetadataDefaults) DeepCopy() *NovaMetadataDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaMetadataDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaMetadataList) DeepCopyInto(out *NovaMetadataList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NovaMetadata, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataList.
+func (in *NovaMetadataList) DeepCopy() *NovaMetadataList {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaMetadataList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaMetadataList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaMetadataSpec) DeepCopyInto(out *NovaMetadataSpec) {
+	*out = *in
+	out.KeystoneServiceIdentity = in.KeystoneServiceIdentity
+	out.Telemetry = in.Telemetry
+	in.NovaServiceBase.DeepCopyInto(&out.NovaServiceBase)
+	in.Override.DeepCopyInto(&out.Override)
+	if in.RegisteredCells != nil {
+		in, out := &in.RegisteredCells, &out.RegisteredCells
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.TLS.DeepCopyInto(&out.TLS)
+	if in.DefaultConfigOverwrite != nil {
+		in, out := &in.DefaultConfigOverwrite, &out.DefaultConfigOverwrite
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataSpec.
+func (in *NovaMetadataSpec) DeepCopy() *NovaMetadataSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaMetadataSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaMetadataStatus) DeepCopyInto(out *NovaMetadataStatus) {
+	*out = *in
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NetworkAttachments != nil {
+		in, out := &in.NetworkAttachments, &out.NetworkAttachments
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.LastAppliedTopology != nil {
+		in, out := &in.LastAppliedTopology, &out.LastAppliedTopology
+		*out = new(topologyv1beta1.TopoRef)
+		**out = **in
+	}
+	in.Rollout.DeepCopyInto(&out.Rollout)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataStatus.
+func (in *NovaMetadataStatus) DeepCopy() *NovaMetadataStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaMetadataStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaMetadataTemplate) DeepCopyInto(out *NovaMetadataTemplate) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(map[string]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make(map[string]string, len(*in))
+			for key, val := range *in {
+				(*out)[key] = val
+			}
+		}
+	}
+	if in.DefaultConfigOverwrite != nil {
+		in, out := &in.DefaultConfigOverwrite, &out.DefaultConfigOverwrite
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NetworkAttachments != nil {
+		in, out := &in.NetworkAttachments, &out.NetworkAttachments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Override.DeepCopyInto(&out.Override)
+	in.TLS.DeepCopyInto(&out.TLS)
+	if in.TopologyRef != nil {
+		in, out := &in.TopologyRef, &out.TopologyRef
+		*out = new(topologyv1beta1.TopoRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataTemplate.
+func (in *NovaMetadataTemplate) DeepCopy() *NovaMetadataTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaMetadataTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaNoVNCProxy) DeepCopyInto(out *NovaNoVNCProxy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadata.
-func (in *NovaMetadata) DeepCopy() *NovaMetadata {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxy.
+func (in *NovaNoVNCProxy) DeepCopy() *NovaNoVNCProxy {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaMetadata)
+	out := new(NovaNoVNCProxy)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NovaMetadata) DeepCopyObject() runtime.Object {
+func (in *NovaNoVNCProxy) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1032,46 +2320,46 @@ func (in *NovaMetadata) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaMetadataDefaults) DeepCopyInto(out *NovaMetadataDefaults) {
+func (in *NovaNoVNCProxyDefaults) DeepCopyInto(out *NovaNoVNCProxyDefaults) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataDefaults.
-func (in *NovaMetadataDefaults) DeepCopy() *NovaMetadataDefaults {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxyDefaults.
+func (in *NovaNoVNCProxyDefaults) DeepCopy() *NovaNoVNCProxyDefaults {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaMetadataDefaults)
+	out := new(NovaNoVNCProxyDefaults)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaMetadataList) DeepCopyInto(out *NovaMetadataList) {
+func (in *NovaNoVNCProxyList) DeepCopyInto(out *NovaNoVNCProxyList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NovaMetadata, len(*in))
+		*out = make([]NovaNoVNCProxy, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataList.
-func (in *NovaMetadataList) DeepCopy() *NovaMetadataList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxyList.
+func (in *NovaNoVNCProxyList) DeepCopy() *NovaNoVNCProxyList {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaMetadataList)
+	out := new(NovaNoVNCProxyList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NovaMetadataList) DeepCopyObject() runtime.Object {
+func (in *NovaNoVNCProxyList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1079,39 +2367,27 @@ func (in *NovaMetadataList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaMetadataSpec) DeepCopyInto(out *NovaMetadataSpec) {
+func (in *NovaNoVNCProxySpec) DeepCopyInto(out *NovaNoVNCProxySpec) {
 	*out = *in
+	out.KeystoneServiceIdentity = in.KeystoneServiceIdentity
+	out.Telemetry = in.Telemetry
 	in.NovaServiceBase.DeepCopyInto(&out.NovaServiceBase)
 	in.Override.DeepCopyInto(&out.Override)
-	if in.RegisteredCells != nil {
-		in, out := &in.RegisteredCells, &out.RegisteredCells
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 	in.TLS.DeepCopyInto(&out.TLS)
-	if in.DefaultConfigOverwrite != nil {
-		in, out := &in.DefaultConfigOverwrite, &out.DefaultConfigOverwrite
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataSpec.
-func (in *NovaMetadataSpec) DeepCopy() *NovaMetadataSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxySpec.
+func (in *NovaNoVNCProxySpec) DeepCopy() *NovaNoVNCProxySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaMetadataSpec)
+	out := new(NovaNoVNCProxySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaMetadataStatus) DeepCopyInto(out *NovaMetadataStatus) {
+func (in *NovaNoVNCProxyStatus) DeepCopyInto(out *NovaNoVNCProxyStatus) {
 	*out = *in
 	if in.Hash != nil {
 		in, out := &in.Hash, &out.Hash
@@ -1148,76 +2424,169 @@ func (in *NovaMetadataStatus) DeepCopyInto(out *NovaMetadataStatus) {
 		*out = new(topologyv1beta1.TopoRef)
 		**out = **in
 	}
+	in.Rollout.DeepCopyInto(&out.Rollout)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxyStatus.
+func (in *NovaNoVNCProxyStatus) DeepCopy() *NovaNoVNCProxyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaNoVNCProxyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaNoVNCProxyTemplate) DeepCopyInto(out *NovaNoVNCProxyTemplate) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = new(map[string]string)
+		if **in != nil {
+			in, out := *in, *out
+			*out = make(map[string]string, len(*in))
+			for key, val := range *in {
+				(*out)[key] = val
+			}
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.NetworkAttachments != nil {
+		in, out := &in.NetworkAttachments, &out.NetworkAttachments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Override.DeepCopyInto(&out.Override)
+	in.TLS.DeepCopyInto(&out.TLS)
+	if in.TopologyRef != nil {
+		in, out := &in.TopologyRef, &out.TopologyRef
+		*out = new(topologyv1beta1.TopoRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxyTemplate.
+func (in *NovaNoVNCProxyTemplate) DeepCopy() *NovaNoVNCProxyTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaNoVNCProxyTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaProjectQuota) DeepCopyInto(out *NovaProjectQuota) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaProjectQuota.
+func (in *NovaProjectQuota) DeepCopy() *NovaProjectQuota {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaProjectQuota)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaProjectQuota) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaProjectQuotaList) DeepCopyInto(out *NovaProjectQuotaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NovaProjectQuota, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaProjectQuotaList.
+func (in *NovaProjectQuotaList) DeepCopy() *NovaProjectQuotaList {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaProjectQuotaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *NovaProjectQuotaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataStatus.
-func (in *NovaMetadataStatus) DeepCopy() *NovaMetadataStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaProjectQuotaSpec) DeepCopyInto(out *NovaProjectQuotaSpec) {
+	*out = *in
+	in.NovaQuotaLimits.DeepCopyInto(&out.NovaQuotaLimits)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaProjectQuotaSpec.
+func (in *NovaProjectQuotaSpec) DeepCopy() *NovaProjectQuotaSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaMetadataStatus)
+	out := new(NovaProjectQuotaSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaMetadataTemplate) DeepCopyInto(out *NovaMetadataTemplate) {
+func (in *NovaProjectQuotaStatus) DeepCopyInto(out *NovaProjectQuotaStatus) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
-		**out = **in
-	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = new(map[string]string)
-		if **in != nil {
-			in, out := *in, *out
-			*out = make(map[string]string, len(*in))
-			for key, val := range *in {
-				(*out)[key] = val
-			}
-		}
-	}
-	if in.DefaultConfigOverwrite != nil {
-		in, out := &in.DefaultConfigOverwrite, &out.DefaultConfigOverwrite
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(condition.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.NetworkAttachments != nil {
-		in, out := &in.NetworkAttachments, &out.NetworkAttachments
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	in.Override.DeepCopyInto(&out.Override)
-	in.TLS.DeepCopyInto(&out.TLS)
-	if in.TopologyRef != nil {
-		in, out := &in.TopologyRef, &out.TopologyRef
-		*out = new(topologyv1beta1.TopoRef)
-		**out = **in
-	}
+	in.AppliedLimits.DeepCopyInto(&out.AppliedLimits)
+	in.InUse.DeepCopyInto(&out.InUse)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaMetadataTemplate.
-func (in *NovaMetadataTemplate) DeepCopy() *NovaMetadataTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaProjectQuotaStatus.
+func (in *NovaProjectQuotaStatus) DeepCopy() *NovaProjectQuotaStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaMetadataTemplate)
+	out := new(NovaProjectQuotaStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaNoVNCProxy) DeepCopyInto(out *NovaNoVNCProxy) {
+func (in *NovaQuotaClass) DeepCopyInto(out *NovaQuotaClass) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1225,18 +2594,18 @@ func (in *NovaNoVNCProxy) DeepCopyInto(out *NovaNoVNCProxy) {
 	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxy.
-func (in *NovaNoVNCProxy) DeepCopy() *NovaNoVNCProxy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaQuotaClass.
+func (in *NovaQuotaClass) DeepCopy() *NovaQuotaClass {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaNoVNCProxy)
+	out := new(NovaQuotaClass)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NovaNoVNCProxy) DeepCopyObject() runtime.Object {
+func (in *NovaQuotaClass) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1244,46 +2613,31 @@ func (in *NovaNoVNCProxy) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaNoVNCProxyDefaults) DeepCopyInto(out *NovaNoVNCProxyDefaults) {
-	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxyDefaults.
-func (in *NovaNoVNCProxyDefaults) DeepCopy() *NovaNoVNCProxyDefaults {
-	if in == nil {
-		return nil
-	}
-	out := new(NovaNoVNCProxyDefaults)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaNoVNCProxyList) DeepCopyInto(out *NovaNoVNCProxyList) {
+func (in *NovaQuotaClassList) DeepCopyInto(out *NovaQuotaClassList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]NovaNoVNCProxy, len(*in))
+		*out = make([]NovaQuotaClass, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxyList.
-func (in *NovaNoVNCProxyList) DeepCopy() *NovaNoVNCProxyList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaQuotaClassList.
+func (in *NovaQuotaClassList) DeepCopy() *NovaQuotaClassList {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaNoVNCProxyList)
+	out := new(NovaQuotaClassList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *NovaNoVNCProxyList) DeepCopyObject() runtime.Object {
+func (in *NovaQuotaClassList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1291,33 +2645,24 @@ func (in *NovaNoVNCProxyList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaNoVNCProxySpec) DeepCopyInto(out *NovaNoVNCProxySpec) {
+func (in *NovaQuotaClassSpec) DeepCopyInto(out *NovaQuotaClassSpec) {
 	*out = *in
-	in.NovaServiceBase.DeepCopyInto(&out.NovaServiceBase)
-	in.Override.DeepCopyInto(&out.Override)
-	in.TLS.DeepCopyInto(&out.TLS)
+	in.NovaQuotaLimits.DeepCopyInto(&out.NovaQuotaLimits)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxySpec.
-func (in *NovaNoVNCProxySpec) DeepCopy() *NovaNoVNCProxySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaQuotaClassSpec.
+func (in *NovaQuotaClassSpec) DeepCopy() *NovaQuotaClassSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaNoVNCProxySpec)
+	out := new(NovaQuotaClassSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaNoVNCProxyStatus) DeepCopyInto(out *NovaNoVNCProxyStatus) {
+func (in *NovaQuotaClassStatus) DeepCopyInto(out *NovaQuotaClassStatus) {
 	*out = *in
-	if in.Hash != nil {
-		in, out := &in.Hash, &out.Hash
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(condition.Conditions, len(*in))
@@ -1325,84 +2670,114 @@ func (in *NovaNoVNCProxyStatus) DeepCopyInto(out *NovaNoVNCProxyStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.NetworkAttachments != nil {
-		in, out := &in.NetworkAttachments, &out.NetworkAttachments
-		*out = make(map[string][]string, len(*in))
-		for key, val := range *in {
-			var outVal []string
-			if val == nil {
-				(*out)[key] = nil
-			} else {
-				inVal := (*in)[key]
-				in, out := &inVal, &outVal
-				*out = make([]string, len(*in))
-				copy(*out, *in)
-			}
-			(*out)[key] = outVal
-		}
+	in.AppliedLimits.DeepCopyInto(&out.AppliedLimits)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaQuotaClassStatus.
+func (in *NovaQuotaClassStatus) DeepCopy() *NovaQuotaClassStatus {
+	if in == nil {
+		return nil
 	}
-	if in.LastAppliedTopology != nil {
-		in, out := &in.LastAppliedTopology, &out.LastAppliedTopology
-		*out = new(topologyv1beta1.TopoRef)
+	out := new(NovaQuotaClassStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaQuotaLimits) DeepCopyInto(out *NovaQuotaLimits) {
+	*out = *in
+	if in.Instances != nil {
+		in, out := &in.Instances, &out.Instances
+		*out = new(int)
+		**out = **in
+	}
+	if in.Cores != nil {
+		in, out := &in.Cores, &out.Cores
+		*out = new(int)
+		**out = **in
+	}
+	if in.RAM != nil {
+		in, out := &in.RAM, &out.RAM
+		*out = new(int)
+		**out = **in
+	}
+	if in.KeyPairs != nil {
+		in, out := &in.KeyPairs, &out.KeyPairs
+		*out = new(int)
+		**out = **in
+	}
+	if in.MetadataItems != nil {
+		in, out := &in.MetadataItems, &out.MetadataItems
+		*out = new(int)
+		**out = **in
+	}
+	if in.ServerGroups != nil {
+		in, out := &in.ServerGroups, &out.ServerGroups
+		*out = new(int)
+		**out = **in
+	}
+	if in.ServerGroupMembers != nil {
+		in, out := &in.ServerGroupMembers, &out.ServerGroupMembers
+		*out = new(int)
 		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxyStatus.
-func (in *NovaNoVNCProxyStatus) DeepCopy() *NovaNoVNCProxyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaQuotaLimits.
+func (in *NovaQuotaLimits) DeepCopy() *NovaQuotaLimits {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaNoVNCProxyStatus)
+	out := new(NovaQuotaLimits)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *NovaNoVNCProxyTemplate) DeepCopyInto(out *NovaNoVNCProxyTemplate) {
+func (in *NovaRolloutStatus) DeepCopyInto(out *NovaRolloutStatus) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
+	if in.LastKnownGood != nil {
+		in, out := &in.LastKnownGood, &out.LastKnownGood
+		*out = new(NovaServiceRevision)
 		**out = **in
 	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
-		*out = new(int32)
+	if in.Current != nil {
+		in, out := &in.Current, &out.Current
+		*out = new(NovaServiceRevision)
 		**out = **in
 	}
-	if in.NodeSelector != nil {
-		in, out := &in.NodeSelector, &out.NodeSelector
-		*out = new(map[string]string)
-		if **in != nil {
-			in, out := *in, *out
-			*out = make(map[string]string, len(*in))
-			for key, val := range *in {
-				(*out)[key] = val
-			}
-		}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.NetworkAttachments != nil {
-		in, out := &in.NetworkAttachments, &out.NetworkAttachments
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaRolloutStatus.
+func (in *NovaRolloutStatus) DeepCopy() *NovaRolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaRolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaRolloutStrategy) DeepCopyInto(out *NovaRolloutStrategy) {
+	*out = *in
+	if in.CellOrder != nil {
+		in, out := &in.CellOrder, &out.CellOrder
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	in.Override.DeepCopyInto(&out.Override)
-	in.TLS.DeepCopyInto(&out.TLS)
-	if in.TopologyRef != nil {
-		in, out := &in.TopologyRef, &out.TopologyRef
-		*out = new(topologyv1beta1.TopoRef)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaNoVNCProxyTemplate.
-func (in *NovaNoVNCProxyTemplate) DeepCopy() *NovaNoVNCProxyTemplate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaRolloutStrategy.
+func (in *NovaRolloutStrategy) DeepCopy() *NovaRolloutStrategy {
 	if in == nil {
 		return nil
 	}
-	out := new(NovaNoVNCProxyTemplate)
+	out := new(NovaRolloutStrategy)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1484,6 +2859,8 @@ func (in *NovaSchedulerList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaSchedulerSpec) DeepCopyInto(out *NovaSchedulerSpec) {
 	*out = *in
+	out.KeystoneServiceIdentity = in.KeystoneServiceIdentity
+	out.Telemetry = in.Telemetry
 	in.NovaServiceBase.DeepCopyInto(&out.NovaServiceBase)
 	if in.RegisteredCells != nil {
 		in, out := &in.RegisteredCells, &out.RegisteredCells
@@ -1543,6 +2920,7 @@ func (in *NovaSchedulerStatus) DeepCopyInto(out *NovaSchedulerStatus) {
 		*out = new(topologyv1beta1.TopoRef)
 		**out = **in
 	}
+	in.Rollout.DeepCopyInto(&out.Rollout)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaSchedulerStatus.
@@ -1639,6 +3017,21 @@ func (in *NovaServiceBase) DeepCopy() *NovaServiceBase {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaServiceRevision) DeepCopyInto(out *NovaServiceRevision) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaServiceRevision.
+func (in *NovaServiceRevision) DeepCopy() *NovaServiceRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaServiceRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaSpec) DeepCopyInto(out *NovaSpec) {
 	*out = *in
@@ -1659,6 +3052,17 @@ func (in *NovaSpec) DeepCopy() *NovaSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NovaSpecCore) DeepCopyInto(out *NovaSpecCore) {
 	*out = *in
+	if in.NotificationsBusInstance != nil {
+		in, out := &in.NotificationsBusInstance, &out.NotificationsBusInstance
+		*out = new(string)
+		**out = **in
+	}
+	out.Telemetry = in.Telemetry
+	if in.RolloutStrategy != nil {
+		in, out := &in.RolloutStrategy, &out.RolloutStrategy
+		*out = new(NovaRolloutStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.CellTemplates != nil {
 		in, out := &in.CellTemplates, &out.CellTemplates
 		*out = make(map[string]NovaCellTemplate, len(*in))
@@ -1666,6 +3070,7 @@ func (in *NovaSpecCore) DeepCopyInto(out *NovaSpecCore) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	out.KeystoneServiceIdentity = in.KeystoneServiceIdentity
 	out.PasswordSelectors = in.PasswordSelectors
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
@@ -1715,6 +3120,13 @@ func (in *NovaStatus) DeepCopyInto(out *NovaStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.CellMappings != nil {
+		in, out := &in.CellMappings, &out.CellMappings
+		*out = make(map[string]NovaCellMapping, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 	if in.DiscoveredCells != nil {
 		in, out := &in.DiscoveredCells, &out.DiscoveredCells
 		*out = make(map[string]string, len(*in))
@@ -1722,6 +3134,53 @@ func (in *NovaStatus) DeepCopyInto(out *NovaStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.DisabledCells != nil {
+		in, out := &in.DisabledCells, &out.DisabledCells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AdoptionMismatches != nil {
+		in, out := &in.AdoptionMismatches, &out.AdoptionMismatches
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = new(NovaCapacity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DeployedImages != nil {
+		in, out := &in.DeployedImages, &out.DeployedImages
+		*out = new(NovaImages)
+		**out = **in
+	}
+	if in.PreviousImages != nil {
+		in, out := &in.PreviousImages, &out.PreviousImages
+		*out = new(NovaImages)
+		**out = **in
+	}
+	if in.TargetImages != nil {
+		in, out := &in.TargetImages, &out.TargetImages
+		*out = new(NovaImages)
+		**out = **in
+	}
+	if in.UpgradeCheckFailure != nil {
+		in, out := &in.UpgradeCheckFailure, &out.UpgradeCheckFailure
+		*out = new(NovaUpgradeCheckFailure)
+		**out = **in
+	}
+	if in.RolloutHeldCells != nil {
+		in, out := &in.RolloutHeldCells, &out.RolloutHeldCells
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hash != nil {
+		in, out := &in.Hash, &out.Hash
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaStatus.
@@ -1734,6 +3193,37 @@ func (in *NovaStatus) DeepCopy() *NovaStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaTelemetry) DeepCopyInto(out *NovaTelemetry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaTelemetry.
+func (in *NovaTelemetry) DeepCopy() *NovaTelemetry {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaTelemetry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NovaUpgradeCheckFailure) DeepCopyInto(out *NovaUpgradeCheckFailure) {
+	*out = *in
+	out.Images = in.Images
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NovaUpgradeCheckFailure.
+func (in *NovaUpgradeCheckFailure) DeepCopy() *NovaUpgradeCheckFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(NovaUpgradeCheckFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PasswordSelector) DeepCopyInto(out *PasswordSelector) {
 	*out = *in
@@ -0,0 +1,351 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/hypervisors"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/services"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+const (
+	// capacityCollectionInterval defines how often the capacity information
+	// of the cells is collected from the compute API
+	capacityCollectionInterval = 5 * time.Minute
+
+	// hypervisorMicroversion is the latest compute API microversion that
+	// still reports the resource totals and usage of the hypervisors.
+	// These fields are removed from the os-hypervisors API in 2.88.
+	hypervisorMicroversion = "2.87"
+)
+
+var (
+	cellMetricLabels = []string{"namespace", "novacell", "cell"}
+
+	cellInstancesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "nova_operator",
+		Subsystem: "cell",
+		Name:      "instances",
+		Help:      "Number of instances running on the compute hosts of the cell",
+	}, cellMetricLabels)
+	cellComputeServicesGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "nova_operator",
+		Subsystem: "cell",
+		Name:      "compute_services",
+		Help:      "Number of nova-compute services of the cell by state (up, down, disabled)",
+	}, append(cellMetricLabels, "state"))
+	cellVCPUsGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "nova_operator",
+		Subsystem: "cell",
+		Name:      "vcpus",
+		Help:      "Total number of vCPUs of the hypervisors of the cell",
+	}, cellMetricLabels)
+	cellVCPUsUsedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "nova_operator",
+		Subsystem: "cell",
+		Name:      "vcpus_used",
+		Help:      "Number of vCPUs used by instances in the cell",
+	}, cellMetricLabels)
+	cellMemoryMBGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "nova_operator",
+		Subsystem: "cell",
+		Name:      "memory_mb",
+		Help:      "Total RAM of the hypervisors of the cell in MB",
+	}, cellMetricLabels)
+	cellMemoryMBUsedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "nova_operator",
+		Subsystem: "cell",
+		Name:      "memory_mb_used",
+		Help:      "RAM used by instances in the cell in MB",
+	}, cellMetricLabels)
+	cellLocalDiskGBGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "nova_operator",
+		Subsystem: "cell",
+		Name:      "local_disk_gb",
+		Help:      "Total local disk of the hypervisors of the cell in GB",
+	}, cellMetricLabels)
+	cellLocalDiskGBUsedGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "nova_operator",
+		Subsystem: "cell",
+		Name:      "local_disk_gb_used",
+		Help:      "Local disk used by instances in the cell in GB",
+	}, cellMetricLabels)
+
+	cellCapacityGauges = []*prometheus.GaugeVec{
+		cellInstancesGauge,
+		cellComputeServicesGauge,
+		cellVCPUsGauge,
+		cellVCPUsUsedGauge,
+		cellMemoryMBGauge,
+		cellMemoryMBUsedGauge,
+		cellLocalDiskGBGauge,
+		cellLocalDiskGBUsedGauge,
+	}
+)
+
+func init() {
+	// Register the gauges to the controller-runtime registry so that they are
+	// exposed on the metrics endpoint of the operator
+	for _, gauge := range cellCapacityGauges {
+		metrics.Registry.MustRegister(gauge)
+	}
+}
+
+// setCellCapacityMetrics exports the capacity information of the cell as
+// prometheus gauges
+func setCellCapacityMetrics(instance *novav1.NovaCell) {
+	capacity := instance.Status.Capacity
+	if capacity == nil {
+		return
+	}
+	labels := prometheus.Labels{
+		"namespace": instance.Namespace,
+		"novacell":  instance.Name,
+		"cell":      instance.Spec.CellName,
+	}
+	cellInstancesGauge.With(labels).Set(float64(capacity.Instances))
+	cellVCPUsGauge.With(labels).Set(float64(capacity.VCPUs))
+	cellVCPUsUsedGauge.With(labels).Set(float64(capacity.VCPUsUsed))
+	cellMemoryMBGauge.With(labels).Set(float64(capacity.MemoryMB))
+	cellMemoryMBUsedGauge.With(labels).Set(float64(capacity.MemoryMBUsed))
+	cellLocalDiskGBGauge.With(labels).Set(float64(capacity.LocalDiskGB))
+	cellLocalDiskGBUsedGauge.With(labels).Set(float64(capacity.LocalDiskGBUsed))
+
+	for state, count := range map[string]int{
+		"up":       capacity.ComputeServicesUp,
+		"down":     capacity.ComputeServicesDown,
+		"disabled": capacity.ComputeServicesDisabled,
+	} {
+		stateLabels := prometheus.Labels{"state": state}
+		for k, v := range labels {
+			stateLabels[k] = v
+		}
+		cellComputeServicesGauge.With(stateLabels).Set(float64(count))
+	}
+}
+
+// deleteCellCapacityMetrics removes the gauges of a deleted NovaCell
+func deleteCellCapacityMetrics(name types.NamespacedName) {
+	labels := prometheus.Labels{
+		"namespace": name.Namespace,
+		"novacell":  name.Name,
+	}
+	for _, gauge := range cellCapacityGauges {
+		gauge.DeletePartialMatch(labels)
+	}
+}
+
+// sumCellCapacity returns the sum of the capacity information of the cells
+// or nil if none of the cells reported capacity yet. The collection time of
+// the result is the oldest collection time of the cells.
+func sumCellCapacity(cells map[string]*novav1.NovaCell) *novav1.NovaCapacity {
+	var result *novav1.NovaCapacity
+	for _, cell := range cells {
+		if cell == nil || cell.Status.Capacity == nil {
+			continue
+		}
+		capacity := cell.Status.Capacity
+		if result == nil {
+			result = &novav1.NovaCapacity{CollectedAt: capacity.CollectedAt}
+		}
+		result.Instances += capacity.Instances
+		result.ComputeServicesUp += capacity.ComputeServicesUp
+		result.ComputeServicesDown += capacity.ComputeServicesDown
+		result.ComputeServicesDisabled += capacity.ComputeServicesDisabled
+		result.VCPUs += capacity.VCPUs
+		result.VCPUsUsed += capacity.VCPUsUsed
+		result.MemoryMB += capacity.MemoryMB
+		result.MemoryMBUsed += capacity.MemoryMBUsed
+		result.LocalDiskGB += capacity.LocalDiskGB
+		result.LocalDiskGBUsed += capacity.LocalDiskGBUsed
+		if capacity.CollectedAt.Before(&result.CollectedAt) {
+			result.CollectedAt = capacity.CollectedAt
+		}
+	}
+	return result
+}
+
+// ensureCapacityReported periodically collects the capacity information of
+// the cell from the compute API and stores it in the Status. The capacity
+// information is informational only so failing to collect it does not
+// affect the readiness of the cell.
+func (r *NovaCellReconciler) ensureCapacityReported(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaCell,
+) ctrl.Result {
+	Log := r.GetLogger(ctx)
+
+	if instance.Spec.CellName == novav1.Cell0Name {
+		// cell0 has no compute hosts
+		return ctrl.Result{}
+	}
+
+	if instance.Status.Capacity != nil {
+		setCellCapacityMetrics(instance)
+		next := instance.Status.Capacity.CollectedAt.Add(capacityCollectionInterval)
+		if wait := time.Until(next); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}
+		}
+	}
+
+	capacity, err := r.collectCapacity(ctx, h, instance)
+	if err != nil {
+		Log.Info("Failed to collect the capacity of the cell", "error", err.Error())
+	} else if capacity != nil {
+		instance.Status.Capacity = capacity
+		setCellCapacityMetrics(instance)
+	}
+	return ctrl.Result{RequeueAfter: capacityCollectionInterval}
+}
+
+// collectCapacity queries the compute services and the hypervisors of the
+// cell from the compute API. It returns nil without an error if the compute
+// API is not available yet or the hosts of the cell are not reported yet.
+func (r *NovaCellReconciler) collectCapacity(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaCell,
+) (*novav1.NovaCapacity, error) {
+	Log := r.GetLogger(ctx)
+
+	owner := metav1.GetControllerOf(instance)
+	if owner == nil || owner.Kind != "Nova" {
+		return nil, nil
+	}
+
+	// The state of the compute API is not reflected in the conditions of the
+	// cell as the capacity information is optional
+	computeClient, result, err := getNovaAPIClient(
+		ctx, h, instance.Namespace, owner.Name, &condition.Conditions{}, r.RequeueTimeout, Log)
+	if (err != nil || result != ctrl.Result{}) {
+		return nil, err
+	}
+
+	hosts, allHosts, err := r.getCellComputeHosts(ctx, instance, owner)
+	if err != nil {
+		return nil, err
+	}
+	if hosts == nil && !allHosts {
+		// We will be reconciled when the hosts of the cell are reported
+		Log.Info("The hosts of the cell are not reported yet, skipping the capacity collection")
+		return nil, nil
+	}
+	inCell := func(host string) bool {
+		return allHosts || hosts[host]
+	}
+
+	capacity := &novav1.NovaCapacity{CollectedAt: metav1.Now()}
+
+	allPages, err := services.List(computeClient, services.ListOpts{Binary: "nova-compute"}).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the compute services: %w", err)
+	}
+	computeServices, err := services.ExtractServices(allPages)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range computeServices {
+		if !inCell(service.Host) {
+			continue
+		}
+		if service.State == "up" {
+			capacity.ComputeServicesUp++
+		} else {
+			capacity.ComputeServicesDown++
+		}
+		if service.Status == "disabled" {
+			capacity.ComputeServicesDisabled++
+		}
+	}
+
+	hypervisorClient := *computeClient
+	hypervisorClient.Microversion = hypervisorMicroversion
+	allPages, err = hypervisors.List(&hypervisorClient, nil).AllPages()
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the hypervisors: %w", err)
+	}
+	computeHypervisors, err := hypervisors.ExtractHypervisors(allPages)
+	if err != nil {
+		return nil, err
+	}
+	for _, hypervisor := range computeHypervisors {
+		if !inCell(hypervisor.Service.Host) {
+			continue
+		}
+		capacity.Instances += hypervisor.RunningVMs
+		capacity.VCPUs += hypervisor.VCPUs
+		capacity.VCPUsUsed += hypervisor.VCPUsUsed
+		capacity.MemoryMB += hypervisor.MemoryMB
+		capacity.MemoryMBUsed += hypervisor.MemoryMBUsed
+		capacity.LocalDiskGB += hypervisor.LocalGB
+		capacity.LocalDiskGBUsed += hypervisor.LocalGBUsed
+	}
+
+	return capacity, nil
+}
+
+// getCellComputeHosts returns the compute host names that belong to the
+// cell. The compute API does not expose the cell of a compute host so the
+// hosts are the ones mapped to the cell as reported in the Status. If the
+// cell is the only cell with compute hosts in the Nova deployment then every
+// compute host belongs to it, that is signalled by the second return value.
+// It returns nil if the hosts of the cell are not reported yet.
+func (r *NovaCellReconciler) getCellComputeHosts(
+	ctx context.Context,
+	instance *novav1.NovaCell,
+	owner *metav1.OwnerReference,
+) (map[string]bool, bool, error) {
+	cells := &novav1.NovaCellList{}
+	err := r.Client.List(ctx, cells, client.InNamespace(instance.Namespace))
+	if err != nil {
+		return nil, false, err
+	}
+	computeCells := 0
+	for _, cell := range cells.Items {
+		cellOwner := metav1.GetControllerOf(&cell)
+		if cellOwner == nil || cellOwner.UID != owner.UID || cell.Spec.CellName == novav1.Cell0Name {
+			continue
+		}
+		computeCells++
+	}
+	if computeCells == 1 {
+		return nil, true, nil
+	}
+
+	if instance.Status.HostMappings == nil {
+		return nil, false, nil
+	}
+	hosts := map[string]bool{}
+	for _, host := range instance.Status.HostMappings.Hosts {
+		hosts[host] = true
+	}
+	return hosts, false, nil
+}
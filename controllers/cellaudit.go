@@ -0,0 +1,194 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/cronjob"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
+)
+
+// cellAuditReport is the report the cell audit Job writes to the termination
+// message of its pod
+type cellAuditReport struct {
+	OrphanedAllocations int      `json:"orphanedAllocations"`
+	UnmappedHosts       []string `json:"unmappedHosts"`
+	AggregatesInSync    bool     `json:"aggregatesInSync"`
+	Errors              []string `json:"errors"`
+}
+
+func getCellAuditLabels(instance *novav1.NovaCell) map[string]string {
+	return labels.GetLabels(
+		instance, labels.GetGroupLabel(NovaCellLabelPrefix),
+		map[string]string{common.AppSelector: NovaCellAuditLabelPrefix},
+	)
+}
+
+// ensureCellAudit ensures that the audit CronJob of the cell exists if the
+// audit is enabled and reports the findings of its last finished Job
+func (r *NovaCellReconciler) ensureCellAudit(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaCell,
+) error {
+	Log := r.GetLogger(ctx)
+
+	if !instance.Spec.Audit.Enabled {
+		cron := cronjob.NewCronJob(
+			&batchv1.CronJob{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      nova.CellAuditCronJobName(instance),
+					Namespace: instance.Namespace,
+				},
+			},
+			r.RequeueTimeout)
+		instance.Status.Conditions.Remove(novav1.NovaCellAuditCondition)
+		instance.Status.Audit = nil
+		return cron.Delete(ctx, h)
+	}
+
+	// The audit uses the nova-manage config of the cell as it needs access
+	// to the API DB and to placement
+	configName, scriptName := getNovaManageJobSecretNames(instance)
+	cronDef := nova.CellAuditCronJob(
+		instance, configName, scriptName, getCellAuditLabels(instance))
+	cron := cronjob.NewCronJob(cronDef, r.RequeueTimeout)
+	_, err := cron.CreateOrPatch(ctx, h)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaCellAuditCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaCellAuditErrorMessage,
+			err.Error()))
+		return err
+	}
+
+	auditJob, err := getLastCompletedJob(
+		ctx, h.GetClient(), instance.Namespace, getCellAuditLabels(instance))
+	if err != nil {
+		return err
+	}
+	if auditJob != nil && (instance.Status.Audit == nil ||
+		instance.Status.Audit.LastAuditTime.Before(auditJob.Status.CompletionTime)) {
+		message, err := getJobTerminationMessage(
+			ctx, h.GetClient(), auditJob.Namespace, auditJob.Name)
+		if err != nil {
+			return err
+		}
+		report := cellAuditReport{}
+		if err := json.Unmarshal([]byte(message), &report); err != nil {
+			Log.Info("The audit findings are not reported by the job",
+				"job", auditJob.Name, "error", err.Error())
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				novav1.NovaCellAuditCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				novav1.NovaCellAuditErrorMessage,
+				"the Job "+auditJob.Name+" finished without reporting the findings"))
+			return nil
+		}
+		instance.Status.Audit = &novav1.NovaCellAuditStatus{
+			LastAuditTime:       *auditJob.Status.CompletionTime,
+			OrphanedAllocations: report.OrphanedAllocations,
+			UnmappedHosts:       report.UnmappedHosts,
+			AggregatesInSync:    report.AggregatesInSync,
+			Errors:              report.Errors,
+		}
+	}
+
+	switch {
+	case instance.Status.Audit == nil:
+		instance.Status.Conditions.Set(condition.UnknownCondition(
+			novav1.NovaCellAuditCondition,
+			condition.InitReason,
+			novav1.NovaCellAuditInitMessage))
+	case instance.Status.Audit.IsConsistent():
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaCellAuditCondition, novav1.NovaCellAuditMessage)
+	default:
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaCellAuditCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaCellAuditInconsistentMessage,
+			describeCellAuditFindings(instance.Status.Audit)))
+	}
+
+	return nil
+}
+
+// describeCellAuditFindings returns a human readable summary of the
+// inconsistencies found by the audit
+func describeCellAuditFindings(audit *novav1.NovaCellAuditStatus) string {
+	findings := []string{}
+	if audit.OrphanedAllocations > 0 {
+		findings = append(findings,
+			fmt.Sprintf("%d orphaned allocations", audit.OrphanedAllocations))
+	}
+	if len(audit.UnmappedHosts) > 0 {
+		findings = append(findings,
+			"unmapped hosts "+strings.Join(audit.UnmappedHosts, ","))
+	}
+	if !audit.AggregatesInSync {
+		findings = append(findings, "aggregates out of sync with placement")
+	}
+	if len(audit.Errors) > 0 {
+		findings = append(findings,
+			"failed steps: "+strings.Join(audit.Errors, ", "))
+	}
+	return strings.Join(findings, "; ")
+}
+
+// findNovaCellForAuditJob returns the NovaCell of the audit Job so that the
+// findings of the Job are reported when it finishes. The Job is owned by the
+// CronJob so it is not mapped to the NovaCell by ownership.
+func (r *NovaCellReconciler) findNovaCellForAuditJob(
+	ctx context.Context, src client.Object,
+) []reconcile.Request {
+	jobLabels := src.GetLabels()
+	if jobLabels[common.AppSelector] != NovaCellAuditLabelPrefix {
+		return nil
+	}
+	name := jobLabels[labels.GetOwnerNameLabelSelector(labels.GetGroupLabel(NovaCellLabelPrefix))]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Namespace: src.GetNamespace(),
+				Name:      name,
+			},
+		},
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/cronjob"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
+)
+
+// cellHostsReport is the report the cell hosts Job writes to the termination
+// message of its pod
+type cellHostsReport struct {
+	HostCount int `json:"hostCount"`
+	// Hosts is the newline separated list of hosts compressed with zlib and
+	// base64 encoded
+	Hosts string `json:"hosts"`
+}
+
+// decodeHosts returns the list of hosts of the report
+func (r cellHostsReport) decodeHosts() ([]string, error) {
+	compressed, err := base64.StdEncoding.DecodeString(r.Hosts)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	hosts := []string{}
+	if len(data) > 0 {
+		hosts = strings.Split(string(data), "\n")
+	}
+	if len(hosts) != r.HostCount {
+		return nil, fmt.Errorf("%d hosts are reported instead of %d", len(hosts), r.HostCount)
+	}
+	return hosts, nil
+}
+
+func getCellHostsLabels(instance *novav1.NovaCell) map[string]string {
+	return labels.GetLabels(
+		instance, labels.GetGroupLabel(NovaCellLabelPrefix),
+		map[string]string{common.AppSelector: NovaCellHostsLabelPrefix},
+	)
+}
+
+// ensureCellHostsReported ensures that the CronJob reporting the compute
+// hosts mapped to the cell exists and records the hosts reported by its last
+// finished Job. The hosts are informational for the cell but the aggregates
+// and the capacity of the cell are based on them.
+func (r *NovaCellReconciler) ensureCellHostsReported(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaCell,
+) error {
+	Log := r.GetLogger(ctx)
+
+	// cell0 has no compute hosts
+	if instance.Spec.CellName == novav1.Cell0Name {
+		return nil
+	}
+
+	configName, scriptName := getNovaManageJobSecretNames(instance)
+	cronDef := nova.CellHostsCronJob(
+		instance, configName, scriptName, getCellHostsLabels(instance))
+	cron := cronjob.NewCronJob(cronDef, r.RequeueTimeout)
+	_, err := cron.CreateOrPatch(ctx, h)
+	if err != nil {
+		return err
+	}
+
+	hostsJob, err := getLastCompletedJob(
+		ctx, h.GetClient(), instance.Namespace, getCellHostsLabels(instance))
+	if err != nil {
+		return err
+	}
+	if hostsJob == nil || (instance.Status.HostMappings != nil &&
+		!instance.Status.HostMappings.LastReportTime.Before(hostsJob.Status.CompletionTime)) {
+		return nil
+	}
+
+	message, err := getJobTerminationMessage(
+		ctx, h.GetClient(), hostsJob.Namespace, hostsJob.Name)
+	if err != nil {
+		return err
+	}
+	report := cellHostsReport{}
+	err = json.Unmarshal([]byte(message), &report)
+	var hosts []string
+	if err == nil {
+		hosts, err = report.decodeHosts()
+	}
+	if err != nil {
+		// The previously reported hosts are kept
+		Log.Info("The hosts of the cell are not reported by the job",
+			"job", hostsJob.Name, "error", err.Error())
+		return nil
+	}
+	instance.Status.HostMappings = &novav1.NovaCellHostMappingsStatus{
+		LastReportTime: *hostsJob.Status.CompletionTime,
+		Hosts:          hosts,
+	}
+	return nil
+}
+
+// findNovaCellForCellHostsJob returns the NovaCell of the hosts Job so that
+// the reported hosts are recorded when it finishes. The Job is owned by the
+// CronJob so it is not mapped to the NovaCell by ownership.
+func (r *NovaCellReconciler) findNovaCellForCellHostsJob(
+	ctx context.Context, src client.Object,
+) []reconcile.Request {
+	jobLabels := src.GetLabels()
+	if jobLabels[common.AppSelector] != NovaCellHostsLabelPrefix {
+		return nil
+	}
+	name := jobLabels[labels.GetOwnerNameLabelSelector(labels.GetGroupLabel(NovaCellLabelPrefix))]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Namespace: src.GetNamespace(),
+				Name:      name,
+			},
+		},
+	}
+}
@@ -18,20 +18,22 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -41,7 +43,7 @@ import (
 	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
 
 	gophercloud "github.com/gophercloud/gophercloud"
-	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/services"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 	networkv1 "github.com/k8snetworkplumbingwg/network-attachment-definition-client/pkg/apis/k8s.cni.cncf.io/v1"
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
@@ -68,6 +70,18 @@ const (
 	// NovaCellLabelPrefix - a unique, prefix used for the compute config
 	// Secret
 	NovaCellLabelPrefix = "nova-cell"
+	// NovaCellAuditLabelPrefix - a unique, prefix used for the labels of the
+	// cell audit CronJob and its Jobs
+	NovaCellAuditLabelPrefix = "nova-cell-audit"
+	// NovaCellHostsLabelPrefix - a unique, prefix used for the labels of the
+	// CronJob reporting the hosts of the cell and its Jobs
+	NovaCellHostsLabelPrefix = "nova-cell-hosts"
+	// NovaDatabaseBackupLabelPrefix - a unique, prefix used for the labels
+	// of the database backup Jobs
+	NovaDatabaseBackupLabelPrefix = "nova-db-backup"
+	// NovaDatabaseRestoreLabelPrefix - a unique, prefix used for the labels
+	// of the database restore Jobs
+	NovaDatabaseRestoreLabelPrefix = "nova-db-restore"
 	// NovaLabelPrefix - a unique, prefix used for labels on Nova CR level jobs
 	// and Secrets
 	NovaLabelPrefix = "nova"
@@ -95,6 +109,9 @@ const (
 	// TransportURLSelector is the name of key in the internal cell
 	// Secret for the cell message bus transport URL
 	TransportURLSelector = "transport_url"
+	// NotificationTransportURLSelector is the name of key in the internal
+	// Secrets for the transport URL of the notifications message bus
+	NotificationTransportURLSelector = "notification_transport_url"
 
 	// fields to index to reconcile when change
 	passwordSecretField        = ".spec.secret"
@@ -112,6 +129,9 @@ const (
 	// NovaCell0DatabaseName - the name of the DB to store the cell schema for
 	// cell0
 	NovaCell0DatabaseName = "nova_cell0"
+	// novaAPIDatabaseCRName is the name of the MariaDBDatabase CR of the top
+	// level nova DB
+	novaAPIDatabaseCRName = "nova-api"
 )
 
 type conditionsGetter interface {
@@ -168,71 +188,92 @@ func ensureTopology(
 	return topology, nil
 }
 
-func cleanNovaServiceFromNovaDb(
-	computeClient *gophercloud.ServiceClient,
-	serviceName string,
-	l logr.Logger,
-	replicaCount int32,
-	cellName string,
-) error {
-	opts := services.ListOpts{
-		Binary: serviceName,
+func allSubConditionIsTrue(conditionsGetter conditionsGetter) bool {
+	// It assumes that all of our conditions report success via the True status
+	for _, c := range conditionsGetter.GetConditions() {
+		if c.Type == condition.ReadyCondition {
+			continue
+		}
+		if c.Status != corev1.ConditionTrue {
+			return false
+		}
 	}
+	return true
+}
 
-	allPages, err := services.List(computeClient, opts).AllPages()
-	if err != nil {
-		return err
+// getJobTerminationMessage returns the termination message of the most
+// recently terminated container of the pods of the Job
+func getJobTerminationMessage(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	jobName string,
+) (string, error) {
+	terminated, err := getJobTerminatedState(ctx, c, namespace, jobName)
+	if err != nil || terminated == nil {
+		return "", err
 	}
+	return strings.TrimSpace(terminated.Message), nil
+}
 
-	allServices, err := services.ExtractServices(allPages)
+// getJobTerminatedState returns the state of the most recently terminated
+// container of the pods of the Job. It is nil if no container is terminated.
+func getJobTerminatedState(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	jobName string,
+) (*corev1.ContainerStateTerminated, error) {
+	pods := &corev1.PodList{}
+	err := c.List(
+		ctx, pods, client.InNamespace(namespace),
+		client.MatchingLabels{"job-name": jobName})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, service := range allServices {
-		// delete only if serviceHost is for our cell. If no cell is
-		// provided (e.g. scheduler cleanup case) then this check is
-		// non-operational (noop)
-		if !strings.Contains(service.Host, cellName) {
-			continue
-		}
-
-		// extract 0 (suffix) from hostname nova-scheduler-0
-		hostSplits := strings.Split(service.Host, "-")
-		hostIndexStr := hostSplits[len(hostSplits)-1]
-
-		hostIndex, err := strconv.Atoi(hostIndexStr)
-		if err != nil {
-			return err
-		}
-
-		// name index start from 0
-		// which means if replicaCount is 1, only nova-scheduler-0 is valid case
-		// so delete >= 1
-		if hostIndex >= int(replicaCount) {
-			rsp := services.Delete(computeClient, service.ID)
-			if rsp.Err != nil {
-				l.Error(rsp.Err, "Failed to delete service", "service", service, "response", rsp)
-				return rsp.Err
+	var last *corev1.ContainerStateTerminated
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			terminated := status.State.Terminated
+			if terminated == nil {
+				continue
+			}
+			if last == nil || last.FinishedAt.Before(&terminated.FinishedAt) {
+				last = terminated.DeepCopy()
 			}
-			l.Info("Deleted service", "service", service)
 		}
 	}
-
-	return err
+	return last, nil
 }
 
-func allSubConditionIsTrue(conditionsGetter conditionsGetter) bool {
-	// It assumes that all of our conditions report success via the True status
-	for _, c := range conditionsGetter.GetConditions() {
-		if c.Type == condition.ReadyCondition {
+// getLastCompletedJob returns the most recently completed Job with the given
+// labels or nil if no such Job exists
+func getLastCompletedJob(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	jobLabels map[string]string,
+) (*batchv1.Job, error) {
+	jobs := &batchv1.JobList{}
+	err := c.List(ctx, jobs,
+		client.InNamespace(namespace),
+		client.MatchingLabels(jobLabels))
+	if err != nil {
+		return nil, err
+	}
+
+	var last *batchv1.Job
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if job.Status.CompletionTime == nil {
 			continue
 		}
-		if c.Status != corev1.ConditionTrue {
-			return false
+		if last == nil || last.Status.CompletionTime.Before(job.Status.CompletionTime) {
+			last = job
 		}
 	}
-	return true
+	return last, nil
 }
 
 type conditionUpdater interface {
@@ -360,6 +401,7 @@ type ReconcilerBase struct {
 	Client         client.Client
 	Kclient        kubernetes.Interface
 	Scheme         *runtime.Scheme
+	Recorder       record.EventRecorder
 	RequeueTimeout time.Duration
 }
 
@@ -382,6 +424,7 @@ func NewReconcilerBase(
 		Client:         mgr.GetClient(),
 		Scheme:         mgr.GetScheme(),
 		Kclient:        kclient,
+		Recorder:       mgr.GetEventRecorderFor("nova-operator"),
 		RequeueTimeout: time.Duration(5) * time.Second,
 	}
 }
@@ -425,6 +468,33 @@ func NewReconcilers(mgr ctrl.Manager, kclient *kubernetes.Clientset) *Reconciler
 			"NovaCompute": &NovaComputeReconciler{
 				ReconcilerBase: NewReconcilerBase(mgr, kclient),
 			},
+			"NovaFlavor": &NovaFlavorReconciler{
+				ReconcilerBase: NewReconcilerBase(mgr, kclient),
+			},
+			"NovaAggregate": &NovaAggregateReconciler{
+				ReconcilerBase: NewReconcilerBase(mgr, kclient),
+			},
+			"NovaQuotaClass": &NovaQuotaClassReconciler{
+				ReconcilerBase: NewReconcilerBase(mgr, kclient),
+			},
+			"NovaProjectQuota": &NovaProjectQuotaReconciler{
+				ReconcilerBase: NewReconcilerBase(mgr, kclient),
+			},
+			"NovaHostDrain": &NovaHostDrainReconciler{
+				ReconcilerBase: NewReconcilerBase(mgr, kclient),
+			},
+			"NovaHostEvacuation": &NovaHostEvacuationReconciler{
+				ReconcilerBase: NewReconcilerBase(mgr, kclient),
+			},
+			"NovaManageCommand": &NovaManageCommandReconciler{
+				ReconcilerBase: NewReconcilerBase(mgr, kclient),
+			},
+			"NovaDatabaseBackup": &NovaDatabaseBackupReconciler{
+				ReconcilerBase: NewReconcilerBase(mgr, kclient),
+			},
+			"NovaDatabaseRestore": &NovaDatabaseRestoreReconciler{
+				ReconcilerBase: NewReconcilerBase(mgr, kclient),
+			},
 		}}
 }
 
@@ -600,6 +670,7 @@ type clientAuth interface {
 	GetKeystoneAuthURL() string
 	GetKeystoneUser() string
 	GetCABundleSecretName() string
+	GetKeystoneServiceIdentity() novav1.KeystoneServiceIdentity
 }
 
 func getNovaClient(
@@ -644,14 +715,21 @@ func getNovaClient(
 		}
 	}
 
+	identity := auth.GetKeystoneServiceIdentity()
 	cfg := openstack.AuthOpts{
 		AuthURL:    authURL,
 		Username:   auth.GetKeystoneUser(),
 		Password:   password,
-		DomainName: "Default",   // fixme",
-		Region:     "regionOne", // fixme",
-		TenantName: "service",   // fixme",
-		TLS:        tlsConfig,
+		DomainName: identity.ServiceUserDomain,
+		Region:     identity.Region,
+		TenantName: identity.ServiceProjectName,
+		// The project domain can differ from the domain of the user so the
+		// scope needs to be explicit
+		Scope: &gophercloud.AuthScope{
+			ProjectName: identity.ServiceProjectName,
+			DomainName:  identity.ServiceProjectDomain,
+		},
+		TLS: tlsConfig,
 	}
 	endpointOpts := gophercloud.EndpointOpts{
 		Region:       cfg.Region,
@@ -669,10 +747,169 @@ func getNovaClient(
 	return client, nil
 }
 
+// getNovaAPIClient returns a compute API client using the service
+// credentials of the NovaAPI that belongs to the Nova CR named novaInstance.
+// It reports the state of the NovaAPI in the NovaAPIReadyCondition. If the
+// NovaAPI is not usable yet then the returned client is nil and the Result
+// asks for a requeue.
+func getNovaAPIClient(
+	ctx context.Context,
+	h *helper.Helper,
+	namespace string,
+	novaInstance string,
+	conditionUpdater conditionUpdater,
+	requeueTimeout time.Duration,
+	l logr.Logger,
+) (*gophercloud.ServiceClient, ctrl.Result, error) {
+	apiName := novaInstance + "-api"
+	api := &novav1.NovaAPI{}
+	err := h.GetClient().Get(ctx, types.NamespacedName{Namespace: namespace, Name: apiName}, api)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		conditionUpdater.Set(condition.FalseCondition(
+			novav1.NovaAPIReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaAPIReadyErrorMessage,
+			err.Error()))
+		return nil, ctrl.Result{}, err
+	}
+	if k8s_errors.IsNotFound(err) || !api.IsReady() {
+		conditionUpdater.Set(condition.FalseCondition(
+			novav1.NovaAPIReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaAPIReadyWaitingMessage,
+			apiName))
+		return nil, ctrl.Result{RequeueAfter: requeueTimeout}, nil
+	}
+
+	_, result, secret, err := ensureSecret(
+		ctx,
+		types.NamespacedName{Namespace: namespace, Name: api.Spec.Secret},
+		[]string{ServicePasswordSelector},
+		h.GetClient(),
+		conditionUpdater,
+		requeueTimeout,
+	)
+	if (err != nil || result != ctrl.Result{}) {
+		return nil, result, err
+	}
+
+	computeClient, err := getNovaClient(ctx, h, api, string(secret.Data[ServicePasswordSelector]), l)
+	if err != nil {
+		conditionUpdater.Set(condition.FalseCondition(
+			novav1.NovaAPIReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaAPIReadyErrorMessage,
+			err.Error()))
+		return nil, ctrl.Result{}, err
+	}
+
+	conditionUpdater.MarkTrue(novav1.NovaAPIReadyCondition, novav1.NovaAPIReadyMessage, apiName)
+	return computeClient, ctrl.Result{}, nil
+}
+
+// isComputeNotFound returns true if the error is a 404 response from the
+// compute API
+func isComputeNotFound(err error) bool {
+	var notFound gophercloud.ErrDefault404
+	return errors.As(err, &notFound)
+}
+
+// computeQuotaSet holds the quota limits managed by the operator as the
+// compute API represents them
+type computeQuotaSet struct {
+	Instances          int `json:"instances"`
+	Cores              int `json:"cores"`
+	RAM                int `json:"ram"`
+	KeyPairs           int `json:"key_pairs"`
+	MetadataItems      int `json:"metadata_items"`
+	ServerGroups       int `json:"server_groups"`
+	ServerGroupMembers int `json:"server_group_members"`
+}
+
+// toQuotaLimits converts the quota set to NovaQuotaLimits with every limit
+// set
+func (q computeQuotaSet) toQuotaLimits() novav1.NovaQuotaLimits {
+	return novav1.NovaQuotaLimits{
+		Instances:          &q.Instances,
+		Cores:              &q.Cores,
+		RAM:                &q.RAM,
+		KeyPairs:           &q.KeyPairs,
+		MetadataItems:      &q.MetadataItems,
+		ServerGroups:       &q.ServerGroups,
+		ServerGroupMembers: &q.ServerGroupMembers,
+	}
+}
+
+// getQuotaLimitsDiff returns the limits, keyed by their compute API name,
+// that are set in the desired limits but differ from the current quota set
+func getQuotaLimitsDiff(desired novav1.NovaQuotaLimits, current computeQuotaSet) map[string]int {
+	diff := map[string]int{}
+	for _, limit := range []struct {
+		name    string
+		desired *int
+		current int
+	}{
+		{"instances", desired.Instances, current.Instances},
+		{"cores", desired.Cores, current.Cores},
+		{"ram", desired.RAM, current.RAM},
+		{"key_pairs", desired.KeyPairs, current.KeyPairs},
+		{"metadata_items", desired.MetadataItems, current.MetadataItems},
+		{"server_groups", desired.ServerGroups, current.ServerGroups},
+		{"server_group_members", desired.ServerGroupMembers, current.ServerGroupMembers},
+	} {
+		if limit.desired != nil && *limit.desired != limit.current {
+			diff[limit.name] = *limit.desired
+		}
+	}
+	return diff
+}
+
+// computeServer holds the fields of a server needed to follow its move off
+// a compute host. The admin only attributes are not part of servers.Server
+// in gophercloud.
+type computeServer struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Host      string            `json:"OS-EXT-SRV-ATTR:host"`
+	TaskState string            `json:"OS-EXT-STS:task_state"`
+	Metadata  map[string]string `json:"metadata"`
+	Fault     struct {
+		Message string `json:"message"`
+	} `json:"fault"`
+}
+
+// listServersOnHost returns the servers of every project on the compute host
+func listServersOnHost(
+	computeClient *gophercloud.ServiceClient,
+	opts servers.ListOpts,
+) ([]computeServer, error) {
+	opts.AllTenants = true
+	allPages, err := servers.List(computeClient, opts).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	onHost := []computeServer{}
+	err = servers.ExtractServersInto(allPages, &onHost)
+	if err != nil {
+		return nil, err
+	}
+	return onHost, nil
+}
+
 func getCellDatabaseName(cellName string) string {
 	return "nova_" + cellName
 }
 
+// getCellDatabaseCRName returns the name of the MariaDBDatabase CR of the
+// cell DB
+func getCellDatabaseCRName(cellName string) string {
+	return "nova-" + cellName
+}
+
 func getMemcachedInstance(instance *novav1.Nova, cellTemplate novav1.NovaCellTemplate) string {
 	if cellTemplate.MemcachedInstance != "" {
 		return cellTemplate.MemcachedInstance
@@ -0,0 +1,191 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/secret"
+	util "github.com/openstack-k8s-operators/lib-common/modules/common/util"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
+)
+
+// configRevisionHashLength is the number of characters of the config hash
+// used in the name of the config revision Secrets
+const configRevisionHashLength = 10
+
+func getConfigRevisionNumber(revision *corev1.Secret) int {
+	number, err := strconv.Atoi(revision.Annotations[novav1.ConfigRevisionNumberAnnotation])
+	if err != nil {
+		return 0
+	}
+	return number
+}
+
+// restoreServiceConfig overwrites the service config Secret with the config
+// stored in the source Secret and returns the hash of the service config
+func restoreServiceConfig(
+	ctx context.Context,
+	h *helper.Helper,
+	instance client.Object,
+	source *corev1.Secret,
+) (string, error) {
+	config := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nova.GetServiceConfigSecretName(instance.GetName()),
+			Namespace: instance.GetNamespace(),
+		},
+		Data: source.Data,
+	}
+	hash, _, err := secret.CreateOrPatchSecret(ctx, h, instance, config)
+	return hash, err
+}
+
+// ensureConfigRevisionRestored overwrites the service config Secret with the
+// config of the requested config revision
+func (r *ReconcilerBase) ensureConfigRevisionRestored(
+	ctx context.Context,
+	h *helper.Helper,
+	instance client.Object,
+	revisionName string,
+	hashes *map[string]env.Setter,
+) error {
+	revision, _, err := secret.GetSecret(ctx, h, revisionName, instance.GetNamespace())
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			return fmt.Errorf("requested config revision %s not found", revisionName)
+		}
+		return err
+	}
+	if revision.Labels[novav1.ConfigRevisionOfLabel] != instance.GetName() {
+		return fmt.Errorf(
+			"requested Secret %s is not a config revision of %s", revisionName, instance.GetName())
+	}
+
+	hash, err := restoreServiceConfig(ctx, h, instance, revision)
+	if err != nil {
+		return err
+	}
+	(*hashes)[nova.GetServiceConfigSecretName(instance.GetName())] = env.SetValue(hash)
+	return nil
+}
+
+// ensureConfigRevision stores the service config in an immutable config
+// revision Secret and marks it as the most recent revision of the service.
+// The oldest revisions beyond the history limit are deleted. It returns the
+// name of the config revision.
+func (r *ReconcilerBase) ensureConfigRevision(
+	ctx context.Context,
+	h *helper.Helper,
+	instance client.Object,
+	historyLimit int,
+) (string, error) {
+	Log := r.GetLogger(ctx)
+
+	config, hash, err := secret.GetSecret(
+		ctx, h, nova.GetServiceConfigSecretName(instance.GetName()), instance.GetNamespace())
+	if err != nil {
+		return "", err
+	}
+	revisionName := nova.GetConfigRevisionSecretName(
+		instance.GetName(), hash[:configRevisionHashLength])
+
+	revisions := &corev1.SecretList{}
+	err = h.GetClient().List(
+		ctx, revisions, client.InNamespace(instance.GetNamespace()),
+		client.MatchingLabels{novav1.ConfigRevisionOfLabel: instance.GetName()})
+	if err != nil {
+		return "", err
+	}
+
+	latest := 0
+	var active *corev1.Secret
+	for i := range revisions.Items {
+		revision := &revisions.Items[i]
+		latest = max(latest, getConfigRevisionNumber(revision))
+		if revision.Name == revisionName {
+			active = revision
+		}
+	}
+
+	if active == nil {
+		revision := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      revisionName,
+				Namespace: instance.GetNamespace(),
+				Labels: map[string]string{
+					novav1.ConfigRevisionOfLabel: instance.GetName(),
+				},
+				Annotations: map[string]string{
+					novav1.ConfigRevisionNumberAnnotation: strconv.Itoa(latest + 1),
+				},
+			},
+			Immutable: ptr.To(true),
+			Data:      config.Data,
+		}
+		err = controllerutil.SetControllerReference(instance, revision, h.GetScheme())
+		if err != nil {
+			return "", err
+		}
+		err = h.GetClient().Create(ctx, revision)
+		if err != nil {
+			return "", err
+		}
+		Log.Info("Created config revision", "revision", revisionName)
+		revisions.Items = append(revisions.Items, *revision)
+	} else if getConfigRevisionNumber(active) != latest {
+		// the config of an older revision is deployed again so it becomes
+		// the most recent revision
+		patch := client.MergeFrom(active.DeepCopy())
+		active.Annotations = util.MergeStringMaps(
+			active.Annotations,
+			map[string]string{
+				novav1.ConfigRevisionNumberAnnotation: strconv.Itoa(latest + 1),
+			})
+		err = h.GetClient().Patch(ctx, active, patch)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	sort.Slice(revisions.Items, func(i, j int) bool {
+		return getConfigRevisionNumber(&revisions.Items[i]) > getConfigRevisionNumber(&revisions.Items[j])
+	})
+	for i := max(historyLimit, 1); i < len(revisions.Items); i++ {
+		err = h.GetClient().Delete(ctx, &revisions.Items[i])
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return "", err
+		}
+		Log.Info("Deleted config revision", "revision", revisions.Items[i].Name)
+	}
+
+	return revisionName, nil
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/secret"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/tls"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// novaDatabase is a database of the Nova deployment the backup and the
+// restore Jobs access
+type novaDatabase struct {
+	// Name is the name of the DB schema, e.g. nova_api
+	Name string
+	// CRName is the name of the MariaDBDatabase CR of the DB
+	CRName string
+	// Account is the name of the MariaDBAccount used to access the DB
+	Account string
+	// Hostname is the hostname of the DB
+	Hostname string
+}
+
+// getNovaDatabaseCells returns the Nova CR and the NovaCells with the given
+// names together with cell0. Every cell of the Nova deployment is returned
+// if no cell name is given. The returned waitingFor describes what is
+// missing if the Nova CR or a NovaCell does not exist yet.
+func getNovaDatabaseCells(
+	ctx context.Context,
+	c client.Client,
+	namespace string,
+	novaName string,
+	cellNames []string,
+) (
+	novaInstance *novav1.Nova,
+	cells map[string]*novav1.NovaCell,
+	resolvedCellNames []string,
+	waitingFor string,
+	err error,
+) {
+	novaInstance = &novav1.Nova{}
+	err = c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: novaName}, novaInstance)
+	if k8s_errors.IsNotFound(err) {
+		return nil, nil, nil, "Nova " + novaName, nil
+	}
+	if err != nil {
+		return nil, nil, nil, "", err
+	}
+
+	if len(cellNames) == 0 {
+		for cellName := range novaInstance.Spec.CellTemplates {
+			cellNames = append(cellNames, cellName)
+		}
+		sort.Strings(cellNames)
+	}
+
+	cells = map[string]*novav1.NovaCell{}
+	// cell0 is always needed as it knows the hostname of the API DB and
+	// runs the conductor image of the deployment
+	for _, cellName := range append([]string{novav1.Cell0Name}, cellNames...) {
+		if _, exists := novaInstance.Spec.CellTemplates[cellName]; !exists {
+			return nil, nil, nil, "", fmt.Errorf(
+				"cell %s is not defined in Nova %s", cellName, novaName)
+		}
+		cell := &novav1.NovaCell{}
+		err = c.Get(ctx, types.NamespacedName{
+			Namespace: namespace,
+			Name:      getNovaCellCRName(novaName, cellName),
+		}, cell)
+		if k8s_errors.IsNotFound(err) {
+			return nil, nil, nil, "NovaCell " + getNovaCellCRName(novaName, cellName), nil
+		}
+		if err != nil {
+			return nil, nil, nil, "", err
+		}
+		cells[cellName] = cell
+	}
+	return novaInstance, cells, cellNames, "", nil
+}
+
+// getNovaDatabases returns the API DB if requested and the DBs of the given
+// cells. They are created with the same MariaDBDatabase CRs and accounts as
+// in ensureAPIDB and ensureCellDB.
+func getNovaDatabases(
+	novaInstance *novav1.Nova,
+	cells map[string]*novav1.NovaCell,
+	cellNames []string,
+	withAPIDatabase bool,
+) []novaDatabase {
+	databases := []novaDatabase{}
+	if withAPIDatabase {
+		databases = append(databases, novaDatabase{
+			Name:     NovaAPIDatabaseName,
+			CRName:   novaAPIDatabaseCRName,
+			Account:  novaInstance.Spec.APIDatabaseAccount,
+			Hostname: cells[novav1.Cell0Name].Spec.APIDatabaseHostname,
+		})
+	}
+	for _, cellName := range cellNames {
+		databases = append(databases, novaDatabase{
+			Name:     getCellDatabaseName(cellName),
+			CRName:   getCellDatabaseCRName(cellName),
+			Account:  cells[cellName].Spec.CellDatabaseAccount,
+			Hostname: cells[cellName].Spec.CellDatabaseHostname,
+		})
+	}
+	return databases
+}
+
+// getNovaDatabaseNames returns the names of the DB schemas of the databases
+func getNovaDatabaseNames(databases []novaDatabase) []string {
+	names := []string{}
+	for _, db := range databases {
+		names = append(names, db.Name)
+	}
+	return names
+}
+
+// ensureDatabaseJobSecrets ensures the config and the scripts Secrets of
+// the backup and the restore Jobs. The config Secret has a mysql client
+// config with the credentials of the MariaDBAccount for each database.
+func ensureDatabaseJobSecrets(
+	ctx context.Context,
+	h *helper.Helper,
+	novaInstance *novav1.Nova,
+	databases []novaDatabase,
+	configName string,
+	scriptName string,
+	cmLabels map[string]string,
+) error {
+	var tlsCfg *tls.Service
+	if novaInstance.Spec.APIServiceTemplate.TLS.Ca.CaBundleSecretName != "" {
+		tlsCfg = &tls.Service{}
+	}
+
+	extraData := map[string]string{}
+	for _, db := range databases {
+		mariaDB, err := mariadbv1.GetDatabaseByNameAndAccount(
+			ctx, h, db.CRName, db.Account, novaInstance.Namespace)
+		if err != nil {
+			return err
+		}
+		extraData[db.Name+".cnf"] = fmt.Sprintf(
+			"%s\nhost=%s\nport=3306\nuser=%s\npassword=%s\n",
+			mariaDB.GetDatabaseClientConfig(tlsCfg),
+			db.Hostname,
+			mariaDB.GetAccount().Spec.UserName,
+			string(mariaDB.GetSecret().Data[mariadbv1.DatabasePasswordSelector]))
+	}
+
+	cms := []util.Template{
+		{
+			Name:         scriptName,
+			Namespace:    h.GetBeforeObject().GetNamespace(),
+			Type:         util.TemplateTypeScripts,
+			InstanceType: "nova-db-backup",
+			Labels:       cmLabels,
+		},
+		{
+			Name:          configName,
+			Namespace:     h.GetBeforeObject().GetNamespace(),
+			Type:          util.TemplateTypeConfig,
+			InstanceType:  "nova-db-backup",
+			ConfigOptions: map[string]interface{}{},
+			Labels:        cmLabels,
+			CustomData:    extraData,
+			Annotations:   map[string]string{},
+		},
+	}
+
+	configHash := make(map[string]env.Setter)
+	return secret.EnsureSecrets(ctx, h, h.GetBeforeObject(), cms, &configHash)
+}
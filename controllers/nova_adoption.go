@@ -0,0 +1,243 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	job "github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	util "github.com/openstack-k8s-operators/lib-common/modules/common/util"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
+)
+
+// adoptionReport is the report the adoption check Job writes to the
+// termination message of its pod
+type adoptionReport struct {
+	// Mismatches found between the existing DBs and the Spec
+	Mismatches []string `json:"mismatches"`
+	// CellMappings are the existing cell mappings matched to the cells of
+	// the Spec keyed by the name of the cell
+	CellMappings map[string]novav1.NovaCellMapping `json:"cellMappings"`
+}
+
+// ensureAdoptionChecked verifies in adoption mode that the existing DBs and
+// cell mappings match the Spec before any cell is deployed. It returns true
+// if the cells can be deployed. The matched cell mappings are recorded in
+// the status so that the cell mapping jobs update them instead of creating
+// new ones. Once the check passed it is not run again.
+func (r *NovaReconciler) ensureAdoptionChecked(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.Nova,
+	orderedCellNames []string,
+	apiDB *mariadbv1.Database,
+	apiDBStatus nova.DatabaseStatus,
+	cellDBs map[string]*nova.Database,
+	cellMQs map[string]*nova.MessageBus,
+	keystoneAuthURL string,
+	secret corev1.Secret,
+	image string,
+) (bool, error) {
+	Log := r.GetLogger(ctx)
+
+	if !instance.Spec.Adoption {
+		instance.Status.AdoptionMismatches = nil
+		return true, nil
+	}
+	if instance.Status.AdoptionCompleted {
+		// The existing cells are adopted, cells added or removed later are
+		// handled as in any other deployment
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaAdoptionReadyCondition, novav1.NovaAdoptionReadyMessage)
+		return true, nil
+	}
+
+	// The existing cell mappings are matched to the hosts of the DBs and
+	// the message buses so we need all of them
+	ready := apiDBStatus == nova.DBCompleted
+	for _, cellName := range orderedCellNames {
+		ready = ready && cellDBs[cellName].Status == nova.DBCompleted &&
+			cellMQs[cellName].Status == nova.MQCompleted
+	}
+	if !ready {
+		// We will be reconciled when the DBs and the message buses are ready
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaAdoptionReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaAdoptionReadyWaitingMessage))
+		return false, nil
+	}
+
+	expectedCells := []nova.AdoptionExpectedCell{}
+	for _, cellName := range orderedCellNames {
+		expected := nova.AdoptionExpectedCell{
+			Name:         cellName,
+			DatabaseHost: cellDBs[cellName].Database.GetDatabaseHostname(),
+			DatabaseName: getCellDatabaseName(cellName),
+		}
+		// cell0 has no message bus in its cell mapping
+		if cellName != novav1.Cell0Name {
+			expected.TransportURLHost = nova.TransportURLHosts(cellMQs[cellName].TransportURL)
+		}
+		expectedCells = append(expectedCells, expected)
+	}
+
+	// The check runs with the nova-manage config of cell0 but before the
+	// NovaCell of cell0 is created so the config is generated for a
+	// NovaCell that only exists in memory
+	cell0DB := cellDBs[novav1.Cell0Name].Database
+	adoptionCell := &novav1.NovaCell{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      instance.Name + "-adoption",
+			Namespace: instance.Namespace,
+		},
+		Spec: novav1.NovaCellSpec{
+			CellName:                novav1.Cell0Name,
+			CellDatabaseHostname:    cell0DB.GetDatabaseHostname(),
+			CellDatabaseAccount:     instance.Spec.CellTemplates[novav1.Cell0Name].CellDatabaseAccount,
+			KeystoneAuthURL:         keystoneAuthURL,
+			ServiceUser:             instance.Spec.ServiceUser,
+			KeystoneServiceIdentity: instance.Spec.KeystoneServiceIdentity,
+		},
+	}
+	configHash, scriptName, configName, err := r.ensureNovaManageJobSecret(
+		ctx, h, instance, adoptionCell, secret, apiDB.GetDatabaseHostname(), "", cell0DB)
+	if err == nil {
+		var inputHash string
+		inputHash, err = util.HashOfInputHashes(configHash)
+		if err == nil {
+			return r.ensureAdoptionCheckJob(
+				ctx, h, instance, image, expectedCells, configName, scriptName, inputHash)
+		}
+	}
+	instance.Status.Conditions.Set(condition.FalseCondition(
+		novav1.NovaAdoptionReadyCondition,
+		condition.ErrorReason,
+		condition.SeverityError,
+		novav1.NovaAdoptionReadyErrorMessage,
+		err.Error()))
+	Log.Error(err, "Failed to generate the config of the adoption check")
+	return false, err
+}
+
+func (r *NovaReconciler) ensureAdoptionCheckJob(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.Nova,
+	image string,
+	expectedCells []nova.AdoptionExpectedCell,
+	configName string,
+	scriptName string,
+	inputHash string,
+) (bool, error) {
+	Log := r.GetLogger(ctx)
+
+	labels := map[string]string{
+		common.AppSelector: NovaLabelPrefix,
+	}
+	jobDef, err := nova.AdoptionCheckJob(
+		instance, image, expectedCells, configName, scriptName, inputHash, labels)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaAdoptionReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaAdoptionReadyErrorMessage,
+			err.Error()))
+		return false, err
+	}
+	checkJob := job.NewJob(
+		jobDef, "adoptioncheck", instance.Spec.PreserveJobs, r.RequeueTimeout,
+		instance.Status.Hash[novav1.AdoptionCheckHashKey])
+	result, err := checkJob.DoJob(ctx, h)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaAdoptionReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaAdoptionReadyErrorMessage,
+			err.Error()))
+		return false, err
+	}
+	if (result != ctrl.Result{}) {
+		// We will be reconciled when the Job status changes
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaAdoptionReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaAdoptionReadyRunningMessage))
+		return false, nil
+	}
+
+	if checkJob.HasChanged() {
+		message, err := getJobTerminationMessage(ctx, h.GetClient(), jobDef.Namespace, jobDef.Name)
+		if err != nil {
+			return false, err
+		}
+		report := adoptionReport{}
+		if err := json.Unmarshal([]byte(message), &report); err != nil {
+			Log.Info("The result of the adoption check is not reported by the job",
+				"job", jobDef.Name, "report", message)
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				novav1.NovaAdoptionReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityError,
+				novav1.NovaAdoptionReadyErrorMessage,
+				"the Job "+jobDef.Name+" finished without reporting the result of the check"))
+			return false, nil
+		}
+
+		instance.Status.AdoptionMismatches = report.Mismatches
+		if len(report.Mismatches) == 0 {
+			for cellName, mapping := range report.CellMappings {
+				instance.Status.CellMappings[getNovaCellCRName(instance.Name, cellName)] = mapping
+			}
+		}
+		instance.Status.Hash[novav1.AdoptionCheckHashKey] = checkJob.GetHash()
+		Log.Info("Adoption check finished", "mismatches", report.Mismatches)
+	}
+
+	if len(instance.Status.AdoptionMismatches) > 0 {
+		// The check is re-run when its input changes or the
+		// AdoptionRecheckAnnotation is set to a new value
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaAdoptionReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaAdoptionReadyMismatchMessage,
+			strings.Join(instance.Status.AdoptionMismatches, "; ")))
+		return false, nil
+	}
+
+	instance.Status.AdoptionCompleted = true
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaAdoptionReadyCondition, novav1.NovaAdoptionReadyMessage)
+	return true, nil
+}
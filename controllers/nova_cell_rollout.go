@@ -0,0 +1,76 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
+)
+
+// cellRollout tracks the cell by cell rollout of the changes of the cells
+// while the cells are reconciled in order
+type cellRollout struct {
+	strategy         *novav1.NovaRolloutStrategy
+	unavailableCells []string
+	failedCell       string
+}
+
+func newCellRollout(instance *novav1.Nova) *cellRollout {
+	return &cellRollout{strategy: instance.Spec.RolloutStrategy}
+}
+
+// isHeld returns true if the changes cannot be rolled out to the next cell
+// yet as too many previous cells are not Ready or the rollout is paused by a
+// failed cell. Without a rollout strategy the changes are rolled out to
+// every cell at once.
+func (r *cellRollout) isHeld() bool {
+	if r.strategy == nil {
+		return false
+	}
+	return r.failedCell != "" || len(r.unavailableCells) >= r.strategy.MaxUnavailableCells
+}
+
+// record records the state of a reconciled cell
+func (r *cellRollout) record(
+	cellName string, cell *novav1.NovaCell, status nova.CellDeploymentStatus,
+) {
+	if status == nova.CellReady {
+		return
+	}
+	r.unavailableCells = append(r.unavailableCells, cellName)
+	if r.failedCell == "" && isCellFailed(cell, status) {
+		r.failedCell = cellName
+	}
+}
+
+// isCellFailed returns true if the cell failed to deploy or its NovaCell
+// reports an error
+func isCellFailed(cell *novav1.NovaCell, status nova.CellDeploymentStatus) bool {
+	switch status {
+	case nova.CellFailed, nova.CellMappingFailed, nova.CellComputeDiscoveryFailed:
+		return true
+	}
+	if cell == nil {
+		return false
+	}
+	ready := cell.Status.Conditions.Get(condition.ReadyCondition)
+	return ready != nil && ready.Status == corev1.ConditionFalse && ready.Reason == condition.ErrorReason
+}
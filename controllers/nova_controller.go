@@ -18,8 +18,10 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	batchv1 "k8s.io/api/batch/v1"
@@ -86,6 +88,7 @@ func (r *NovaReconciler) GetLogger(ctx context.Context) logr.Logger {
 // +kubebuilder:rbac:groups=rabbitmq.openstack.org,resources=transporturls,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=memcached.openstack.org,resources=memcacheds,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=memcached.openstack.org,resources=memcacheds/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaaggregates,verbs=get;list;watch;create;update;patch;delete
 
 // service account, role, rolebinding
 // +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch
@@ -155,6 +158,10 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
 			panic(r)
 		}
+		// The telemetry condition only warns about notifications that are
+		// not emitted so it does not affect the Ready condition
+		telemetryCondition := instance.Status.Conditions.Get(novav1.NovaTelemetryReadyCondition)
+		instance.Status.Conditions.Remove(novav1.NovaTelemetryReadyCondition)
 		// update the Ready condition based on the sub conditions
 		if allSubConditionIsTrue(instance.Status) {
 			instance.Status.Conditions.MarkTrue(
@@ -167,6 +174,9 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 			instance.Status.Conditions.Set(
 				instance.Status.Conditions.Mirror(condition.ReadyCondition))
 		}
+		if telemetryCondition != nil {
+			instance.Status.Conditions.Set(telemetryCondition)
+		}
 		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
 		err := h.PatchInstance(ctx, instance)
 		if err != nil {
@@ -262,6 +272,20 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 	}
 	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
 
+	// New container images are only deployed after the upgrade check passed
+	// with them. Until then the services are kept on the deployed images.
+	err = r.ensureUpgradeChecked(ctx, h, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// The services are upgraded in order so the images of each cell depend
+	// on the phase of the upgrade
+	cellImages, err := r.ensureUpgradeProgressed(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	_, err = ensureMemcached(ctx, h, instance.Namespace, instance.Spec.MemcachedInstance, &instance.Status.Conditions)
 	if err != nil {
 		return ctrl.Result{}, err
@@ -315,13 +339,9 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 
 	// We need to create a list of cellNames to iterate on and as the map
 	// iteration order is undefined we need to make sure that cell0 is the
-	// first to allow dependency handling during ensureCell calls.
-	orderedCellNames := []string{novav1.Cell0Name}
-	for cellName := range instance.Spec.CellTemplates {
-		if cellName != novav1.Cell0Name {
-			orderedCellNames = append(orderedCellNames, cellName)
-		}
-	}
+	// first to allow dependency handling during ensureCell calls. The order
+	// of the other cells defines the order of the cell by cell rollout.
+	orderedCellNames := getOrderedCellNames(instance)
 
 	// Create the Cell DBs. Note that we are not returning on error or if the
 	// DB creation is still in progress. We move forward with whatever we can
@@ -439,6 +459,58 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 			novav1.NovaAllCellsMQReadyCondition, novav1.NovaAllCellsMQReadyMessage)
 	}
 
+	// Create TransportURLs to access the notifications message bus. The top
+	// level services and the cells use the same bus unless the cell
+	// overrides it in its template. Notifications are disabled if no bus is
+	// configured.
+	notificationMQs, notificationMQsConfigured, err := r.ensureNotificationMQs(
+		ctx, h, instance, orderedCellNames)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !notificationMQsConfigured {
+		instance.Status.Conditions.Remove(novav1.NovaNotificationMQReadyCondition)
+	}
+
+	// Telemetry consumes the notifications so warn if some of the services
+	// would drop them due to the missing notifications bus
+	if instance.Spec.Telemetry.Enabled {
+		var missingBuses []string
+		if instance.Spec.NotificationsBusInstance == nil {
+			missingBuses = append(missingBuses, "top level")
+			for _, cellName := range orderedCellNames {
+				if instance.Spec.CellTemplates[cellName].NotificationsBusInstance == nil {
+					missingBuses = append(missingBuses, cellName)
+				}
+			}
+		}
+		if len(missingBuses) > 0 {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				novav1.NovaTelemetryReadyCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				novav1.NovaTelemetryReadyNoNotificationsBusMessage,
+				strings.Join(missingBuses, ",")))
+		} else {
+			instance.Status.Conditions.MarkTrue(
+				novav1.NovaTelemetryReadyCondition, novav1.NovaTelemetryReadyMessage)
+		}
+	}
+
+	// In adoption mode the existing cell mappings and DB schemas are
+	// verified before any cell is deployed
+	adoptionChecked, err := r.ensureAdoptionChecked(
+		ctx, h, instance, orderedCellNames, apiDB, apiDBStatus, cellDBs, cellMQs,
+		keystoneInternalAuthURL, secret,
+		cellImages[novav1.Cell0Name].ConductorContainerImageURL)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !adoptionChecked {
+		Log.Info("Waiting for the adoption check before deploying the cells")
+		return ctrl.Result{}, nil
+	}
+
 	// Kick of the creation of Cells. We skip over those cells where the cell
 	// DB or MQ is not yet created and those which needs API DB access but
 	// cell0 is not ready yet
@@ -448,8 +520,10 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 	discoveringCells := []string{}
 	skippedCells := []string{}
 	readyCells := []string{}
+	heldCells := []string{}
 	cells := map[string]*novav1.NovaCell{}
 	allCellsReady := true
+	rollout := newCellRollout(instance)
 	for _, cellName := range orderedCellNames {
 		cellTemplate := instance.Spec.CellTemplates[cellName]
 		cellDB := cellDBs[cellName]
@@ -468,6 +542,14 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 				"CellName", cellName)
 			continue
 		}
+		cellNotificationMQ := notificationMQs[cellName]
+		if cellNotificationMQ.Status != nova.MQCompleted {
+			allCellsReady = false
+			skippedCells = append(skippedCells, cellName)
+			Log.Info("Skipping NovaCell as waiting for the notification MQ to be created",
+				"CellName", cellName)
+			continue
+		}
 
 		// The cell0 is always handled first in the loop as we iterate on
 		// orderedCellNames. So for any other cells we can assume that if cell0
@@ -479,12 +561,18 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 			Log.Info("Skip NovaCell as cell0 is not ready yet and this cell needs API DB access", "CellName", cellName)
 			continue
 		}
+		held := rollout.isHeld()
+		if held {
+			heldCells = append(heldCells, cellName)
+		}
 		cell, status, err := r.ensureCell(
 			ctx, h, instance, cellName, cellTemplate,
 			cellDB.Database, apiDB, cellMQ.TransportURL,
-			keystoneInternalAuthURL, secret,
+			cellNotificationMQ.TransportURL, keystoneInternalAuthURL, secret,
+			cellImages[cellName], held,
 		)
 		cells[cellName] = cell
+		rollout.record(cellName, cell, status)
 		switch status {
 		case nova.CellDeploying:
 			deployingCells = append(deployingCells, cellName)
@@ -506,7 +594,16 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 		"discovering", discoveringCells,
 		"ready", readyCells,
 		"failed", failedCells,
+		"held", heldCells,
+		"rollout paused by", rollout.failedCell,
 		"all cells ready", allCellsReady)
+	instance.Status.Capacity = sumCellCapacity(cells)
+	if len(heldCells) > 0 {
+		instance.Status.RolloutHeldCells = heldCells
+	} else {
+		instance.Status.RolloutHeldCells = nil
+	}
+	instance.Status.RolloutPausedBy = rollout.failedCell
 	if len(failedCells) > 0 {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			novav1.NovaAllCellsReadyCondition,
@@ -546,7 +643,16 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 		return ctrl.Result{}, nil
 	}
 
-	topLevelSecretName, err := r.ensureTopLevelSecret(ctx, h, instance, apiTransportURL, secret)
+	// The top level services need the notification message bus to be ready
+	// before they can be configured
+	topLevelNotificationMQ := notificationMQs[""]
+	if topLevelNotificationMQ.Status != nova.MQCompleted {
+		Log.Info("Waiting for the notification MQ to be created before creating the top level services")
+		return ctrl.Result{}, nil
+	}
+
+	topLevelSecretName, err := r.ensureTopLevelSecret(
+		ctx, h, instance, apiTransportURL, topLevelNotificationMQ.TransportURL, secret)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
@@ -590,6 +696,11 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 		instance.Status.MetadataServiceReadyCount = 0
 	}
 
+	err = r.ensureCellAggregates(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// remove finalizers from unused MariaDBAccount records but ONLY if
 	// ensureAPIDB finished
 	if apiDBStatus == nova.DBCompleted {
@@ -612,6 +723,7 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 
 	var deleteErrs []error
 	toDeletCells := map[string]string{}
+	blockedCells := []string{}
 
 	for _, cr := range novaCellList.Items {
 		_, ok := instance.Spec.CellTemplates[cr.Spec.CellName]
@@ -621,12 +733,16 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 			result, err := r.ensureCellDeleted(ctx, h, instance,
 				cr.Spec.CellName, apiTransportURL,
 				secret, apiDB, cellDBs[novav1.Cell0Name].Database.GetDatabaseHostname(), cells[novav1.Cell0Name])
-			if err != nil {
+			if result == nova.CellDeleteBlocked {
+				blockedCells = append(blockedCells, fmt.Sprintf("%s(%v)", cr.Spec.CellName, err.Error()))
+			} else if err != nil {
 				deleteErrs = append(deleteErrs, fmt.Errorf("Cell '%s' deletion failed, because: %w", cr.Spec.CellName, err))
 			}
 			if result == nova.CellDeleteComplete {
 				Log.Info("Cell deleted", "cell", cr.Spec.CellName)
 				delete(instance.Status.RegisteredCells, cr.Name)
+				delete(instance.Status.CellMappings, cr.Name)
+				setCellDisabled(instance, cr.Spec.CellName, false)
 				delete(toDeletCells, cr.Spec.CellName)
 			}
 		}
@@ -641,8 +757,27 @@ func (r *NovaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (resul
 		}
 	}
 
+	if len(blockedCells) > 0 {
+		// We will be reconciled when the cells are forced to be deleted via
+		// the annotation or their failed Jobs are deleted
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaCellsDeletionCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaCellsDeletionBlockedMessage,
+			strings.Join(blockedCells, ", "),
+		))
+	}
+
 	if len(deleteErrs) > 0 {
 		delErrs := errors.Join(deleteErrs...)
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaCellsDeletionCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaCellsDeletionErrorMessage,
+			delErrs.Error(),
+		))
 		return ctrl.Result{}, delErrs
 	}
 
@@ -698,6 +833,42 @@ func (r *NovaReconciler) ensureAccountDeletedIfOwned(
 	return nil
 }
 
+// isCellDeleteForced returns true if the cell is listed in the force cell
+// delete annotation of the Nova CR
+func isCellDeleteForced(instance *novav1.Nova, cellName string) bool {
+	for _, name := range strings.Split(instance.Annotations[novav1.CellForceDeleteAnnotation], ",") {
+		if strings.TrimSpace(name) == cellName {
+			return true
+		}
+	}
+	return false
+}
+
+// setCellMappingStatus records the cell mapping in the status of the
+// NovaCell. Only the cell mapping is patched so it does not conflict with the
+// status the NovaCell controller reports.
+func setCellMappingStatus(
+	ctx context.Context,
+	h *helper.Helper,
+	cell *novav1.NovaCell,
+	mapping novav1.NovaCellMapping,
+) error {
+	patch := client.MergeFrom(cell.DeepCopy())
+	cell.Status.CellMapping = &mapping
+	return h.GetClient().Status().Patch(ctx, cell, patch)
+}
+
+// setCellDisabled records in the Nova status if the cell is disabled for
+// scheduling in its cell mapping
+func setCellDisabled(instance *novav1.Nova, cellName string, disabled bool) {
+	instance.Status.DisabledCells = slices.DeleteFunc(
+		instance.Status.DisabledCells, func(name string) bool { return name == cellName })
+	if disabled {
+		instance.Status.DisabledCells = append(instance.Status.DisabledCells, cellName)
+		slices.Sort(instance.Status.DisabledCells)
+	}
+}
+
 func (r *NovaReconciler) ensureCellDeleted(
 	ctx context.Context,
 	h *helper.Helper,
@@ -749,13 +920,34 @@ func (r *NovaReconciler) ensureCellDeleted(
 	labels := map[string]string{
 		common.AppSelector: NovaLabelPrefix,
 	}
-	jobDef := nova.CellDeleteJob(instance, cell, configName, scriptName, inputHash, labels)
+	force := isCellDeleteForced(instance, cellName)
+	jobDef := nova.CellDeleteJob(instance, cell, configName, scriptName, inputHash, force, labels)
 	job := job.NewJob(
 		jobDef, cell.Name+"-cell-delete",
 		instance.Spec.PreserveJobs, r.RequeueTimeout,
 		inputHash)
 
 	result, err := job.DoJob(ctx, h)
+	if err != nil && job.GetTotalFailedAttempts() > 0 {
+		// The job refuses to delete a cell that still has hosts or
+		// instances with a dedicated exit code, a forced deletion only
+		// refuses a cell with instances. Any other failure is retried by
+		// the Job.
+		terminated, stateErr := getJobTerminatedState(ctx, h.GetClient(), jobDef.Namespace, jobDef.Name)
+		if stateErr != nil {
+			return nova.CellDeleteFailed, stateErr
+		}
+		if terminated != nil && slices.Contains(nova.CellDeleteBlockedExitCodes, terminated.ExitCode) {
+			report := strings.TrimSpace(terminated.Message)
+			if report == "" {
+				report = "check the logs of the " + jobDef.Name + " Job"
+			}
+			Log.Info("Cell deletion is blocked", "cell", cellName, "report", report)
+			// The failed Job is not re-run until it is deleted as its input
+			// does not change when the cell is emptied
+			return nova.CellDeleteBlocked, fmt.Errorf("%s; Job %s", report, jobDef.Name)
+		}
+	}
 	if err != nil {
 		return nova.CellDeleteFailed, err
 	}
@@ -771,7 +963,7 @@ func (r *NovaReconciler) ensureCellDeleted(
 	if err != nil && !k8s_errors.IsNotFound(err) {
 		return nova.CellDeleteFailed, err
 	}
-	configSecret, scriptSecret := r.getNovaManageJobSecretNames(cell)
+	configSecret, scriptSecret := getNovaManageJobSecretNames(cell)
 	err = secret.DeleteSecretsWithName(ctx, h, configSecret, instance.Namespace)
 	if err != nil && !k8s_errors.IsNotFound(err) {
 		return nova.CellDeleteFailed, err
@@ -781,15 +973,13 @@ func (r *NovaReconciler) ensureCellDeleted(
 		return nova.CellDeleteFailed, err
 	}
 
-	// Delete transportURL cr
-	transportURL := &rabbitmqv1.TransportURL{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      instance.Name + "-" + cellName + "-transport",
-			Namespace: instance.Namespace,
-		},
+	// Delete the transportURL CRs of the cell
+	err = r.deleteTransportURL(ctx, instance, instance.Name+"-"+cellName+"-transport")
+	if err != nil {
+		return nova.CellDeleteFailed, err
 	}
-	err = r.Client.Delete(ctx, transportURL)
-	if err != nil && !k8s_errors.IsNotFound(err) {
+	err = r.deleteTransportURL(ctx, instance, getNotificationTransportURLName(instance, cellName))
+	if err != nil {
 		return nova.CellDeleteFailed, err
 	}
 
@@ -838,9 +1028,15 @@ func (r *NovaReconciler) initStatus(
 	if instance.Status.RegisteredCells == nil {
 		instance.Status.RegisteredCells = map[string]string{}
 	}
+	if instance.Status.CellMappings == nil {
+		instance.Status.CellMappings = map[string]novav1.NovaCellMapping{}
+	}
 	if instance.Status.DiscoveredCells == nil {
 		instance.Status.DiscoveredCells = map[string]string{}
 	}
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
 
 	return nil
 }
@@ -905,6 +1101,11 @@ func (r *NovaReconciler) initConditions(
 			condition.InitReason,
 			novav1.NovaAllCellsMQReadyInitMessage,
 		),
+		condition.UnknownCondition(
+			novav1.NovaNotificationMQReadyCondition,
+			condition.InitReason,
+			novav1.NovaNotificationMQReadyInitMessage,
+		),
 		condition.UnknownCondition(
 			novav1.NovaSchedulerReadyCondition,
 			condition.InitReason,
@@ -936,12 +1137,31 @@ func (r *NovaReconciler) initConditions(
 			condition.InitReason,
 			condition.MemcachedReadyInitMessage,
 		),
+		condition.UnknownCondition(
+			novav1.NovaUpgradeCheckReadyCondition,
+			condition.InitReason,
+			novav1.NovaUpgradeCheckReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaUpgradeReadyCondition,
+			condition.InitReason,
+			novav1.NovaUpgradeReadyInitMessage,
+		),
 	)
+	if instance.Spec.Adoption {
+		cl.Set(condition.UnknownCondition(
+			novav1.NovaAdoptionReadyCondition,
+			condition.InitReason,
+			novav1.NovaAdoptionReadyInitMessage,
+		))
+	}
 	instance.Status.Conditions.Init(&cl)
 	return nil
 }
 
-func (r *NovaReconciler) getNovaManageJobSecretNames(
+// getNovaManageJobSecretNames returns the names of the config and the scripts
+// Secrets of the nova-manage jobs run against the cell
+func getNovaManageJobSecretNames(
 	cell *novav1.NovaCell,
 ) (configName string, scriptName string) {
 	configName = fmt.Sprintf("%s-config-data", cell.Name+"-manage")
@@ -957,7 +1177,7 @@ func (r *NovaReconciler) ensureNovaManageJobSecret(
 	cellTransportURL string,
 	cellDB *mariadbv1.Database,
 ) (map[string]env.Setter, string, string, error) {
-	configName, scriptName := r.getNovaManageJobSecretNames(cell)
+	configName, scriptName := getNovaManageJobSecretNames(cell)
 
 	cmLabels := labels.GetLabels(
 		instance, labels.GetGroupLabel(NovaLabelPrefix), map[string]string{},
@@ -1007,9 +1227,10 @@ func (r *NovaReconciler) ensureNovaManageJobSecret(
 		"cell_db_password":       string(cellDbSecret.Data[mariadbv1.DatabasePasswordSelector]),
 		"cell_db_address":        cell.Spec.CellDatabaseHostname,
 		"cell_db_port":           3306,
-		"openstack_region_name":  "regionOne", // fixme
-		"default_project_domain": "Default",   // fixme
-		"default_user_domain":    "Default",   // fixme
+		"openstack_region_name":  cell.Spec.Region,
+		"default_project_domain": cell.Spec.ServiceProjectDomain,
+		"default_user_domain":    cell.Spec.ServiceUserDomain,
+		"service_project_name":   cell.Spec.ServiceProjectName,
 	}
 
 	// NOTE(gibi): cell mapping for cell0 should not have transport_url
@@ -1080,7 +1301,7 @@ func (r *NovaReconciler) ensureAPIDB(
 	apiDB := mariadbv1.NewDatabaseForAccount(
 		instance.Spec.APIDatabaseInstance, // mariadb/galera service to target
 		NovaAPIDatabaseName,               // name used in CREATE DATABASE in mariadb
-		"nova-api",                        // CR name for MariaDBDatabase
+		novaAPIDatabaseCRName,             // CR name for MariaDBDatabase
 		instance.Spec.APIDatabaseAccount,  // CR name for MariaDBAccount
 		instance.Namespace,                // namespace
 	)
@@ -1114,8 +1335,8 @@ func (r *NovaReconciler) ensureCellDB(
 
 	cellDB := mariadbv1.NewDatabaseForAccount(
 		cellTemplate.CellDatabaseInstance, // mariadb/galera service to target
-		"nova_"+cellName,                  // name used in CREATE DATABASE in mariadb
-		"nova-"+cellName,                  // CR name for MariaDBDatabase
+		getCellDatabaseName(cellName),     // name used in CREATE DATABASE in mariadb
+		getCellDatabaseCRName(cellName),   // CR name for MariaDBDatabase
 		cellTemplate.CellDatabaseAccount,  // CR name for MariaDBAccount
 		instance.Namespace,                // namespace
 	)
@@ -1133,12 +1354,16 @@ func (r *NovaReconciler) ensureCell(
 	cellDB *mariadbv1.Database,
 	apiDB *mariadbv1.Database,
 	cellTransportURL string,
+	notificationTransportURL string,
 	keystoneAuthURL string,
 	secret corev1.Secret,
+	cellImages novav1.NovaCellImages,
+	held bool,
 ) (*novav1.NovaCell, nova.CellDeploymentStatus, error) {
 	Log := r.GetLogger(ctx)
 
-	cellSecretName, err := r.ensureCellSecret(ctx, h, instance, cellName, cellTemplate, cellTransportURL, secret)
+	cellSecretName, err := r.ensureCellSecret(
+		ctx, h, instance, cellName, cellTemplate, cellTransportURL, notificationTransportURL, secret)
 	if err != nil {
 		return nil, nova.CellDeploying, err
 	}
@@ -1155,17 +1380,30 @@ func (r *NovaReconciler) ensureCell(
 		NodeSelector:              cellTemplate.NodeSelector,
 		TopologyRef:               cellTemplate.TopologyRef,
 		// TODO(gibi): this should be part of the secret
-		ServiceUser:     instance.Spec.ServiceUser,
-		KeystoneAuthURL: keystoneAuthURL,
-		ServiceAccount:  instance.RbacResourceName(),
-		APITimeout:      instance.Spec.APITimeout,
+		ServiceUser:                instance.Spec.ServiceUser,
+		KeystoneServiceIdentity:    instance.Spec.KeystoneServiceIdentity,
+		Telemetry:                  instance.Spec.Telemetry,
+		ComputeRPCPin:              getComputeRPCPin(instance),
+		RolloutDeadline:            instance.Spec.RolloutDeadline,
+		ConfigRevisionHistoryLimit: instance.Spec.ConfigRevisionHistoryLimit,
+		StaleServiceGracePeriod:    instance.Spec.StaleServiceGracePeriod,
+		KeystoneAuthURL:            keystoneAuthURL,
+		ServiceAccount:             instance.RbacResourceName(),
+		APITimeout:                 instance.Spec.APITimeout,
 		// The assumption is that the CA bundle for ironic compute in the cell
 		// and the conductor in the cell always the same as the NovaAPI
 		TLS:               instance.Spec.APIServiceTemplate.TLS.Ca,
 		PreserveJobs:      instance.Spec.PreserveJobs,
 		MemcachedInstance: getMemcachedInstance(instance, cellTemplate),
 		DBPurge:           cellTemplate.DBPurge,
-		NovaCellImages:    instance.Spec.NovaCellImages,
+		Audit:             cellTemplate.Audit,
+		NovaCellImages:    cellImages,
+		Disabled:          cellTemplate.Disabled,
+		ScaleDownServices: cellTemplate.ScaleDownServices,
+		// The online data migrations can only run when every service is
+		// upgraded
+		OnlineDataMigrationsDeferred: instance.IsUpgrading() &&
+			!upgradePhaseReached(instance, novav1.NovaUpgradePhaseOnlineDataMigration),
 	}
 	if cellTemplate.HasAPIAccess {
 		cellSpec.APIDatabaseHostname = apiDB.GetDatabaseHostname()
@@ -1188,9 +1426,16 @@ func (r *NovaReconciler) ensureCell(
 	}
 
 	op, err := controllerutil.CreateOrPatch(ctx, r.Client, cell, func() error {
-		// TODO(gibi): Pass down a narrowed secret that only hold
-		// specific information but also holds user names
-		cell.Spec = cellSpec
+		// A held cell keeps its current spec until the cell by cell rollout
+		// reaches it
+		if held && !cell.CreationTimestamp.IsZero() {
+			Log.Info("Holding back the changes of the NovaCell until the previous cells are Ready",
+				"NovaCell.Name", cell.Name)
+		} else {
+			// TODO(gibi): Pass down a narrowed secret that only hold
+			// specific information but also holds user names
+			cell.Spec = cellSpec
+		}
 
 		err := controllerutil.SetControllerReference(instance, cell, r.Scheme)
 		if err != nil {
@@ -1319,7 +1564,7 @@ func (r *NovaReconciler) ensureAPI(
 		Cell0DatabaseHostname: cell0DB.GetDatabaseHostname(),
 		Cell0DatabaseAccount:  cell0Template.CellDatabaseAccount,
 		NovaServiceBase: novav1.NovaServiceBase{
-			ContainerImage:      instance.Spec.APIContainerImageURL,
+			ContainerImage:      getTopLevelImages(instance).APIContainerImageURL,
 			Replicas:            instance.Spec.APIServiceTemplate.Replicas,
 			NodeSelector:        instance.Spec.APIServiceTemplate.NodeSelector,
 			CustomServiceConfig: instance.Spec.APIServiceTemplate.CustomServiceConfig,
@@ -1327,16 +1572,21 @@ func (r *NovaReconciler) ensureAPI(
 			NetworkAttachments:  instance.Spec.APIServiceTemplate.NetworkAttachments,
 			TopologyRef:         instance.Spec.APIServiceTemplate.TopologyRef,
 		},
-		Override:               instance.Spec.APIServiceTemplate.Override,
-		KeystoneAuthURL:        keystoneInternalAuthURL,
-		KeystonePublicAuthURL:  keystonePublicAuthURL,
-		ServiceUser:            instance.Spec.ServiceUser,
-		ServiceAccount:         instance.RbacResourceName(),
-		RegisteredCells:        instance.Status.RegisteredCells,
-		TLS:                    instance.Spec.APIServiceTemplate.TLS,
-		DefaultConfigOverwrite: instance.Spec.APIServiceTemplate.DefaultConfigOverwrite,
-		MemcachedInstance:      getMemcachedInstance(instance, cell0Template),
-		APITimeout:             instance.Spec.APITimeout,
+		Override:                   instance.Spec.APIServiceTemplate.Override,
+		KeystoneAuthURL:            keystoneInternalAuthURL,
+		KeystonePublicAuthURL:      keystonePublicAuthURL,
+		ServiceUser:                instance.Spec.ServiceUser,
+		KeystoneServiceIdentity:    instance.Spec.KeystoneServiceIdentity,
+		Telemetry:                  instance.Spec.Telemetry,
+		ComputeRPCPin:              getComputeRPCPin(instance),
+		RolloutDeadline:            instance.Spec.RolloutDeadline,
+		ConfigRevisionHistoryLimit: instance.Spec.ConfigRevisionHistoryLimit,
+		ServiceAccount:             instance.RbacResourceName(),
+		RegisteredCells:            instance.Status.RegisteredCells,
+		TLS:                        instance.Spec.APIServiceTemplate.TLS,
+		DefaultConfigOverwrite:     instance.Spec.APIServiceTemplate.DefaultConfigOverwrite,
+		MemcachedInstance:          getMemcachedInstance(instance, cell0Template),
+		APITimeout:                 instance.Spec.APITimeout,
 	}
 	api := &novav1.NovaAPI{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1412,7 +1662,7 @@ func (r *NovaReconciler) ensureScheduler(
 		// can convert between them directly. As soon as these two structs
 		// start to diverge we need to copy fields one by one here.
 		NovaServiceBase: novav1.NovaServiceBase{
-			ContainerImage:      instance.Spec.SchedulerContainerImageURL,
+			ContainerImage:      getTopLevelImages(instance).SchedulerContainerImageURL,
 			Replicas:            instance.Spec.SchedulerServiceTemplate.Replicas,
 			NodeSelector:        instance.Spec.SchedulerServiceTemplate.NodeSelector,
 			CustomServiceConfig: instance.Spec.SchedulerServiceTemplate.CustomServiceConfig,
@@ -1420,10 +1670,16 @@ func (r *NovaReconciler) ensureScheduler(
 			NetworkAttachments:  instance.Spec.SchedulerServiceTemplate.NetworkAttachments,
 			TopologyRef:         instance.Spec.SchedulerServiceTemplate.TopologyRef,
 		},
-		KeystoneAuthURL: keystoneAuthURL,
-		ServiceUser:     instance.Spec.ServiceUser,
-		ServiceAccount:  instance.RbacResourceName(),
-		RegisteredCells: instance.Status.RegisteredCells,
+		KeystoneAuthURL:            keystoneAuthURL,
+		ServiceUser:                instance.Spec.ServiceUser,
+		KeystoneServiceIdentity:    instance.Spec.KeystoneServiceIdentity,
+		Telemetry:                  instance.Spec.Telemetry,
+		ComputeRPCPin:              getComputeRPCPin(instance),
+		RolloutDeadline:            instance.Spec.RolloutDeadline,
+		ConfigRevisionHistoryLimit: instance.Spec.ConfigRevisionHistoryLimit,
+		StaleServiceGracePeriod:    instance.Spec.StaleServiceGracePeriod,
+		ServiceAccount:             instance.RbacResourceName(),
+		RegisteredCells:            instance.Status.RegisteredCells,
 		// The assumption is that the CA bundle for the NovaScheduler is the same as the NovaAPI
 		TLS:               instance.Spec.APIServiceTemplate.TLS.Ca,
 		MemcachedInstance: getMemcachedInstance(instance, cell0Template),
@@ -1702,6 +1958,130 @@ func (r *NovaReconciler) ensureMQ(
 	return string(url), nova.MQCompleted, nil
 }
 
+// ensureNotificationMQs creates the TransportURLs for the notifications
+// message bus of the top level services and of each cell. The returned map
+// is keyed by the cell name while the top level services use the "" key. The
+// returned bool is false if notifications are not configured at all.
+func (r *NovaReconciler) ensureNotificationMQs(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.Nova,
+	orderedCellNames []string,
+) (map[string]*nova.MessageBus, bool, error) {
+	notificationMQs := map[string]*nova.MessageBus{}
+	configured := false
+	var failedMQs []string
+	var creatingMQs []string
+
+	topLevelMQ := &nova.MessageBus{Status: nova.MQCompleted}
+	if instance.Spec.NotificationsBusInstance == nil {
+		// The notifications might have been disabled since
+		err := r.deleteTransportURL(ctx, instance, getNotificationTransportURLName(instance, ""))
+		if err != nil {
+			return nil, configured, err
+		}
+	} else {
+		configured = true
+		url, status, err := r.ensureMQ(
+			ctx, h, instance, getNotificationTransportURLName(instance, ""),
+			*instance.Spec.NotificationsBusInstance)
+		topLevelMQ = &nova.MessageBus{TransportURL: url, Status: status}
+		switch status {
+		case nova.MQFailed:
+			failedMQs = append(failedMQs, fmt.Sprintf("top level(%v)", err.Error()))
+		case nova.MQCreating:
+			creatingMQs = append(creatingMQs, "top level")
+		case nova.MQCompleted:
+		default:
+			return nil, configured, fmt.Errorf(
+				"%w from ensureMQ: %d for the notification MQ", util.ErrInvalidStatus, status)
+		}
+	}
+	notificationMQs[""] = topLevelMQ
+
+	for _, cellName := range orderedCellNames {
+		cellTemplate := instance.Spec.CellTemplates[cellName]
+		if cellTemplate.NotificationsBusInstance == nil {
+			// The cell might have overridden the bus before
+			err := r.deleteTransportURL(ctx, instance, getNotificationTransportURLName(instance, cellName))
+			if err != nil {
+				return nil, configured, err
+			}
+			notificationMQs[cellName] = topLevelMQ
+			continue
+		}
+		configured = true
+		url, status, err := r.ensureMQ(
+			ctx, h, instance, getNotificationTransportURLName(instance, cellName),
+			*cellTemplate.NotificationsBusInstance)
+		switch status {
+		case nova.MQFailed:
+			failedMQs = append(failedMQs, fmt.Sprintf("%s(%v)", cellName, err.Error()))
+		case nova.MQCreating:
+			creatingMQs = append(creatingMQs, cellName)
+		case nova.MQCompleted:
+		default:
+			return nil, configured, fmt.Errorf(
+				"%w from ensureMQ: %d for the notification MQ of cell %s", util.ErrInvalidStatus, status, cellName)
+		}
+		notificationMQs[cellName] = &nova.MessageBus{TransportURL: url, Status: status}
+	}
+
+	if len(failedMQs) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaNotificationMQReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaNotificationMQReadyErrorMessage,
+			strings.Join(failedMQs, ",")))
+	} else if len(creatingMQs) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaNotificationMQReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaNotificationMQReadyCreatingMessage,
+			strings.Join(creatingMQs, ",")))
+	} else if configured {
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaNotificationMQReadyCondition, novav1.NovaNotificationMQReadyMessage)
+	}
+
+	return notificationMQs, configured, nil
+}
+
+// getNotificationTransportURLName returns the name of the TransportURL of the
+// notifications bus of the cell or of the top level services if the cellName
+// is empty
+func getNotificationTransportURLName(instance *novav1.Nova, cellName string) string {
+	if cellName == "" {
+		return instance.Name + "-notification-transport"
+	}
+	return instance.Name + "-" + cellName + "-notification-transport"
+}
+
+// deleteTransportURL deletes the TransportURL with the given name if it
+// exists
+func (r *NovaReconciler) deleteTransportURL(
+	ctx context.Context,
+	instance *novav1.Nova,
+	name string,
+) error {
+	transportURL := &rabbitmqv1.TransportURL{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: instance.Namespace,
+		},
+	}
+	err := r.Client.Delete(ctx, transportURL)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		r.GetLogger(ctx).Info("Deleted TransportURL", "name", name)
+	}
+	return nil
+}
+
 func getNovaMetadataName(instance client.Object) types.NamespacedName {
 	return types.NamespacedName{Namespace: instance.GetNamespace(), Name: instance.GetName() + "-metadata"}
 }
@@ -1762,7 +2142,7 @@ func (r *NovaReconciler) ensureMetadata(
 		CellDatabaseHostname: cell0DB.GetDatabaseHostname(),
 		CellDatabaseAccount:  cell0Template.CellDatabaseAccount,
 		NovaServiceBase: novav1.NovaServiceBase{
-			ContainerImage:      instance.Spec.MetadataContainerImageURL,
+			ContainerImage:      getTopLevelImages(instance).MetadataContainerImageURL,
 			Replicas:            instance.Spec.MetadataServiceTemplate.Replicas,
 			NodeSelector:        instance.Spec.MetadataServiceTemplate.NodeSelector,
 			CustomServiceConfig: instance.Spec.MetadataServiceTemplate.CustomServiceConfig,
@@ -1770,15 +2150,20 @@ func (r *NovaReconciler) ensureMetadata(
 			NetworkAttachments:  instance.Spec.MetadataServiceTemplate.NetworkAttachments,
 			TopologyRef:         instance.Spec.MetadataServiceTemplate.TopologyRef,
 		},
-		Override:               instance.Spec.MetadataServiceTemplate.Override,
-		ServiceUser:            instance.Spec.ServiceUser,
-		KeystoneAuthURL:        keystoneAuthURL,
-		ServiceAccount:         instance.RbacResourceName(),
-		RegisteredCells:        instance.Status.RegisteredCells,
-		TLS:                    instance.Spec.MetadataServiceTemplate.TLS,
-		DefaultConfigOverwrite: instance.Spec.MetadataServiceTemplate.DefaultConfigOverwrite,
-		MemcachedInstance:      getMemcachedInstance(instance, cell0Template),
-		APITimeout:             instance.Spec.APITimeout,
+		Override:                   instance.Spec.MetadataServiceTemplate.Override,
+		ServiceUser:                instance.Spec.ServiceUser,
+		KeystoneServiceIdentity:    instance.Spec.KeystoneServiceIdentity,
+		Telemetry:                  instance.Spec.Telemetry,
+		ComputeRPCPin:              getComputeRPCPin(instance),
+		RolloutDeadline:            instance.Spec.RolloutDeadline,
+		ConfigRevisionHistoryLimit: instance.Spec.ConfigRevisionHistoryLimit,
+		KeystoneAuthURL:            keystoneAuthURL,
+		ServiceAccount:             instance.RbacResourceName(),
+		RegisteredCells:            instance.Status.RegisteredCells,
+		TLS:                        instance.Spec.MetadataServiceTemplate.TLS,
+		DefaultConfigOverwrite:     instance.Spec.MetadataServiceTemplate.DefaultConfigOverwrite,
+		MemcachedInstance:          getMemcachedInstance(instance, cell0Template),
+		APITimeout:                 instance.Spec.APITimeout,
 	}
 	metadata = &novav1.NovaMetadata{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1831,6 +2216,61 @@ func (r *NovaReconciler) ensureMetadata(
 	return ctrl.Result{}, nil
 }
 
+// ensureCellAggregates creates a NovaAggregate for each cell that requests an
+// availability zone and deletes the ones that are not requested any more
+func (r *NovaReconciler) ensureCellAggregates(
+	ctx context.Context,
+	instance *novav1.Nova,
+) error {
+	Log := r.GetLogger(ctx)
+
+	requested := map[string]bool{}
+	for cellName, cellTemplate := range instance.Spec.CellTemplates {
+		if cellTemplate.AvailabilityZone == "" {
+			continue
+		}
+		aggregate := &novav1.NovaAggregate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      getNovaCellCRName(instance.Name, cellName),
+				Namespace: instance.Namespace,
+			},
+		}
+		requested[aggregate.Name] = true
+
+		op, err := controllerutil.CreateOrPatch(ctx, r.Client, aggregate, func() error {
+			aggregate.Spec = novav1.NovaAggregateSpec{
+				NovaInstance:     instance.Name,
+				AvailabilityZone: cellTemplate.AvailabilityZone,
+				CellName:         cellName,
+			}
+			return controllerutil.SetControllerReference(instance, aggregate, r.Scheme)
+		})
+		if err != nil {
+			return err
+		}
+		if op != controllerutil.OperationResultNone {
+			Log.Info(fmt.Sprintf("NovaAggregate %s , NovaAggregate.Name %s.", string(op), aggregate.Name))
+		}
+	}
+
+	aggregates := &novav1.NovaAggregateList{}
+	err := r.Client.List(ctx, aggregates, client.InNamespace(instance.Namespace))
+	if err != nil {
+		return err
+	}
+	for _, aggregate := range aggregates.Items {
+		if !metav1.IsControlledBy(&aggregate, instance) || requested[aggregate.Name] {
+			continue
+		}
+		err = r.Client.Delete(ctx, &aggregate)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return err
+		}
+		Log.Info("Deleted NovaAggregate", "NovaAggregate.Name", aggregate.Name)
+	}
+	return nil
+}
+
 // ensureCellMapped makes sure that the cell has a row in the
 // nova_api.CellMapping table by calling nova-manage cell_v2 CLI commands in a
 // Job. When a cell is mapped then the name of the cell and the hash of the
@@ -1877,11 +2317,31 @@ func (r *NovaReconciler) ensureCellMapped(
 		return nova.CellMapping, nil
 	}
 
+	// The disabled flag is an input of the job so the cell mapping is up to
+	// date with it at this point
+	setCellDisabled(instance, cell.Spec.CellName, cell.Spec.Disabled)
+
 	if !job.HasChanged() {
 		// there was no need to run a new job as nothing changed
 		return nova.CellMappingReady, nil
 	}
 
+	// The job reports the resulting cell mapping via its termination message
+	report, err := getJobTerminationMessage(ctx, h.GetClient(), instance.Namespace, jobDef.Name)
+	if err != nil {
+		return nova.CellMappingFailed, err
+	}
+	mapping := novav1.NovaCellMapping{}
+	if err := json.Unmarshal([]byte(report), &mapping); err != nil || mapping.UUID == "" {
+		Log.Info("The cell mapping is not reported by the job", "job", jobDef.Name, "report", report)
+	} else {
+		instance.Status.CellMappings[cell.Name] = mapping
+		err = setCellMappingStatus(ctx, h, cell, mapping)
+		if err != nil {
+			return nova.CellMappingFailed, err
+		}
+	}
+
 	// A new cell mapping job is finished. Let's store the result so we
 	// won't run the job with the same inputs again.
 	// Also the controller distributes the instance.Status.RegisteredCells
@@ -1893,6 +2353,158 @@ func (r *NovaReconciler) ensureCellMapped(
 	return nova.CellMappingReady, nil
 }
 
+// ensureUpgradeChecked runs nova-status upgrade check with the container
+// images from the Spec against the existing DBs if they differ from the
+// deployed images. The upgrade to the new images is only started if the
+// check passes or if the check is deliberately overridden via annotation.
+// If other images are requested during an upgrade then the upgrade is
+// restarted with them once they pass the check, or it is aborted if the
+// previous images are requested.
+func (r *NovaReconciler) ensureUpgradeChecked(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.Nova,
+) error {
+	Log := r.GetLogger(ctx)
+
+	if instance.IsUpgrading() {
+		switch instance.Spec.NovaImages {
+		case *instance.Status.TargetImages:
+			// The images of the current upgrade are already checked
+			if instance.Annotations[novav1.UpgradeCheckOverrideAnnotation] == "true" {
+				instance.Status.Conditions.MarkTrue(
+					novav1.NovaUpgradeCheckReadyCondition,
+					novav1.NovaUpgradeCheckReadyOverriddenMessage,
+					novav1.UpgradeCheckOverrideAnnotation)
+			} else {
+				instance.Status.Conditions.MarkTrue(
+					novav1.NovaUpgradeCheckReadyCondition, novav1.NovaUpgradeCheckReadyMessage)
+			}
+			return nil
+		case *instance.Status.PreviousImages:
+			Log.Info("Aborting the upgrade as the previous container images are requested",
+				"images", instance.Spec.NovaImages)
+			abortUpgrade(instance)
+			instance.Status.UpgradeCheckFailure = nil
+			instance.Status.Conditions.MarkTrue(
+				novav1.NovaUpgradeCheckReadyCondition, novav1.NovaUpgradeCheckReadyMessage)
+			return nil
+		}
+		// Other images are requested during the upgrade. The current
+		// upgrade continues until the new images pass the check and then it
+		// is restarted with them.
+	}
+
+	if instance.Status.DeployedImages == nil {
+		// Nothing is deployed yet so there is nothing to check the images
+		// against
+		instance.Status.DeployedImages = instance.Spec.NovaImages.DeepCopy()
+	}
+	if *instance.Status.DeployedImages == instance.Spec.NovaImages {
+		instance.Status.UpgradeCheckFailure = nil
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaUpgradeCheckReadyCondition, novav1.NovaUpgradeCheckReadyMessage)
+		return nil
+	}
+	if instance.Status.UpgradeCheckFailure != nil &&
+		instance.Status.UpgradeCheckFailure.Images != instance.Spec.NovaImages {
+		// The failure was reported for other images
+		instance.Status.UpgradeCheckFailure = nil
+	}
+
+	if instance.Annotations[novav1.UpgradeCheckOverrideAnnotation] == "true" {
+		Log.Info("Upgrading to the new container images without upgrade check",
+			"annotation", novav1.UpgradeCheckOverrideAnnotation)
+		startUpgrade(instance)
+		instance.Status.UpgradeCheckFailure = nil
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaUpgradeCheckReadyCondition,
+			novav1.NovaUpgradeCheckReadyOverriddenMessage,
+			novav1.UpgradeCheckOverrideAnnotation)
+		return nil
+	}
+
+	cell0 := &novav1.NovaCell{}
+	err := r.Client.Get(ctx, types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      getNovaCellCRName(instance.Name, novav1.Cell0Name),
+	}, cell0)
+	if k8s_errors.IsNotFound(err) {
+		// The DBs are not deployed yet so there is nothing to check the
+		// images against
+		instance.Status.DeployedImages = instance.Spec.NovaImages.DeepCopy()
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaUpgradeCheckReadyCondition, novav1.NovaUpgradeCheckReadyMessage)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	configName, scriptName := getNovaManageJobSecretNames(cell0)
+	labels := map[string]string{
+		common.AppSelector: NovaLabelPrefix,
+	}
+	jobDef := nova.UpgradeCheckJob(instance, cell0, configName, scriptName, labels)
+	checkJob := job.NewJob(
+		jobDef, "upgradecheck", instance.Spec.PreserveJobs, r.RequeueTimeout,
+		instance.Status.Hash[novav1.UpgradeCheckHashKey])
+	result, err := checkJob.DoJob(ctx, h)
+	if err != nil && checkJob.GetTotalFailedAttempts() > 0 {
+		report, reportErr := getJobTerminationMessage(ctx, h.GetClient(), jobDef.Namespace, jobDef.Name)
+		if reportErr != nil {
+			return reportErr
+		}
+		if report != "" {
+			// The pod of the Job is not kept forever so the report is
+			// persisted
+			instance.Status.UpgradeCheckFailure = &novav1.NovaUpgradeCheckFailure{
+				Images: instance.Spec.NovaImages,
+				Report: report,
+			}
+		} else if instance.Status.UpgradeCheckFailure != nil {
+			report = instance.Status.UpgradeCheckFailure.Report
+		}
+		Log.Info("Upgrade check failed with the new container images", "report", report)
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaUpgradeCheckReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaUpgradeCheckReadyFailedMessage,
+			report))
+		// The rest of the deployment is still reconciled with the deployed
+		// images. We will be reconciled if the Job is re-run.
+		return nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaUpgradeCheckReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaUpgradeCheckReadyErrorMessage,
+			err.Error()))
+		return err
+	}
+	if (result != ctrl.Result{}) {
+		// We will be reconciled when the Job status changes
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaUpgradeCheckReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaUpgradeCheckReadyRunningMessage))
+		return nil
+	}
+
+	instance.Status.Hash[novav1.UpgradeCheckHashKey] = checkJob.GetHash()
+	Log.Info("Upgrade check passed, upgrading to the new container images", "images", instance.Spec.NovaImages)
+	startUpgrade(instance)
+	instance.Status.UpgradeCheckFailure = nil
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaUpgradeCheckReadyCondition, novav1.NovaUpgradeCheckReadyMessage)
+
+	return nil
+}
+
 // ensureCellSecret makes sure that the internal Cell Secret exists and up to
 // date
 func (r *NovaReconciler) ensureCellSecret(
@@ -1902,6 +2514,7 @@ func (r *NovaReconciler) ensureCellSecret(
 	cellName string,
 	cellTemplate novav1.NovaCellTemplate,
 	cellTransportURL string,
+	notificationTransportURL string,
 	externalSecret corev1.Secret,
 ) (string, error) {
 	// NOTE(gibi): We can move other sensitive data to the internal Secret from
@@ -1910,6 +2523,9 @@ func (r *NovaReconciler) ensureCellSecret(
 		ServicePasswordSelector: string(externalSecret.Data[instance.Spec.PasswordSelectors.Service]),
 		TransportURLSelector:    cellTransportURL,
 	}
+	if notificationTransportURL != "" {
+		data[NotificationTransportURLSelector] = notificationTransportURL
+	}
 
 	// If metadata is enabled in the cell then the cell secret needs the
 	// metadata shared secret
@@ -1952,6 +2568,7 @@ func (r *NovaReconciler) ensureTopLevelSecret(
 	h *helper.Helper,
 	instance *novav1.Nova,
 	apiTransportURL string,
+	notificationTransportURL string,
 	externalSecret corev1.Secret,
 ) (string, error) {
 	// NOTE(gibi): We can move other sensitive data to the internal Secret from
@@ -1961,6 +2578,9 @@ func (r *NovaReconciler) ensureTopLevelSecret(
 		MetadataSecretSelector:  string(externalSecret.Data[instance.Spec.PasswordSelectors.MetadataSecret]),
 		TransportURLSelector:    apiTransportURL,
 	}
+	if notificationTransportURL != "" {
+		data[NotificationTransportURLSelector] = notificationTransportURL
+	}
 
 	// NOTE(gibi): When we switch to immutable secrets then we need to include
 	// the hash of the secret data into the name of the secret to avoid
@@ -2077,6 +2697,7 @@ func (r *NovaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&novav1.NovaScheduler{}).
 		Owns(&novav1.NovaCell{}).
 		Owns(&novav1.NovaMetadata{}).
+		Owns(&novav1.NovaAggregate{}).
 		Owns(&rabbitmqv1.TransportURL{}).
 		Owns(&batchv1.Job{}).
 		Owns(&corev1.ServiceAccount{}).
@@ -0,0 +1,396 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	util "github.com/openstack-k8s-operators/lib-common/modules/common/util"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// upgradePhases are the phases of the ordered rollout of new container
+// images in the order they are executed
+var upgradePhases = []novav1.NovaUpgradePhase{
+	novav1.NovaUpgradePhaseDBSync,
+	novav1.NovaUpgradePhaseConductors,
+	novav1.NovaUpgradePhaseScheduler,
+	novav1.NovaUpgradePhaseServices,
+	novav1.NovaUpgradePhaseOnlineDataMigration,
+	novav1.NovaUpgradePhaseCompleted,
+}
+
+// upgradePhaseReached returns true if the current upgrade is already in the
+// given phase or after it
+func upgradePhaseReached(instance *novav1.Nova, phase novav1.NovaUpgradePhase) bool {
+	return slices.Index(upgradePhases, instance.Status.UpgradePhase) >= slices.Index(upgradePhases, phase)
+}
+
+// startUpgrade starts the ordered rollout of the container images from the
+// Spec
+func startUpgrade(instance *novav1.Nova) {
+	instance.Status.PreviousImages = instance.Status.DeployedImages.DeepCopy()
+	instance.Status.TargetImages = instance.Spec.NovaImages.DeepCopy()
+	instance.Status.UpgradePhase = novav1.NovaUpgradePhaseDBSync
+}
+
+// abortUpgrade stops the ordered rollout of the target images. The
+// deployed images are only updated when an upgrade is completed so every
+// service is moved back to the images it ran before the upgrade.
+func abortUpgrade(instance *novav1.Nova) {
+	instance.Status.UpgradePhase = novav1.NovaUpgradePhaseAborted
+}
+
+// getComputeRPCPin returns the compute RPC version the services are
+// configured with. It is only pinned explicitly during an upgrade.
+func getComputeRPCPin(instance *novav1.Nova) string {
+	if instance.IsUpgrading() {
+		return instance.Spec.ComputeRPCPin
+	}
+	return ""
+}
+
+// selectImage returns the target image if the service is upgraded already
+// otherwise the previous image
+func selectImage(previous string, target string, upgraded bool) string {
+	if upgraded {
+		return target
+	}
+	return previous
+}
+
+// getTopLevelImages returns the container images of the top level services
+// in the current phase of the upgrade
+func getTopLevelImages(instance *novav1.Nova) novav1.NovaImages {
+	if !instance.IsUpgrading() {
+		return *instance.Status.DeployedImages
+	}
+	previous := instance.Status.PreviousImages
+	target := instance.Status.TargetImages
+	images := *previous.DeepCopy()
+	images.SchedulerContainerImageURL = selectImage(
+		previous.SchedulerContainerImageURL, target.SchedulerContainerImageURL,
+		upgradePhaseReached(instance, novav1.NovaUpgradePhaseScheduler))
+	servicesUpgraded := upgradePhaseReached(instance, novav1.NovaUpgradePhaseServices)
+	images.APIContainerImageURL = selectImage(
+		previous.APIContainerImageURL, target.APIContainerImageURL, servicesUpgraded)
+	images.MetadataContainerImageURL = selectImage(
+		previous.MetadataContainerImageURL, target.MetadataContainerImageURL, servicesUpgraded)
+	return images
+}
+
+// getCellTargetImages returns the container images the services of a cell
+// are upgraded to including the image overrides of the cell
+func getCellTargetImages(instance *novav1.Nova, cellName string) novav1.NovaCellImages {
+	return instance.Spec.CellTemplates[cellName].Images.Apply(
+		instance.Status.TargetImages.NovaCellImages)
+}
+
+// getCellImages returns the container images of the services of a cell in
+// the current phase of the upgrade. The conductor is handled separately as
+// it is upgraded cell by cell. The image overrides of the cell are applied
+// regardless of the upgrade.
+func getCellImages(
+	instance *novav1.Nova, cellName string, conductorUpgraded bool,
+) novav1.NovaCellImages {
+	overrides := instance.Spec.CellTemplates[cellName].Images
+	if !instance.IsUpgrading() {
+		return overrides.Apply(instance.Status.DeployedImages.NovaCellImages)
+	}
+	previous := instance.Status.PreviousImages.NovaCellImages
+	target := instance.Status.TargetImages.NovaCellImages
+	servicesUpgraded := upgradePhaseReached(instance, novav1.NovaUpgradePhaseServices)
+	return overrides.Apply(novav1.NovaCellImages{
+		ConductorContainerImageURL: selectImage(
+			previous.ConductorContainerImageURL, target.ConductorContainerImageURL, conductorUpgraded),
+		MetadataContainerImageURL: selectImage(
+			previous.MetadataContainerImageURL, target.MetadataContainerImageURL, servicesUpgraded),
+		NoVNCContainerImageURL: selectImage(
+			previous.NoVNCContainerImageURL, target.NoVNCContainerImageURL, servicesUpgraded),
+		NovaComputeContainerImageURL: selectImage(
+			previous.NovaComputeContainerImageURL, target.NovaComputeContainerImageURL, servicesUpgraded),
+	})
+}
+
+// isRolledOut returns true if the service CR reconciled its latest spec and
+// it is Ready with the given image. If optional is true then a service CR
+// that does not exist is reported as rolled out.
+func (r *NovaReconciler) isRolledOut(
+	ctx context.Context,
+	obj client.Object,
+	name types.NamespacedName,
+	image string,
+	optional bool,
+) (bool, error) {
+	err := r.Client.Get(ctx, name, obj)
+	if k8s_errors.IsNotFound(err) {
+		return optional, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	var containerImage string
+	var observedGeneration int64
+	var conditions condition.Conditions
+	switch o := obj.(type) {
+	case *novav1.NovaConductor:
+		containerImage, observedGeneration, conditions = o.Spec.ContainerImage, o.Status.ObservedGeneration, o.Status.Conditions
+	case *novav1.NovaScheduler:
+		containerImage, observedGeneration, conditions = o.Spec.ContainerImage, o.Status.ObservedGeneration, o.Status.Conditions
+	case *novav1.NovaAPI:
+		containerImage, observedGeneration, conditions = o.Spec.ContainerImage, o.Status.ObservedGeneration, o.Status.Conditions
+	case *novav1.NovaMetadata:
+		containerImage, observedGeneration, conditions = o.Spec.ContainerImage, o.Status.ObservedGeneration, o.Status.Conditions
+	case *novav1.NovaNoVNCProxy:
+		containerImage, observedGeneration, conditions = o.Spec.ContainerImage, o.Status.ObservedGeneration, o.Status.Conditions
+	case *novav1.NovaCompute:
+		containerImage, observedGeneration, conditions = o.Spec.ContainerImage, o.Status.ObservedGeneration, o.Status.Conditions
+	default:
+		return false, fmt.Errorf("%w: unexpected service type %T", util.ErrInvalidStatus, obj)
+	}
+
+	return obj.GetGeneration() == observedGeneration &&
+		containerImage == image &&
+		conditions.IsTrue(condition.ReadyCondition), nil
+}
+
+// getCellConductorName returns the name of the NovaConductor of the cell
+func getCellConductorName(instance *novav1.Nova, cellName string) types.NamespacedName {
+	return types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      getNovaCellCRName(instance.Name, cellName) + "-conductor",
+	}
+}
+
+// getOrderedCellNames returns the names of the cells in the order the
+// changes are rolled out to them and their conductors are upgraded. The
+// cell0 is always the first as the other cells depend on it. Then the cells
+// follow in the order of the rollout strategy and the rest alphabetically.
+func getOrderedCellNames(instance *novav1.Nova) []string {
+	orderedCellNames := []string{novav1.Cell0Name}
+	ordered := map[string]bool{novav1.Cell0Name: true}
+	if instance.Spec.RolloutStrategy != nil {
+		for _, cellName := range instance.Spec.RolloutStrategy.CellOrder {
+			if _, ok := instance.Spec.CellTemplates[cellName]; ok && !ordered[cellName] {
+				orderedCellNames = append(orderedCellNames, cellName)
+				ordered[cellName] = true
+			}
+		}
+	}
+
+	cellNames := []string{}
+	for cellName := range instance.Spec.CellTemplates {
+		if !ordered[cellName] {
+			cellNames = append(cellNames, cellName)
+		}
+	}
+	sort.Strings(cellNames)
+	return append(orderedCellNames, cellNames...)
+}
+
+// ensureUpgradeProgressed moves the ordered upgrade forward to the next
+// phase when every service of the current phase runs the target images. It
+// returns the container images of the services of each cell in the
+// resulting phase.
+func (r *NovaReconciler) ensureUpgradeProgressed(
+	ctx context.Context,
+	instance *novav1.Nova,
+) (map[string]novav1.NovaCellImages, error) {
+	Log := r.GetLogger(ctx)
+
+	cellImages, err := r.progressUpgrade(ctx, instance)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaUpgradeReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaUpgradeReadyErrorMessage,
+			err.Error()))
+		return nil, err
+	}
+
+	if instance.IsUpgrading() {
+		Log.Info("Upgrade in progress", "phase", instance.Status.UpgradePhase)
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaUpgradeReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaUpgradeReadyInProgressMessage,
+			instance.Status.UpgradePhase))
+	} else {
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaUpgradeReadyCondition, novav1.NovaUpgradeReadyMessage)
+	}
+	return cellImages, nil
+}
+
+func (r *NovaReconciler) progressUpgrade(
+	ctx context.Context,
+	instance *novav1.Nova,
+) (map[string]novav1.NovaCellImages, error) {
+	Log := r.GetLogger(ctx)
+	cellNames := getOrderedCellNames(instance)
+	cellImages := map[string]novav1.NovaCellImages{}
+
+	for instance.IsUpgrading() {
+		target := instance.Status.TargetImages
+		done := true
+
+		switch instance.Status.UpgradePhase {
+		case novav1.NovaUpgradePhaseDBSync:
+			// The cell0 conductor syncs both the API and the cell0 DB
+			// schema before it is deployed
+			rolledOut, err := r.isRolledOut(
+				ctx, &novav1.NovaConductor{}, getCellConductorName(instance, novav1.Cell0Name),
+				getCellTargetImages(instance, novav1.Cell0Name).ConductorContainerImageURL, false)
+			if err != nil {
+				return nil, err
+			}
+			done = rolledOut
+
+		case novav1.NovaUpgradePhaseConductors:
+			// A cell conductor is upgraded after the conductor of the
+			// previous cell is rolled out. A conductor that already got the
+			// target image is never moved back to the previous image.
+			for _, cellName := range cellNames {
+				conductor := &novav1.NovaConductor{}
+				targetImage := getCellTargetImages(instance, cellName).ConductorContainerImageURL
+				rolledOut, err := r.isRolledOut(
+					ctx, conductor, getCellConductorName(instance, cellName), targetImage, false)
+				if err != nil {
+					return nil, err
+				}
+				upgraded := conductor.Spec.ContainerImage == targetImage
+				cellImages[cellName] = getCellImages(instance, cellName, upgraded || done)
+				done = done && rolledOut
+			}
+
+		case novav1.NovaUpgradePhaseScheduler:
+			rolledOut, err := r.isRolledOut(
+				ctx, &novav1.NovaScheduler{}, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name + "-scheduler"},
+				target.SchedulerContainerImageURL, false)
+			if err != nil {
+				return nil, err
+			}
+			done = rolledOut
+
+		case novav1.NovaUpgradePhaseServices:
+			rolledOut, err := r.areServicesRolledOut(ctx, instance, cellNames)
+			if err != nil {
+				return nil, err
+			}
+			done = rolledOut
+
+		case novav1.NovaUpgradePhaseOnlineDataMigration:
+			for _, cellName := range cellNames {
+				cell := &novav1.NovaCell{}
+				err := r.Client.Get(ctx, types.NamespacedName{
+					Namespace: instance.Namespace,
+					Name:      getNovaCellCRName(instance.Name, cellName),
+				}, cell)
+				if err != nil && !k8s_errors.IsNotFound(err) {
+					return nil, err
+				}
+				done = done && err == nil &&
+					cell.Generation == cell.Status.ObservedGeneration &&
+					!cell.Spec.OnlineDataMigrationsDeferred &&
+					cell.Status.OnlineDataMigrationImage ==
+						getCellTargetImages(instance, cellName).ConductorContainerImageURL
+			}
+		}
+
+		if !done {
+			break
+		}
+
+		next := slices.Index(upgradePhases, instance.Status.UpgradePhase) + 1
+		instance.Status.UpgradePhase = upgradePhases[next]
+		Log.Info("Upgrade moved to the next phase", "phase", instance.Status.UpgradePhase)
+		if instance.Status.UpgradePhase == novav1.NovaUpgradePhaseCompleted {
+			instance.Status.DeployedImages = instance.Status.TargetImages.DeepCopy()
+		}
+	}
+
+	// In the Conductors phase the images are already calculated per cell.
+	// Otherwise only the cell0 conductor is upgraded before that phase.
+	if instance.Status.UpgradePhase != novav1.NovaUpgradePhaseConductors {
+		for _, cellName := range cellNames {
+			cellImages[cellName] = getCellImages(
+				instance,
+				cellName,
+				cellName == novav1.Cell0Name ||
+					upgradePhaseReached(instance, novav1.NovaUpgradePhaseScheduler))
+		}
+	}
+	return cellImages, nil
+}
+
+// areServicesRolledOut returns true if the API, metadata, novncproxy and
+// compute services run the target images
+func (r *NovaReconciler) areServicesRolledOut(
+	ctx context.Context,
+	instance *novav1.Nova,
+	cellNames []string,
+) (bool, error) {
+	target := instance.Status.TargetImages
+	type service struct {
+		obj   client.Object
+		name  types.NamespacedName
+		image string
+	}
+	services := []service{
+		{&novav1.NovaAPI{}, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name + "-api"}, target.APIContainerImageURL},
+		{&novav1.NovaMetadata{}, getNovaMetadataName(instance), target.MetadataContainerImageURL},
+	}
+	for _, cellName := range cellNames {
+		cell := &novav1.NovaCell{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: instance.Namespace,
+				Name:      getNovaCellCRName(instance.Name, cellName),
+			},
+		}
+		cellTarget := getCellTargetImages(instance, cellName)
+		services = append(services,
+			service{&novav1.NovaMetadata{}, getNovaMetadataName(cell), cellTarget.MetadataContainerImageURL},
+			service{&novav1.NovaNoVNCProxy{}, getNoVNCProxyName(cell), cellTarget.NoVNCContainerImageURL},
+		)
+		for computeName := range instance.Spec.CellTemplates[cellName].NovaComputeTemplates {
+			services = append(services, service{
+				&novav1.NovaCompute{}, getNovaComputeName(cell, computeName), cellTarget.NovaComputeContainerImageURL})
+		}
+	}
+
+	for _, s := range services {
+		// The services that are not deployed do not need to be upgraded
+		rolledOut, err := r.isRolledOut(ctx, s.obj, s.name, s.image, true)
+		if err != nil || !rolledOut {
+			return false, err
+		}
+	}
+	return true, nil
+}
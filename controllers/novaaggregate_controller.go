@@ -0,0 +1,640 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	gophercloud "github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/aggregates"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/services"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+const (
+	cellNameField = ".spec.cellName"
+
+	// aggregateResyncInterval defines how often the aggregate is compared
+	// with the compute API to detect changes made outside of the operator
+	aggregateResyncInterval = 10 * time.Minute
+
+	// aggregateAZMetadataKey is the aggregate metadata key the compute API
+	// uses to store the availability zone of the aggregate
+	aggregateAZMetadataKey = "availability_zone"
+)
+
+// NovaAggregateReconciler reconciles a NovaAggregate object
+type NovaAggregateReconciler struct {
+	ReconcilerBase
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *NovaAggregateReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("NovaAggregate")
+}
+
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaaggregates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaaggregates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaaggregates/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novacells,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;
+
+// Reconcile keeps the host aggregate in the compute API in sync with the
+// NovaAggregate CR
+func (r *NovaAggregateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	// Fetch the NovaAggregate instance that needs to be reconciled
+	instance := &novav1.NovaAggregate{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers. Return and don't requeue.
+			Log.Info("NovaAggregate instance not found, probably deleted before reconciled. Nothing to do.")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		Log.Error(err, "Failed to read the NovaAggregate instance.")
+		return ctrl.Result{}, err
+	}
+
+	h, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, "Failed to create lib-common Helper")
+		return ctrl.Result{}, err
+	}
+	Log.Info("Reconciling")
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// initialize status fields
+	r.initConditions(instance)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// Always update the instance status when exiting this function so we can
+	// persist any changes happened during the current reconciliation.
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
+			panic(r)
+		}
+		// update the Ready condition based on the sub conditions
+		if allSubConditionIsTrue(instance.Status) {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			// something is not ready so reset the Ready condition
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			// and recalculate it based on the state of the rest of the conditions
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := h.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, h, instance)
+	}
+
+	// We need a finalizer to be able to delete the aggregate from the
+	// compute API when the CR is deleted
+	updated := controllerutil.AddFinalizer(instance, h.GetFinalizer())
+	if updated {
+		Log.Info("Added finalizer to ourselves")
+		// we intentionally return immediately to force the deferred function
+		// to persist the Instance with the finalizer. We need to have our own
+		// finalizer persisted before we create the aggregate to avoid
+		// orphaning it.
+		return ctrl.Result{}, nil
+	}
+
+	computeClient, result, err := getNovaAPIClient(
+		ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+		&instance.Status.Conditions, r.RequeueTimeout, Log)
+	if (err != nil || result != ctrl.Result{}) {
+		return result, err
+	}
+
+	requestedHosts, err := r.getRequestedHosts(ctx, instance)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	err = r.ensureAggregate(instance, computeClient, requestedHosts, Log)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaAggregateSyncedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaAggregateSyncedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	if len(instance.Status.FailedHosts) > 0 {
+		// The hosts are retried but they likely need manual intervention
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaAggregateSyncedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaAggregateSyncedFailedHostsMessage,
+			strings.Join(instance.Status.FailedHosts, ", ")))
+		return ctrl.Result{RequeueAfter: r.RequeueTimeout}, nil
+	}
+
+	if len(instance.Status.MissingHosts) > 0 {
+		// The compute services register themselves in the compute API when
+		// they start so we need to poll until they appear
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaAggregateSyncedCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaAggregateSyncedMissingHostsMessage,
+			strings.Join(instance.Status.MissingHosts, ", ")))
+		return ctrl.Result{RequeueAfter: r.RequeueTimeout}, nil
+	}
+
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaAggregateSyncedCondition, novav1.NovaAggregateSyncedMessage)
+
+	// The aggregate can be changed via the compute API directly so we
+	// periodically check it for drift
+	return ctrl.Result{RequeueAfter: aggregateResyncInterval}, nil
+}
+
+func (r *NovaAggregateReconciler) initConditions(
+	instance *novav1.NovaAggregate,
+) {
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	// initialize all conditions to Unknown
+	cl := condition.CreateList(
+		condition.UnknownCondition(
+			novav1.NovaAPIReadyCondition,
+			condition.InitReason,
+			novav1.NovaAPIReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			condition.InputReadyCondition,
+			condition.InitReason,
+			condition.InputReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaAggregateSyncedCondition,
+			condition.InitReason,
+			novav1.NovaAggregateSyncedInitMessage,
+		),
+	)
+	instance.Status.Conditions.Init(&cl)
+}
+
+// getRequestedHosts returns the sorted list of hosts that should be the
+// members of the aggregate. It is the union of the explicitly listed hosts
+// and the compute hosts mapped to the requested cell as reported by the
+// NovaCell.
+func (r *NovaAggregateReconciler) getRequestedHosts(
+	ctx context.Context,
+	instance *novav1.NovaAggregate,
+) ([]string, error) {
+	hosts := map[string]bool{}
+	for _, host := range instance.Spec.Hosts {
+		hosts[host] = true
+	}
+
+	if instance.Spec.CellName != "" {
+		cell := &novav1.NovaCell{}
+		err := r.Client.Get(ctx, types.NamespacedName{
+			Namespace: instance.Namespace,
+			Name:      getNovaCellCRName(instance.Spec.NovaInstance, instance.Spec.CellName),
+		}, cell)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return nil, err
+		}
+		// The host mappings cover every compute host of the cell, including
+		// the ones not deployed by a NovaCompute. Until the first report
+		// only the explicitly listed hosts are requested.
+		if err == nil && cell.Status.HostMappings != nil {
+			for _, host := range cell.Status.HostMappings.Hosts {
+				hosts[host] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(hosts))
+	for host := range hosts {
+		result = append(result, host)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// getAggregate looks up the aggregate first by the ID stored in the Status
+// then by name. It returns nil if the aggregate does not exist.
+func (r *NovaAggregateReconciler) getAggregate(
+	instance *novav1.NovaAggregate,
+	computeClient *gophercloud.ServiceClient,
+	l logr.Logger,
+) (*aggregates.Aggregate, error) {
+	if instance.Status.AggregateID != 0 {
+		aggregate, err := aggregates.Get(computeClient, instance.Status.AggregateID).Extract()
+		if err == nil {
+			return aggregate, nil
+		}
+		if !isComputeNotFound(err) {
+			return nil, err
+		}
+		l.Info("Aggregate is deleted from the compute API, recreating it", "id", instance.Status.AggregateID)
+		instance.Status.AggregateID = 0
+	}
+
+	allPages, err := aggregates.List(computeClient).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	allAggregates, err := aggregates.ExtractAggregates(allPages)
+	if err != nil {
+		return nil, err
+	}
+	for _, aggregate := range allAggregates {
+		if aggregate.Name == instance.GetAggregateName() {
+			l.Info("Adopting existing aggregate", "name", aggregate.Name, "id", aggregate.ID)
+			return &aggregate, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *NovaAggregateReconciler) ensureAggregate(
+	instance *novav1.NovaAggregate,
+	computeClient *gophercloud.ServiceClient,
+	requestedHosts []string,
+	l logr.Logger,
+) error {
+	aggregate, err := r.getAggregate(instance, computeClient, l)
+	if err != nil {
+		return err
+	}
+
+	if aggregate == nil {
+		aggregate, err = aggregates.Create(computeClient, aggregates.CreateOpts{
+			Name:             instance.GetAggregateName(),
+			AvailabilityZone: instance.Spec.AvailabilityZone,
+		}).Extract()
+		if err != nil {
+			return err
+		}
+		l.Info("Created aggregate", "name", aggregate.Name, "id", aggregate.ID)
+	}
+	instance.Status.AggregateID = aggregate.ID
+
+	if aggregate.Name != instance.GetAggregateName() {
+		aggregate, err = aggregates.Update(computeClient, aggregate.ID, aggregates.UpdateOpts{
+			Name: instance.GetAggregateName(),
+		}).Extract()
+		if err != nil {
+			return err
+		}
+		l.Info("Renamed aggregate", "name", aggregate.Name, "id", aggregate.ID)
+	}
+
+	err = r.ensureMetadata(instance, computeClient, aggregate, l)
+	if err != nil {
+		return err
+	}
+
+	return r.ensureHosts(instance, computeClient, aggregate, requestedHosts, l)
+}
+
+// ensureMetadata syncs the metadata of the aggregate including the
+// availability zone as the compute API stores it as a metadata key too
+func (r *NovaAggregateReconciler) ensureMetadata(
+	instance *novav1.NovaAggregate,
+	computeClient *gophercloud.ServiceClient,
+	aggregate *aggregates.Aggregate,
+	l logr.Logger,
+) error {
+	desired := map[string]string{}
+	for key, value := range instance.Spec.Metadata {
+		desired[key] = value
+	}
+	if instance.Spec.AvailabilityZone != "" {
+		desired[aggregateAZMetadataKey] = instance.Spec.AvailabilityZone
+	}
+
+	// a nil value removes the key from the aggregate
+	toSet := map[string]interface{}{}
+	for key, value := range desired {
+		if currentValue, ok := aggregate.Metadata[key]; !ok || currentValue != value {
+			toSet[key] = value
+		}
+	}
+	for key := range aggregate.Metadata {
+		if _, ok := desired[key]; !ok {
+			toSet[key] = nil
+		}
+	}
+	if len(toSet) == 0 {
+		return nil
+	}
+
+	_, err := aggregates.SetMetadata(
+		computeClient, aggregate.ID, aggregates.SetMetadataOpts{Metadata: toSet}).Extract()
+	if err != nil {
+		return err
+	}
+	l.Info("Updated aggregate metadata", "id", aggregate.ID, "metadata", toSet)
+	return nil
+}
+
+// ensureHosts adds the requested hosts that are registered in the compute API
+// to the aggregate and removes the hosts that are not requested. The
+// requested but unregistered hosts and the hosts the compute API refused to
+// add are reported in the Status.
+func (r *NovaAggregateReconciler) ensureHosts(
+	instance *novav1.NovaAggregate,
+	computeClient *gophercloud.ServiceClient,
+	aggregate *aggregates.Aggregate,
+	requestedHosts []string,
+	l logr.Logger,
+) error {
+	allPages, err := services.List(
+		computeClient, services.ListOpts{Binary: "nova-compute"}).AllPages()
+	if err != nil {
+		return err
+	}
+	computeServices, err := services.ExtractServices(allPages)
+	if err != nil {
+		return err
+	}
+	registered := map[string]bool{}
+	for _, service := range computeServices {
+		registered[service.Host] = true
+	}
+
+	current := map[string]bool{}
+	for _, host := range aggregate.Hosts {
+		current[host] = true
+	}
+
+	requested := map[string]bool{}
+	missingHosts := []string{}
+	failedHosts := []string{}
+	for _, host := range requestedHosts {
+		requested[host] = true
+		if current[host] {
+			continue
+		}
+		if !registered[host] {
+			missingHosts = append(missingHosts, host)
+			continue
+		}
+		_, err = aggregates.AddHost(
+			computeClient, aggregate.ID, aggregates.AddHostOpts{Host: host}).Extract()
+		if err != nil {
+			// e.g. the host is in another aggregate with a different
+			// availability zone. The rest of the hosts are still added.
+			l.Info("Failed to add host to aggregate", "id", aggregate.ID, "host", host, "error", err.Error())
+			failedHosts = append(failedHosts, fmt.Sprintf("%s: %s", host, err.Error()))
+			continue
+		}
+		current[host] = true
+		l.Info("Added host to aggregate", "id", aggregate.ID, "host", host)
+	}
+
+	for _, host := range aggregate.Hosts {
+		if requested[host] {
+			continue
+		}
+		_, err = aggregates.RemoveHost(
+			computeClient, aggregate.ID, aggregates.RemoveHostOpts{Host: host}).Extract()
+		if err != nil {
+			return err
+		}
+		delete(current, host)
+		l.Info("Removed host from aggregate", "id", aggregate.ID, "host", host)
+	}
+
+	hosts := make([]string, 0, len(current))
+	for host := range current {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	instance.Status.Hosts = hosts
+	instance.Status.MissingHosts = missingHosts
+	instance.Status.FailedHosts = failedHosts
+	return nil
+}
+
+func (r *NovaAggregateReconciler) reconcileDelete(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaAggregate,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+	Log.Info("Reconciling delete")
+
+	if instance.Status.AggregateID != 0 {
+		// If the nova-api is being deleted, e.g. as part of deleting the
+		// whole Nova deployment, then there is no compute API left to delete
+		// the aggregate from
+		api := &novav1.NovaAPI{}
+		err := h.GetClient().Get(
+			ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.NovaInstance + "-api"}, api)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if k8s_errors.IsNotFound(err) || !api.DeletionTimestamp.IsZero() {
+			Log.Info("NovaAPI is deleted, skipping the deletion of the aggregate", "id", instance.Status.AggregateID)
+		} else {
+			computeClient, result, err := getNovaAPIClient(
+				ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+				&instance.Status.Conditions, r.RequeueTimeout, Log)
+			if (err != nil || result != ctrl.Result{}) {
+				return result, err
+			}
+			err = r.deleteAggregate(computeClient, instance.Status.AggregateID, Log)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	// Successfully cleaned up everything. So as the final step let's remove the
+	// finalizer from ourselves to allow the deletion of NovaAggregate CR itself
+	updated := controllerutil.RemoveFinalizer(instance, h.GetFinalizer())
+	if updated {
+		Log.Info("Removed finalizer from ourselves")
+	}
+
+	Log.Info("Reconciled delete successfully")
+	return ctrl.Result{}, nil
+}
+
+// deleteAggregate deletes the aggregate from the compute API. The compute
+// API only allows deleting empty aggregates so the hosts are removed first.
+func (r *NovaAggregateReconciler) deleteAggregate(
+	computeClient *gophercloud.ServiceClient,
+	aggregateID int,
+	l logr.Logger,
+) error {
+	aggregate, err := aggregates.Get(computeClient, aggregateID).Extract()
+	if err != nil {
+		if isComputeNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	for _, host := range aggregate.Hosts {
+		_, err = aggregates.RemoveHost(
+			computeClient, aggregateID, aggregates.RemoveHostOpts{Host: host}).Extract()
+		if err != nil && !isComputeNotFound(err) {
+			return err
+		}
+	}
+	err = aggregates.Delete(computeClient, aggregateID).ExtractErr()
+	switch {
+	case err == nil:
+		l.Info("Deleted aggregate", "id", aggregateID)
+	case isComputeNotFound(err):
+		l.Info("Aggregate is already deleted", "id", aggregateID)
+	default:
+		return err
+	}
+	return nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NovaAggregateReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index novaInstanceField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaAggregate{}, novaInstanceField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaAggregate)
+		return []string{cr.Spec.NovaInstance}
+	}); err != nil {
+		return err
+	}
+	// index cellNameField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaAggregate{}, cellNameField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaAggregate)
+		if cr.Spec.CellName == "" {
+			return nil
+		}
+		return []string{cr.Spec.CellName}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&novav1.NovaAggregate{}).
+		// watch the NovaAPI to know when it becomes usable
+		Watches(
+			&novav1.NovaAPI{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNovaAPI),
+		).
+		// watch the NovaCells to follow the hosts of the cell
+		Watches(
+			&novav1.NovaCell{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNovaCell),
+		).
+		Complete(r)
+}
+
+func (r *NovaAggregateReconciler) findObjectsForNovaAPI(ctx context.Context, src client.Object) []reconcile.Request {
+	// The NovaAPI is named after the Nova CR it belongs to
+	novaInstance := strings.TrimSuffix(src.GetName(), "-api")
+	return r.findObjectsForField(ctx, src, novaInstanceField, novaInstance)
+}
+
+func (r *NovaAggregateReconciler) findObjectsForNovaCell(ctx context.Context, src client.Object) []reconcile.Request {
+	cell := src.(*novav1.NovaCell)
+	return r.findObjectsForField(ctx, src, cellNameField, cell.Spec.CellName)
+}
+
+func (r *NovaAggregateReconciler) findObjectsForField(
+	ctx context.Context, src client.Object, field string, value string,
+) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := r.GetLogger(ctx)
+
+	crList := &novav1.NovaAggregateList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(field, value),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.Client.List(ctx, crList, listOps)
+	if err != nil {
+		l.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, field, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
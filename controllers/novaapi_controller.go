@@ -73,6 +73,7 @@ func (r *NovaAPIReconciler) GetLogger(ctx context.Context) logr.Logger {
 //+kubebuilder:rbac:groups=nova.openstack.org,resources=novaapis/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=nova.openstack.org,resources=novaapis/finalizers,verbs=update;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch;
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;
@@ -305,7 +306,9 @@ func (r *NovaAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (re
 		return ctrl.Result{}, err
 	}
 
-	instance.Status.Hash[common.InputHashName] = inputHash
+	revision := getRolloutRevision(
+		instance.Status.Rollout, instance.Generation, instance.Spec.ContainerImage, inputHash)
+	instance.Status.Hash[common.InputHashName] = revision.ConfigHash
 
 	instance.Status.Conditions.MarkTrue(condition.ServiceConfigReadyCondition, condition.ServiceConfigReadyMessage)
 
@@ -314,7 +317,14 @@ func (r *NovaAPIReconciler) Reconcile(ctx context.Context, req ctrl.Request) (re
 		return result, err
 	}
 
-	result, err = r.ensureDeployment(ctx, h, instance, inputHash, serviceAnnotations)
+	result, err = r.ensureDeployment(ctx, h, instance, revision, serviceAnnotations)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = r.ensureRollout(
+		ctx, h, instance, &instance.Status.Rollout, &instance.Status.Conditions,
+		instance.Spec.RolloutDeadline, revision, result)
 	if (err != nil || result != ctrl.Result{}) {
 		return result, err
 	}
@@ -387,6 +397,11 @@ func (r *NovaAPIReconciler) initConditions(
 			condition.InitReason,
 			condition.DeploymentReadyInitMessage,
 		),
+		condition.UnknownCondition(
+			novav1.NovaRolloutReadyCondition,
+			condition.InitReason,
+			novav1.NovaRolloutReadyInitMessage,
+		),
 		condition.UnknownCondition(
 			condition.CreateServiceReadyCondition,
 			condition.InitReason,
@@ -434,7 +449,21 @@ func (r *NovaAPIReconciler) ensureConfigs(
 	secret corev1.Secret,
 	memcachedInstance *memcachedv1.Memcached,
 ) error {
-	err := r.generateConfigs(ctx, h, instance, hashes, secret, memcachedInstance)
+	var err error
+	requestedRevision := instance.Annotations[novav1.ConfigRevisionAnnotation]
+	switch {
+	case instance.Status.Rollout.IsRolledBack(instance.Generation):
+		// keep the last known-good config while the service is rolled back
+		err = r.ensureKnownGoodConfigRestored(ctx, h, instance)
+	case requestedRevision != "":
+		err = r.ensureConfigRevisionRestored(ctx, h, instance, requestedRevision, hashes)
+	default:
+		err = r.generateConfigs(ctx, h, instance, hashes, secret, memcachedInstance)
+	}
+	if err == nil {
+		instance.Status.ConfigRevision, err = r.ensureConfigRevision(
+			ctx, h, instance, instance.Spec.ConfigRevisionHistoryLimit)
+	}
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.ServiceConfigReadyCondition,
@@ -486,9 +515,10 @@ func (r *NovaAPIReconciler) generateConfigs(
 		"cell_db_password":         string(cellDbSecret.Data[mariadbv1.DatabasePasswordSelector]),
 		"cell_db_address":          instance.Spec.Cell0DatabaseHostname,
 		"cell_db_port":             3306,
-		"openstack_region_name":    "regionOne", // fixme
-		"default_project_domain":   "Default",   // fixme
-		"default_user_domain":      "Default",   // fixme
+		"openstack_region_name":    instance.Spec.Region,
+		"default_project_domain":   instance.Spec.ServiceProjectDomain,
+		"default_user_domain":      instance.Spec.ServiceUserDomain,
+		"service_project_name":     instance.Spec.ServiceProjectName,
 		"transport_url":            string(secret.Data[TransportURLSelector]),
 		"log_file":                 "/var/log/nova/nova-api.log",
 		"tls":                      false,
@@ -496,6 +526,16 @@ func (r *NovaAPIReconciler) generateConfigs(
 		"MemcachedServersWithInet": memcachedInstance.GetMemcachedServerListWithInetString(),
 		"MemcachedTLS":             memcachedInstance.GetMemcachedTLSSupport(),
 	}
+	if notificationURL, ok := secret.Data[NotificationTransportURLSelector]; ok {
+		templateParameters["nova_enabled_notification"] = true
+		templateParameters["nova_cell_notify_transport_url"] = string(notificationURL)
+	}
+	if instance.Spec.Telemetry.Enabled {
+		templateParameters["enable_ceilometer"] = true
+		templateParameters["instance_usage_audit_period"] = instance.Spec.Telemetry.InstanceUsageAuditPeriod
+		templateParameters["notify_on_state_change"] = instance.Spec.Telemetry.NotifyOnStateChange
+	}
+	templateParameters["compute_rpc_pin"] = instance.Spec.ComputeRPCPin
 	// create httpd  vhost template parameters
 	httpdVhostConfig := map[string]interface{}{}
 	for _, endpt := range []service.Endpoint{service.EndpointInternal, service.EndpointPublic} {
@@ -545,7 +585,7 @@ func (r *NovaAPIReconciler) ensureDeployment(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *novav1.NovaAPI,
-	inputHash string,
+	revision novav1.NovaServiceRevision,
 	annotations map[string]string,
 ) (ctrl.Result, error) {
 	Log := r.GetLogger(ctx)
@@ -566,7 +606,11 @@ func (r *NovaAPIReconciler) ensureDeployment(
 		return ctrl.Result{}, fmt.Errorf("waiting for Topology requirements: %w", err)
 	}
 
-	ssSpec, err := novaapi.StatefulSet(instance, inputHash, serviceLabels, annotations, topology)
+	// the StatefulSet runs the image of the revision that differs from the
+	// Spec while the service is rolled back
+	ssInstance := instance.DeepCopy()
+	ssInstance.Spec.ContainerImage = revision.ContainerImage
+	ssSpec, err := novaapi.StatefulSet(ssInstance, revision.ConfigHash, serviceLabels, annotations, topology)
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.DeploymentReadyCondition,
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -38,15 +39,18 @@ import (
 
 	"github.com/go-logr/logr"
 	memcachedv1 "github.com/openstack-k8s-operators/infra-operator/apis/memcached/v1beta1"
+	common "github.com/openstack-k8s-operators/lib-common/modules/common"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
 	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	job "github.com/openstack-k8s-operators/lib-common/modules/common/job"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	util "github.com/openstack-k8s-operators/lib-common/modules/common/util"
 
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/novaconductor"
 )
 
 // NovaCellReconciler reconciles a NovaCell object
@@ -65,6 +69,11 @@ func (r *NovaCellReconciler) GetLogger(ctx context.Context) logr.Logger {
 // +kubebuilder:rbac:groups=memcached.openstack.org,resources=memcacheds,verbs=get;list;watch;update;patch
 // +kubebuilder:rbac:groups=memcached.openstack.org,resources=memcacheds/finalizers,verbs=update;patch
 // +kubebuilder:rbac:groups=topology.openstack.org,resources=topologies,verbs=get;list;watch;update
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaaggregates,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -87,6 +96,7 @@ func (r *NovaCellReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 			// Owned objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers. Return and don't requeue.
 			Log.Info("NovaCell instance not found, probably deleted before reconciled. Nothing to do.")
+			deleteCellCapacityMetrics(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -124,6 +134,10 @@ func (r *NovaCellReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
 			panic(r)
 		}
+		// The audit condition only reports the findings of the last audit
+		// so it does not affect the Ready condition
+		auditCondition := instance.Status.Conditions.Get(novav1.NovaCellAuditCondition)
+		instance.Status.Conditions.Remove(novav1.NovaCellAuditCondition)
 		// update the Ready condition based on the sub conditions
 		if allSubConditionIsTrue(instance.Status) {
 			instance.Status.Conditions.MarkTrue(
@@ -136,6 +150,9 @@ func (r *NovaCellReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 			instance.Status.Conditions.Set(
 				instance.Status.Conditions.Mirror(condition.ReadyCondition))
 		}
+		if auditCondition != nil {
+			instance.Status.Conditions.Set(auditCondition)
+		}
 		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
 		err := h.PatchInstance(ctx, instance)
 		if err != nil {
@@ -269,6 +286,10 @@ func (r *NovaCellReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 		return ctrl.Result{}, nil
 	}
 
+	// Every service of the cell is reconciled according to the disabled flag
+	// of the cell at this point
+	instance.Status.Disabled = instance.Spec.Disabled
+
 	var vncProxyURL *string
 	if cellHasVNCService {
 		vncProxyURL, err = r.getVNCProxyURL(ctx, h, instance)
@@ -287,8 +308,26 @@ func (r *NovaCellReconciler) Reconcile(ctx context.Context, req ctrl.Request) (r
 		instance.Status.Conditions.Remove(novav1.NovaComputeServiceConfigReady)
 	}
 
+	result, err = r.ensureOnlineDataMigrations(
+		ctx, h, instance, savedConditions.IsTrue(condition.ReadyCondition))
+	if (err != nil || result != ctrl.Result{}) {
+		return result, err
+	}
+
+	err = r.ensureCellAudit(ctx, h, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	err = r.ensureCellHostsReported(ctx, h, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	result = r.ensureCapacityReported(ctx, h, instance)
+
 	Log.Info("Successfully reconciled")
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
 func (r *NovaCellReconciler) initStatus(
@@ -348,6 +387,11 @@ func (r *NovaCellReconciler) initConditions(
 			condition.InitReason,
 			novav1.NovaComputeReadyInitMessage,
 		),
+		condition.UnknownCondition(
+			novav1.NovaOnlineDataMigrationReadyCondition,
+			condition.InitReason,
+			novav1.NovaOnlineDataMigrationReadyInitMessage,
+		),
 	)
 	instance.Status.Conditions.Init(&cl)
 	return nil
@@ -391,6 +435,7 @@ func (r *NovaCellReconciler) ensureConductor(
 	}
 	if conductor.Generation == conductor.Status.ObservedGeneration {
 		instance.Status.ConductorServiceReadyCount = conductor.Status.ReadyCount
+		instance.Status.DBPurge = conductor.Status.DBPurge
 
 		c := conductor.Status.Conditions.Mirror(novav1.NovaConductorReadyCondition)
 		// NOTE(gibi): it can be nil if the NovaConductor CR is created but no
@@ -403,6 +448,123 @@ func (r *NovaCellReconciler) ensureConductor(
 	return ctrl.Result{}, nil
 }
 
+// ensureOnlineDataMigrations runs the online data migrations of the cell DB
+// when the conductor image changes. Nova only supports running them when
+// every service of the cell already runs the new code so it waits until
+// every cell service is Ready.
+func (r *NovaCellReconciler) ensureOnlineDataMigrations(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaCell,
+	wasReady bool,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	image := instance.Spec.ConductorContainerImageURL
+	if instance.Status.OnlineDataMigrationImage == image {
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaOnlineDataMigrationReadyCondition,
+			novav1.NovaOnlineDataMigrationReadyMessage)
+		return ctrl.Result{}, nil
+	}
+
+	// Nova defers the migrations during an upgrade until every service of
+	// the deployment runs the new images. The cell is usable meanwhile.
+	if instance.Spec.OnlineDataMigrationsDeferred {
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaOnlineDataMigrationReadyCondition,
+			novav1.NovaOnlineDataMigrationReadyDeferredMessage)
+		return ctrl.Result{}, nil
+	}
+
+	conductor := &novav1.NovaConductor{}
+	err := r.Client.Get(ctx, types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      instance.Name + "-conductor",
+	}, conductor)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	// The conductor needs to be rolled out with the new image and every
+	// other service of the cell needs to be Ready too. The NovaNoVNCProxy
+	// is already checked before the compute config is generated.
+	servicesReady := err == nil &&
+		conductor.Generation == conductor.Status.ObservedGeneration &&
+		conductor.Spec.ContainerImage == image &&
+		conductor.Status.Conditions.IsTrue(condition.ReadyCondition)
+	if *instance.Spec.MetadataServiceTemplate.Enabled {
+		servicesReady = servicesReady &&
+			instance.Status.Conditions.IsTrue(novav1.NovaMetadataReadyCondition)
+	}
+	if len(instance.Spec.NovaComputeTemplates) > 0 {
+		servicesReady = servicesReady &&
+			instance.Status.Conditions.IsTrue(novav1.NovaAllControlPlaneComputesReadyCondition)
+	}
+	if !servicesReady {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaOnlineDataMigrationReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaOnlineDataMigrationReadyWaitingMessage))
+		return ctrl.Result{}, nil
+	}
+
+	// A new cell DB is created by the db sync of the current image so there
+	// is nothing to migrate before the cell becomes Ready the first time.
+	if instance.Status.OnlineDataMigrationImage == "" && !wasReady {
+		Log.Info("Skipping online data migration of the new cell DB")
+		instance.Status.OnlineDataMigrationImage = image
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaOnlineDataMigrationReadyCondition,
+			novav1.NovaOnlineDataMigrationReadyMessage)
+		return ctrl.Result{}, nil
+	}
+
+	// The job needs to reach the cell DB on the same networks as the
+	// conductor
+	annotations, result, err := ensureNetworkAttachments(
+		ctx, h, conductor.Spec.NetworkAttachments, &condition.Conditions{}, r.RequeueTimeout)
+	if (err != nil || result != ctrl.Result{}) {
+		return result, err
+	}
+
+	serviceLabels := map[string]string{
+		common.AppSelector: NovaConductorLabelPrefix,
+	}
+	jobDef := novaconductor.CellOnlineDataMigrationJob(conductor, serviceLabels, annotations)
+	migrationJob := job.NewJob(
+		jobDef, "onlinedatamigration", instance.Spec.PreserveJobs, r.RequeueTimeout,
+		instance.Status.Hash[novav1.OnlineDataMigrationHashKey])
+	result, err = migrationJob.DoJob(ctx, h)
+	if (result != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaOnlineDataMigrationReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaOnlineDataMigrationReadyRunningMessage))
+		return result, nil
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaOnlineDataMigrationReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaOnlineDataMigrationReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if migrationJob.HasChanged() {
+		instance.Status.Hash[novav1.OnlineDataMigrationHashKey] = migrationJob.GetHash()
+		Log.Info(fmt.Sprintf("Job %s hash added %s", jobDef.Name, instance.Status.Hash[novav1.OnlineDataMigrationHashKey]))
+	}
+	instance.Status.OnlineDataMigrationImage = image
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaOnlineDataMigrationReadyCondition,
+		novav1.NovaOnlineDataMigrationReadyMessage)
+
+	return ctrl.Result{}, nil
+}
+
 func getNoVNCProxyName(instance *novav1.NovaCell) types.NamespacedName {
 	return types.NamespacedName{Namespace: instance.Namespace, Name: instance.Name + "-novncproxy"}
 }
@@ -771,12 +933,23 @@ func (r *NovaCellReconciler) generateComputeConfigs(
 		"keystone_internal_url":  instance.Spec.KeystoneAuthURL,
 		"nova_keystone_user":     instance.Spec.ServiceUser,
 		"nova_keystone_password": string(secret.Data[ServicePasswordSelector]),
-		"openstack_region_name":  "regionOne", // fixme
-		"default_project_domain": "Default",   // fixme
-		"default_user_domain":    "Default",   // fixme
+		"openstack_region_name":  instance.Spec.Region,
+		"default_project_domain": instance.Spec.ServiceProjectDomain,
+		"default_user_domain":    instance.Spec.ServiceUserDomain,
+		"service_project_name":   instance.Spec.ServiceProjectName,
 		"compute_driver":         "libvirt.LibvirtDriver",
 		"transport_url":          string(secret.Data[TransportURLSelector]),
 	}
+	if notificationURL, ok := secret.Data[NotificationTransportURLSelector]; ok {
+		templateParameters["nova_enabled_notification"] = true
+		templateParameters["nova_cell_notify_transport_url"] = string(notificationURL)
+	}
+	if instance.Spec.Telemetry.Enabled {
+		templateParameters["enable_ceilometer"] = true
+		templateParameters["instance_usage_audit_period"] = instance.Spec.Telemetry.InstanceUsageAuditPeriod
+		templateParameters["notify_on_state_change"] = instance.Spec.Telemetry.NotifyOnStateChange
+	}
+	templateParameters["compute_rpc_pin"] = instance.Spec.ComputeRPCPin
 	// vnc is optional so we only need to configure it for the compute
 	// if the proxy service is deployed in the cell
 	if vncProxyURL != nil {
@@ -945,6 +1118,19 @@ func (r *NovaCellReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&novav1.NovaMetadata{}).
 		Owns(&novav1.NovaNoVNCProxy{}).
 		Owns(&novav1.NovaCompute{}).
+		// It runs the online data migrations
+		Owns(&batchv1.Job{}).
+		// It runs the consistency audit
+		Owns(&batchv1.CronJob{}).
+		Watches(
+			&batchv1.Job{},
+			handler.EnqueueRequestsFromMapFunc(r.findNovaCellForAuditJob),
+		).
+		// It reports the hosts of the cell from the Jobs of its CronJob
+		Watches(
+			&batchv1.Job{},
+			handler.EnqueueRequestsFromMapFunc(r.findNovaCellForCellHostsJob),
+		).
 		// It generates and therefor owns the compute config secret
 		Owns(&corev1.Secret{}).
 		// watch the input secrets
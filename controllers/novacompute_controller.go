@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -241,6 +242,22 @@ func (r *NovaComputeReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return result, err
 	}
 
+	if !instance.Status.Conditions.IsTrue(condition.DeploymentReadyCondition) {
+		Log.Info("Waiting for the deployment to be ready before doing service cleanup in the nova database.")
+
+		return ctrl.Result{}, nil
+	}
+
+	// clean up nova services from nova db should be always a last step in reconcile
+	requeueAfter, err := r.cleanServiceFromNovaDb(ctx, h, instance, secret, Log)
+	if err != nil {
+		Log.Error(err, "Failed cleaning services from nova db")
+	}
+	if requeueAfter > 0 {
+		Log.Info("Stale services are waiting for their grace period", "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
 	Log.Info("Successfully reconciled")
 	return ctrl.Result{}, nil
 }
@@ -340,14 +357,25 @@ func (r *NovaComputeReconciler) generateConfigs(
 		"keystone_internal_url":  instance.Spec.KeystoneAuthURL,
 		"nova_keystone_user":     instance.Spec.ServiceUser,
 		"nova_keystone_password": string(secret.Data[ServicePasswordSelector]),
-		"openstack_region_name":  "regionOne", // fixme
-		"default_project_domain": "Default",   // fixme
-		"default_user_domain":    "Default",   // fixme
+		"openstack_region_name":  instance.Spec.Region,
+		"default_project_domain": instance.Spec.ServiceProjectDomain,
+		"default_user_domain":    instance.Spec.ServiceUserDomain,
+		"service_project_name":   instance.Spec.ServiceProjectName,
 		"transport_url":          string(secret.Data[TransportURLSelector]),
 		"compute_driver":         instance.Spec.ComputeDriver,
 		// Neither the ironic driver nor the fake driver support VNC
 		"vnc_enabled": false,
 	}
+	if notificationURL, ok := secret.Data[NotificationTransportURLSelector]; ok {
+		templateParameters["nova_enabled_notification"] = true
+		templateParameters["nova_cell_notify_transport_url"] = string(notificationURL)
+	}
+	if instance.Spec.Telemetry.Enabled {
+		templateParameters["enable_ceilometer"] = true
+		templateParameters["instance_usage_audit_period"] = instance.Spec.Telemetry.InstanceUsageAuditPeriod
+		templateParameters["notify_on_state_change"] = instance.Spec.Telemetry.NotifyOnStateChange
+	}
+	templateParameters["compute_rpc_pin"] = instance.Spec.ComputeRPCPin
 
 	extraData := map[string]string{}
 	if instance.Spec.CustomServiceConfig != "" {
@@ -460,6 +488,30 @@ func (r *NovaComputeReconciler) ensureDeployment(
 	return ctrl.Result{}, nil
 }
 
+func (r *NovaComputeReconciler) cleanServiceFromNovaDb(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaCompute,
+	secret corev1.Secret,
+	l logr.Logger,
+) (time.Duration, error) {
+	authPassword := string(secret.Data[ServicePasswordSelector])
+	computeClient, err := getNovaClient(ctx, h, instance, authPassword, l)
+	if err != nil {
+		return 0, err
+	}
+	statefulSetName := types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      instance.Name,
+	}
+
+	return reapStaleNovaServices(
+		ctx, h.GetClient(), computeClient, "nova-compute", statefulSetName,
+		*instance.Spec.Replicas,
+		time.Duration(instance.Spec.StaleServiceGracePeriod)*time.Second,
+		instance.Spec.KeystoneServiceIdentity.Region, l)
+}
+
 func (r *NovaComputeReconciler) reconcileDelete(
 	ctx context.Context,
 	h *helper.Helper,
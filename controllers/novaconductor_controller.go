@@ -18,12 +18,15 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	v1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -69,6 +72,7 @@ func (r *NovaConductorReconciler) GetLogger(ctx context.Context) logr.Logger {
 //+kubebuilder:rbac:groups=nova.openstack.org,resources=novaconductors/finalizers,verbs=update;patch
 //+kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
 //+kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
+//+kubebuilder:rbac:groups=core,resources=events,verbs=create;patch;
 //+kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete;
 //+kubebuilder:rbac:groups=k8s.cni.cncf.io,resources=network-attachment-definitions,verbs=get;list;watch
@@ -270,7 +274,9 @@ func (r *NovaConductorReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	if err != nil {
 		return ctrl.Result{}, err
 	}
-	instance.Status.Hash[common.InputHashName] = inputHash
+	revision := getRolloutRevision(
+		instance.Status.Rollout, instance.Generation, instance.Spec.ContainerImage, inputHash)
+	instance.Status.Hash[common.InputHashName] = revision.ConfigHash
 
 	instance.Status.Conditions.MarkTrue(condition.ServiceConfigReadyCondition, condition.ServiceConfigReadyMessage)
 
@@ -284,7 +290,14 @@ func (r *NovaConductorReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return result, err
 	}
 
-	result, err = r.ensureDeployment(ctx, h, instance, inputHash, serviceAnnotations)
+	result, err = r.ensureDeployment(ctx, h, instance, revision, serviceAnnotations)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = r.ensureRollout(
+		ctx, h, instance, &instance.Status.Rollout, &instance.Status.Conditions,
+		instance.Spec.RolloutDeadline, revision, result)
 	if (err != nil || result != ctrl.Result{}) {
 		return result, err
 	}
@@ -294,16 +307,25 @@ func (r *NovaConductorReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
+	err = r.reportDBPurgeRuns(ctx, h, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if !instance.Status.Conditions.IsTrue(condition.DeploymentReadyCondition) {
 		Log.Info("Waiting for the deployment to be ready before doing service cleanup in the nova database.")
 
 		return ctrl.Result{}, nil
 	}
 	// clean up nova services from nova db should be always a last step in reconcile
-	err = r.cleanServiceFromNovaDb(ctx, h, instance, secret, Log)
+	requeueAfter, err := r.cleanServiceFromNovaDb(ctx, h, instance, secret, Log)
 	if err != nil {
 		Log.Error(err, "Failed cleaning services from nova db")
 	}
+	if requeueAfter > 0 {
+		Log.Info("Stale services are waiting for their grace period", "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
 
 	Log.Info("Successfully reconciled")
 	return ctrl.Result{}, nil
@@ -359,6 +381,11 @@ func (r *NovaConductorReconciler) initConditions(
 			condition.InitReason,
 			condition.DeploymentReadyInitMessage,
 		),
+		condition.UnknownCondition(
+			novav1.NovaRolloutReadyCondition,
+			condition.InitReason,
+			novav1.NovaRolloutReadyInitMessage,
+		),
 		condition.UnknownCondition(
 			condition.NetworkAttachmentsReadyCondition,
 			condition.InitReason,
@@ -401,7 +428,21 @@ func (r *NovaConductorReconciler) ensureConfigs(
 	secret corev1.Secret,
 	memcachedInstance *memcachedv1.Memcached,
 ) error {
-	err := r.generateConfigs(ctx, h, instance, hashes, secret, memcachedInstance)
+	var err error
+	requestedRevision := instance.Annotations[novav1.ConfigRevisionAnnotation]
+	switch {
+	case instance.Status.Rollout.IsRolledBack(instance.Generation):
+		// keep the last known-good config while the service is rolled back
+		err = r.ensureKnownGoodConfigRestored(ctx, h, instance)
+	case requestedRevision != "":
+		err = r.ensureConfigRevisionRestored(ctx, h, instance, requestedRevision, hashes)
+	default:
+		err = r.generateConfigs(ctx, h, instance, hashes, secret, memcachedInstance)
+	}
+	if err == nil {
+		instance.Status.ConfigRevision, err = r.ensureConfigRevision(
+			ctx, h, instance, instance.Spec.ConfigRevisionHistoryLimit)
+	}
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.ServiceConfigReadyCondition,
@@ -440,14 +481,25 @@ func (r *NovaConductorReconciler) generateConfigs(
 		"cell_db_password":         string(cellDbSecret.Data[mariadbv1.DatabasePasswordSelector]),
 		"cell_db_address":          instance.Spec.CellDatabaseHostname,
 		"cell_db_port":             3306,
-		"openstack_region_name":    "regionOne", // fixme
-		"default_project_domain":   "Default",   // fixme
-		"default_user_domain":      "Default",   // fixme
+		"openstack_region_name":    instance.Spec.Region,
+		"default_project_domain":   instance.Spec.ServiceProjectDomain,
+		"default_user_domain":      instance.Spec.ServiceUserDomain,
+		"service_project_name":     instance.Spec.ServiceProjectName,
 		"transport_url":            string(secret.Data[TransportURLSelector]),
 		"MemcachedServers":         memcachedInstance.GetMemcachedServerListString(),
 		"MemcachedServersWithInet": memcachedInstance.GetMemcachedServerListWithInetString(),
 		"MemcachedTLS":             memcachedInstance.GetMemcachedTLSSupport(),
 	}
+	if notificationURL, ok := secret.Data[NotificationTransportURLSelector]; ok {
+		templateParameters["nova_enabled_notification"] = true
+		templateParameters["nova_cell_notify_transport_url"] = string(notificationURL)
+	}
+	if instance.Spec.Telemetry.Enabled {
+		templateParameters["enable_ceilometer"] = true
+		templateParameters["instance_usage_audit_period"] = instance.Spec.Telemetry.InstanceUsageAuditPeriod
+		templateParameters["notify_on_state_change"] = instance.Spec.Telemetry.NotifyOnStateChange
+	}
+	templateParameters["compute_rpc_pin"] = instance.Spec.ComputeRPCPin
 	if len(instance.Spec.APIDatabaseHostname) > 0 {
 		apiDatabaseAccount, apiDbSecret, err := mariadbv1.GetAccountAndSecret(ctx, h, instance.Spec.APIDatabaseAccount, instance.Namespace)
 		if err != nil {
@@ -524,7 +576,7 @@ func (r *NovaConductorReconciler) ensureDeployment(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *novav1.NovaConductor,
-	inputHash string,
+	revision novav1.NovaServiceRevision,
 	annotations map[string]string,
 ) (ctrl.Result, error) {
 	serviceLabels := map[string]string{
@@ -548,7 +600,11 @@ func (r *NovaConductorReconciler) ensureDeployment(
 		return ctrl.Result{}, fmt.Errorf("waiting for Topology requirements: %w", err)
 	}
 
-	ss := statefulset.NewStatefulSet(novaconductor.StatefulSet(instance, inputHash, serviceLabels, annotations, topology), r.RequeueTimeout)
+	// the StatefulSet runs the image of the revision that differs from the
+	// Spec while the service is rolled back
+	ssInstance := instance.DeepCopy()
+	ssInstance.Spec.ContainerImage = revision.ContainerImage
+	ss := statefulset.NewStatefulSet(novaconductor.StatefulSet(ssInstance, revision.ConfigHash, serviceLabels, annotations, topology), r.RequeueTimeout)
 	ctrlResult, err := ss.CreateOrPatch(ctx, h)
 	if err != nil && !k8s_errors.IsNotFound(err) {
 		Log.Error(err, "Deployment failed")
@@ -644,22 +700,157 @@ func (r *NovaConductorReconciler) ensureDBPurgeCronJob(
 	return nil
 }
 
+// dbPurgeReport is the report the DB purge Job writes to the termination
+// message of its pod
+type dbPurgeReport struct {
+	ArchivedRows int  `json:"archivedRows"`
+	PurgedRows   int  `json:"purgedRows"`
+	DryRun       bool `json:"dryRun"`
+}
+
+// reportDBPurgeRuns records the result of the last successful and the last
+// failed Job of the DB purge CronJob
+func (r *NovaConductorReconciler) reportDBPurgeRuns(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaConductor,
+) error {
+	Log := r.GetLogger(ctx)
+
+	jobs := &batchv1.JobList{}
+	err := r.Client.List(ctx, jobs,
+		client.InNamespace(instance.Namespace),
+		client.MatchingLabels{common.AppSelector: NovaConductorLabelPrefix})
+	if err != nil {
+		return err
+	}
+
+	cronName := novaconductor.DBPurgeCronJobName(instance)
+	var lastSuccess, lastFailure *batchv1.Job
+	var lastFailureCondition *batchv1.JobCondition
+	for i := range jobs.Items {
+		purgeJob := &jobs.Items[i]
+		owner := metav1.GetControllerOf(purgeJob)
+		if owner == nil || owner.Kind != "CronJob" || owner.Name != cronName {
+			continue
+		}
+		if purgeJob.Status.CompletionTime != nil {
+			if lastSuccess == nil ||
+				lastSuccess.Status.CompletionTime.Before(purgeJob.Status.CompletionTime) {
+				lastSuccess = purgeJob
+			}
+			continue
+		}
+		for j := range purgeJob.Status.Conditions {
+			c := &purgeJob.Status.Conditions[j]
+			if c.Type != batchv1.JobFailed || c.Status != corev1.ConditionTrue {
+				continue
+			}
+			if lastFailureCondition == nil ||
+				lastFailureCondition.LastTransitionTime.Before(&c.LastTransitionTime) {
+				lastFailure = purgeJob
+				lastFailureCondition = c
+			}
+		}
+	}
+
+	status := instance.Status.DBPurge
+	if status == nil {
+		status = &novav1.NovaCellDBPurgeStatus{}
+	}
+
+	if lastSuccess != nil &&
+		(status.LastSuccessTime == nil || status.LastSuccessTime.Before(lastSuccess.Status.CompletionTime)) {
+		message, err := getJobTerminationMessage(
+			ctx, h.GetClient(), lastSuccess.Namespace, lastSuccess.Name)
+		if err != nil {
+			return err
+		}
+		report := dbPurgeReport{}
+		if err := json.Unmarshal([]byte(message), &report); err != nil {
+			Log.Info("The archived and purged rows are not reported by the job",
+				"job", lastSuccess.Name, "error", err.Error())
+		}
+		status.LastSuccessTime = lastSuccess.Status.CompletionTime.DeepCopy()
+		status.ArchivedRows = report.ArchivedRows
+		status.PurgedRows = report.PurgedRows
+		status.DryRun = report.DryRun
+	}
+
+	if lastFailure != nil &&
+		(status.LastFailureTime == nil || status.LastFailureTime.Before(&lastFailureCondition.LastTransitionTime)) {
+		reason := lastFailureCondition.Reason + ": " + lastFailureCondition.Message
+		// The end of the log of the failed command if the pod still exists
+		message, err := getJobTerminationMessage(
+			ctx, h.GetClient(), lastFailure.Namespace, lastFailure.Name)
+		if err != nil {
+			return err
+		}
+		if message != "" {
+			reason += ": " + message
+		}
+		status.LastFailureTime = lastFailureCondition.LastTransitionTime.DeepCopy()
+		status.LastFailureReason = reason
+	}
+
+	if status.LastSuccessTime != nil || status.LastFailureTime != nil {
+		instance.Status.DBPurge = status
+	}
+	return nil
+}
+
+// findNovaConductorForDBPurgeJob returns the NovaConductor of the DB purge
+// Job so that the result of the Job is reported when it finishes. The Job is
+// owned by the CronJob that is owned by the NovaConductor.
+func (r *NovaConductorReconciler) findNovaConductorForDBPurgeJob(
+	ctx context.Context, src client.Object,
+) []reconcile.Request {
+	owner := metav1.GetControllerOf(src)
+	if owner == nil || owner.Kind != "CronJob" {
+		return nil
+	}
+	cron := &batchv1.CronJob{}
+	err := r.Client.Get(
+		ctx, types.NamespacedName{Namespace: src.GetNamespace(), Name: owner.Name}, cron)
+	if err != nil {
+		return nil
+	}
+	cronOwner := metav1.GetControllerOf(cron)
+	if cronOwner == nil || cronOwner.Kind != "NovaConductor" {
+		return nil
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Namespace: src.GetNamespace(),
+				Name:      cronOwner.Name,
+			},
+		},
+	}
+}
+
 func (r *NovaConductorReconciler) cleanServiceFromNovaDb(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *novav1.NovaConductor,
 	secret corev1.Secret,
 	l logr.Logger,
-) error {
+) (time.Duration, error) {
 	authPassword := string(secret.Data[ServicePasswordSelector])
 	computeClient, err := getNovaClient(ctx, h, instance, authPassword, l)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	statefulSetName := types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      instance.Name,
 	}
-	replicaCount := instance.Spec.Replicas
-	cellName := instance.Spec.CellName
 
-	return cleanNovaServiceFromNovaDb(computeClient, "nova-conductor", l, *replicaCount, cellName)
+	return reapStaleNovaServices(
+		ctx, h.GetClient(), computeClient, "nova-conductor", statefulSetName,
+		*instance.Spec.Replicas,
+		time.Duration(instance.Spec.StaleServiceGracePeriod)*time.Second,
+		instance.Spec.KeystoneServiceIdentity.Region, l)
 }
 
 func (r *NovaConductorReconciler) findObjectsForSrc(ctx context.Context, src client.Object) []reconcile.Request {
@@ -818,6 +1009,11 @@ func (r *NovaConductorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&v1.StatefulSet{}).
 		Owns(&batchv1.Job{}).
 		Owns(&batchv1.CronJob{}).
+		// watch the Jobs of the DB purge CronJob
+		Watches(
+			&batchv1.Job{},
+			handler.EnqueueRequestsFromMapFunc(r.findNovaConductorForDBPurgeJob),
+		).
 		Owns(&corev1.Secret{}).
 		// watch the input secrets
 		Watches(
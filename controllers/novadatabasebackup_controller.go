@@ -0,0 +1,533 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/cronjob"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	job "github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/pvc"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
+)
+
+// databaseBackupHashKey is the key of the hash of the one-shot backup Job in
+// the status of the NovaDatabaseBackup
+const databaseBackupHashKey = "db-backup"
+
+// databaseBackupReport is the report the backup Job writes to the
+// termination message of its pod
+type databaseBackupReport struct {
+	Path           string            `json:"path"`
+	Cells          []string          `json:"cells"`
+	SchemaVersions map[string]string `json:"schemaVersions"`
+	Image          string            `json:"image"`
+}
+
+// NovaDatabaseBackupReconciler reconciles a NovaDatabaseBackup object
+type NovaDatabaseBackupReconciler struct {
+	ReconcilerBase
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *NovaDatabaseBackupReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("NovaDatabaseBackup")
+}
+
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novadatabasebackups,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novadatabasebackups/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novadatabasebackups/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=nova,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novacells,verbs=get;list;watch
+// +kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbdatabases,verbs=get;list;watch
+// +kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=batch,resources=cronjobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile takes a backup of the API DB and the cell DBs of the Nova
+// deployment to a PVC once or schedules the backups and records the
+// metadata of the last backup
+func (r *NovaDatabaseBackupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	// Fetch the NovaDatabaseBackup instance that needs to be reconciled
+	instance := &novav1.NovaDatabaseBackup{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers. Return and don't requeue.
+			Log.Info("NovaDatabaseBackup instance not found, probably deleted before reconciled. Nothing to do.")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		Log.Error(err, "Failed to read the NovaDatabaseBackup instance.")
+		return ctrl.Result{}, err
+	}
+
+	h, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, "Failed to create lib-common Helper")
+		return ctrl.Result{}, err
+	}
+	Log.Info("Reconciling")
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// initialize status fields
+	r.initStatus(instance)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// Always update the instance status when exiting this function so we can
+	// persist any changes happened during the current reconciliation.
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
+			panic(r)
+		}
+		// update the Ready condition based on the sub conditions
+		if allSubConditionIsTrue(instance.Status) {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			// something is not ready so reset the Ready condition
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			// and recalculate it based on the state of the rest of the conditions
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := h.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	// A one-shot backup is not taken again
+	if instance.Spec.Schedule == nil && instance.Status.LastBackup != nil {
+		instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaDatabaseBackupCondition, novav1.NovaDatabaseBackupMessage)
+		return ctrl.Result{}, nil
+	}
+
+	novaInstance, cells, cellNames, waitingFor, err := getNovaDatabaseCells(
+		ctx, r.Client, instance.Namespace, instance.Spec.NovaInstance, instance.Spec.Cells)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if waitingFor != "" {
+		// We will be reconciled when the Nova CR changes
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaDatabaseInputWaitingMessage,
+			waitingFor))
+		return ctrl.Result{}, nil
+	}
+
+	databases := getNovaDatabases(novaInstance, cells, cellNames, true)
+
+	backupLabels := getDatabaseBackupLabels(instance)
+	configName, scriptName := getDatabaseBackupSecretNames(instance)
+	err = ensureDatabaseJobSecrets(
+		ctx, h, novaInstance, databases, configName, scriptName, backupLabels)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	result, err = r.ensurePVC(ctx, h, instance, backupLabels)
+	if err != nil || (result != ctrl.Result{}) {
+		return result, err
+	}
+
+	novaImage := cells[novav1.Cell0Name].Spec.ConductorContainerImageURL
+	image := instance.Spec.ContainerImage
+	if image == "" {
+		image = novaImage
+	}
+
+	if instance.Spec.Schedule != nil {
+		return ctrl.Result{}, r.ensureScheduledBackups(
+			ctx, h, instance, novaInstance, image, novaImage,
+			getNovaDatabaseNames(databases), cellNames, configName, scriptName, backupLabels)
+	}
+	return r.ensureBackup(
+		ctx, h, instance, novaInstance, image, novaImage,
+		getNovaDatabaseNames(databases), cellNames, configName, scriptName, backupLabels)
+}
+
+func getDatabaseBackupLabels(instance *novav1.NovaDatabaseBackup) map[string]string {
+	return labels.GetLabels(
+		instance, labels.GetGroupLabel(NovaDatabaseBackupLabelPrefix),
+		map[string]string{common.AppSelector: NovaDatabaseBackupLabelPrefix},
+	)
+}
+
+// getDatabaseBackupSecretNames returns the names of the config and the
+// scripts Secrets of the backup Jobs
+func getDatabaseBackupSecretNames(
+	instance *novav1.NovaDatabaseBackup,
+) (configName string, scriptName string) {
+	return nova.DatabaseBackupName(instance) + "-config-data",
+		nova.DatabaseBackupName(instance) + "-scripts"
+}
+
+func (r *NovaDatabaseBackupReconciler) ensurePVC(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaDatabaseBackup,
+	backupLabels map[string]string,
+) (ctrl.Result, error) {
+	pvcDef, err := nova.DatabaseBackupPVC(instance, backupLabels)
+	if err == nil {
+		var result ctrl.Result
+		result, err = pvc.NewPvc(pvcDef, r.RequeueTimeout).CreateOrPatch(ctx, h)
+		if err == nil {
+			return result, nil
+		}
+	}
+	instance.Status.Conditions.Set(condition.FalseCondition(
+		novav1.NovaDatabaseBackupCondition,
+		condition.ErrorReason,
+		condition.SeverityError,
+		novav1.NovaDatabaseBackupErrorMessage,
+		err.Error()))
+	return ctrl.Result{}, err
+}
+
+// ensureBackup takes the backup once in a Job and records its metadata when
+// the Job finished
+func (r *NovaDatabaseBackupReconciler) ensureBackup(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaDatabaseBackup,
+	novaInstance *novav1.Nova,
+	image string,
+	novaImage string,
+	databases []string,
+	cellNames []string,
+	configName string,
+	scriptName string,
+	backupLabels map[string]string,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	// the backup is not scheduled anymore
+	err := r.deleteCronJob(ctx, h, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	jobDef := nova.DatabaseBackupJob(
+		instance, novaInstance, image, novaImage, databases, cellNames,
+		configName, scriptName, backupLabels)
+	backupJob := job.NewJob(
+		jobDef, databaseBackupHashKey,
+		novaInstance.Spec.PreserveJobs, r.RequeueTimeout,
+		instance.Status.Hash[databaseBackupHashKey])
+
+	result, err := backupJob.DoJob(ctx, h)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaDatabaseBackupCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaDatabaseBackupErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if (result != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaDatabaseBackupCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaDatabaseBackupRunningMessage))
+		return result, nil
+	}
+
+	message, err := getJobTerminationMessage(ctx, h.GetClient(), jobDef.Namespace, jobDef.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	err = setLastBackup(instance, message, metav1.Now())
+	if err != nil {
+		Log.Info("The backup is not reported by the job", "job", jobDef.Name, "error", err.Error())
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaDatabaseBackupCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaDatabaseBackupErrorMessage,
+			"the Job "+jobDef.Name+" finished without reporting the backup"))
+		return ctrl.Result{}, nil
+	}
+	instance.Status.Hash[databaseBackupHashKey] = backupJob.GetHash()
+	Log.Info("Database backup completed", "path", instance.Status.LastBackup.Path)
+
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaDatabaseBackupCondition, novav1.NovaDatabaseBackupMessage)
+	return ctrl.Result{}, nil
+}
+
+// ensureScheduledBackups ensures the backup CronJob and records the metadata
+// of the backup of its last completed Job
+func (r *NovaDatabaseBackupReconciler) ensureScheduledBackups(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaDatabaseBackup,
+	novaInstance *novav1.Nova,
+	image string,
+	novaImage string,
+	databases []string,
+	cellNames []string,
+	configName string,
+	scriptName string,
+	backupLabels map[string]string,
+) error {
+	Log := r.GetLogger(ctx)
+
+	cronDef := nova.DatabaseBackupCronJob(
+		instance, novaInstance, image, novaImage, databases, cellNames,
+		configName, scriptName, backupLabels)
+	_, err := cronjob.NewCronJob(cronDef, r.RequeueTimeout).CreateOrPatch(ctx, h)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaDatabaseBackupCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaDatabaseBackupErrorMessage,
+			err.Error()))
+		return err
+	}
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaDatabaseBackupCondition,
+		novav1.NovaDatabaseBackupScheduledMessage,
+		*instance.Spec.Schedule)
+
+	backupJob, err := getLastCompletedJob(ctx, h.GetClient(), instance.Namespace, backupLabels)
+	if err != nil {
+		return err
+	}
+	if backupJob == nil || (instance.Status.LastBackup != nil &&
+		!instance.Status.LastBackup.Time.Before(backupJob.Status.CompletionTime)) {
+		return nil
+	}
+	message, err := getJobTerminationMessage(
+		ctx, h.GetClient(), backupJob.Namespace, backupJob.Name)
+	if err != nil {
+		return err
+	}
+	err = setLastBackup(instance, message, *backupJob.Status.CompletionTime)
+	if err != nil {
+		Log.Info("The backup is not reported by the job", "job", backupJob.Name, "error", err.Error())
+	}
+	return nil
+}
+
+// setLastBackup records the backup reported by the backup Job
+func setLastBackup(
+	instance *novav1.NovaDatabaseBackup,
+	message string,
+	completionTime metav1.Time,
+) error {
+	report := databaseBackupReport{}
+	err := json.Unmarshal([]byte(message), &report)
+	if err != nil {
+		return err
+	}
+	instance.Status.LastBackup = &novav1.NovaDatabaseBackupRecord{
+		Time:           completionTime,
+		Path:           report.Path,
+		Cells:          report.Cells,
+		SchemaVersions: report.SchemaVersions,
+		ContainerImage: report.Image,
+	}
+	return nil
+}
+
+func (r *NovaDatabaseBackupReconciler) deleteCronJob(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaDatabaseBackup,
+) error {
+	cron := cronjob.NewCronJob(
+		&batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      nova.DatabaseBackupName(instance),
+				Namespace: instance.Namespace,
+			},
+		},
+		r.RequeueTimeout)
+	return cron.Delete(ctx, h)
+}
+
+func (r *NovaDatabaseBackupReconciler) initStatus(
+	instance *novav1.NovaDatabaseBackup,
+) {
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	// initialize all conditions to Unknown
+	cl := condition.CreateList(
+		condition.UnknownCondition(
+			condition.InputReadyCondition,
+			condition.InitReason,
+			condition.InputReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaDatabaseBackupCondition,
+			condition.InitReason,
+			novav1.NovaDatabaseBackupInitMessage,
+		),
+	)
+	instance.Status.Conditions.Init(&cl)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NovaDatabaseBackupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index novaInstanceField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaDatabaseBackup{}, novaInstanceField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaDatabaseBackup)
+		return []string{cr.Spec.NovaInstance}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&novav1.NovaDatabaseBackup{}).
+		Owns(&batchv1.Job{}).
+		Owns(&batchv1.CronJob{}).
+		Owns(&corev1.PersistentVolumeClaim{}).
+		Owns(&corev1.Secret{}).
+		// watch the Jobs of the backup CronJob
+		Watches(
+			&batchv1.Job{},
+			handler.EnqueueRequestsFromMapFunc(r.findNovaDatabaseBackupForJob),
+		).
+		// watch the Nova CR to know when the cells are created
+		Watches(
+			&novav1.Nova{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNova),
+		).
+		Complete(r)
+}
+
+// findNovaDatabaseBackupForJob returns the NovaDatabaseBackup of the Job of
+// the backup CronJob so that the backup is recorded when the Job finishes
+func (r *NovaDatabaseBackupReconciler) findNovaDatabaseBackupForJob(
+	ctx context.Context, src client.Object,
+) []reconcile.Request {
+	jobLabels := src.GetLabels()
+	if jobLabels[common.AppSelector] != NovaDatabaseBackupLabelPrefix {
+		return nil
+	}
+	name := jobLabels[labels.GetOwnerNameLabelSelector(labels.GetGroupLabel(NovaDatabaseBackupLabelPrefix))]
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{
+			NamespacedName: types.NamespacedName{
+				Namespace: src.GetNamespace(),
+				Name:      name,
+			},
+		},
+	}
+}
+
+func (r *NovaDatabaseBackupReconciler) findObjectsForNova(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := r.GetLogger(ctx)
+
+	crList := &novav1.NovaDatabaseBackupList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(novaInstanceField, src.GetName()),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.Client.List(ctx, crList, listOps)
+	if err != nil {
+		l.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, novaInstanceField, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
@@ -0,0 +1,542 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	job "github.com/openstack-k8s-operators/lib-common/modules/common/job"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/labels"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
+)
+
+const (
+	// databaseRestoreHashKey is the key of the hash of the restore Job in the
+	// status of the NovaDatabaseRestore
+	databaseRestoreHashKey = "db-restore"
+	// backupNameField is the field the NovaDatabaseRestores are indexed by
+	backupNameField = ".spec.backupName"
+)
+
+// NovaDatabaseRestoreReconciler reconciles a NovaDatabaseRestore object
+type NovaDatabaseRestoreReconciler struct {
+	ReconcilerBase
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *NovaDatabaseRestoreReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("NovaDatabaseRestore")
+}
+
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novadatabaserestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novadatabaserestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novadatabaserestores/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novadatabasebackups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=nova,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novacells,verbs=get;list;watch
+// +kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbdatabases,verbs=get;list;watch
+// +kubebuilder:rbac:groups=mariadb.openstack.org,resources=mariadbaccounts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete
+
+// Reconcile restores the API DB and the cell DBs of the Nova deployment from
+// the last backup of a NovaDatabaseBackup once the services using the
+// databases are scaled down
+func (r *NovaDatabaseRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	// Fetch the NovaDatabaseRestore instance that needs to be reconciled
+	instance := &novav1.NovaDatabaseRestore{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers. Return and don't requeue.
+			Log.Info("NovaDatabaseRestore instance not found, probably deleted before reconciled. Nothing to do.")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		Log.Error(err, "Failed to read the NovaDatabaseRestore instance.")
+		return ctrl.Result{}, err
+	}
+
+	h, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, "Failed to create lib-common Helper")
+		return ctrl.Result{}, err
+	}
+	Log.Info("Reconciling")
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// initialize status fields
+	r.initStatus(instance)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// Always update the instance status when exiting this function so we can
+	// persist any changes happened during the current reconciliation.
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
+			panic(r)
+		}
+		// update the Ready condition based on the sub conditions
+		if allSubConditionIsTrue(instance.Status) {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			// something is not ready so reset the Ready condition
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			// and recalculate it based on the state of the rest of the conditions
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := h.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	// The restore is only run once
+	if instance.Status.CompletionTime != nil {
+		instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaDatabaseRestoreCondition, novav1.NovaDatabaseRestoreMessage)
+		return ctrl.Result{}, nil
+	}
+
+	backup, cellNames, waitingFor, err := r.getBackup(ctx, instance)
+	if err == nil && waitingFor == "" {
+		var novaInstance *novav1.Nova
+		var cells map[string]*novav1.NovaCell
+		novaInstance, cells, cellNames, waitingFor, err = getNovaDatabaseCells(
+			ctx, r.Client, instance.Namespace, instance.Spec.NovaInstance, cellNames)
+		if err == nil && waitingFor == "" {
+			return r.ensureRestore(ctx, h, instance, backup, novaInstance, cells, cellNames)
+		}
+	}
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	// We will be reconciled when the NovaDatabaseBackup or the Nova CR
+	// changes
+	instance.Status.Conditions.Set(condition.FalseCondition(
+		condition.InputReadyCondition,
+		condition.RequestedReason,
+		condition.SeverityInfo,
+		novav1.NovaDatabaseInputWaitingMessage,
+		waitingFor))
+	return ctrl.Result{}, nil
+}
+
+// getBackup returns the NovaDatabaseBackup to restore from and the cells to
+// restore. The cells default to the cells in the last backup.
+func (r *NovaDatabaseRestoreReconciler) getBackup(
+	ctx context.Context,
+	instance *novav1.NovaDatabaseRestore,
+) (backup *novav1.NovaDatabaseBackup, cellNames []string, waitingFor string, err error) {
+	backup = &novav1.NovaDatabaseBackup{}
+	err = r.Client.Get(ctx, types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      instance.Spec.BackupName,
+	}, backup)
+	if k8s_errors.IsNotFound(err) {
+		return nil, nil, "NovaDatabaseBackup " + instance.Spec.BackupName, nil
+	}
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if backup.Status.LastBackup == nil {
+		return nil, nil, "a backup in NovaDatabaseBackup " + instance.Spec.BackupName, nil
+	}
+
+	if len(instance.Spec.Cells) == 0 {
+		return backup, backup.Status.LastBackup.Cells, "", nil
+	}
+	for _, cellName := range instance.Spec.Cells {
+		if !util.StringInSlice(cellName, backup.Status.LastBackup.Cells) {
+			return nil, nil, "", fmt.Errorf(
+				"the DB of cell %s is not in the last backup of NovaDatabaseBackup %s",
+				cellName, instance.Spec.BackupName)
+		}
+	}
+	return backup, instance.Spec.Cells, "", nil
+}
+
+// ensureRestore runs the restore Job once the services using the restored
+// databases are scaled down and records the restored backup when the Job
+// finished
+func (r *NovaDatabaseRestoreReconciler) ensureRestore(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaDatabaseRestore,
+	backup *novav1.NovaDatabaseBackup,
+	novaInstance *novav1.Nova,
+	cells map[string]*novav1.NovaCell,
+	cellNames []string,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	databases := getNovaDatabases(novaInstance, cells, cellNames, instance.Spec.RestoreAPIDatabase)
+
+	restoreLabels := getDatabaseRestoreLabels(instance)
+	configName, scriptName := getDatabaseRestoreSecretNames(instance)
+	err := ensureDatabaseJobSecrets(
+		ctx, h, novaInstance, databases, configName, scriptName, restoreLabels)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	image := backup.Spec.ContainerImage
+	if image == "" {
+		image = cells[novav1.Cell0Name].Spec.ConductorContainerImageURL
+	}
+	jobDef := nova.DatabaseRestoreJob(
+		instance, novaInstance, backup, image, getNovaDatabaseNames(databases),
+		configName, scriptName, restoreLabels)
+
+	// The services are only checked before the restore is started. A
+	// service scaled up while the Job runs is the responsibility of the
+	// human operator.
+	err = r.Client.Get(ctx, types.NamespacedName{Namespace: jobDef.Namespace, Name: jobDef.Name}, &batchv1.Job{})
+	if k8s_errors.IsNotFound(err) {
+		checkedCellNames := cellNames
+		if instance.Spec.RestoreAPIDatabase {
+			checkedCellNames, err = r.addAPIDatabaseCells(ctx, novaInstance, cells, cellNames)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+		running := getRunningDatabaseServices(novaInstance, cells, checkedCellNames, instance.Spec.RestoreAPIDatabase)
+		if len(running) > 0 {
+			// We will be reconciled when the services are scaled down
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				novav1.NovaDatabaseRestoreCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				novav1.NovaDatabaseRestoreRefusedMessage,
+				strings.Join(running, ", ")))
+			return ctrl.Result{}, nil
+		}
+	} else if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	restoreJob := job.NewJob(
+		jobDef, databaseRestoreHashKey,
+		novaInstance.Spec.PreserveJobs, r.RequeueTimeout,
+		instance.Status.Hash[databaseRestoreHashKey])
+
+	result, err := restoreJob.DoJob(ctx, h)
+	if err != nil {
+		reason := err.Error()
+		if restoreJob.GetTotalFailedAttempts() > 0 {
+			// The Job is not retried so report the reason of the failure
+			// instead of requeueing
+			message, msgErr := getJobTerminationMessage(ctx, h.GetClient(), jobDef.Namespace, jobDef.Name)
+			if msgErr != nil {
+				return ctrl.Result{}, msgErr
+			}
+			if message != "" {
+				reason += ": " + message
+			}
+			err = nil
+		}
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaDatabaseRestoreCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaDatabaseRestoreErrorMessage,
+			reason))
+		return ctrl.Result{}, err
+	}
+	if (result != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaDatabaseRestoreCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaDatabaseRestoreRunningMessage))
+		return result, nil
+	}
+
+	now := metav1.Now()
+	instance.Status.Databases = getNovaDatabaseNames(databases)
+	instance.Status.BackupPath = backup.Status.LastBackup.Path
+	instance.Status.CompletionTime = &now
+	instance.Status.Hash[databaseRestoreHashKey] = restoreJob.GetHash()
+	Log.Info("Database restore completed",
+		"backup", instance.Status.BackupPath, "databases", instance.Status.Databases)
+
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaDatabaseRestoreCondition, novav1.NovaDatabaseRestoreMessage)
+	return ctrl.Result{}, nil
+}
+
+// addAPIDatabaseCells adds cell0 and the cells with API DB access to the
+// given cells as their conductors, metadata and novncproxy services also use
+// the API DB. A cell without a NovaCell has no running services so it is
+// skipped.
+func (r *NovaDatabaseRestoreReconciler) addAPIDatabaseCells(
+	ctx context.Context,
+	novaInstance *novav1.Nova,
+	cells map[string]*novav1.NovaCell,
+	cellNames []string,
+) ([]string, error) {
+	apiCellNames := []string{}
+	for cellName, cellTemplate := range novaInstance.Spec.CellTemplates {
+		if cellName == novav1.Cell0Name || cellTemplate.HasAPIAccess {
+			apiCellNames = append(apiCellNames, cellName)
+		}
+	}
+	sort.Strings(apiCellNames)
+
+	allCellNames := append([]string{}, cellNames...)
+	for _, cellName := range apiCellNames {
+		if util.StringInSlice(cellName, allCellNames) {
+			continue
+		}
+		if _, exists := cells[cellName]; !exists {
+			cell := &novav1.NovaCell{}
+			err := r.Client.Get(ctx, types.NamespacedName{
+				Namespace: novaInstance.Namespace,
+				Name:      getNovaCellCRName(novaInstance.Name, cellName),
+			}, cell)
+			if k8s_errors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			cells[cellName] = cell
+		}
+		allCellNames = append(allCellNames, cellName)
+	}
+	return allCellNames, nil
+}
+
+// getRunningDatabaseServices returns the services that have ready replicas
+// and use one of the databases to be restored
+func getRunningDatabaseServices(
+	novaInstance *novav1.Nova,
+	cells map[string]*novav1.NovaCell,
+	cellNames []string,
+	withAPIDatabase bool,
+) []string {
+	running := []string{}
+	if withAPIDatabase {
+		if novaInstance.Status.APIServiceReadyCount > 0 {
+			running = append(running, "nova-api")
+		}
+		if novaInstance.Status.SchedulerServiceReadyCount > 0 {
+			running = append(running, "nova-scheduler")
+		}
+		if novaInstance.Status.MetadataServiceReadyCount > 0 {
+			running = append(running, "nova-metadata")
+		}
+	}
+	for _, cellName := range cellNames {
+		cell := cells[cellName]
+		if cell.Status.ConductorServiceReadyCount > 0 {
+			running = append(running, cellName+" nova-conductor")
+		}
+		if cell.Status.MetadataServiceReadyCount > 0 {
+			running = append(running, cellName+" nova-metadata")
+		}
+		if cell.Status.NoVNCPRoxyServiceReadyCount > 0 {
+			running = append(running, cellName+" nova-novncproxy")
+		}
+	}
+	return running
+}
+
+func getDatabaseRestoreLabels(instance *novav1.NovaDatabaseRestore) map[string]string {
+	return labels.GetLabels(
+		instance, labels.GetGroupLabel(NovaDatabaseRestoreLabelPrefix),
+		map[string]string{common.AppSelector: NovaDatabaseRestoreLabelPrefix},
+	)
+}
+
+// getDatabaseRestoreSecretNames returns the names of the config and the
+// scripts Secrets of the restore Job
+func getDatabaseRestoreSecretNames(
+	instance *novav1.NovaDatabaseRestore,
+) (configName string, scriptName string) {
+	return nova.DatabaseRestoreJobName(instance) + "-config-data",
+		nova.DatabaseRestoreJobName(instance) + "-scripts"
+}
+
+func (r *NovaDatabaseRestoreReconciler) initStatus(
+	instance *novav1.NovaDatabaseRestore,
+) {
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	// initialize all conditions to Unknown
+	cl := condition.CreateList(
+		condition.UnknownCondition(
+			condition.InputReadyCondition,
+			condition.InitReason,
+			condition.InputReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaDatabaseRestoreCondition,
+			condition.InitReason,
+			novav1.NovaDatabaseRestoreInitMessage,
+		),
+	)
+	instance.Status.Conditions.Init(&cl)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NovaDatabaseRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index novaInstanceField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaDatabaseRestore{}, novaInstanceField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaDatabaseRestore)
+		return []string{cr.Spec.NovaInstance}
+	}); err != nil {
+		return err
+	}
+	// index backupNameField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaDatabaseRestore{}, backupNameField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaDatabaseRestore)
+		return []string{cr.Spec.BackupName}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&novav1.NovaDatabaseRestore{}).
+		Owns(&batchv1.Job{}).
+		Owns(&corev1.Secret{}).
+		// watch the Nova CR to know when the cells are created and when the
+		// top level services are scaled down
+		Watches(
+			&novav1.Nova{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNova),
+		).
+		// watch the NovaCells to know when the cell services are scaled down
+		Watches(
+			&novav1.NovaCell{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNovaCell),
+		).
+		// watch the NovaDatabaseBackups to know when the backup is taken
+		Watches(
+			&novav1.NovaDatabaseBackup{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNovaDatabaseBackup),
+		).
+		Complete(r)
+}
+
+func (r *NovaDatabaseRestoreReconciler) findObjectsForNova(ctx context.Context, src client.Object) []reconcile.Request {
+	return r.findObjectsForField(ctx, novaInstanceField, src.GetName(), src.GetNamespace())
+}
+
+func (r *NovaDatabaseRestoreReconciler) findObjectsForNovaCell(ctx context.Context, src client.Object) []reconcile.Request {
+	cell := src.(*novav1.NovaCell)
+	// the name of the owning Nova CR is not in the spec of the NovaCell but
+	// the NovaCell is named after it
+	novaName := strings.TrimSuffix(cell.Name, "-"+cell.Spec.CellName)
+	return r.findObjectsForField(ctx, novaInstanceField, novaName, src.GetNamespace())
+}
+
+func (r *NovaDatabaseRestoreReconciler) findObjectsForNovaDatabaseBackup(ctx context.Context, src client.Object) []reconcile.Request {
+	return r.findObjectsForField(ctx, backupNameField, src.GetName(), src.GetNamespace())
+}
+
+func (r *NovaDatabaseRestoreReconciler) findObjectsForField(
+	ctx context.Context, field string, value string, namespace string,
+) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := r.GetLogger(ctx)
+
+	crList := &novav1.NovaDatabaseRestoreList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(field, value),
+		Namespace:     namespace,
+	}
+	err := r.Client.List(ctx, crList, listOps)
+	if err != nil {
+		l.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, field, namespace))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
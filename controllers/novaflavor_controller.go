@@ -0,0 +1,523 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	gophercloud "github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/flavors"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+const (
+	novaInstanceField = ".spec.novaInstance"
+
+	// flavorResyncInterval defines how often the flavor is compared with
+	// the compute API to detect changes made outside of the operator
+	flavorResyncInterval = 10 * time.Minute
+)
+
+// NovaFlavorReconciler reconciles a NovaFlavor object
+type NovaFlavorReconciler struct {
+	ReconcilerBase
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *NovaFlavorReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("NovaFlavor")
+}
+
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaflavors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaflavors/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaflavors/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;
+
+// Reconcile keeps the flavor in the compute API in sync with the NovaFlavor
+// CR
+func (r *NovaFlavorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	// Fetch the NovaFlavor instance that needs to be reconciled
+	instance := &novav1.NovaFlavor{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers. Return and don't requeue.
+			Log.Info("NovaFlavor instance not found, probably deleted before reconciled. Nothing to do.")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		Log.Error(err, "Failed to read the NovaFlavor instance.")
+		return ctrl.Result{}, err
+	}
+
+	h, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, "Failed to create lib-common Helper")
+		return ctrl.Result{}, err
+	}
+	Log.Info("Reconciling")
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// initialize status fields
+	r.initConditions(instance)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// Always update the instance status when exiting this function so we can
+	// persist any changes happened during the current reconciliation.
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
+			panic(r)
+		}
+		// update the Ready condition based on the sub conditions
+		if allSubConditionIsTrue(instance.Status) {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			// something is not ready so reset the Ready condition
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			// and recalculate it based on the state of the rest of the conditions
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := h.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, h, instance)
+	}
+
+	// We need a finalizer to be able to delete the flavor from the compute
+	// API when the CR is deleted
+	updated := controllerutil.AddFinalizer(instance, h.GetFinalizer())
+	if updated {
+		Log.Info("Added finalizer to ourselves")
+		// we intentionally return immediately to force the deferred function
+		// to persist the Instance with the finalizer. We need to have our own
+		// finalizer persisted before we create the flavor to avoid orphaning
+		// it.
+		return ctrl.Result{}, nil
+	}
+
+	if instance.Spec.IsPublic && len(instance.Spec.ProjectAccess) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaFlavorSyncedCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaFlavorSyncedErrorMessage,
+			"projectAccess can only be defined for non public flavors"))
+		// this needs a spec change, so no point to requeue
+		return ctrl.Result{}, nil
+	}
+
+	computeClient, result, err := getNovaAPIClient(
+		ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+		&instance.Status.Conditions, r.RequeueTimeout, Log)
+	if (err != nil || result != ctrl.Result{}) {
+		return result, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	err = r.ensureFlavor(instance, computeClient, Log)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaFlavorSyncedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaFlavorSyncedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	if len(instance.Status.DriftedFields) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaFlavorSyncedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaFlavorSyncedDriftMessage,
+			strings.Join(instance.Status.DriftedFields, ", ")))
+	} else {
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaFlavorSyncedCondition, novav1.NovaFlavorSyncedMessage)
+	}
+
+	// The flavor can be changed via the compute API directly so we
+	// periodically check it for drift
+	return ctrl.Result{RequeueAfter: flavorResyncInterval}, nil
+}
+
+func (r *NovaFlavorReconciler) initConditions(
+	instance *novav1.NovaFlavor,
+) {
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	// initialize all conditions to Unknown
+	cl := condition.CreateList(
+		condition.UnknownCondition(
+			novav1.NovaAPIReadyCondition,
+			condition.InitReason,
+			novav1.NovaAPIReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			condition.InputReadyCondition,
+			condition.InitReason,
+			condition.InputReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaFlavorSyncedCondition,
+			condition.InitReason,
+			novav1.NovaFlavorSyncedInitMessage,
+		),
+	)
+	instance.Status.Conditions.Init(&cl)
+}
+
+// getFlavor looks up the flavor first by the ID stored in the Status then by
+// name. It returns nil if the flavor does not exist.
+func (r *NovaFlavorReconciler) getFlavor(
+	instance *novav1.NovaFlavor,
+	computeClient *gophercloud.ServiceClient,
+	l logr.Logger,
+) (*flavors.Flavor, error) {
+	if instance.Status.FlavorID != "" {
+		flavor, err := flavors.Get(computeClient, instance.Status.FlavorID).Extract()
+		if err == nil {
+			return flavor, nil
+		}
+		if !isComputeNotFound(err) {
+			return nil, err
+		}
+		l.Info("Flavor is deleted from the compute API, recreating it", "id", instance.Status.FlavorID)
+		instance.Status.FlavorID = ""
+	}
+
+	allPages, err := flavors.ListDetail(
+		computeClient, flavors.ListOpts{AccessType: flavors.AllAccess}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	allFlavors, err := flavors.ExtractFlavors(allPages)
+	if err != nil {
+		return nil, err
+	}
+	for _, flavor := range allFlavors {
+		if flavor.Name == instance.GetFlavorName() {
+			l.Info("Adopting existing flavor", "name", flavor.Name, "id", flavor.ID)
+			return &flavor, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *NovaFlavorReconciler) ensureFlavor(
+	instance *novav1.NovaFlavor,
+	computeClient *gophercloud.ServiceClient,
+	l logr.Logger,
+) error {
+	flavor, err := r.getFlavor(instance, computeClient, l)
+	if err != nil {
+		return err
+	}
+
+	if flavor == nil {
+		disk := instance.Spec.Disk
+		isPublic := instance.Spec.IsPublic
+		flavor, err = flavors.Create(computeClient, flavors.CreateOpts{
+			Name:     instance.GetFlavorName(),
+			VCPUs:    instance.Spec.VCPUs,
+			RAM:      instance.Spec.RAM,
+			Disk:     &disk,
+			IsPublic: &isPublic,
+		}).Extract()
+		if err != nil {
+			return err
+		}
+		l.Info("Created flavor", "name", flavor.Name, "id", flavor.ID)
+	}
+	instance.Status.FlavorID = flavor.ID
+	instance.Status.DriftedFields = getFlavorDrift(instance, flavor)
+
+	err = r.ensureExtraSpecs(instance, computeClient, l)
+	if err != nil {
+		return err
+	}
+
+	// The project access list can only be managed for private flavors. If
+	// the visibility drifted then it is already reported
+	if !flavor.IsPublic {
+		return r.ensureProjectAccess(instance, computeClient, l)
+	}
+	return nil
+}
+
+// getFlavorDrift returns the name of the fields that differ between the
+// Spec and the flavor but cannot be updated in the compute API
+func getFlavorDrift(instance *novav1.NovaFlavor, flavor *flavors.Flavor) []string {
+	drifted := []string{}
+	if flavor.Name != instance.GetFlavorName() {
+		drifted = append(drifted, "flavorName")
+	}
+	if flavor.VCPUs != instance.Spec.VCPUs {
+		drifted = append(drifted, "vcpus")
+	}
+	if flavor.RAM != instance.Spec.RAM {
+		drifted = append(drifted, "ram")
+	}
+	if flavor.Disk != instance.Spec.Disk {
+		drifted = append(drifted, "disk")
+	}
+	if flavor.IsPublic != instance.Spec.IsPublic {
+		drifted = append(drifted, "isPublic")
+	}
+	return drifted
+}
+
+func (r *NovaFlavorReconciler) ensureExtraSpecs(
+	instance *novav1.NovaFlavor,
+	computeClient *gophercloud.ServiceClient,
+	l logr.Logger,
+) error {
+	current, err := flavors.ListExtraSpecs(computeClient, instance.Status.FlavorID).Extract()
+	if err != nil {
+		return err
+	}
+
+	toSet := flavors.ExtraSpecsOpts{}
+	for key, value := range instance.Spec.ExtraSpecs {
+		if currentValue, ok := current[key]; !ok || currentValue != value {
+			toSet[key] = value
+		}
+	}
+	if len(toSet) > 0 {
+		_, err = flavors.CreateExtraSpecs(computeClient, instance.Status.FlavorID, toSet).Extract()
+		if err != nil {
+			return err
+		}
+		l.Info("Updated flavor extra specs", "id", instance.Status.FlavorID, "extraSpecs", toSet)
+	}
+
+	// iterate in a stable order to have a predictable request sequence
+	keys := make([]string, 0, len(current))
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, ok := instance.Spec.ExtraSpecs[key]; ok {
+			continue
+		}
+		err = flavors.DeleteExtraSpec(computeClient, instance.Status.FlavorID, key).ExtractErr()
+		switch {
+		case err == nil:
+			l.Info("Deleted flavor extra spec", "id", instance.Status.FlavorID, "key", key)
+		case isComputeNotFound(err):
+			l.Info("Flavor extra spec is already deleted", "id", instance.Status.FlavorID, "key", key)
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *NovaFlavorReconciler) ensureProjectAccess(
+	instance *novav1.NovaFlavor,
+	computeClient *gophercloud.ServiceClient,
+	l logr.Logger,
+) error {
+	allPages, err := flavors.ListAccesses(computeClient, instance.Status.FlavorID).AllPages()
+	if err != nil {
+		return err
+	}
+	accesses, err := flavors.ExtractAccesses(allPages)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]bool{}
+	for _, access := range accesses {
+		current[access.TenantID] = true
+	}
+	desired := map[string]bool{}
+	for _, project := range instance.Spec.ProjectAccess {
+		desired[project] = true
+		if current[project] {
+			continue
+		}
+		_, err = flavors.AddAccess(
+			computeClient, instance.Status.FlavorID,
+			flavors.AddAccessOpts{Tenant: project}).Extract()
+		if err != nil {
+			return err
+		}
+		l.Info("Added flavor access", "id", instance.Status.FlavorID, "project", project)
+	}
+	for _, access := range accesses {
+		if desired[access.TenantID] {
+			continue
+		}
+		_, err = flavors.RemoveAccess(
+			computeClient, instance.Status.FlavorID,
+			flavors.RemoveAccessOpts{Tenant: access.TenantID}).Extract()
+		if err != nil {
+			return err
+		}
+		l.Info("Removed flavor access", "id", instance.Status.FlavorID, "project", access.TenantID)
+	}
+	return nil
+}
+
+func (r *NovaFlavorReconciler) reconcileDelete(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaFlavor,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+	Log.Info("Reconciling delete")
+
+	if instance.Status.FlavorID != "" {
+		// If the nova-api is being deleted, e.g. as part of deleting the
+		// whole Nova deployment, then there is no compute API left to delete
+		// the flavor from
+		api := &novav1.NovaAPI{}
+		err := h.GetClient().Get(
+			ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.NovaInstance + "-api"}, api)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if k8s_errors.IsNotFound(err) || !api.DeletionTimestamp.IsZero() {
+			Log.Info("NovaAPI is deleted, skipping the deletion of the flavor", "id", instance.Status.FlavorID)
+		} else {
+			computeClient, result, err := getNovaAPIClient(
+				ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+				&instance.Status.Conditions, r.RequeueTimeout, Log)
+			if (err != nil || result != ctrl.Result{}) {
+				return result, err
+			}
+			err = flavors.Delete(computeClient, instance.Status.FlavorID).ExtractErr()
+			switch {
+			case err == nil:
+				Log.Info("Deleted flavor", "id", instance.Status.FlavorID)
+			case isComputeNotFound(err):
+				Log.Info("Flavor is already deleted", "id", instance.Status.FlavorID)
+			default:
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	// Successfully cleaned up everything. So as the final step let's remove the
+	// finalizer from ourselves to allow the deletion of NovaFlavor CR itself
+	updated := controllerutil.RemoveFinalizer(instance, h.GetFinalizer())
+	if updated {
+		Log.Info("Removed finalizer from ourselves")
+	}
+
+	Log.Info("Reconciled delete successfully")
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NovaFlavorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index novaInstanceField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaFlavor{}, novaInstanceField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaFlavor)
+		return []string{cr.Spec.NovaInstance}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&novav1.NovaFlavor{}).
+		// watch the NovaAPI to know when it becomes usable
+		Watches(
+			&novav1.NovaAPI{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNovaAPI),
+		).
+		Complete(r)
+}
+
+func (r *NovaFlavorReconciler) findObjectsForNovaAPI(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := r.GetLogger(ctx)
+
+	// The NovaAPI is named after the Nova CR it belongs to
+	novaInstance := strings.TrimSuffix(src.GetName(), "-api")
+	crList := &novav1.NovaFlavorList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(novaInstanceField, novaInstance),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.Client.List(ctx, crList, listOps)
+	if err != nil {
+		l.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, novaInstanceField, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
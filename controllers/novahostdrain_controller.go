@@ -0,0 +1,527 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	gophercloud "github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/services"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// hostDrainResyncInterval defines how often a drained host, or a host with
+// failed migrations, is checked for instances moved to or from it outside
+// of the operator
+const hostDrainResyncInterval = time.Minute
+
+// NovaHostDrainReconciler reconciles a NovaHostDrain object
+type NovaHostDrainReconciler struct {
+	ReconcilerBase
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *NovaHostDrainReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("NovaHostDrain")
+}
+
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novahostdrains,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novahostdrains/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novahostdrains/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;
+
+// Reconcile disables the nova-compute service of the host and live migrates
+// every instance off it
+func (r *NovaHostDrainReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	// Fetch the NovaHostDrain instance that needs to be reconciled
+	instance := &novav1.NovaHostDrain{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers. Return and don't requeue.
+			Log.Info("NovaHostDrain instance not found, probably deleted before reconciled. Nothing to do.")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		Log.Error(err, "Failed to read the NovaHostDrain instance.")
+		return ctrl.Result{}, err
+	}
+
+	h, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, "Failed to create lib-common Helper")
+		return ctrl.Result{}, err
+	}
+	Log.Info("Reconciling")
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// initialize status fields
+	r.initConditions(instance)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// Always update the instance status when exiting this function so we can
+	// persist any changes happened during the current reconciliation.
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
+			panic(r)
+		}
+		// update the Ready condition based on the sub conditions
+		if allSubConditionIsTrue(instance.Status) {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			// something is not ready so reset the Ready condition
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			// and recalculate it based on the state of the rest of the conditions
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := h.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, h, instance)
+	}
+
+	// We need a finalizer to be able to enable the service again when the
+	// CR is deleted
+	updated := controllerutil.AddFinalizer(instance, h.GetFinalizer())
+	if updated {
+		Log.Info("Added finalizer to ourselves")
+		// we intentionally return immediately to force the deferred function
+		// to persist the Instance with the finalizer. We need to have our own
+		// finalizer persisted before we disable the service.
+		return ctrl.Result{}, nil
+	}
+
+	computeClient, result, err := getNovaAPIClient(
+		ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+		&instance.Status.Conditions, r.RequeueTimeout, Log)
+	if (err != nil || result != ctrl.Result{}) {
+		return result, err
+	}
+
+	service, err := getComputeService(computeClient, instance.Spec.Host)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+	instance.Status.ServiceID = service.ID
+
+	err = r.ensureServiceDisabled(instance, computeClient, service, Log)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaHostServiceDisabledCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaHostServiceDisabledErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaHostServiceDisabledCondition, novav1.NovaHostServiceDisabledMessage)
+
+	err = r.ensureInstancesMigrated(instance, computeClient, Log)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaHostDrainedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaHostDrainedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	inProgress := 0
+	failed := []string{}
+	for _, server := range instance.Status.Instances {
+		switch server.State {
+		case novav1.NovaHostDrainInstancePending, novav1.NovaHostDrainInstanceMigrating:
+			inProgress++
+		case novav1.NovaHostDrainInstanceFailed:
+			failed = append(failed, server.ID)
+		}
+	}
+
+	if inProgress > 0 {
+		// the compute API does not notify us about the progress of the
+		// migrations so we need to poll
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaHostDrainedCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaHostDrainedInProgressMessage,
+			instance.Status.MigratedCount,
+			len(instance.Status.Instances)))
+		return ctrl.Result{RequeueAfter: r.RequeueTimeout}, nil
+	}
+
+	if len(failed) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaHostDrainedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaHostDrainedFailedMessage,
+			len(failed),
+			strings.Join(failed, ", ")))
+		return ctrl.Result{RequeueAfter: hostDrainResyncInterval}, nil
+	}
+
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaHostDrainedCondition, novav1.NovaHostDrainedMessage)
+
+	Log.Info("Successfully reconciled")
+	return ctrl.Result{RequeueAfter: hostDrainResyncInterval}, nil
+}
+
+func (r *NovaHostDrainReconciler) initConditions(
+	instance *novav1.NovaHostDrain,
+) {
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	// initialize all conditions to Unknown
+	cl := condition.CreateList(
+		condition.UnknownCondition(
+			novav1.NovaAPIReadyCondition,
+			condition.InitReason,
+			novav1.NovaAPIReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			condition.InputReadyCondition,
+			condition.InitReason,
+			condition.InputReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaHostServiceDisabledCondition,
+			condition.InitReason,
+			novav1.NovaHostServiceDisabledInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaHostDrainedCondition,
+			condition.InitReason,
+			novav1.NovaHostDrainedInitMessage,
+		),
+	)
+	instance.Status.Conditions.Init(&cl)
+}
+
+// getComputeService returns the nova-compute service of the host
+func getComputeService(
+	computeClient *gophercloud.ServiceClient,
+	host string,
+) (*services.Service, error) {
+	allPages, err := services.List(
+		computeClient, services.ListOpts{Binary: "nova-compute", Host: host}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	allServices, err := services.ExtractServices(allPages)
+	if err != nil {
+		return nil, err
+	}
+	for _, service := range allServices {
+		if service.Host == host {
+			return &service, nil
+		}
+	}
+	return nil, fmt.Errorf("nova-compute service of host %s is not found", host)
+}
+
+func (r *NovaHostDrainReconciler) ensureServiceDisabled(
+	instance *novav1.NovaHostDrain,
+	computeClient *gophercloud.ServiceClient,
+	service *services.Service,
+	l logr.Logger,
+) error {
+	if service.Status == string(services.ServiceDisabled) {
+		return nil
+	}
+	_, err := services.Update(computeClient, service.ID, services.UpdateOpts{
+		Status:         services.ServiceDisabled,
+		DisabledReason: fmt.Sprintf("Drained by NovaHostDrain %s/%s", instance.Namespace, instance.Name),
+	}).Extract()
+	if err != nil {
+		return err
+	}
+	l.Info("Disabled compute service", "host", instance.Spec.Host, "id", service.ID)
+	return nil
+}
+
+// ensureInstancesMigrated updates the state of each instance on the host and
+// starts new live migrations up to the configured concurrency
+func (r *NovaHostDrainReconciler) ensureInstancesMigrated(
+	instance *novav1.NovaHostDrain,
+	computeClient *gophercloud.ServiceClient,
+	l logr.Logger,
+) error {
+	onHost, err := listServersOnHost(computeClient, servers.ListOpts{Host: instance.Spec.Host})
+	if err != nil {
+		return err
+	}
+	serversOnHost := map[string]computeServer{}
+	for _, server := range onHost {
+		serversOnHost[server.ID] = server
+	}
+
+	known := map[string]bool{}
+	statuses := []novav1.NovaHostDrainInstanceStatus{}
+	for _, status := range instance.Status.Instances {
+		known[status.ID] = true
+		server, stillOnHost := serversOnHost[status.ID]
+		switch {
+		case !stillOnHost:
+			// the instance is either migrated or deleted, both ways it is
+			// not on the host any more
+			status.State = novav1.NovaHostDrainInstanceMigrated
+			status.Message = ""
+		case status.State == novav1.NovaHostDrainInstanceMigrating && server.TaskState == "":
+			// the migration is finished but the instance is still on the
+			// host so the migration failed and was rolled back
+			status.State = novav1.NovaHostDrainInstanceFailed
+			status.Message = "live migration failed, the instance is still on the host"
+			if server.Fault.Message != "" {
+				status.Message = server.Fault.Message
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for _, server := range onHost {
+		if !known[server.ID] {
+			statuses = append(statuses, novav1.NovaHostDrainInstanceStatus{
+				ID:    server.ID,
+				Name:  server.Name,
+				State: novav1.NovaHostDrainInstancePending,
+			})
+		}
+	}
+
+	migrating := 0
+	for _, status := range statuses {
+		if status.State == novav1.NovaHostDrainInstanceMigrating {
+			migrating++
+		}
+	}
+	for i := range statuses {
+		status := &statuses[i]
+		if status.State != novav1.NovaHostDrainInstancePending {
+			continue
+		}
+		server := serversOnHost[status.ID]
+		if server.TaskState == "migrating" {
+			// somebody else already started the migration
+			status.State = novav1.NovaHostDrainInstanceMigrating
+			migrating++
+			continue
+		}
+		// only running and paused instances can be live migrated
+		if server.Status != "ACTIVE" && server.Status != "PAUSED" {
+			status.State = novav1.NovaHostDrainInstanceFailed
+			status.Message = fmt.Sprintf(
+				"instance in %s status cannot be live migrated", server.Status)
+			continue
+		}
+		if migrating >= instance.Spec.MaxConcurrentMigrations {
+			continue
+		}
+		err = liveMigrateServer(computeClient, status.ID)
+		if err != nil {
+			status.State = novav1.NovaHostDrainInstanceFailed
+			status.Message = err.Error()
+			continue
+		}
+		l.Info("Started live migration", "instance", status.ID, "host", instance.Spec.Host)
+		status.State = novav1.NovaHostDrainInstanceMigrating
+		migrating++
+	}
+
+	instance.Status.Instances = statuses
+	instance.Status.MigratedCount = 0
+	instance.Status.FailedCount = 0
+	for _, status := range statuses {
+		switch status.State {
+		case novav1.NovaHostDrainInstanceMigrated:
+			instance.Status.MigratedCount++
+		case novav1.NovaHostDrainInstanceFailed:
+			instance.Status.FailedCount++
+		}
+	}
+	return nil
+}
+
+// liveMigrateServer starts the live migration of the server to a host
+// selected by the scheduler. The migrate extension of gophercloud only
+// supports the boolean block_migration of the old microversions so the
+// action is called directly.
+func liveMigrateServer(computeClient *gophercloud.ServiceClient, serverID string) error {
+	body := map[string]interface{}{
+		"os-migrateLive": map[string]interface{}{
+			"host":            nil,
+			"block_migration": "auto",
+		},
+	}
+	_, err := computeClient.Post(
+		computeClient.ServiceURL("servers", serverID, "action"), body, nil,
+		&gophercloud.RequestOpts{OkCodes: []int{202}})
+	return err
+}
+
+func (r *NovaHostDrainReconciler) reconcileDelete(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaHostDrain,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+	Log.Info("Reconciling delete")
+
+	if instance.Spec.EnableServiceOnDelete && instance.Status.ServiceID != "" {
+		// If the nova-api is being deleted, e.g. as part of deleting the
+		// whole Nova deployment, then there is no compute API left to enable
+		// the service in
+		api := &novav1.NovaAPI{}
+		err := h.GetClient().Get(
+			ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.NovaInstance + "-api"}, api)
+		if err != nil && !k8s_errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if k8s_errors.IsNotFound(err) || !api.DeletionTimestamp.IsZero() {
+			Log.Info("NovaAPI is deleted, skipping enabling the compute service", "host", instance.Spec.Host)
+		} else {
+			computeClient, result, err := getNovaAPIClient(
+				ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+				&instance.Status.Conditions, r.RequeueTimeout, Log)
+			if (err != nil || result != ctrl.Result{}) {
+				return result, err
+			}
+			_, err = services.Update(computeClient, instance.Status.ServiceID, services.UpdateOpts{
+				Status: services.ServiceEnabled,
+			}).Extract()
+			if err != nil && !isComputeNotFound(err) {
+				return ctrl.Result{}, err
+			}
+			Log.Info("Enabled compute service", "host", instance.Spec.Host, "id", instance.Status.ServiceID)
+		}
+	}
+
+	// Successfully cleaned up everything. So as the final step let's remove the
+	// finalizer from ourselves to allow the deletion of NovaHostDrain CR itself
+	updated := controllerutil.RemoveFinalizer(instance, h.GetFinalizer())
+	if updated {
+		Log.Info("Removed finalizer from ourselves")
+	}
+
+	Log.Info("Reconciled delete successfully")
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NovaHostDrainReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index novaInstanceField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaHostDrain{}, novaInstanceField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaHostDrain)
+		return []string{cr.Spec.NovaInstance}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&novav1.NovaHostDrain{}).
+		// watch the NovaAPI to know when it becomes usable
+		Watches(
+			&novav1.NovaAPI{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNovaAPI),
+		).
+		Complete(r)
+}
+
+func (r *NovaHostDrainReconciler) findObjectsForNovaAPI(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := r.GetLogger(ctx)
+
+	// The NovaAPI is named after the Nova CR it belongs to
+	novaInstance := strings.TrimSuffix(src.GetName(), "-api")
+	crList := &novav1.NovaHostDrainList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(novaInstanceField, novaInstance),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.Client.List(ctx, crList, listOps)
+	if err != nil {
+		l.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, novaInstanceField, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
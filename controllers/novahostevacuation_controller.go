@@ -0,0 +1,517 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	gophercloud "github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/services"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// hostRecoveryPollInterval defines how often the compute service of an
+// evacuated host is checked for reporting again
+const hostRecoveryPollInterval = time.Minute
+
+// NovaHostEvacuationReconciler reconciles a NovaHostEvacuation object
+type NovaHostEvacuationReconciler struct {
+	ReconcilerBase
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *NovaHostEvacuationReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("NovaHostEvacuation")
+}
+
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novahostevacuations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novahostevacuations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novahostevacuations/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;
+
+// Reconcile forces the nova-compute service of the failed host down,
+// evacuates the selected instances from it and unsets the forced down flag
+// when the host comes back. Deleting the CR leaves the service as it is.
+func (r *NovaHostEvacuationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	// Fetch the NovaHostEvacuation instance that needs to be reconciled
+	instance := &novav1.NovaHostEvacuation{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers. Return and don't requeue.
+			Log.Info("NovaHostEvacuation instance not found, probably deleted before reconciled. Nothing to do.")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		Log.Error(err, "Failed to read the NovaHostEvacuation instance.")
+		return ctrl.Result{}, err
+	}
+
+	h, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, "Failed to create lib-common Helper")
+		return ctrl.Result{}, err
+	}
+	Log.Info("Reconciling")
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// initialize status fields
+	r.initConditions(instance)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// Always update the instance status when exiting this function so we can
+	// persist any changes happened during the current reconciliation.
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
+			panic(r)
+		}
+		// update the Ready condition based on the sub conditions
+		if allSubConditionIsTrue(instance.Status) {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			// something is not ready so reset the Ready condition
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			// and recalculate it based on the state of the rest of the conditions
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := h.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	computeClient, result, err := getNovaAPIClient(
+		ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+		&instance.Status.Conditions, r.RequeueTimeout, Log)
+	if (err != nil || result != ctrl.Result{}) {
+		return result, err
+	}
+
+	service, err := getComputeService(computeClient, instance.Spec.Host)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+	instance.Status.ServiceID = service.ID
+
+	if instance.Status.Recovered {
+		// The host is back and the evacuation is finished, nothing to do
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaHostServiceForcedDownCondition, novav1.NovaHostServiceRecoveredMessage)
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaHostEvacuatedCondition, novav1.NovaHostEvacuatedMessage)
+		return ctrl.Result{}, nil
+	}
+
+	// Forcing down a running service would bypass the check of the compute
+	// API that the host is really gone and the instances would run twice,
+	// so only a host already detected as down is evacuated. After we forced
+	// it down the service is reported as down anyhow.
+	if instance.Status.ServiceUpdatedAt == nil && service.State != "down" {
+		Log.Info("Compute service is up, refusing to evacuate", "host", instance.Spec.Host)
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaHostServiceForcedDownCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaHostServiceForcedDownServiceUpMessage))
+		return ctrl.Result{RequeueAfter: r.RequeueTimeout}, nil
+	}
+
+	err = r.ensureServiceForcedDown(instance, computeClient, service, Log)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaHostServiceForcedDownCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaHostServiceForcedDownErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaHostServiceForcedDownCondition, novav1.NovaHostServiceForcedDownMessage)
+
+	err = r.ensureInstancesEvacuated(instance, computeClient, Log)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaHostEvacuatedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaHostEvacuatedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	inProgress := 0
+	failed := []string{}
+	for _, server := range instance.Status.Instances {
+		switch server.State {
+		case novav1.NovaHostEvacuationInstancePending, novav1.NovaHostEvacuationInstanceEvacuating:
+			inProgress++
+		case novav1.NovaHostEvacuationInstanceFailed:
+			failed = append(failed, server.ID)
+		}
+	}
+
+	if inProgress > 0 {
+		// the compute API does not notify us about the progress of the
+		// evacuations so we need to poll
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaHostEvacuatedCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaHostEvacuatedInProgressMessage,
+			instance.Status.EvacuatedCount,
+			len(instance.Status.Instances)))
+		return ctrl.Result{RequeueAfter: r.RequeueTimeout}, nil
+	}
+
+	if len(failed) > 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaHostEvacuatedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaHostEvacuatedFailedMessage,
+			len(failed),
+			strings.Join(failed, ", ")))
+	} else {
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaHostEvacuatedCondition, novav1.NovaHostEvacuatedMessage)
+	}
+
+	// The compute service keeps updating its service record while it is
+	// running, even if it is forced down. So a newer update than the one
+	// seen after forcing it down means the host is back. The stored time
+	// only has second precision.
+	if instance.Status.ServiceUpdatedAt != nil &&
+		service.UpdatedAt.Truncate(time.Second).After(instance.Status.ServiceUpdatedAt.Time) {
+		err = unsetServiceForcedDown(computeClient, service.ID)
+		if err != nil {
+			instance.Status.Conditions.Set(condition.FalseCondition(
+				novav1.NovaHostServiceForcedDownCondition,
+				condition.ErrorReason,
+				condition.SeverityWarning,
+				novav1.NovaHostServiceForcedDownErrorMessage,
+				err.Error()))
+			return ctrl.Result{}, err
+		}
+		Log.Info("Compute service is reporting again, unset forced down", "host", instance.Spec.Host)
+		instance.Status.Recovered = true
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaHostServiceForcedDownCondition, novav1.NovaHostServiceRecoveredMessage)
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{RequeueAfter: hostRecoveryPollInterval}, nil
+}
+
+func (r *NovaHostEvacuationReconciler) initConditions(
+	instance *novav1.NovaHostEvacuation,
+) {
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	// initialize all conditions to Unknown
+	cl := condition.CreateList(
+		condition.UnknownCondition(
+			novav1.NovaAPIReadyCondition,
+			condition.InitReason,
+			novav1.NovaAPIReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			condition.InputReadyCondition,
+			condition.InitReason,
+			condition.InputReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaHostServiceForcedDownCondition,
+			condition.InitReason,
+			novav1.NovaHostServiceForcedDownInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaHostEvacuatedCondition,
+			condition.InitReason,
+			novav1.NovaHostEvacuatedInitMessage,
+		),
+	)
+	instance.Status.Conditions.Init(&cl)
+}
+
+func (r *NovaHostEvacuationReconciler) ensureServiceForcedDown(
+	instance *novav1.NovaHostEvacuation,
+	computeClient *gophercloud.ServiceClient,
+	service *services.Service,
+	l logr.Logger,
+) error {
+	if service.ForcedDown && instance.Status.ServiceUpdatedAt != nil {
+		return nil
+	}
+	if !service.ForcedDown {
+		updated, err := services.Update(computeClient, service.ID, services.UpdateOpts{
+			ForcedDown: true,
+		}).Extract()
+		if err != nil {
+			return err
+		}
+		l.Info("Forced down compute service", "host", instance.Spec.Host, "id", service.ID)
+		service = updated
+	}
+	// Remember the update time of the service record after forcing it down
+	// to be able to detect when the service is reporting again
+	instance.Status.ServiceUpdatedAt = &metav1.Time{Time: service.UpdatedAt}
+	return nil
+}
+
+// unsetServiceForcedDown clears the forced down flag of the service.
+// services.UpdateOpts omits a false ForcedDown so the request is sent
+// directly.
+func unsetServiceForcedDown(computeClient *gophercloud.ServiceClient, serviceID string) error {
+	_, err := computeClient.Put(
+		computeClient.ServiceURL("os-services", serviceID),
+		map[string]interface{}{"forced_down": false},
+		nil,
+		&gophercloud.RequestOpts{OkCodes: []int{200}})
+	return err
+}
+
+// isSelectedForEvacuation returns true if the server has every requested
+// metadata key value pair. The tags are filtered by the compute API.
+func isSelectedForEvacuation(server computeServer, metadata map[string]string) bool {
+	for key, value := range metadata {
+		if server.Metadata[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ensureInstancesEvacuated updates the state of each selected instance on
+// the host and starts new evacuations up to the configured concurrency
+func (r *NovaHostEvacuationReconciler) ensureInstancesEvacuated(
+	instance *novav1.NovaHostEvacuation,
+	computeClient *gophercloud.ServiceClient,
+	l logr.Logger,
+) error {
+	onHost, err := listServersOnHost(computeClient, servers.ListOpts{
+		Host: instance.Spec.Host,
+		Tags: strings.Join(instance.Spec.ServerTags, ","),
+	})
+	if err != nil {
+		return err
+	}
+	serversOnHost := map[string]computeServer{}
+	for _, server := range onHost {
+		if isSelectedForEvacuation(server, instance.Spec.ServerMetadata) {
+			serversOnHost[server.ID] = server
+		}
+	}
+
+	known := map[string]bool{}
+	statuses := []novav1.NovaHostEvacuationInstanceStatus{}
+	for _, status := range instance.Status.Instances {
+		known[status.ID] = true
+		server, stillOnHost := serversOnHost[status.ID]
+		switch {
+		case !stillOnHost:
+			// the instance is either rebuilt on another host or deleted,
+			// both ways it is not on the failed host any more
+			status.State = novav1.NovaHostEvacuationInstanceEvacuated
+			status.Message = ""
+		case status.State == novav1.NovaHostEvacuationInstanceEvacuating && server.TaskState == "":
+			// the evacuation is finished but the instance is still on the
+			// host so the evacuation failed
+			status.State = novav1.NovaHostEvacuationInstanceFailed
+			status.Message = "evacuation failed, the instance is still on the host"
+			if server.Fault.Message != "" {
+				status.Message = server.Fault.Message
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	for _, server := range onHost {
+		if _, selected := serversOnHost[server.ID]; selected && !known[server.ID] {
+			statuses = append(statuses, novav1.NovaHostEvacuationInstanceStatus{
+				ID:    server.ID,
+				Name:  server.Name,
+				State: novav1.NovaHostEvacuationInstancePending,
+			})
+		}
+	}
+
+	evacuating := 0
+	for _, status := range statuses {
+		if status.State == novav1.NovaHostEvacuationInstanceEvacuating {
+			evacuating++
+		}
+	}
+	for i := range statuses {
+		status := &statuses[i]
+		if status.State != novav1.NovaHostEvacuationInstancePending {
+			continue
+		}
+		server := serversOnHost[status.ID]
+		if server.TaskState != "" {
+			// somebody else already started an action on the instance
+			status.State = novav1.NovaHostEvacuationInstanceEvacuating
+			evacuating++
+			continue
+		}
+		// the compute API only allows evacuating active, stopped and
+		// errored instances
+		if server.Status != "ACTIVE" && server.Status != "SHUTOFF" && server.Status != "ERROR" {
+			status.State = novav1.NovaHostEvacuationInstanceFailed
+			status.Message = fmt.Sprintf(
+				"instance in %s status cannot be evacuated", server.Status)
+			continue
+		}
+		if evacuating >= instance.Spec.MaxConcurrentEvacuations {
+			continue
+		}
+		err = evacuateServer(computeClient, status.ID)
+		if err != nil {
+			status.State = novav1.NovaHostEvacuationInstanceFailed
+			status.Message = err.Error()
+			continue
+		}
+		l.Info("Started evacuation", "instance", status.ID, "host", instance.Spec.Host)
+		status.State = novav1.NovaHostEvacuationInstanceEvacuating
+		evacuating++
+	}
+
+	instance.Status.Instances = statuses
+	instance.Status.EvacuatedCount = 0
+	instance.Status.FailedCount = 0
+	for _, status := range statuses {
+		switch status.State {
+		case novav1.NovaHostEvacuationInstanceEvacuated:
+			instance.Status.EvacuatedCount++
+		case novav1.NovaHostEvacuationInstanceFailed:
+			instance.Status.FailedCount++
+		}
+	}
+	return nil
+}
+
+// evacuateServer starts the evacuation of the server to a host selected by
+// the scheduler. The evacuate extension of gophercloud always sends the
+// onSharedStorage parameter that is rejected since microversion 2.14 so the
+// action is called directly.
+func evacuateServer(computeClient *gophercloud.ServiceClient, serverID string) error {
+	body := map[string]interface{}{
+		"evacuate": map[string]interface{}{},
+	}
+	_, err := computeClient.Post(
+		computeClient.ServiceURL("servers", serverID, "action"), body, nil,
+		&gophercloud.RequestOpts{OkCodes: []int{200}})
+	return err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NovaHostEvacuationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index novaInstanceField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaHostEvacuation{}, novaInstanceField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaHostEvacuation)
+		return []string{cr.Spec.NovaInstance}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&novav1.NovaHostEvacuation{}).
+		// watch the NovaAPI to know when it becomes usable
+		Watches(
+			&novav1.NovaAPI{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNovaAPI),
+		).
+		Complete(r)
+}
+
+func (r *NovaHostEvacuationReconciler) findObjectsForNovaAPI(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := r.GetLogger(ctx)
+
+	// The NovaAPI is named after the Nova CR it belongs to
+	novaInstance := strings.TrimSuffix(src.GetName(), "-api")
+	crList := &novav1.NovaHostEvacuationList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(novaInstanceField, novaInstance),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.Client.List(ctx, crList, listOps)
+	if err != nil {
+		l.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, novaInstanceField, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
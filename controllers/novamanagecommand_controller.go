@@ -0,0 +1,361 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	"github.com/openstack-k8s-operators/lib-common/modules/common"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	job "github.com/openstack-k8s-operators/lib-common/modules/common/job"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
+)
+
+// manageCommandHashKey is the key of the hash of the nova-manage Job in the
+// status of the NovaManageCommand
+const manageCommandHashKey = "nova-manage"
+
+// NovaManageCommandReconciler reconciles a NovaManageCommand object
+type NovaManageCommandReconciler struct {
+	ReconcilerBase
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *NovaManageCommandReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("NovaManageCommand")
+}
+
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novamanagecommands,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novamanagecommands/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novamanagecommands/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=nova,verbs=get;list;watch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novacells,verbs=get;list;watch
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch
+
+// Reconcile runs the nova-manage command in a Job with the nova-manage config
+// of the target cell and reports its exit code and output
+func (r *NovaManageCommandReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	// Fetch the NovaManageCommand instance that needs to be reconciled
+	instance := &novav1.NovaManageCommand{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers. Return and don't requeue.
+			Log.Info("NovaManageCommand instance not found, probably deleted before reconciled. Nothing to do.")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		Log.Error(err, "Failed to read the NovaManageCommand instance.")
+		return ctrl.Result{}, err
+	}
+
+	h, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, "Failed to create lib-common Helper")
+		return ctrl.Result{}, err
+	}
+	Log.Info("Reconciling")
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// initialize status fields
+	r.initStatus(instance)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// Always update the instance status when exiting this function so we can
+	// persist any changes happened during the current reconciliation.
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
+			panic(r)
+		}
+		// update the Ready condition based on the sub conditions
+		if allSubConditionIsTrue(instance.Status) {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			// something is not ready so reset the Ready condition
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			// and recalculate it based on the state of the rest of the conditions
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := h.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	novaInstance, cell, err := r.getMappedCell(ctx, instance)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			condition.InputReadyErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if cell == nil {
+		// We will be reconciled when the Nova CR maps the cell
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			condition.InputReadyCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaManageCommandInputWaitingMessage,
+			instance.Spec.CellName, instance.Spec.NovaInstance))
+		return ctrl.Result{}, nil
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	return r.ensureCommandRun(ctx, h, instance, novaInstance, cell)
+}
+
+// getMappedCell returns the Nova CR and the NovaCell targeted by the
+// command. The cell is nil if it is not mapped yet as its nova-manage config
+// is only generated by then.
+func (r *NovaManageCommandReconciler) getMappedCell(
+	ctx context.Context,
+	instance *novav1.NovaManageCommand,
+) (*novav1.Nova, *novav1.NovaCell, error) {
+	novaInstance := &novav1.Nova{}
+	err := r.Client.Get(ctx, types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      instance.Spec.NovaInstance,
+	}, novaInstance)
+	if k8s_errors.IsNotFound(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cellCRName := getNovaCellCRName(novaInstance.Name, instance.Spec.CellName)
+	if _, mapped := novaInstance.Status.RegisteredCells[cellCRName]; !mapped {
+		return novaInstance, nil, nil
+	}
+
+	cell := &novav1.NovaCell{}
+	err = r.Client.Get(ctx, types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      cellCRName,
+	}, cell)
+	if k8s_errors.IsNotFound(err) {
+		return novaInstance, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return novaInstance, cell, nil
+}
+
+// ensureCommandRun runs the command in a Job and records its exit code and
+// output when the Job finished. The command is only run once.
+func (r *NovaManageCommandReconciler) ensureCommandRun(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaManageCommand,
+	novaInstance *novav1.Nova,
+	cell *novav1.NovaCell,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	if instance.Status.ExitCode != nil {
+		instance.Status.Conditions.MarkTrue(
+			novav1.NovaManageCommandCompletedCondition,
+			novav1.NovaManageCommandCompletedMessage,
+			*instance.Status.ExitCode)
+		return ctrl.Result{}, nil
+	}
+
+	// The Job uses the same config and scripts as the nova-manage jobs the
+	// Nova controller runs against the cell
+	configName, scriptName := getNovaManageJobSecretNames(cell)
+	labels := map[string]string{
+		common.AppSelector: NovaLabelPrefix,
+	}
+	jobDef, err := nova.ManageCommandJob(instance, novaInstance, cell, configName, scriptName, labels)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaManageCommandCompletedCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaManageCommandCompletedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+
+	commandJob := job.NewJob(
+		jobDef, manageCommandHashKey,
+		novaInstance.Spec.PreserveJobs, r.RequeueTimeout,
+		instance.Status.Hash[manageCommandHashKey])
+
+	result, err := commandJob.DoJob(ctx, h)
+	// A command exiting with non zero fails the Job but it is reported the
+	// same way as a successful command
+	if err != nil && commandJob.GetTotalFailedAttempts() == 0 {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaManageCommandCompletedCondition,
+			condition.ErrorReason,
+			condition.SeverityError,
+			novav1.NovaManageCommandCompletedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	if (err == nil && result != ctrl.Result{}) {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaManageCommandCompletedCondition,
+			condition.RequestedReason,
+			condition.SeverityInfo,
+			novav1.NovaManageCommandCompletedRunningMessage))
+		return result, nil
+	}
+
+	terminated, err := getJobTerminatedState(ctx, h.GetClient(), jobDef.Namespace, jobDef.Name)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if terminated == nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaManageCommandCompletedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaManageCommandCompletedErrorMessage,
+			"the Job "+jobDef.Name+" finished without reporting the exit code of the command"))
+		return ctrl.Result{}, nil
+	}
+	instance.Status.ExitCode = &terminated.ExitCode
+	instance.Status.Output = terminated.Message
+	instance.Status.Hash[manageCommandHashKey] = commandJob.GetHash()
+	Log.Info("nova-manage command finished",
+		"command", instance.Spec.Command, "exitCode", terminated.ExitCode)
+
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaManageCommandCompletedCondition,
+		novav1.NovaManageCommandCompletedMessage,
+		terminated.ExitCode)
+
+	return ctrl.Result{}, nil
+}
+
+func (r *NovaManageCommandReconciler) initStatus(
+	instance *novav1.NovaManageCommand,
+) {
+	if instance.Status.Hash == nil {
+		instance.Status.Hash = map[string]string{}
+	}
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	// initialize all conditions to Unknown
+	cl := condition.CreateList(
+		condition.UnknownCondition(
+			condition.InputReadyCondition,
+			condition.InitReason,
+			condition.InputReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaManageCommandCompletedCondition,
+			condition.InitReason,
+			novav1.NovaManageCommandCompletedInitMessage,
+		),
+	)
+	instance.Status.Conditions.Init(&cl)
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NovaManageCommandReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index novaInstanceField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaManageCommand{}, novaInstanceField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaManageCommand)
+		return []string{cr.Spec.NovaInstance}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&novav1.NovaManageCommand{}).
+		Owns(&batchv1.Job{}).
+		// watch the Nova CR to know when the target cell is mapped
+		Watches(
+			&novav1.Nova{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNova),
+		).
+		Complete(r)
+}
+
+func (r *NovaManageCommandReconciler) findObjectsForNova(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := r.GetLogger(ctx)
+
+	crList := &novav1.NovaManageCommandList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(novaInstanceField, src.GetName()),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.Client.List(ctx, crList, listOps)
+	if err != nil {
+		l.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, novaInstanceField, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
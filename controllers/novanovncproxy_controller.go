@@ -67,6 +67,7 @@ func (r *NovaNoVNCProxyReconciler) GetLogger(ctx context.Context) logr.Logger {
 //+kubebuilder:rbac:groups=nova.openstack.org,resources=novanovncproxies/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=nova.openstack.org,resources=novanovncproxies/finalizers,verbs=update;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch;
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete;
@@ -317,7 +318,9 @@ func (r *NovaNoVNCProxyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
-	instance.Status.Hash[common.InputHashName] = inputHash
+	revision := getRolloutRevision(
+		instance.Status.Rollout, instance.Generation, instance.Spec.ContainerImage, inputHash)
+	instance.Status.Hash[common.InputHashName] = revision.ConfigHash
 
 	instance.Status.Conditions.MarkTrue(condition.ServiceConfigReadyCondition, condition.ServiceConfigReadyMessage)
 
@@ -326,7 +329,14 @@ func (r *NovaNoVNCProxyReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return result, err
 	}
 
-	result, err = r.ensureDeployment(ctx, h, instance, inputHash, serviceAnnotations)
+	result, err = r.ensureDeployment(ctx, h, instance, revision, serviceAnnotations)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = r.ensureRollout(
+		ctx, h, instance, &instance.Status.Rollout, &instance.Status.Conditions,
+		instance.Spec.RolloutDeadline, revision, result)
 	if (err != nil || result != ctrl.Result{}) {
 		return result, err
 	}
@@ -361,7 +371,21 @@ func (r *NovaNoVNCProxyReconciler) ensureConfigs(
 	secret corev1.Secret,
 	memcachedInstance *memcachedv1.Memcached,
 ) error {
-	err := r.generateConfigs(ctx, h, instance, hashes, secret, memcachedInstance)
+	var err error
+	requestedRevision := instance.Annotations[novav1.ConfigRevisionAnnotation]
+	switch {
+	case instance.Status.Rollout.IsRolledBack(instance.Generation):
+		// keep the last known-good config while the service is rolled back
+		err = r.ensureKnownGoodConfigRestored(ctx, h, instance)
+	case requestedRevision != "":
+		err = r.ensureConfigRevisionRestored(ctx, h, instance, requestedRevision, hashes)
+	default:
+		err = r.generateConfigs(ctx, h, instance, hashes, secret, memcachedInstance)
+	}
+	if err == nil {
+		instance.Status.ConfigRevision, err = r.ensureConfigRevision(
+			ctx, h, instance, instance.Spec.ConfigRevisionHistoryLimit)
+	}
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.ServiceConfigReadyCondition,
@@ -400,6 +424,11 @@ func (r *NovaNoVNCProxyReconciler) initConditions(
 			condition.InitReason,
 			condition.DeploymentReadyInitMessage,
 		),
+		condition.UnknownCondition(
+			novav1.NovaRolloutReadyCondition,
+			condition.InitReason,
+			novav1.NovaRolloutReadyInitMessage,
+		),
 		condition.UnknownCondition(
 			condition.CreateServiceReadyCondition,
 			condition.InitReason,
@@ -458,13 +487,24 @@ func (r *NovaNoVNCProxyReconciler) generateConfigs(
 		"cell_db_address":          instance.Spec.CellDatabaseHostname,
 		"cell_db_port":             3306,
 		"transport_url":            string(secret.Data[TransportURLSelector]),
-		"openstack_region_name":    "regionOne", // fixme
-		"default_project_domain":   "Default",   // fixme
-		"default_user_domain":      "Default",   // fixme
+		"openstack_region_name":    instance.Spec.Region,
+		"default_project_domain":   instance.Spec.ServiceProjectDomain,
+		"default_user_domain":      instance.Spec.ServiceUserDomain,
+		"service_project_name":     instance.Spec.ServiceProjectName,
 		"MemcachedServers":         memcachedInstance.GetMemcachedServerListString(),
 		"MemcachedServersWithInet": memcachedInstance.GetMemcachedServerListWithInetString(),
 		"MemcachedTLS":             memcachedInstance.GetMemcachedTLSSupport(),
 	}
+	if notificationURL, ok := secret.Data[NotificationTransportURLSelector]; ok {
+		templateParameters["nova_enabled_notification"] = true
+		templateParameters["nova_cell_notify_transport_url"] = string(notificationURL)
+	}
+	if instance.Spec.Telemetry.Enabled {
+		templateParameters["enable_ceilometer"] = true
+		templateParameters["instance_usage_audit_period"] = instance.Spec.Telemetry.InstanceUsageAuditPeriod
+		templateParameters["notify_on_state_change"] = instance.Spec.Telemetry.NotifyOnStateChange
+	}
+	templateParameters["compute_rpc_pin"] = instance.Spec.ComputeRPCPin
 	if instance.Spec.TLS.Service.Enabled() {
 		templateParameters["SSLCertificateFile"] = fmt.Sprintf("/etc/pki/tls/certs/%s.crt", novncproxy.ServiceName)
 		templateParameters["SSLCertificateKeyFile"] = fmt.Sprintf("/etc/pki/tls/private/%s.key", novncproxy.ServiceName)
@@ -502,7 +542,7 @@ func (r *NovaNoVNCProxyReconciler) ensureDeployment(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *novav1.NovaNoVNCProxy,
-	inputHash string,
+	revision novav1.NovaServiceRevision,
 	annotations map[string]string,
 ) (ctrl.Result, error) {
 	Log := r.GetLogger(ctx)
@@ -523,7 +563,11 @@ func (r *NovaNoVNCProxyReconciler) ensureDeployment(
 		return ctrl.Result{}, fmt.Errorf("waiting for Topology requirements: %w", err)
 	}
 
-	ssSpec, err := novncproxy.StatefulSet(instance, inputHash, serviceLabels, annotations, topology)
+	// the StatefulSet runs the image of the revision that differs from the
+	// Spec while the service is rolled back
+	ssInstance := instance.DeepCopy()
+	ssInstance.Spec.ContainerImage = revision.ContainerImage
+	ssSpec, err := novncproxy.StatefulSet(ssInstance, revision.ConfigHash, serviceLabels, annotations, topology)
 	if err != nil {
 		Log.Info("Deployment failed")
 		instance.Status.Conditions.Set(condition.FalseCondition(
@@ -0,0 +1,360 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	gophercloud "github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/quotasets"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// NovaProjectQuotaReconciler reconciles a NovaProjectQuota object
+type NovaProjectQuotaReconciler struct {
+	ReconcilerBase
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *NovaProjectQuotaReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("NovaProjectQuota")
+}
+
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaprojectquotas,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaprojectquotas/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaprojectquotas/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;
+
+// Reconcile keeps the quota of a project in the compute API in sync with the
+// NovaProjectQuota CR and reports the current usage of the project
+func (r *NovaProjectQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	// Fetch the NovaProjectQuota instance that needs to be reconciled
+	instance := &novav1.NovaProjectQuota{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers. Return and don't requeue.
+			Log.Info("NovaProjectQuota instance not found, probably deleted before reconciled. Nothing to do.")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		Log.Error(err, "Failed to read the NovaProjectQuota instance.")
+		return ctrl.Result{}, err
+	}
+
+	h, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, "Failed to create lib-common Helper")
+		return ctrl.Result{}, err
+	}
+	Log.Info("Reconciling")
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// initialize status fields
+	r.initConditions(instance)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// Always update the instance status when exiting this function so we can
+	// persist any changes happened during the current reconciliation.
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
+			panic(r)
+		}
+		// update the Ready condition based on the sub conditions
+		if allSubConditionIsTrue(instance.Status) {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			// something is not ready so reset the Ready condition
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			// and recalculate it based on the state of the rest of the conditions
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := h.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, h, instance)
+	}
+
+	// We need a finalizer to be able to reset the project quota to the
+	// defaults when the CR is deleted
+	updated := controllerutil.AddFinalizer(instance, h.GetFinalizer())
+	if updated {
+		Log.Info("Added finalizer to ourselves")
+		// we intentionally return immediately to force the deferred function
+		// to persist the Instance with the finalizer. We need to have our own
+		// finalizer persisted before we change the quota to avoid leaving
+		// it behind.
+		return ctrl.Result{}, nil
+	}
+
+	computeClient, result, err := getNovaAPIClient(
+		ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+		&instance.Status.Conditions, r.RequeueTimeout, Log)
+	if (err != nil || result != ctrl.Result{}) {
+		return result, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	err = r.ensureProjectQuota(instance, computeClient, Log)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaQuotaSyncedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaQuotaSyncedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaQuotaSyncedCondition, novav1.NovaQuotaSyncedMessage)
+
+	// The quota can be changed via the compute API directly and the usage
+	// changes continuously so we periodically refresh them
+	return ctrl.Result{RequeueAfter: quotaResyncInterval}, nil
+}
+
+func (r *NovaProjectQuotaReconciler) initConditions(
+	instance *novav1.NovaProjectQuota,
+) {
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	// initialize all conditions to Unknown
+	cl := condition.CreateList(
+		condition.UnknownCondition(
+			novav1.NovaAPIReadyCondition,
+			condition.InitReason,
+			novav1.NovaAPIReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			condition.InputReadyCondition,
+			condition.InitReason,
+			condition.InputReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaQuotaSyncedCondition,
+			condition.InitReason,
+			novav1.NovaQuotaSyncedInitMessage,
+		),
+	)
+	instance.Status.Conditions.Init(&cl)
+}
+
+func (r *NovaProjectQuotaReconciler) ensureProjectQuota(
+	instance *novav1.NovaProjectQuota,
+	computeClient *gophercloud.ServiceClient,
+	l logr.Logger,
+) error {
+	detail, err := quotasets.GetDetail(computeClient, instance.Spec.ProjectID).Extract()
+	if err != nil {
+		return err
+	}
+	limits, inUse := splitQuotaDetailSet(detail)
+
+	diff := getQuotaLimitsDiff(instance.Spec.NovaQuotaLimits, limits)
+	if len(diff) > 0 {
+		spec := instance.Spec
+		_, err = quotasets.Update(computeClient, spec.ProjectID, quotasets.UpdateOpts{
+			Instances:          spec.Instances,
+			Cores:              spec.Cores,
+			RAM:                spec.RAM,
+			KeyPairs:           spec.KeyPairs,
+			MetadataItems:      spec.MetadataItems,
+			ServerGroups:       spec.ServerGroups,
+			ServerGroupMembers: spec.ServerGroupMembers,
+			Force:              spec.Force,
+		}).Extract()
+		if err != nil {
+			return err
+		}
+		l.Info("Updated project quota", "project", spec.ProjectID, "limits", diff)
+
+		// Re-read the quota to report the usage consistently with the
+		// applied limits
+		detail, err = quotasets.GetDetail(computeClient, spec.ProjectID).Extract()
+		if err != nil {
+			return err
+		}
+		limits, inUse = splitQuotaDetailSet(detail)
+	}
+
+	instance.Status.AppliedLimits = limits.toQuotaLimits()
+	instance.Status.InUse = inUse.toQuotaLimits()
+	return nil
+}
+
+// splitQuotaDetailSet returns the limits and the usage from the detailed
+// quota set of a project
+func splitQuotaDetailSet(detail quotasets.QuotaDetailSet) (computeQuotaSet, computeQuotaSet) {
+	limits := computeQuotaSet{
+		Instances:          detail.Instances.Limit,
+		Cores:              detail.Cores.Limit,
+		RAM:                detail.RAM.Limit,
+		KeyPairs:           detail.KeyPairs.Limit,
+		MetadataItems:      detail.MetadataItems.Limit,
+		ServerGroups:       detail.ServerGroups.Limit,
+		ServerGroupMembers: detail.ServerGroupMembers.Limit,
+	}
+	inUse := computeQuotaSet{
+		Instances:          detail.Instances.InUse,
+		Cores:              detail.Cores.InUse,
+		RAM:                detail.RAM.InUse,
+		KeyPairs:           detail.KeyPairs.InUse,
+		MetadataItems:      detail.MetadataItems.InUse,
+		ServerGroups:       detail.ServerGroups.InUse,
+		ServerGroupMembers: detail.ServerGroupMembers.InUse,
+	}
+	return limits, inUse
+}
+
+func (r *NovaProjectQuotaReconciler) reconcileDelete(
+	ctx context.Context,
+	h *helper.Helper,
+	instance *novav1.NovaProjectQuota,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+	Log.Info("Reconciling delete")
+
+	// If the nova-api is being deleted, e.g. as part of deleting the whole
+	// Nova deployment, then there is no compute API left to reset the quota in
+	api := &novav1.NovaAPI{}
+	err := h.GetClient().Get(
+		ctx, types.NamespacedName{Namespace: instance.Namespace, Name: instance.Spec.NovaInstance + "-api"}, api)
+	if err != nil && !k8s_errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if k8s_errors.IsNotFound(err) || !api.DeletionTimestamp.IsZero() {
+		Log.Info("NovaAPI is deleted, skipping the reset of the project quota", "project", instance.Spec.ProjectID)
+	} else {
+		computeClient, result, err := getNovaAPIClient(
+			ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+			&instance.Status.Conditions, r.RequeueTimeout, Log)
+		if (err != nil || result != ctrl.Result{}) {
+			return result, err
+		}
+		// Deleting the quota set of the project reverts it to the limits of
+		// the default quota class
+		err = quotasets.Delete(computeClient, instance.Spec.ProjectID).Err
+		if err != nil && !isComputeNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		Log.Info("Reset project quota to the defaults", "project", instance.Spec.ProjectID)
+	}
+
+	// Successfully cleaned up everything. So as the final step let's remove the
+	// finalizer from ourselves to allow the deletion of NovaProjectQuota CR itself
+	updated := controllerutil.RemoveFinalizer(instance, h.GetFinalizer())
+	if updated {
+		Log.Info("Removed finalizer from ourselves")
+	}
+
+	Log.Info("Reconciled delete successfully")
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NovaProjectQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index novaInstanceField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaProjectQuota{}, novaInstanceField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaProjectQuota)
+		return []string{cr.Spec.NovaInstance}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&novav1.NovaProjectQuota{}).
+		// watch the NovaAPI to know when it becomes usable
+		Watches(
+			&novav1.NovaAPI{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNovaAPI),
+		).
+		Complete(r)
+}
+
+func (r *NovaProjectQuotaReconciler) findObjectsForNovaAPI(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := r.GetLogger(ctx)
+
+	// The NovaAPI is named after the Nova CR it belongs to
+	novaInstance := strings.TrimSuffix(src.GetName(), "-api")
+	crList := &novav1.NovaProjectQuotaList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(novaInstanceField, novaInstance),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.Client.List(ctx, crList, listOps)
+	if err != nil {
+		l.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, novaInstanceField, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/go-logr/logr"
+	gophercloud "github.com/gophercloud/gophercloud"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// quotaResyncInterval defines how often the quota is compared with the
+// compute API to detect changes made outside of the operator and to refresh
+// the reported usage
+const quotaResyncInterval = 10 * time.Minute
+
+// NovaQuotaClassReconciler reconciles a NovaQuotaClass object
+type NovaQuotaClassReconciler struct {
+	ReconcilerBase
+}
+
+// GetLogger returns a logger object with a prefix of "controller.name" and additional controller context fields
+func (r *NovaQuotaClassReconciler) GetLogger(ctx context.Context) logr.Logger {
+	return log.FromContext(ctx).WithName("Controllers").WithName("NovaQuotaClass")
+}
+
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaquotaclasses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaquotaclasses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaquotaclasses/finalizers,verbs=update;patch
+// +kubebuilder:rbac:groups=nova.openstack.org,resources=novaapis,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;
+
+// Reconcile keeps the quota class in the compute API in sync with the
+// NovaQuotaClass CR. The compute API does not support deleting a quota class
+// so deleting the CR leaves the last applied limits in place.
+func (r *NovaQuotaClassReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, _err error) {
+	Log := r.GetLogger(ctx)
+
+	// Fetch the NovaQuotaClass instance that needs to be reconciled
+	instance := &novav1.NovaQuotaClass{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if k8s_errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Owned objects are automatically garbage collected.
+			// For additional cleanup logic use finalizers. Return and don't requeue.
+			Log.Info("NovaQuotaClass instance not found, probably deleted before reconciled. Nothing to do.")
+			return ctrl.Result{}, nil
+		}
+		// Error reading the object - requeue the request.
+		Log.Error(err, "Failed to read the NovaQuotaClass instance.")
+		return ctrl.Result{}, err
+	}
+
+	h, err := helper.NewHelper(
+		instance,
+		r.Client,
+		r.Kclient,
+		r.Scheme,
+		Log,
+	)
+	if err != nil {
+		Log.Error(err, "Failed to create lib-common Helper")
+		return ctrl.Result{}, err
+	}
+	Log.Info("Reconciling")
+
+	// Save a copy of the conditions so that we can restore the LastTransitionTime
+	// when a condition's state doesn't change.
+	savedConditions := instance.Status.Conditions.DeepCopy()
+
+	// initialize status fields
+	r.initConditions(instance)
+	instance.Status.ObservedGeneration = instance.Generation
+
+	// Always update the instance status when exiting this function so we can
+	// persist any changes happened during the current reconciliation.
+	defer func() {
+		// Don't update the status, if Reconciler Panics
+		if r := recover(); r != nil {
+			Log.Info(fmt.Sprintf("Panic during reconcile %v\n", r))
+			panic(r)
+		}
+		// update the Ready condition based on the sub conditions
+		if allSubConditionIsTrue(instance.Status) {
+			instance.Status.Conditions.MarkTrue(
+				condition.ReadyCondition, condition.ReadyMessage)
+		} else {
+			// something is not ready so reset the Ready condition
+			instance.Status.Conditions.MarkUnknown(
+				condition.ReadyCondition, condition.InitReason, condition.ReadyInitMessage)
+			// and recalculate it based on the state of the rest of the conditions
+			instance.Status.Conditions.Set(
+				instance.Status.Conditions.Mirror(condition.ReadyCondition))
+		}
+		condition.RestoreLastTransitionTimes(&instance.Status.Conditions, savedConditions)
+		err := h.PatchInstance(ctx, instance)
+		if err != nil {
+			_err = err
+			return
+		}
+	}()
+
+	computeClient, result, err := getNovaAPIClient(
+		ctx, h, instance.Namespace, instance.Spec.NovaInstance,
+		&instance.Status.Conditions, r.RequeueTimeout, Log)
+	if (err != nil || result != ctrl.Result{}) {
+		return result, err
+	}
+	instance.Status.Conditions.MarkTrue(condition.InputReadyCondition, condition.InputReadyMessage)
+
+	err = r.ensureQuotaClass(instance, computeClient, Log)
+	if err != nil {
+		instance.Status.Conditions.Set(condition.FalseCondition(
+			novav1.NovaQuotaSyncedCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			novav1.NovaQuotaSyncedErrorMessage,
+			err.Error()))
+		return ctrl.Result{}, err
+	}
+	instance.Status.Conditions.MarkTrue(
+		novav1.NovaQuotaSyncedCondition, novav1.NovaQuotaSyncedMessage)
+
+	// The quota class can be changed via the compute API directly so we
+	// periodically check it for drift
+	return ctrl.Result{RequeueAfter: quotaResyncInterval}, nil
+}
+
+func (r *NovaQuotaClassReconciler) initConditions(
+	instance *novav1.NovaQuotaClass,
+) {
+	if instance.Status.Conditions == nil {
+		instance.Status.Conditions = condition.Conditions{}
+	}
+	// initialize all conditions to Unknown
+	cl := condition.CreateList(
+		condition.UnknownCondition(
+			novav1.NovaAPIReadyCondition,
+			condition.InitReason,
+			novav1.NovaAPIReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			condition.InputReadyCondition,
+			condition.InitReason,
+			condition.InputReadyInitMessage,
+		),
+		condition.UnknownCondition(
+			novav1.NovaQuotaSyncedCondition,
+			condition.InitReason,
+			novav1.NovaQuotaSyncedInitMessage,
+		),
+	)
+	instance.Status.Conditions.Init(&cl)
+}
+
+func (r *NovaQuotaClassReconciler) ensureQuotaClass(
+	instance *novav1.NovaQuotaClass,
+	computeClient *gophercloud.ServiceClient,
+	l logr.Logger,
+) error {
+	current, err := getQuotaClassSet(computeClient, instance.Spec.QuotaClassName)
+	if err != nil {
+		return err
+	}
+
+	diff := getQuotaLimitsDiff(instance.Spec.NovaQuotaLimits, *current)
+	if len(diff) > 0 {
+		current, err = updateQuotaClassSet(computeClient, instance.Spec.QuotaClassName, diff)
+		if err != nil {
+			return err
+		}
+		l.Info("Updated quota class", "name", instance.Spec.QuotaClassName, "limits", diff)
+	}
+
+	instance.Status.AppliedLimits = current.toQuotaLimits()
+	return nil
+}
+
+// getQuotaClassSet returns the limits of the quota class. Gophercloud does
+// not support the os-quota-class-sets API so it is called directly.
+func getQuotaClassSet(
+	computeClient *gophercloud.ServiceClient,
+	name string,
+) (*computeQuotaSet, error) {
+	var body struct {
+		QuotaClassSet computeQuotaSet `json:"quota_class_set"`
+	}
+	_, err := computeClient.Get(
+		computeClient.ServiceURL("os-quota-class-sets", name), &body, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &body.QuotaClassSet, nil
+}
+
+// updateQuotaClassSet sets the given limits of the quota class and returns
+// the resulting limits
+func updateQuotaClassSet(
+	computeClient *gophercloud.ServiceClient,
+	name string,
+	limits map[string]int,
+) (*computeQuotaSet, error) {
+	var body struct {
+		QuotaClassSet computeQuotaSet `json:"quota_class_set"`
+	}
+	_, err := computeClient.Put(
+		computeClient.ServiceURL("os-quota-class-sets", name),
+		map[string]interface{}{"quota_class_set": limits},
+		&body,
+		&gophercloud.RequestOpts{OkCodes: []int{200}})
+	if err != nil {
+		return nil, err
+	}
+	return &body.QuotaClassSet, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *NovaQuotaClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// index novaInstanceField
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &novav1.NovaQuotaClass{}, novaInstanceField, func(rawObj client.Object) []string {
+		cr := rawObj.(*novav1.NovaQuotaClass)
+		return []string{cr.Spec.NovaInstance}
+	}); err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&novav1.NovaQuotaClass{}).
+		// watch the NovaAPI to know when it becomes usable
+		Watches(
+			&novav1.NovaAPI{},
+			handler.EnqueueRequestsFromMapFunc(r.findObjectsForNovaAPI),
+		).
+		Complete(r)
+}
+
+func (r *NovaQuotaClassReconciler) findObjectsForNovaAPI(ctx context.Context, src client.Object) []reconcile.Request {
+	requests := []reconcile.Request{}
+
+	l := r.GetLogger(ctx)
+
+	// The NovaAPI is named after the Nova CR it belongs to
+	novaInstance := strings.TrimSuffix(src.GetName(), "-api")
+	crList := &novav1.NovaQuotaClassList{}
+	listOps := &client.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector(novaInstanceField, novaInstance),
+		Namespace:     src.GetNamespace(),
+	}
+	err := r.Client.List(ctx, crList, listOps)
+	if err != nil {
+		l.Error(err, fmt.Sprintf("listing %s for field: %s - %s", crList.GroupVersionKind().Kind, novaInstanceField, src.GetNamespace()))
+		return requests
+	}
+
+	for _, item := range crList.Items {
+		requests = append(requests,
+			reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      item.GetName(),
+					Namespace: item.GetNamespace(),
+				},
+			},
+		)
+	}
+
+	return requests
+}
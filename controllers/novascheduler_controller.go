@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -67,6 +68,7 @@ func (r *NovaSchedulerReconciler) GetLogger(ctx context.Context) logr.Logger {
 // +kubebuilder:rbac:groups=nova.openstack.org,resources=novaschedulers/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=nova.openstack.org,resources=novaschedulers/finalizers,verbs=update;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch;
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;
 // +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete;
@@ -278,7 +280,9 @@ func (r *NovaSchedulerReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, err
 	}
 
-	instance.Status.Hash[common.InputHashName] = inputHash
+	revision := getRolloutRevision(
+		instance.Status.Rollout, instance.Generation, instance.Spec.ContainerImage, inputHash)
+	instance.Status.Hash[common.InputHashName] = revision.ConfigHash
 
 	instance.Status.Conditions.MarkTrue(condition.ServiceConfigReadyCondition, condition.ServiceConfigReadyMessage)
 
@@ -287,7 +291,14 @@ func (r *NovaSchedulerReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return result, err
 	}
 
-	result, err = r.ensureDeployment(ctx, h, instance, inputHash, serviceAnnotations)
+	result, err = r.ensureDeployment(ctx, h, instance, revision, serviceAnnotations)
+	if err != nil {
+		return result, err
+	}
+
+	result, err = r.ensureRollout(
+		ctx, h, instance, &instance.Status.Rollout, &instance.Status.Conditions,
+		instance.Spec.RolloutDeadline, revision, result)
 	if (err != nil || result != ctrl.Result{}) {
 		return result, err
 	}
@@ -300,10 +311,14 @@ func (r *NovaSchedulerReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	// clean up nova services from nova db should be always a last step in reconcile
 	// to make sure that
-	err = r.cleanServiceFromNovaDb(ctx, h, instance, secret, Log)
+	requeueAfter, err := r.cleanServiceFromNovaDb(ctx, h, instance, secret, Log)
 	if err != nil {
 		Log.Error(err, "Failed cleaning services from nova db")
 	}
+	if requeueAfter > 0 {
+		Log.Info("Stale services are waiting for their grace period", "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
 
 	return ctrl.Result{}, nil
 }
@@ -454,6 +469,11 @@ func (r *NovaSchedulerReconciler) initConditions(
 			condition.InitReason,
 			condition.DeploymentReadyInitMessage,
 		),
+		condition.UnknownCondition(
+			novav1.NovaRolloutReadyCondition,
+			condition.InitReason,
+			novav1.NovaRolloutReadyInitMessage,
+		),
 		condition.UnknownCondition(
 			condition.NetworkAttachmentsReadyCondition,
 			condition.InitReason,
@@ -486,7 +506,21 @@ func (r *NovaSchedulerReconciler) ensureConfigs(
 	secret corev1.Secret,
 	memcachedInstance *memcachedv1.Memcached,
 ) error {
-	err := r.generateConfigs(ctx, h, instance, hashes, secret, memcachedInstance)
+	var err error
+	requestedRevision := instance.Annotations[novav1.ConfigRevisionAnnotation]
+	switch {
+	case instance.Status.Rollout.IsRolledBack(instance.Generation):
+		// keep the last known-good config while the service is rolled back
+		err = r.ensureKnownGoodConfigRestored(ctx, h, instance)
+	case requestedRevision != "":
+		err = r.ensureConfigRevisionRestored(ctx, h, instance, requestedRevision, hashes)
+	default:
+		err = r.generateConfigs(ctx, h, instance, hashes, secret, memcachedInstance)
+	}
+	if err == nil {
+		instance.Status.ConfigRevision, err = r.ensureConfigRevision(
+			ctx, h, instance, instance.Spec.ConfigRevisionHistoryLimit)
+	}
 	if err != nil {
 		instance.Status.Conditions.Set(condition.FalseCondition(
 			condition.ServiceConfigReadyCondition,
@@ -532,14 +566,25 @@ func (r *NovaSchedulerReconciler) generateConfigs(
 		"cell_db_password":         string(cellDbSecret.Data[mariadbv1.DatabasePasswordSelector]),
 		"cell_db_address":          instance.Spec.Cell0DatabaseHostname,
 		"cell_db_port":             3306,
-		"openstack_region_name":    "regionOne", // fixme
-		"default_project_domain":   "Default",   // fixme
-		"default_user_domain":      "Default",   // fixme
+		"openstack_region_name":    instance.Spec.Region,
+		"default_project_domain":   instance.Spec.ServiceProjectDomain,
+		"default_user_domain":      instance.Spec.ServiceUserDomain,
+		"service_project_name":     instance.Spec.ServiceProjectName,
 		"transport_url":            string(secret.Data[TransportURLSelector]),
 		"MemcachedServers":         memcachedInstance.GetMemcachedServerListString(),
 		"MemcachedServersWithInet": memcachedInstance.GetMemcachedServerListWithInetString(),
 		"MemcachedTLS":             memcachedInstance.GetMemcachedTLSSupport(),
 	}
+	if notificationURL, ok := secret.Data[NotificationTransportURLSelector]; ok {
+		templateParameters["nova_enabled_notification"] = true
+		templateParameters["nova_cell_notify_transport_url"] = string(notificationURL)
+	}
+	if instance.Spec.Telemetry.Enabled {
+		templateParameters["enable_ceilometer"] = true
+		templateParameters["instance_usage_audit_period"] = instance.Spec.Telemetry.InstanceUsageAuditPeriod
+		templateParameters["notify_on_state_change"] = instance.Spec.Telemetry.NotifyOnStateChange
+	}
+	templateParameters["compute_rpc_pin"] = instance.Spec.ComputeRPCPin
 
 	var tlsCfg *tls.Service
 	if instance.Spec.TLS.CaBundleSecretName != "" {
@@ -594,7 +639,7 @@ func (r *NovaSchedulerReconciler) ensureDeployment(
 	ctx context.Context,
 	h *helper.Helper,
 	instance *novav1.NovaScheduler,
-	inputHash string,
+	revision novav1.NovaServiceRevision,
 	annotations map[string]string,
 ) (ctrl.Result, error) {
 	serviceLabels := map[string]string{
@@ -617,7 +662,11 @@ func (r *NovaSchedulerReconciler) ensureDeployment(
 		return ctrl.Result{}, fmt.Errorf("waiting for Topology requirements: %w", err)
 	}
 
-	ss := statefulset.NewStatefulSet(novascheduler.StatefulSet(instance, inputHash, serviceLabels, annotations, topology), r.RequeueTimeout)
+	// the StatefulSet runs the image of the revision that differs from the
+	// Spec while the service is rolled back
+	ssInstance := instance.DeepCopy()
+	ssInstance.Spec.ContainerImage = revision.ContainerImage
+	ss := statefulset.NewStatefulSet(novascheduler.StatefulSet(ssInstance, revision.ConfigHash, serviceLabels, annotations, topology), r.RequeueTimeout)
 	ctrlResult, err := ss.CreateOrPatch(ctx, h)
 	if err != nil && !k8s_errors.IsNotFound(err) {
 		Log.Error(err, "Deployment failed")
@@ -734,13 +783,20 @@ func (r *NovaSchedulerReconciler) cleanServiceFromNovaDb(
 	instance *novav1.NovaScheduler,
 	secret corev1.Secret,
 	l logr.Logger,
-) error {
+) (time.Duration, error) {
 	authPassword := string(secret.Data[ServicePasswordSelector])
 	computeClient, err := getNovaClient(ctx, h, instance, authPassword, l)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	statefulSetName := types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      instance.Name,
 	}
-	replicaCount := instance.Spec.Replicas
 
-	return cleanNovaServiceFromNovaDb(computeClient, "nova-scheduler", l, *replicaCount, "")
+	return reapStaleNovaServices(
+		ctx, h.GetClient(), computeClient, "nova-scheduler", statefulSetName,
+		*instance.Spec.Replicas,
+		time.Duration(instance.Spec.StaleServiceGracePeriod)*time.Second,
+		instance.Spec.KeystoneServiceIdentity.Region, l)
 }
@@ -0,0 +1,191 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	helper "github.com/openstack-k8s-operators/lib-common/modules/common/helper"
+	"github.com/openstack-k8s-operators/lib-common/modules/common/secret"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
+)
+
+// getRolloutRevision returns the revision the service needs to be deployed
+// with. It is the revision requested via the Spec unless the service is
+// rolled back to its last known-good revision.
+func getRolloutRevision(
+	rollout novav1.NovaRolloutStatus,
+	generation int64,
+	containerImage string,
+	inputHash string,
+) novav1.NovaServiceRevision {
+	if rollout.IsRolledBack(generation) {
+		return *rollout.LastKnownGood
+	}
+	return novav1.NovaServiceRevision{
+		ContainerImage: containerImage,
+		ConfigHash:     inputHash,
+	}
+}
+
+// ensureKnownGoodConfigSaved copies the service config Secret to the Secret
+// holding the last known-good config of the service
+func (r *ReconcilerBase) ensureKnownGoodConfigSaved(
+	ctx context.Context,
+	h *helper.Helper,
+	instance client.Object,
+) error {
+	config, _, err := secret.GetSecret(
+		ctx, h, nova.GetServiceConfigSecretName(instance.GetName()), instance.GetNamespace())
+	if err != nil {
+		return err
+	}
+	knownGood := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      nova.GetKnownGoodConfigSecretName(instance.GetName()),
+			Namespace: instance.GetNamespace(),
+			Labels:    config.Labels,
+		},
+		Data: config.Data,
+	}
+	_, _, err = secret.CreateOrPatchSecret(ctx, h, instance, knownGood)
+	return err
+}
+
+// ensureKnownGoodConfigRestored overwrites the service config Secret with
+// the last known-good config of the service
+func (r *ReconcilerBase) ensureKnownGoodConfigRestored(
+	ctx context.Context,
+	h *helper.Helper,
+	instance client.Object,
+) error {
+	knownGood, _, err := secret.GetSecret(
+		ctx, h, nova.GetKnownGoodConfigSecretName(instance.GetName()), instance.GetNamespace())
+	if err != nil {
+		return err
+	}
+	_, err = restoreServiceConfig(ctx, h, instance, knownGood)
+	return err
+}
+
+func getRolledBackMessage(rollout *novav1.NovaRolloutStatus, deadline int) string {
+	failed := novav1.NovaServiceRevision{}
+	if rollout.Current != nil {
+		failed = *rollout.Current
+	}
+	return fmt.Sprintf(
+		novav1.NovaRolloutReadyRolledBackMessage,
+		rollout.LastKnownGood.ContainerImage, rollout.LastKnownGood.ConfigHash,
+		failed.ContainerImage, failed.ConfigHash, deadline)
+}
+
+// ensureRollout tracks the rollout of the given revision of the service.
+// The revision is recorded as the last known-good one when the deployment
+// becomes ready. If the deployment does not become ready within the rollout
+// deadline then the service is rolled back to the last known-good revision
+// until its Spec is changed. It returns the result of the deployment unless
+// the rollout needs an earlier requeue.
+func (r *ReconcilerBase) ensureRollout(
+	ctx context.Context,
+	h *helper.Helper,
+	instance client.Object,
+	rollout *novav1.NovaRolloutStatus,
+	conditions *condition.Conditions,
+	deadline int,
+	revision novav1.NovaServiceRevision,
+	deploymentResult ctrl.Result,
+) (ctrl.Result, error) {
+	Log := r.GetLogger(ctx)
+
+	if rollout.IsRolledBack(instance.GetGeneration()) {
+		conditions.Set(condition.FalseCondition(
+			novav1.NovaRolloutReadyCondition,
+			condition.ErrorReason,
+			condition.SeverityWarning,
+			"%s",
+			getRolledBackMessage(rollout, deadline)))
+		return deploymentResult, nil
+	}
+
+	conditions.MarkTrue(novav1.NovaRolloutReadyCondition, novav1.NovaRolloutReadyMessage)
+
+	if conditions.IsTrue(condition.DeploymentReadyCondition) {
+		if rollout.LastKnownGood == nil || *rollout.LastKnownGood != revision {
+			err := r.ensureKnownGoodConfigSaved(ctx, h, instance)
+			if err != nil {
+				conditions.Set(condition.FalseCondition(
+					novav1.NovaRolloutReadyCondition,
+					condition.ErrorReason,
+					condition.SeverityWarning,
+					novav1.NovaRolloutReadyErrorMessage,
+					err.Error()))
+				return ctrl.Result{}, err
+			}
+			rollout.LastKnownGood = revision.DeepCopy()
+			Log.Info("Recorded the last known-good revision",
+				"image", revision.ContainerImage, "configHash", revision.ConfigHash)
+		}
+		rollout.Current = nil
+		rollout.StartTime = nil
+		return deploymentResult, nil
+	}
+
+	if deadline == 0 || rollout.LastKnownGood == nil || *rollout.LastKnownGood == revision {
+		// there is nothing to roll back to
+		rollout.Current = nil
+		rollout.StartTime = nil
+		return deploymentResult, nil
+	}
+
+	if rollout.Current == nil || *rollout.Current != revision {
+		rollout.Current = revision.DeepCopy()
+		now := metav1.Now()
+		rollout.StartTime = &now
+	}
+
+	remaining := time.Until(rollout.StartTime.Add(time.Duration(deadline) * time.Second))
+	if remaining > 0 {
+		if deploymentResult.RequeueAfter > 0 && deploymentResult.RequeueAfter < remaining {
+			return deploymentResult, nil
+		}
+		return ctrl.Result{RequeueAfter: remaining}, nil
+	}
+
+	rollout.RolledBackGeneration = instance.GetGeneration()
+	msg := getRolledBackMessage(rollout, deadline)
+	conditions.Set(condition.FalseCondition(
+		novav1.NovaRolloutReadyCondition,
+		condition.ErrorReason,
+		condition.SeverityWarning,
+		"%s",
+		msg))
+	r.Recorder.Event(instance, corev1.EventTypeWarning, "RolledBack", msg)
+	Log.Info(msg)
+
+	// requeue to deploy the last known-good revision
+	return ctrl.Result{Requeue: true}, nil
+}
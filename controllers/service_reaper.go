@@ -0,0 +1,236 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	gophercloud "github.com/gophercloud/gophercloud"
+	gophercloud_openstack "github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/hypervisors"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/services"
+	"github.com/gophercloud/gophercloud/openstack/placement/v1/resourceproviders"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// staleServiceRecheckInterval defines how often a stale service is checked
+// while the compute API still reports it up. Nova reports a service down
+// if it did not send a heartbeat in service_down_time that defaults to 60
+// seconds.
+const staleServiceRecheckInterval = time.Minute
+
+// getStatefulSetPodOrdinal returns the ordinal of the pod of the StatefulSet
+// if the host is named as a pod of the StatefulSet
+func getStatefulSetPodOrdinal(host string, statefulSetName string) (int, bool) {
+	suffix, found := strings.CutPrefix(host, statefulSetName+"-")
+	if !found {
+		return 0, false
+	}
+	ordinal, err := strconv.Atoi(suffix)
+	// Only accept the canonical form of the ordinal the StatefulSet
+	// controller uses in the pod names
+	if err != nil || ordinal < 0 || strconv.Itoa(ordinal) != suffix {
+		return 0, false
+	}
+	return ordinal, true
+}
+
+// getStatefulSetPodNames returns the names of the existing pods of the
+// StatefulSet
+func getStatefulSetPodNames(
+	ctx context.Context,
+	c client.Client,
+	statefulSetName types.NamespacedName,
+) (map[string]bool, error) {
+	names := map[string]bool{}
+
+	statefulSet := &appsv1.StatefulSet{}
+	err := c.Get(ctx, statefulSetName, statefulSet)
+	if k8s_errors.IsNotFound(err) {
+		return names, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if statefulSet.Spec.Selector == nil {
+		return names, nil
+	}
+
+	pods := &corev1.PodList{}
+	err = c.List(
+		ctx, pods, client.InNamespace(statefulSetName.Namespace),
+		client.MatchingLabels(statefulSet.Spec.Selector.MatchLabels))
+	if err != nil {
+		return nil, err
+	}
+	for _, pod := range pods.Items {
+		if metav1.IsControlledBy(&pod, statefulSet) {
+			names[pod.Name] = true
+		}
+	}
+	return names, nil
+}
+
+// reapStaleNovaServices deletes the services of the given binary that were
+// run by a pod of the StatefulSet that does not exist any more, e.g. after a
+// scale down. The hosts of the services are matched against the pod names
+// of the StatefulSet so services of other StatefulSets are never touched. A
+// stale service is only deleted after it is reported down for at least the
+// grace period. The resource providers of the compute nodes of a deleted
+// nova-compute service are deleted from placement too.
+// It returns the duration after the stale services that are not deleted yet
+// need to be checked again, or zero if there is no such service.
+func reapStaleNovaServices(
+	ctx context.Context,
+	c client.Client,
+	computeClient *gophercloud.ServiceClient,
+	binary string,
+	statefulSetName types.NamespacedName,
+	replicaCount int32,
+	gracePeriod time.Duration,
+	region string,
+	l logr.Logger,
+) (time.Duration, error) {
+	pods, err := getStatefulSetPodNames(ctx, c, statefulSetName)
+	if err != nil {
+		return 0, err
+	}
+
+	allPages, err := services.List(computeClient, services.ListOpts{Binary: binary}).AllPages()
+	if err != nil {
+		return 0, err
+	}
+	allServices, err := services.ExtractServices(allPages)
+	if err != nil {
+		return 0, err
+	}
+
+	var requeueAfter time.Duration
+	checkLater := func(after time.Duration) {
+		if requeueAfter == 0 || after < requeueAfter {
+			requeueAfter = after
+		}
+	}
+
+	for _, service := range allServices {
+		ordinal, ok := getStatefulSetPodOrdinal(service.Host, statefulSetName.Name)
+		if !ok {
+			// the service is not run by our StatefulSet
+			continue
+		}
+		// name index start from 0 so if replicaCount is 1 then only the
+		// service of the pod with ordinal 0 is expected. A pod that still
+		// exists, e.g. as it is terminating, is not stale yet either.
+		if ordinal < int(replicaCount) || pods[service.Host] {
+			continue
+		}
+
+		if service.State != "down" {
+			l.Info("Stale service is still reported up", "service", service)
+			checkLater(staleServiceRecheckInterval)
+			continue
+		}
+		// If the compute API does not report when the service was last
+		// updated then we cannot wait for the grace period
+		if !service.UpdatedAt.IsZero() {
+			downFor := time.Since(service.UpdatedAt)
+			if downFor < gracePeriod {
+				l.Info("Stale service is down but still in its grace period", "service", service)
+				checkLater(gracePeriod - downFor)
+				continue
+			}
+		}
+
+		err = deleteNovaService(computeClient, service, region, l)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return requeueAfter, nil
+}
+
+// deleteNovaService deletes the service from the compute API. If the service
+// is a nova-compute service then the resource providers of its compute
+// nodes are deleted from placement too.
+func deleteNovaService(
+	computeClient *gophercloud.ServiceClient,
+	service services.Service,
+	region string,
+	l logr.Logger,
+) error {
+	resourceProviders := []string{}
+	if service.Binary == "nova-compute" {
+		// The compute node UUID is the UUID of its resource provider
+		hypervisorClient := *computeClient
+		hypervisorClient.Microversion = hypervisorMicroversion
+		allPages, err := hypervisors.List(&hypervisorClient, nil).AllPages()
+		if err != nil {
+			return err
+		}
+		allHypervisors, err := hypervisors.ExtractHypervisors(allPages)
+		if err != nil {
+			return err
+		}
+		for _, hypervisor := range allHypervisors {
+			if hypervisor.Service.Host == service.Host {
+				resourceProviders = append(resourceProviders, hypervisor.ID)
+			}
+		}
+	}
+
+	rsp := services.Delete(computeClient, service.ID)
+	if rsp.Err != nil && !isComputeNotFound(rsp.Err) {
+		l.Error(rsp.Err, "Failed to delete service", "service", service, "response", rsp)
+		return rsp.Err
+	}
+	l.Info("Deleted service", "service", service)
+
+	if len(resourceProviders) == 0 {
+		return nil
+	}
+
+	placementClient, err := gophercloud_openstack.NewPlacementV1(
+		computeClient.ProviderClient,
+		gophercloud.EndpointOpts{
+			Region:       region,
+			Availability: gophercloud.AvailabilityInternal,
+		})
+	if err != nil {
+		return err
+	}
+	for _, uuid := range resourceProviders {
+		// nova deletes the resource providers of the compute nodes of the
+		// service if it can, so they might be already gone
+		err = resourceproviders.Delete(placementClient, uuid).ExtractErr()
+		if err != nil && !isComputeNotFound(err) {
+			l.Error(err, "Failed to delete resource provider", "uuid", uuid, "host", service.Host)
+			return err
+		}
+		l.Info("Deleted resource provider", "uuid", uuid, "host", service.Host)
+	}
+	return nil
+}
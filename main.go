@@ -181,6 +181,14 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "NovaCompute")
 			os.Exit(1)
 		}
+		if err = (&novav1.NovaQuotaClass{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NovaQuotaClass")
+			os.Exit(1)
+		}
+		if err = (&novav1.NovaProjectQuota{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "NovaProjectQuota")
+			os.Exit(1)
+		}
 	}
 
 	//+kubebuilder:scaffold:builder
@@ -1,6 +1,8 @@
 package nova
 
 import (
+	"strconv"
+
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -10,12 +12,18 @@ import (
 	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
 )
 
+// CellDeleteBlockedExitCodes are the exit codes of the cell delete job when
+// nova-manage refuses to delete the cell as it still has hosts, instances or
+// instance mappings
+var CellDeleteBlockedExitCodes = []int32{2, 3, 4}
+
 func CellDeleteJob(
 	instance *novav1.Nova,
 	cell *novav1.NovaCell,
 	configName string,
 	scriptName string,
 	inputHash string,
+	force bool,
 	labels map[string]string,
 ) *batchv1.Job {
 	args := []string{"-c", KollaServiceCommand}
@@ -24,6 +32,11 @@ func CellDeleteJob(
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
 	envVars["KOLLA_BOOTSTRAP"] = env.SetValue("true")
 	envVars["CELL_NAME"] = env.SetValue(cell.Spec.CellName)
+	envVars["FORCE"] = env.SetValue(strconv.FormatBool(force))
+	// The cell is targeted by its UUID if the cell mapping job reported it
+	if mapping, ok := instance.Status.CellMappings[cell.Name]; ok {
+		envVars["CELL_UUID"] = env.SetValue(mapping.UUID)
+	}
 
 	// This is stored in the Job so that if the input of the job changes
 	// then it results in a new job hash and therefore lib-common will re-run
@@ -50,6 +63,22 @@ func CellDeleteJob(
 		volumeMounts = append(volumeMounts, instance.Spec.APIServiceTemplate.TLS.CreateVolumeMounts(nil)...)
 	}
 
+	// A deletion blocked by a non empty cell is not retried as it would be
+	// blocked the same way until the cell is emptied. Even a forced deletion
+	// is blocked by instances. Other failures are retried.
+	podFailurePolicy := &batchv1.PodFailurePolicy{
+		Rules: []batchv1.PodFailurePolicyRule{
+			{
+				Action: batchv1.PodFailurePolicyActionFailJob,
+				OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+					ContainerName: ptr.To("nova-manage"),
+					Operator:      batchv1.PodFailurePolicyOnExitCodesOpIn,
+					Values:        CellDeleteBlockedExitCodes,
+				},
+			},
+		},
+	}
+
 	job := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      jobName,
@@ -57,9 +86,10 @@ func CellDeleteJob(
 			Labels:    labels,
 		},
 		Spec: batchv1.JobSpec{
+			PodFailurePolicy: podFailurePolicy,
 			Template: corev1.PodTemplateSpec{
 				Spec: corev1.PodSpec{
-					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					RestartPolicy:      corev1.RestartPolicyNever,
 					ServiceAccountName: instance.RbacResourceName(),
 					Volumes:            volumes,
 					Containers: []corev1.Container{
@@ -75,6 +105,10 @@ func CellDeleteJob(
 							},
 							Env:          env,
 							VolumeMounts: volumeMounts,
+							// The reason of the blocked deletion is at the
+							// end of the log so it is used as the termination
+							// message
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 						},
 					},
 				},
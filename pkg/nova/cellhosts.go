@@ -0,0 +1,107 @@
+package nova
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// CellHostsSchedule defines how often the compute hosts mapped to the cell
+// are reported
+const CellHostsSchedule = "*/5 * * * *"
+
+// CellHostsCronJobName returns the name of the CronJob reporting the compute
+// hosts mapped to the cell
+func CellHostsCronJobName(instance *novav1.NovaCell) string {
+	return instance.Name + "-hosts"
+}
+
+// CellHostsCronJob returns the CronJob that periodically reports the compute
+// hosts mapped to the cell in the nova_api database using the nova-manage
+// config of the cell. The compute API does not expose the cell of a host so
+// this is the only way to know which hosts belong to the cell. The cell
+// mapping is looked up by the name of the cell when the Job runs.
+func CellHostsCronJob(
+	instance *novav1.NovaCell,
+	configName string,
+	scriptName string,
+	labels map[string]string,
+) *batchv1.CronJob {
+	args := []string{"-c", KollaServiceCommand}
+
+	envVars := map[string]env.Setter{}
+	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	envVars["KOLLA_BOOTSTRAP"] = env.SetValue("true")
+	envVars["CELL_NAME"] = env.SetValue(instance.Spec.CellName)
+
+	env := env.MergeEnvs([]corev1.EnvVar{}, envVars)
+
+	volumes := []corev1.Volume{
+		GetConfigVolume(configName),
+		GetScriptVolume(scriptName),
+	}
+	volumeMounts := []corev1.VolumeMount{
+		GetConfigVolumeMount(),
+		GetScriptVolumeMount(),
+		GetKollaConfigVolumeMount("cell-hosts"),
+	}
+
+	// add CA cert if defined
+	if instance.Spec.TLS.CaBundleSecretName != "" {
+		volumes = append(volumes, instance.Spec.TLS.CreateVolume())
+		volumeMounts = append(volumeMounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
+	}
+
+	cron := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CellHostsCronJobName(instance),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          CellHostsSchedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: batchv1.JobSpec{
+					Parallelism: ptr.To[int32](1),
+					Completions: ptr.To[int32](1),
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							RestartPolicy:      corev1.RestartPolicyOnFailure,
+							ServiceAccountName: instance.Spec.ServiceAccount,
+							Volumes:            volumes,
+							Containers: []corev1.Container{
+								{
+									Name: "nova-manage",
+									Command: []string{
+										"/bin/bash",
+									},
+									Args:  args,
+									Image: instance.Spec.ConductorContainerImageURL,
+									SecurityContext: &corev1.SecurityContext{
+										RunAsUser: ptr.To(NovaUserID),
+									},
+									Env:          env,
+									VolumeMounts: volumeMounts,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if instance.Spec.NodeSelector != nil {
+		cron.Spec.JobTemplate.Spec.Template.Spec.NodeSelector = *instance.Spec.NodeSelector
+	}
+
+	return cron
+}
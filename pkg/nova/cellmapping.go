@@ -24,6 +24,18 @@ func CellMappingJob(
 	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
 	envVars["KOLLA_BOOTSTRAP"] = env.SetValue("true")
 	envVars["CELL_NAME"] = env.SetValue(cell.Spec.CellName)
+	// Only set when the cell is disabled so that the job hash of the enabled
+	// cells does not change
+	if cell.Spec.Disabled {
+		envVars["CELL_DISABLED"] = env.SetValue("true")
+	}
+	// An adopted cell is updated via the existing cell mapping the adoption
+	// check matched to it so no new cell mapping is created
+	if instance.Spec.Adoption {
+		if mapping, ok := instance.Status.CellMappings[cell.Name]; ok {
+			envVars["CELL_UUID"] = env.SetValue(mapping.UUID)
+		}
+	}
 
 	// This is stored in the Job so that if the input of the job changes
 	// then it results in a new job hash and therefore lib-common will re-run
@@ -42,6 +42,18 @@ func GetServiceConfigSecretName(crName string) string {
 	return fmt.Sprintf("%s-config-data", crName)
 }
 
+// GetKnownGoodConfigSecretName returns the name of the Secret used to
+// store the copy of the last known-good service configuration files
+func GetKnownGoodConfigSecretName(crName string) string {
+	return fmt.Sprintf("%s-config-data-known-good", crName)
+}
+
+// GetConfigRevisionSecretName returns the name of the immutable Secret used
+// to store a revision of the service configuration files
+func GetConfigRevisionSecretName(crName string, revision string) string {
+	return fmt.Sprintf("%s-config-data-%s", crName, revision)
+}
+
 // DatabaseStatus -
 type DatabaseStatus int
 
@@ -102,6 +114,9 @@ const (
 	CellDeleteFailed CellDeploymentStatus = iota
 	// CellDeleteComplete indicates that the NovaCell deletion is complete
 	CellDeleteComplete CellDeploymentStatus = iota
+	// CellDeleteBlocked indicates that the NovaCell is not deleted as the
+	// cell is not empty
+	CellDeleteBlocked CellDeploymentStatus = iota
 )
 
 // Database -
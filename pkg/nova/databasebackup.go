@@ -0,0 +1,264 @@
+package nova
+
+import (
+	"strconv"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+const (
+	backupVolume = "backup"
+	// DatabaseBackupMountPath is where the PVC of the backups is mounted in
+	// the backup and the restore Jobs
+	DatabaseBackupMountPath = "/backup"
+)
+
+// DatabaseBackupName returns the name of the PVC, the Job and the CronJob of
+// the NovaDatabaseBackup
+func DatabaseBackupName(instance *novav1.NovaDatabaseBackup) string {
+	return instance.Name + "-db-backup"
+}
+
+// DatabaseRestoreJobName returns the name of the Job of the
+// NovaDatabaseRestore
+func DatabaseRestoreJobName(instance *novav1.NovaDatabaseRestore) string {
+	return instance.Name + "-db-restore"
+}
+
+// DatabaseBackupPVC returns the PVC the backups of the NovaDatabaseBackup
+// are written to
+func DatabaseBackupPVC(
+	instance *novav1.NovaDatabaseBackup,
+	labels map[string]string,
+) (*corev1.PersistentVolumeClaim, error) {
+	size, err := resource.ParseQuantity(instance.Spec.StorageRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DatabaseBackupName(instance),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{
+				corev1.ReadWriteOnce,
+			},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: size,
+				},
+			},
+		},
+	}
+	if instance.Spec.StorageClass != "" {
+		pvc.Spec.StorageClassName = ptr.To(instance.Spec.StorageClass)
+	}
+	return pvc, nil
+}
+
+// DatabaseBackupJob returns the Job that takes a backup of the given
+// databases to the PVC of the NovaDatabaseBackup
+func DatabaseBackupJob(
+	instance *novav1.NovaDatabaseBackup,
+	novaInstance *novav1.Nova,
+	image string,
+	novaImage string,
+	databases []string,
+	cells []string,
+	configName string,
+	scriptName string,
+	labels map[string]string,
+) *batchv1.Job {
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DatabaseBackupName(instance),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: databaseBackupJobSpec(
+			instance, novaInstance, image, novaImage, databases, cells, configName, scriptName),
+	}
+}
+
+// DatabaseBackupCronJob returns the CronJob that periodically takes a backup
+// of the given databases to the PVC of the NovaDatabaseBackup
+func DatabaseBackupCronJob(
+	instance *novav1.NovaDatabaseBackup,
+	novaInstance *novav1.Nova,
+	image string,
+	novaImage string,
+	databases []string,
+	cells []string,
+	configName string,
+	scriptName string,
+	labels map[string]string,
+) *batchv1.CronJob {
+	return &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DatabaseBackupName(instance),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          *instance.Spec.Schedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: databaseBackupJobSpec(
+					instance, novaInstance, image, novaImage, databases, cells, configName, scriptName),
+			},
+		},
+	}
+}
+
+func databaseBackupJobSpec(
+	instance *novav1.NovaDatabaseBackup,
+	novaInstance *novav1.Nova,
+	image string,
+	novaImage string,
+	databases []string,
+	cells []string,
+	configName string,
+	scriptName string,
+) batchv1.JobSpec {
+	envVars := map[string]env.Setter{}
+	envVars["DATABASES"] = env.SetValue(strings.Join(databases, " "))
+	envVars["CELLS"] = env.SetValue(strings.Join(cells, " "))
+	envVars["NOVA_IMAGE"] = env.SetValue(novaImage)
+	envVars["MAX_BACKUPS"] = env.SetValue(strconv.Itoa(instance.Spec.MaxBackups))
+
+	return batchv1.JobSpec{
+		Template: databaseJobPodTemplate(
+			novaInstance, image, "db-backup", envVars,
+			DatabaseBackupName(instance), false, configName, scriptName),
+	}
+}
+
+// DatabaseRestoreJob returns the Job that restores the given databases from
+// the last backup of the NovaDatabaseBackup
+func DatabaseRestoreJob(
+	instance *novav1.NovaDatabaseRestore,
+	novaInstance *novav1.Nova,
+	backup *novav1.NovaDatabaseBackup,
+	image string,
+	databases []string,
+	configName string,
+	scriptName string,
+	labels map[string]string,
+) *batchv1.Job {
+	envVars := map[string]env.Setter{}
+	envVars["DATABASES"] = env.SetValue(strings.Join(databases, " "))
+	envVars["BACKUP_PATH"] = env.SetValue(backup.Status.LastBackup.Path)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      DatabaseRestoreJobName(instance),
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			// A partially restored database needs the attention of the
+			// human operator so the restore is not retried
+			BackoffLimit: ptr.To[int32](0),
+			Template: databaseJobPodTemplate(
+				novaInstance, image, "db-restore", envVars,
+				DatabaseBackupName(backup), true, configName, scriptName),
+		},
+	}
+}
+
+func databaseJobPodTemplate(
+	novaInstance *novav1.Nova,
+	image string,
+	serviceName string,
+	envVars map[string]env.Setter,
+	pvcName string,
+	readOnly bool,
+	configName string,
+	scriptName string,
+) corev1.PodTemplateSpec {
+	args := []string{"-c", KollaServiceCommand}
+
+	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	envVars["KOLLA_BOOTSTRAP"] = env.SetValue("true")
+	env := env.MergeEnvs([]corev1.EnvVar{}, envVars)
+
+	volumes := []corev1.Volume{
+		GetConfigVolume(configName),
+		GetScriptVolume(scriptName),
+		{
+			Name: backupVolume,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: pvcName,
+					ReadOnly:  readOnly,
+				},
+			},
+		},
+	}
+	volumeMounts := []corev1.VolumeMount{
+		GetConfigVolumeMount(),
+		GetScriptVolumeMount(),
+		GetKollaConfigVolumeMount(serviceName),
+		{
+			Name:      backupVolume,
+			MountPath: DatabaseBackupMountPath,
+			ReadOnly:  readOnly,
+		},
+	}
+
+	// add CA cert if defined
+	if novaInstance.Spec.APIServiceTemplate.TLS.CaBundleSecretName != "" {
+		volumes = append(volumes, novaInstance.Spec.APIServiceTemplate.TLS.CreateVolume())
+		volumeMounts = append(volumeMounts, novaInstance.Spec.APIServiceTemplate.TLS.CreateVolumeMounts(nil)...)
+	}
+
+	template := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			RestartPolicy:      corev1.RestartPolicyNever,
+			ServiceAccountName: novaInstance.RbacResourceName(),
+			// The backups on the PVC are owned by the nova group
+			SecurityContext: &corev1.PodSecurityContext{
+				FSGroup: ptr.To(NovaUserID),
+			},
+			Volumes: volumes,
+			Containers: []corev1.Container{
+				{
+					Name: serviceName,
+					Command: []string{
+						"/bin/bash",
+					},
+					Args:  args,
+					Image: image,
+					SecurityContext: &corev1.SecurityContext{
+						RunAsUser: ptr.To(NovaUserID),
+					},
+					Env:          env,
+					VolumeMounts: volumeMounts,
+					// The end of the log is reported as the reason of a
+					// failed run
+					TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+				},
+			},
+		},
+	}
+
+	if novaInstance.Spec.NodeSelector != nil {
+		template.Spec.NodeSelector = *novaInstance.Spec.NodeSelector
+	}
+
+	return template
+}
@@ -0,0 +1,102 @@
+package nova
+
+import (
+	"encoding/json"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// ManageCommandJob returns the Job that runs the nova-manage command of the
+// NovaManageCommand with the nova-manage config of the given cell
+func ManageCommandJob(
+	command *novav1.NovaManageCommand,
+	instance *novav1.Nova,
+	cell *novav1.NovaCell,
+	configName string,
+	scriptName string,
+	labels map[string]string,
+) (*batchv1.Job, error) {
+	args := []string{"-c", KollaServiceCommand}
+
+	// The arguments are passed as a JSON list so that the script does not
+	// need to split or expand them
+	manageArgs, err := json.Marshal(command.Spec.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	envVars := map[string]env.Setter{}
+	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	envVars["KOLLA_BOOTSTRAP"] = env.SetValue("true")
+	envVars["NOVA_MANAGE_COMMAND"] = env.SetValue(string(command.Spec.Command))
+	envVars["NOVA_MANAGE_ARGS"] = env.SetValue(string(manageArgs))
+
+	env := env.MergeEnvs([]corev1.EnvVar{}, envVars)
+
+	volumes := []corev1.Volume{
+		GetConfigVolume(configName),
+		GetScriptVolume(scriptName),
+	}
+	volumeMounts := []corev1.VolumeMount{
+		GetConfigVolumeMount(),
+		GetScriptVolumeMount(),
+		GetKollaConfigVolumeMount("manage-command"),
+	}
+
+	// add CA cert if defined
+	if instance.Spec.APIServiceTemplate.TLS.CaBundleSecretName != "" {
+		volumes = append(volumes, instance.Spec.APIServiceTemplate.TLS.CreateVolume())
+		volumeMounts = append(volumeMounts, instance.Spec.APIServiceTemplate.TLS.CreateVolumeMounts(nil)...)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      command.Name + "-nova-manage",
+			Namespace: command.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			// The exit code of the command is reported as is so a command
+			// exiting with non zero is not retried
+			BackoffLimit: ptr.To[int32](0),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: instance.RbacResourceName(),
+					Volumes:            volumes,
+					Containers: []corev1.Container{
+						{
+							Name: "nova-manage",
+							Command: []string{
+								"/bin/bash",
+							},
+							Args:  args,
+							Image: cell.Spec.ConductorContainerImageURL,
+							SecurityContext: &corev1.SecurityContext{
+								RunAsUser: ptr.To(NovaUserID),
+							},
+							Env:          env,
+							VolumeMounts: volumeMounts,
+							// The end of the output of the command is at
+							// the end of the log so it is used as the
+							// termination message
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if cell.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *cell.Spec.NodeSelector
+	}
+
+	return job, nil
+}
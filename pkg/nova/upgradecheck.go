@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package nova
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	"github.com/openstack-k8s-operators/lib-common/modules/common/env"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// UpgradeCheckJob defines a Job running nova-status upgrade check with the
+// new container images from the Spec against the existing databases. It
+// uses the nova-manage config of cell0 as that has access to both the API
+// and the cell0 DB.
+func UpgradeCheckJob(
+	instance *novav1.Nova,
+	cell0 *novav1.NovaCell,
+	configName string,
+	scriptName string,
+	labels map[string]string,
+) *batchv1.Job {
+	args := []string{"-c", KollaServiceCommand}
+
+	envVars := map[string]env.Setter{}
+	envVars["KOLLA_CONFIG_STRATEGY"] = env.SetValue("COPY_ALWAYS")
+	envVars["KOLLA_BOOTSTRAP"] = env.SetValue("true")
+
+	env := env.MergeEnvs([]corev1.EnvVar{}, envVars)
+
+	jobName := instance.Name + "-upgrade-check"
+
+	volumes := []corev1.Volume{
+		GetConfigVolume(configName),
+		GetScriptVolume(scriptName),
+	}
+	volumeMounts := []corev1.VolumeMount{
+		GetConfigVolumeMount(),
+		GetScriptVolumeMount(),
+		GetKollaConfigVolumeMount("upgrade-check"),
+	}
+
+	// add CA cert if defined
+	if instance.Spec.APIServiceTemplate.TLS.CaBundleSecretName != "" {
+		volumes = append(volumes, instance.Spec.APIServiceTemplate.TLS.CreateVolume())
+		volumeMounts = append(volumeMounts, instance.Spec.APIServiceTemplate.TLS.CreateVolumeMounts(nil)...)
+	}
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: instance.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1.JobSpec{
+			// A failed check is not retried as it would fail the same way
+			// until the problem in the deployment is fixed
+			BackoffLimit: ptr.To[int32](0),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy:      corev1.RestartPolicyNever,
+					ServiceAccountName: instance.RbacResourceName(),
+					Volumes:            volumes,
+					Containers: []corev1.Container{
+						{
+							Name: "nova-status",
+							Command: []string{
+								"/bin/bash",
+							},
+							Args:  args,
+							Image: instance.Spec.APIContainerImageURL,
+							SecurityContext: &corev1.SecurityContext{
+								RunAsUser: ptr.To(NovaUserID),
+							},
+							Env:          env,
+							VolumeMounts: volumeMounts,
+							// The report of the failed check is at the end of
+							// the log so it is used as the termination message
+							TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if cell0.Spec.NodeSelector != nil {
+		job.Spec.Template.Spec.NodeSelector = *cell0.Spec.NodeSelector
+	}
+
+	return job
+}
@@ -14,6 +14,15 @@ import (
 	"github.com/openstack-k8s-operators/nova-operator/pkg/nova"
 )
 
+// DBPurgeCronJobName returns the name of the DB purge CronJob of the
+// conductor
+func DBPurgeCronJobName(instance *novav1.NovaConductor) string {
+	// we want to hide the fact that the job is created by the conductor
+	// controller, but we don't have direct access to the Cell CR name, so we
+	// remove the known conductor suffix from the Conductor CR name.
+	return strings.TrimSuffix(instance.Name, "-conductor") + "-db-purge"
+}
+
 func DBPurgeCronJob(
 	instance *novav1.NovaConductor,
 	labels map[string]string,
@@ -27,6 +36,13 @@ func DBPurgeCronJob(
 
 	envVars["ARCHIVE_AGE"] = env.SetValue(fmt.Sprintf("%d", *instance.Spec.DBPurge.ArchiveAge))
 	envVars["PURGE_AGE"] = env.SetValue(fmt.Sprintf("%d", *instance.Spec.DBPurge.PurgeAge))
+	envVars["DRY_RUN"] = env.SetValue(fmt.Sprintf("%t", instance.Spec.DBPurge.DryRun))
+	if instance.Spec.DBPurge.MaxRows != nil {
+		envVars["MAX_ROWS"] = env.SetValue(fmt.Sprintf("%d", *instance.Spec.DBPurge.MaxRows))
+	}
+	if instance.Spec.DBPurge.ArchiveTaskLog != nil {
+		envVars["ARCHIVE_TASK_LOG"] = env.SetValue(fmt.Sprintf("%t", *instance.Spec.DBPurge.ArchiveTaskLog))
+	}
 
 	env := env.MergeEnvs([]corev1.EnvVar{}, envVars)
 
@@ -46,14 +62,9 @@ func DBPurgeCronJob(
 		volumeMounts = append(volumeMounts, instance.Spec.TLS.CreateVolumeMounts(nil)...)
 	}
 
-	// we want to hide the fact that the job is created by the conductor
-	// controller, but we don't have direct access to the Cell CR name, so we
-	// remove the known conductor suffix from the Conductor CR name.
-	name := strings.TrimSuffix(instance.Name, "-conductor") + "-db-purge"
-
 	cron := &batchv1.CronJob{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
+			Name:      DBPurgeCronJobName(instance),
 			Namespace: instance.Namespace,
 			Labels:    labels,
 		},
@@ -70,7 +81,9 @@ func DBPurgeCronJob(
 					Completions: ptr.To[int32](1),
 					Template: corev1.PodTemplateSpec{
 						Spec: corev1.PodSpec{
-							RestartPolicy:      corev1.RestartPolicyOnFailure,
+							// Every failed run is kept in its own pod so
+							// the end of its log can be reported
+							RestartPolicy:      corev1.RestartPolicyNever,
 							ServiceAccountName: instance.Spec.ServiceAccount,
 							Volumes:            volumes,
 							Containers: []corev1.Container{
@@ -86,6 +99,9 @@ func DBPurgeCronJob(
 									},
 									Env:          env,
 									VolumeMounts: volumeMounts,
+									// The end of the log is reported as the
+									// reason of a failed run
+									TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
 								},
 							},
 						},
@@ -0,0 +1,66 @@
+#!/bin/bash
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+set -xe -o pipefail
+
+export DATABASES=${DATABASES:?"Please specify a DATABASES variable."}
+export CELLS=${CELLS:-}
+export NOVA_IMAGE=${NOVA_IMAGE:-}
+export MAX_BACKUPS=${MAX_BACKUPS:-3}
+export BACKUP_DIR=${BACKUP_DIR:-/backup}
+# The client config of each database with its credentials is <db>.cnf
+CONFIG_DIR=/etc/nova/db
+
+path="${BACKUP_DIR}/$(date -u +%Y%m%d%H%M%S)"
+# The backup is written to a partial directory first so that an interrupted
+# backup is never mistaken for a complete one
+rm -rf "${path}.partial"
+mkdir -p "${path}.partial"
+
+for db in ${DATABASES}; do
+    mysqldump --defaults-extra-file="${CONFIG_DIR}/${db}.cnf" \
+        --single-transaction --no-tablespaces "${db}" \
+        | gzip > "${path}.partial/${db}.sql.gz"
+    mysql --defaults-extra-file="${CONFIG_DIR}/${db}.cnf" -N -B \
+        -e "SELECT version_num FROM alembic_version" "${db}" \
+        > "${path}.partial/${db}.version"
+done
+
+python3 - "${path}" > "${path}.partial/metadata.json" <<'PYEOF'
+import json
+import os
+import sys
+
+path = sys.argv[1]
+versions = {}
+for db in os.environ["DATABASES"].split():
+    with open(os.path.join(path + ".partial", db + ".version")) as f:
+        versions[db] = f.read().strip()
+
+print(json.dumps({
+    "path": path,
+    "cells": os.environ["CELLS"].split(),
+    "schemaVersions": versions,
+    "image": os.environ["NOVA_IMAGE"],
+}))
+PYEOF
+
+mv "${path}.partial" "${path}"
+
+# Keep only the last MAX_BACKUPS complete backups
+find "${BACKUP_DIR}" -mindepth 1 -maxdepth 1 -type d -name '[0-9]*' ! -name '*.partial' \
+    | sort | head -n -"${MAX_BACKUPS}" | xargs -r rm -rf
+
+# The metadata of the backup is reported to the operator
+cp "${path}/metadata.json" /dev/termination-log
@@ -0,0 +1,33 @@
+#!/bin/bash
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+set -xe -o pipefail
+
+export DATABASES=${DATABASES:?"Please specify a DATABASES variable."}
+export BACKUP_PATH=${BACKUP_PATH:?"Please specify a BACKUP_PATH variable."}
+# The client config of each database with its credentials is <db>.cnf
+CONFIG_DIR=/etc/nova/db
+
+# Nothing is restored unless every requested database is in the backup
+for db in ${DATABASES}; do
+    if [ ! -f "${BACKUP_PATH}/${db}.sql.gz" ]; then
+        echo "The database ${db} is not in the backup ${BACKUP_PATH}"
+        exit 1
+    fi
+done
+
+for db in ${DATABASES}; do
+    gunzip -c "${BACKUP_PATH}/${db}.sql.gz" \
+        | mysql --defaults-extra-file="${CONFIG_DIR}/${db}.cnf" "${db}"
+done
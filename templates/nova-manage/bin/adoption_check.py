@@ -0,0 +1,147 @@
+#!/usr/bin/env python3
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Verifies that the existing nova DBs can be adopted. It checks that the
+# schema version of the API DB and of every cell DB is known by the nova
+# code of the image, and matches the existing cell mappings to the cells in
+# EXPECTED_CELLS by name, by the hosts of the message bus and the DB and by
+# the name of the DB. The DBs are not changed. The mismatches and the matched cell mappings are
+# written as JSON to the termination message of the pod so that the operator
+# can report them.
+
+import json
+import os
+import sys
+
+from alembic import script as alembic_script
+from oslo_config import cfg
+import oslo_messaging as messaging
+from sqlalchemy.engine import url as sqla_url
+
+from nova import config
+from nova import context
+from nova.db import migration
+from nova import objects
+
+TERMINATION_LOG = '/dev/termination-log'
+
+
+def check_schema(database, name, ctxt=None):
+    try:
+        version = migration.db_version(database=database, context=ctxt)
+    except Exception as e:
+        return '%s DB: cannot read the schema version: %s' % (name, e)
+    if version is None:
+        return '%s DB: the schema is not initialized' % name
+    if isinstance(version, int):
+        return ('%s DB: the legacy schema version %d needs to be upgraded '
+                'before adoption' % (name, version))
+
+    scripts = alembic_script.ScriptDirectory.from_config(
+        migration._find_alembic_conf(database))
+    try:
+        scripts.get_revision(version)
+    except Exception:
+        return ('%s DB: the schema version %s is not known by this nova '
+                'version' % (name, version))
+    return None
+
+
+def get_hosts(mapping):
+    # cell0 has no message bus, its transport URL is none:///
+    transport_url = messaging.TransportURL.parse(
+        cfg.CONF, mapping.transport_url)
+    transport_hosts = [host.hostname for host in transport_url.hosts]
+    database_url = sqla_url.make_url(mapping.database_connection)
+    return ','.join(transport_hosts), database_url.host or ''
+
+
+def get_database_name(mapping):
+    return sqla_url.make_url(mapping.database_connection).database or ''
+
+
+def check(expected_cells):
+    mismatches = []
+    cell_mappings = {}
+
+    mismatch = check_schema('api', 'API')
+    if mismatch:
+        mismatches.append(mismatch)
+
+    ctxt = context.get_admin_context()
+    existing = {}
+    for mapping in objects.CellMappingList.get_all(ctxt):
+        existing.setdefault(mapping.name, []).append(mapping)
+
+    for cell in expected_cells:
+        name = cell['name']
+        mappings = existing.pop(name, [])
+        if not mappings:
+            mismatches.append('%s: no existing cell mapping' % name)
+            continue
+        if len(mappings) > 1:
+            mismatches.append('%s: %d existing cell mappings' % (
+                name, len(mappings)))
+            continue
+        mapping = mappings[0]
+
+        transport_host, database_host = get_hosts(mapping)
+        if transport_host != cell['transportURLHost']:
+            mismatches.append(
+                '%s: the message bus host of the cell mapping is %s '
+                'instead of %s' % (
+                    name, transport_host, cell['transportURLHost']))
+        if database_host != cell['databaseHost']:
+            mismatches.append(
+                '%s: the DB host of the cell mapping is %s instead of %s' % (
+                    name, database_host, cell['databaseHost']))
+        # The cell mapping is updated to the DB of the cell so a mapping to
+        # another DB on the same host would point to an empty DB after that
+        database_name = get_database_name(mapping)
+        if database_name != cell['databaseName']:
+            mismatches.append(
+                '%s: the DB of the cell mapping is %s instead of %s' % (
+                    name, database_name, cell['databaseName']))
+
+        with context.target_cell(ctxt, mapping) as cctxt:
+            mismatch = check_schema('main', name, cctxt)
+        if mismatch:
+            mismatches.append(mismatch)
+
+        cell_mappings[name] = {
+            'uuid': mapping.uuid,
+            'transportURLHost': transport_host,
+            'databaseHost': database_host,
+        }
+
+    for name in sorted(existing):
+        mismatches.append('%s: the cell mapping has no cell template' % name)
+
+    return {'mismatches': mismatches, 'cellMappings': cell_mappings}
+
+
+def main():
+    expected_cells = json.loads(os.environ['EXPECTED_CELLS'])
+    config.parse_args(sys.argv[:1])
+    objects.register_all()
+
+    report = check(expected_cells)
+    print(json.dumps(report), flush=True)
+    with open(TERMINATION_LOG, 'w') as f:
+        json.dump(report, f)
+
+
+if __name__ == '__main__':
+    main()
@@ -0,0 +1,125 @@
+#!/usr/bin/env python3
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Audits the consistency of the cell CELL_NAME with placement and the host
+# mappings and writes the findings as JSON to the termination message of the
+# pod so that the operator can report them in the status of the cell. The
+# output of the nova-manage commands is kept in the log of the pod.
+
+import json
+import os
+import re
+import subprocess
+import sys
+
+from nova import config
+from nova import context
+from nova import objects
+
+TERMINATION_LOG = '/dev/termination-log'
+
+# The termination message is limited to 4096 bytes
+MAX_REPORTED_HOSTS = 50
+
+ORPHANED_ALLOCATION = re.compile(
+    r'Allocations for consumer UUID \S+ on Resource Provider (\S+) '
+    r'can be deleted')
+
+
+def nova_manage(*args):
+    proc = subprocess.run(
+        ['nova-manage'] + list(args),
+        stdout=subprocess.PIPE, stderr=subprocess.STDOUT,
+        universal_newlines=True)
+    print(proc.stdout, flush=True)
+    return proc.returncode, proc.stdout
+
+
+def get_mapped_hosts(cell_uuid):
+    # The hosts are printed in the last column of the table
+    ret, output = nova_manage('cell_v2', 'list_hosts', '--cell_uuid', cell_uuid)
+    if ret != 0:
+        return None
+    hosts = set()
+    for line in output.splitlines():
+        if not line.startswith('|'):
+            continue
+        hostname = line.strip().strip('|').split('|')[-1].strip()
+        if hostname != 'Hostname':
+            hosts.add(hostname)
+    return hosts
+
+
+def audit(cell_name):
+    report = {
+        'orphanedAllocations': 0,
+        'unmappedHosts': [],
+        'aggregatesInSync': True,
+        'errors': [],
+    }
+
+    ctxt = context.get_admin_context()
+    cells = [c for c in objects.CellMappingList.get_all(ctxt)
+             if c.name == cell_name]
+    if not cells:
+        report['errors'].append('cell %s is not mapped' % cell_name)
+        return report
+    cell = cells[0]
+
+    with context.target_cell(ctxt, cell) as cctxt:
+        compute_hosts = {
+            service.host for service in objects.ServiceList.get_by_binary(
+                cctxt, 'nova-compute', include_disabled=True)}
+        compute_node_uuids = {
+            node.uuid for node in objects.ComputeNodeList.get_all(cctxt)}
+
+    mapped_hosts = get_mapped_hosts(cell.uuid)
+    if mapped_hosts is None:
+        report['errors'].append('cell_v2 list_hosts failed')
+    else:
+        unmapped_hosts = sorted(compute_hosts - mapped_hosts)
+        report['unmappedHosts'] = unmapped_hosts[:MAX_REPORTED_HOSTS]
+
+    # 0 means no orphaned allocation, 3 means orphaned allocations are found.
+    # The audit covers every resource provider so only the ones of the
+    # compute nodes of this cell are counted.
+    ret, output = nova_manage('placement', 'audit', '--verbose')
+    if ret in (0, 3):
+        report['orphanedAllocations'] = len([
+            m for m in ORPHANED_ALLOCATION.finditer(output)
+            if m.group(1) in compute_node_uuids])
+    else:
+        report['errors'].append(
+            'placement audit failed with exit code %d' % ret)
+
+    ret, _ = nova_manage('placement', 'sync_aggregates', '--verbose')
+    report['aggregatesInSync'] = ret == 0
+
+    return report
+
+
+def main():
+    cell_name = os.environ['CELL_NAME']
+    config.parse_args(sys.argv[:1])
+    objects.register_all()
+
+    report = audit(cell_name)
+    print(json.dumps(report), flush=True)
+    with open(TERMINATION_LOG, 'w') as f:
+        json.dump(report, f)
+
+
+if __name__ == '__main__':
+    main()
@@ -13,15 +13,54 @@
 # See the License for the specific language governing permissions and
 # limitations under the License.
 
-set -xe
+# NOTE: no -x here so that the end of the log only contains the reason of a
+# blocked deletion as that is used as the termination message of the pod.
+set -e
 
 export CELL_NAME=${CELL_NAME:?"Please specify a CELL_NAME variable."}
+export FORCE=${FORCE:-false}
+# The UUID of the cell if the operator knows it from the cell mapping job
+export CELL_UUID=${CELL_UUID:-}
 
-# NOTE(gibi): nova-manage should be enhanced upstream to get rid of this
-# uglyness
-# Note the "|" around the CELL_NAME, that is needed as a single line from
-# nova-manage cell_v2 cell_list can match to multiple cells if the cell name
-# is part of the line, e.g. as the user name of the DB URL
-cell_uuid=$(nova-manage cell_v2 list_cells | tr ' ' '|' | tr --squeeze-repeats '|' | grep -e "^|$CELL_NAME|" | cut -d '|' -f 3)
+cell_uuid=${CELL_UUID}
+if [ -z "${cell_uuid}" ]; then
+    # NOTE(gibi): nova-manage should be enhanced upstream to get rid of this
+    # uglyness
+    # Note the "|" around the CELL_NAME, that is needed as a single line from
+    # nova-manage cell_v2 cell_list can match to multiple cells if the cell name
+    # is part of the line, e.g. as the user name of the DB URL
+    cell_uuid=$(nova-manage cell_v2 list_cells | tr ' ' '|' | tr --squeeze-repeats '|' | grep -e "^|$CELL_NAME|" | cut -d '|' -f 3)
+fi
 
-nova-manage cell_v2 delete_cell --cell_uuid "${cell_uuid}"
+if [ "${FORCE}" == "true" ]; then
+    echo "Forcing the deletion of cell ${CELL_NAME} (${cell_uuid})"
+    # The compute services are removed together with the resource providers
+    # of their compute nodes so nothing is left behind in placement. Then
+    # the cell is deleted with its host and instance mappings. A cell with
+    # instances is refused before anything is removed.
+    /bin/delete_cell_services.py "${cell_uuid}"
+    nova-manage cell_v2 delete_cell --force --cell_uuid "${cell_uuid}"
+    exit 0
+fi
+
+# The host mappings of the cell, the rows of the table after its header
+hosts=$(nova-manage cell_v2 list_hosts --cell_uuid "${cell_uuid}" | grep -e "^|" | tail -n +2 | cut -d '|' -f 4 | tr -d ' ' | paste -s -d ',')
+
+ret=0
+# delete_cell refuses to delete a cell that still has hosts or instances
+nova-manage cell_v2 delete_cell --cell_uuid "${cell_uuid}" || ret=$?
+case ${ret} in
+    0)
+        exit 0
+        ;;
+    2)
+        echo "cell ${CELL_NAME} has hosts: ${hosts}"
+        ;;
+    3)
+        echo "cell ${CELL_NAME} has instances on hosts: ${hosts}"
+        ;;
+    4)
+        echo "cell ${CELL_NAME} has instance mappings of deleted instances"
+        ;;
+esac
+exit ${ret}
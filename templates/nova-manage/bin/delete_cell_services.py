@@ -0,0 +1,70 @@
+#!/usr/bin/env python3
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Deletes the nova-compute services of a cell together with the resource
+# providers of their compute nodes and then the host mappings of the cell.
+# This is what the compute API does when a compute service is deleted but
+# the compute API cannot tell which services belong to a given cell. Nothing
+# is deleted if the cell still has instances as nova-manage cannot delete
+# such a cell even if forced. That exits with 3 like nova-manage cell_v2
+# delete_cell.
+
+import sys
+
+from nova import config
+from nova import context
+from nova import exception
+from nova import objects
+from nova.scheduler.client import report
+
+
+def main():
+    cell_uuid = sys.argv[1]
+    config.parse_args(sys.argv[:1])
+    objects.register_all()
+
+    ctxt = context.get_admin_context()
+    cell = objects.CellMapping.get_by_uuid(ctxt, cell_uuid)
+    placement = report.report_client_singleton()
+
+    with context.target_cell(ctxt, cell) as cctxt:
+        instances = objects.InstanceList.get_by_filters(
+            cctxt, {'deleted': False}, expected_attrs=[])
+        if instances:
+            hosts = sorted({i.host for i in instances if i.host})
+            print("cell %s has instances on hosts: %s" % (
+                cell.name, ','.join(hosts)), flush=True)
+            sys.exit(3)
+
+        services = objects.ServiceList.get_by_binary(
+            cctxt, 'nova-compute', include_disabled=True)
+        for service in services:
+            try:
+                nodes = objects.ComputeNodeList.get_all_by_host(
+                    cctxt, service.host)
+            except exception.ComputeHostNotFound:
+                nodes = []
+            for node in nodes:
+                placement.delete_resource_provider(cctxt, node, cascade=True)
+            service.destroy()
+            print("Deleted compute service of host %s" % service.host)
+
+    for host_mapping in objects.HostMappingList.get_by_cell_id(ctxt, cell.id):
+        host_mapping.destroy()
+        print("Deleted host mapping of host %s" % host_mapping.host)
+
+
+if __name__ == '__main__':
+    main()
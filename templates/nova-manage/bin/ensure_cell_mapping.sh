@@ -16,13 +16,24 @@
 set -xe
 
 export CELL_NAME=${CELL_NAME:?"Please specify a CELL_NAME variable."}
+export CELL_DISABLED=${CELL_DISABLED:-false}
+# Set when an existing cell mapping is adopted
+export CELL_UUID=${CELL_UUID:-}
 
 # NOTE(gibi): nova-manage should be enhanced upstream to get rid of this
 # uglyness
 # Note the "|" around the CELL_NAME, that is needed as a single line from
 # nova-manage cell_v2 cell_list can match to multiple cells if the cell name
 # is part of the line, e.g. as the user name of the DB URL
-cell_uuid=$(nova-manage cell_v2 list_cells | tr ' ' '|' | tr --squeeze-repeats '|' | grep -e "^|$CELL_NAME|" | cut -d '|' -f 3)
+function get_cell_uuid {
+    nova-manage cell_v2 list_cells | tr ' ' '|' | tr --squeeze-repeats '|' | grep -e "^|$CELL_NAME|" | cut -d '|' -f 3
+}
+
+if [ -n "${CELL_UUID}" ]; then
+    cell_uuid=${CELL_UUID}
+else
+    cell_uuid=$(get_cell_uuid)
+fi
 
 if [ -z "${cell_uuid}" ]; then
     if [ "${CELL_NAME}" = "cell0" ]; then
@@ -34,9 +45,28 @@ if [ -z "${cell_uuid}" ]; then
         # from the nova.conf
         nova-manage cell_v2 update_cell --cell_uuid 00000000-0000-0000-0000-000000000000
     else
-        nova-manage cell_v2 create_cell --name "${CELL_NAME}" --verbose
+        create_args=""
+        if [ "${CELL_DISABLED}" = "true" ]; then
+            create_args="--disabled"
+        fi
+        nova-manage cell_v2 create_cell --name "${CELL_NAME}" --verbose ${create_args}
     fi
 
 else
-    nova-manage cell_v2 update_cell --cell_uuid "${cell_uuid}"
+    # NOTE: cell0 is never disabled as it is not used for scheduling
+    if [ "${CELL_NAME}" = "cell0" ]; then
+        nova-manage cell_v2 update_cell --cell_uuid "${cell_uuid}"
+    elif [ "${CELL_DISABLED}" = "true" ]; then
+        nova-manage cell_v2 update_cell --cell_uuid "${cell_uuid}" --disable
+    else
+        nova-manage cell_v2 update_cell --cell_uuid "${cell_uuid}" --enable
+    fi
+fi
+
+if [ -z "${cell_uuid}" ]; then
+    cell_uuid=$(get_cell_uuid)
 fi
+# The cell mapping is reported to the operator via the termination message of
+# the pod so that the later jobs can target the cell by its UUID. The cell is
+# mapped at this point even if the report fails.
+/bin/report_cell_mapping.py "${cell_uuid}" > /dev/termination-log || true
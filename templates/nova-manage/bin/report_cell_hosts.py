@@ -0,0 +1,83 @@
+#!/usr/bin/env python3
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Reports the compute hosts mapped to the cell CELL_NAME in the nova_api
+# database as JSON to the termination message of the pod. A cell that is not
+# mapped yet has no hosts. The termination message is limited to 4096 bytes
+# so the newline separated host list is compressed and base64 encoded.
+
+import base64
+import json
+import os
+import subprocess
+import sys
+import zlib
+
+TERMINATION_LOG = '/dev/termination-log'
+
+
+def parse_table(output):
+    # Returns the columns of the rows of a nova-manage table, header included
+    rows = []
+    for line in output.splitlines():
+        if not line.startswith('|'):
+            continue
+        columns = line.strip().strip('|').split('|')
+        rows.append([column.strip() for column in columns])
+    return rows
+
+
+def get_cell_uuids(cell_name):
+    # The cells are listed with their name and UUID in the first two columns
+    output = subprocess.check_output(
+        ['nova-manage', 'cell_v2', 'list_cells'], universal_newlines=True)
+    return [row[1] for row in parse_table(output)
+            if row[0] == cell_name and row[1] != 'UUID']
+
+
+def get_mapped_hosts(cell_uuid):
+    # The hosts are printed in the last column of the table
+    output = subprocess.check_output(
+        ['nova-manage', 'cell_v2', 'list_hosts', '--cell_uuid', cell_uuid],
+        universal_newlines=True)
+    print(output, flush=True)
+    hosts = set()
+    for row in parse_table(output):
+        if row[-1] != 'Hostname':
+            hosts.add(row[-1])
+    return sorted(hosts)
+
+
+def main():
+    cell_name = os.environ['CELL_NAME']
+    cell_uuids = get_cell_uuids(cell_name)
+    if len(cell_uuids) > 1:
+        print('Cell %s is mapped more than once: %s' % (
+            cell_name, ', '.join(cell_uuids)), file=sys.stderr)
+        sys.exit(1)
+    hosts = []
+    if cell_uuids:
+        hosts = get_mapped_hosts(cell_uuids[0])
+    encoded = base64.b64encode(zlib.compress('\n'.join(hosts).encode()))
+    report = {'hostCount': len(hosts), 'hosts': encoded.decode()}
+    if len(json.dumps(report)) > 4096:
+        print('Too many hosts to report: %d' % len(hosts), file=sys.stderr)
+        sys.exit(1)
+    with open(TERMINATION_LOG, 'w') as f:
+        json.dump(report, f)
+
+
+if __name__ == '__main__':
+    main()
@@ -0,0 +1,53 @@
+#!/usr/bin/env python3
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Prints the cell mapping of a cell as JSON so that the operator can read it
+# from the termination message of the pod. Only the hosts of the transport URL
+# and the database connection are reported as the URLs contain credentials.
+
+import json
+import sys
+
+from oslo_config import cfg
+import oslo_messaging as messaging
+from sqlalchemy.engine import url as sqla_url
+
+from nova import config
+from nova import context
+from nova import objects
+
+
+def main():
+    cell_uuid = sys.argv[1]
+    config.parse_args(sys.argv[:1])
+    objects.register_all()
+
+    ctxt = context.get_admin_context()
+    cell = objects.CellMapping.get_by_uuid(ctxt, cell_uuid)
+
+    # cell0 has no message bus, its transport URL is none:///
+    transport_url = messaging.TransportURL.parse(cfg.CONF, cell.transport_url)
+    transport_hosts = [host.hostname for host in transport_url.hosts]
+    database_url = sqla_url.make_url(cell.database_connection)
+
+    print(json.dumps({
+        'uuid': cell.uuid,
+        'transportURLHost': ','.join(transport_hosts),
+        'databaseHost': database_url.host or '',
+    }))
+
+
+if __name__ == '__main__':
+    main()
@@ -0,0 +1,35 @@
+#!/bin/bash
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# NOTE: no -x here so that the log only contains the output of the command as
+# the end of it is used as the termination message of the pod.
+set -e -o pipefail
+
+export NOVA_MANAGE_COMMAND=${NOVA_MANAGE_COMMAND:?"Please specify a NOVA_MANAGE_COMMAND variable."}
+export NOVA_MANAGE_ARGS=${NOVA_MANAGE_ARGS:-[]}
+
+# The arguments are passed as a JSON list. They are read NUL separated so
+# that they are passed to nova-manage as is.
+mapfile -d '' -t args < <(python3 -c 'import json, os, sys; sys.stdout.write("".join(arg + "\0" for arg in json.loads(os.environ["NOVA_MANAGE_ARGS"])))')
+
+output=$(mktemp)
+ret=0
+# NOTE: NOVA_MANAGE_COMMAND is not quoted so that it is split to the command
+# and the subcommand
+nova-manage ${NOVA_MANAGE_COMMAND} "${args[@]}" 2>&1 | tee "${output}" || ret=$?
+
+# The termination message of a pod is limited to 4096 bytes
+tail -c 4096 "${output}" > /dev/termination-log
+exit ${ret}
@@ -0,0 +1,27 @@
+#!/bin/bash
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# NOTE: no -x here so that the end of the log only contains the report as
+# that is used as the termination message of the pod if the check fails.
+set -e
+
+ret=0
+nova-status upgrade check || ret=$?
+# 0 means every check passed
+# 1 means at least one check reported a warning but none of them failed
+if [ $ret -gt 1 ]; then
+    exit $ret
+fi
+exit 0
@@ -16,23 +16,42 @@
 set -x
 export ARCHIVE_AGE=${ARCHIVE_AGE:?"Please specify ARCHIVE_AGE variable."}
 export PURGE_AGE=${PURGE_AGE:?"Please specify PURGE_AGE variable."}
+export MAX_ROWS=${MAX_ROWS:-1000}
+export ARCHIVE_TASK_LOG=${ARCHIVE_TASK_LOG:-true}
 archive_before=$(date --date="${ARCHIVE_AGE} day ago" +%Y-%m-%d)
 purge_before=$(date --date="${PURGE_AGE} day ago" +%Y-%m-%d)
 
-nova-manage db archive_deleted_rows --verbose --until-complete --task-log --before "${archive_before}"
-ret=$?
+# The dry run only counts the rows that would be archived and purged
+if [ "${DRY_RUN}" == "true" ]; then
+    exec /bin/dbpurge_dry_run.py "${archive_before}" "${purge_before}" "${ARCHIVE_TASK_LOG}"
+fi
+
+archive_args=(--verbose --until-complete --max_rows "${MAX_ROWS}" --before "${archive_before}")
+if [ "${ARCHIVE_TASK_LOG}" == "true" ]; then
+    archive_args+=(--task-log)
+fi
+
+output=$(mktemp)
+nova-manage db archive_deleted_rows "${archive_args[@]}" | tee "${output}"
+ret=${PIPESTATUS[0]}
 # 0 means no error and nothing is archived
 # 1 means no error and someting is archived
 if [ $ret -gt "1" ]; then
     exit $ret
 fi
+# the archived rows are reported in a table per table
+archived=$(awk -F'|' '$3 ~ /^ *[0-9]+ *$/ {sum += $3} END {print sum + 0}' "${output}")
 
-nova-manage db purge --verbose --before "${purge_before}"
-ret=$?
+nova-manage db purge --verbose --before "${purge_before}" | tee "${output}"
+ret=${PIPESTATUS[0]}
 # 0 means no error and something is deleted
 # 3 means no error and nothing is deleted
 if [[ $ret -eq 1 || $ret -eq 2 || $ret -gt 3 ]]; then
     exit $ret
 fi
+purged=$(awk '/^Deleted [0-9]+ rows/ {sum += $2} END {print sum + 0}' "${output}")
 
+# The number of archived and purged rows are written to the termination
+# message of the pod so that the operator can report them
+echo "{\"archivedRows\": ${archived}, \"purgedRows\": ${purged}, \"dryRun\": false}" > /dev/termination-log
 exit 0
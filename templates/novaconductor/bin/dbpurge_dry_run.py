@@ -0,0 +1,74 @@
+#!/usr/bin/env python3
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+# Counts the rows of the cell DB that the DB purge would archive and purge
+# without changing the DB and writes the counts as JSON to the termination
+# message of the pod. The rows archived in the same run are not counted as
+# purged so the count is a lower bound if the archive and purge ages overlap.
+
+import json
+import sys
+
+import sqlalchemy as sa
+
+from nova import config
+from nova.db.main import api as main_db_api
+
+SHADOW_TABLE_PREFIX = 'shadow_'
+TERMINATION_LOG = '/dev/termination-log'
+
+
+def count(conn, table, *criteria):
+    query = sa.select(sa.func.count()).select_from(table).where(*criteria)
+    return conn.execute(query).scalar()
+
+
+def main():
+    archive_before, purge_before, archive_task_log = sys.argv[1:4]
+    config.parse_args(sys.argv[:1])
+
+    engine = main_db_api.get_engine()
+    metadata = sa.MetaData()
+    metadata.reflect(bind=engine)
+
+    archived = 0
+    purged = 0
+    with engine.connect() as conn:
+        for name, table in metadata.tables.items():
+            if name == 'task_log':
+                if archive_task_log == 'true':
+                    archived += count(
+                        conn, table, table.c.updated_at < archive_before)
+            elif name.startswith(SHADOW_TABLE_PREFIX):
+                # purge uses the first of these columns the table has
+                for column in ('deleted_at', 'updated_at', 'created_at'):
+                    if column in table.c:
+                        purged += count(
+                            conn, table, table.c[column] < purge_before)
+                        break
+            elif ('deleted' in table.c and 'deleted_at' in table.c and
+                    SHADOW_TABLE_PREFIX + name in metadata.tables):
+                archived += count(
+                    conn, table, table.c.deleted != 0,
+                    table.c.deleted_at < archive_before)
+
+    report = {'archivedRows': archived, 'purgedRows': purged, 'dryRun': True}
+    print(json.dumps(report), flush=True)
+    with open(TERMINATION_LOG, 'w') as f:
+        json.dump(report, f)
+
+
+if __name__ == '__main__':
+    main()
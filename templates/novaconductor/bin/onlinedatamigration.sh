@@ -0,0 +1,31 @@
+#!/bin/bash
+# Copyright 2024.
+#
+# Licensed under the Apache License, Version 2.0 (the "License");
+# you may not use this file except in compliance with the License.
+# You may obtain a copy of the License at
+#
+#     http://www.apache.org/licenses/LICENSE-2.0
+#
+# Unless required by applicable law or agreed to in writing, software
+# distributed under the License is distributed on an "AS IS" BASIS,
+# WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+# See the License for the specific language governing permissions and
+# limitations under the License.
+
+set -x
+export MAX_COUNT=${MAX_COUNT:-1000}
+
+# nova-manage returns 1 if it migrated some records but there are still
+# records left to migrate, 0 if there is nothing left to migrate and 2 if
+# some of the migrations failed. On failure the Job restarts the pod so the
+# migrations are retried.
+while true; do
+    nova-manage db online_data_migrations --max-count "${MAX_COUNT}"
+    rc=$?
+    if [ $rc -eq 0 ]; then
+        exit 0
+    elif [ $rc -ne 1 ]; then
+        exit $rc
+    fi
+done
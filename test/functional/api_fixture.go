@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/google/uuid"
 
 	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
 
@@ -63,10 +64,118 @@ type Service struct {
 	Zone string `json:"zone"`
 }
 
+// MarshalJSON adds the update time of the service in the format of the
+// compute API
+func (s Service) MarshalJSON() ([]byte, error) {
+	type service Service
+	var updatedAt *string
+	if !s.UpdatedAt.IsZero() {
+		t := s.UpdatedAt.UTC().Format("2006-01-02T15:04:05.000000")
+		updatedAt = &t
+	}
+	return json.Marshal(struct {
+		service
+		UpdatedAt *string `json:"updated_at"`
+	}{service(s), updatedAt})
+}
+
+// Flavor represents a flavor in the OpenStack cloud together with its extra
+// specs and the projects having access to it.
+type Flavor struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	VCPUs         int               `json:"vcpus"`
+	RAM           int               `json:"ram"`
+	Disk          int               `json:"disk"`
+	IsPublic      bool              `json:"os-flavor-access:is_public"`
+	ExtraSpecs    map[string]string `json:"extra_specs"`
+	ProjectAccess []string          `json:"-"`
+}
+
+type Aggregate struct {
+	ID               int               `json:"id"`
+	Name             string            `json:"name"`
+	AvailabilityZone string            `json:"availability_zone"`
+	Hosts            []string          `json:"hosts"`
+	Metadata         map[string]string `json:"metadata"`
+}
+
+// Server represents a server in the OpenStack cloud with the admin only
+// attributes needed to follow its live migration
+type Server struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Status    string            `json:"status"`
+	Host      string            `json:"OS-EXT-SRV-ATTR:host"`
+	TaskState *string           `json:"OS-EXT-STS:task_state"`
+	Fault     map[string]string `json:"fault,omitempty"`
+	Tags      []string          `json:"tags"`
+	Metadata  map[string]string `json:"metadata"`
+}
+
+// Hypervisor represents the resource totals and usage of a compute host as
+// reported by the os-hypervisors API before microversion 2.88
+type Hypervisor struct {
+	ID           string `json:"id"`
+	Hostname     string `json:"hypervisor_hostname"`
+	VCPUs        int    `json:"vcpus"`
+	VCPUsUsed    int    `json:"vcpus_used"`
+	MemoryMB     int    `json:"memory_mb"`
+	MemoryMBUsed int    `json:"memory_mb_used"`
+	LocalGB      int    `json:"local_gb"`
+	LocalGBUsed  int    `json:"local_gb_used"`
+	RunningVMs   int    `json:"running_vms"`
+}
+
+// MarshalJSON adds the compute service and the fields that are required by
+// the client but not relevant for the tests
+func (h Hypervisor) MarshalJSON() ([]byte, error) {
+	type hypervisor Hypervisor
+	return json.Marshal(struct {
+		hypervisor
+		Service            map[string]string `json:"service"`
+		CPUInfo            string            `json:"cpu_info"`
+		HypervisorVersion  int               `json:"hypervisor_version"`
+		FreeDiskGB         int               `json:"free_disk_gb"`
+		FreeRAMMB          int               `json:"free_ram_mb"`
+		State              string            `json:"state"`
+		Status             string            `json:"status"`
+		HypervisorType     string            `json:"hypervisor_type"`
+		DiskAvailableLeast int               `json:"disk_available_least"`
+	}{
+		hypervisor:        hypervisor(h),
+		Service:           map[string]string{"host": h.Hostname, "id": h.ID},
+		CPUInfo:           "{}",
+		HypervisorVersion: 1,
+		FreeDiskGB:        h.LocalGB - h.LocalGBUsed,
+		FreeRAMMB:         h.MemoryMB - h.MemoryMBUsed,
+		State:             "up",
+		Status:            "enabled",
+		HypervisorType:    "fake",
+	})
+}
+
 type NovaAPIFixture struct {
 	api.APIFixture
-	APIRequests []http.Request
-	Services    []Service
+	APIRequests     []http.Request
+	Services        []Service
+	Flavors         map[string]*Flavor
+	Aggregates      map[int]*Aggregate
+	lastAggregateID int
+	// QuotaClasses holds the limits of each quota class keyed by the
+	// compute API name of the limit
+	QuotaClasses map[string]map[string]int
+	// ProjectQuotas holds the project specific limits that override the
+	// default quota class
+	ProjectQuotas map[string]map[string]int
+	// ProjectUsage holds the resource usage of the projects
+	ProjectUsage map[string]map[string]int
+	Servers      map[string]*Server
+	Hypervisors  []Hypervisor
+	// ResourceProviders holds the UUIDs of the resource providers in
+	// placement. The compute node of a hypervisor has the same UUID as its
+	// resource provider.
+	ResourceProviders map[string]bool
 }
 
 func AddNovaAPIFixture(log logr.Logger, server *api.FakeAPIServer) *NovaAPIFixture {
@@ -78,6 +187,47 @@ func AddNovaAPIFixture(log logr.Logger, server *api.FakeAPIServer) *NovaAPIFixtu
 			OwnsServer: false,
 		},
 		APIRequests: []http.Request{},
+		Flavors:     map[string]*Flavor{},
+		Aggregates:  map[int]*Aggregate{},
+		QuotaClasses: map[string]map[string]int{
+			"default": {
+				"instances":            10,
+				"cores":                20,
+				"ram":                  51200,
+				"key_pairs":            100,
+				"metadata_items":       128,
+				"server_groups":        10,
+				"server_group_members": 10,
+			},
+		},
+		ProjectQuotas: map[string]map[string]int{},
+		ProjectUsage:  map[string]map[string]int{},
+		Servers:       map[string]*Server{},
+		ResourceProviders: map[string]bool{
+			"1": true,
+			"2": true,
+		},
+		Hypervisors: []Hypervisor{
+			{
+				ID:           "1",
+				Hostname:     "nova-compute-0",
+				VCPUs:        16,
+				VCPUsUsed:    4,
+				MemoryMB:     32768,
+				MemoryMBUsed: 8192,
+				LocalGB:      500,
+				LocalGBUsed:  40,
+				RunningVMs:   2,
+			},
+			{
+				ID:         "2",
+				Hostname:   "nova-compute-1",
+				VCPUs:      16,
+				MemoryMB:   32768,
+				LocalGB:    500,
+				RunningVMs: 0,
+			},
+		},
 		Services: []Service{
 			{
 				ID:         "1",
@@ -197,6 +347,16 @@ func (f *NovaAPIFixture) registerHandler(handler api.Handler) {
 
 func (f *NovaAPIFixture) registerNormalHandlers() {
 	f.registerHandler(api.Handler{Pattern: "/os-services/", Func: f.ServicesHandler})
+	f.registerHandler(api.Handler{Pattern: "/flavors", Func: f.FlavorsHandler})
+	f.registerHandler(api.Handler{Pattern: "/flavors/", Func: f.FlavorsHandler})
+	f.registerHandler(api.Handler{Pattern: "/os-aggregates", Func: f.AggregatesHandler})
+	f.registerHandler(api.Handler{Pattern: "/os-aggregates/", Func: f.AggregatesHandler})
+	f.registerHandler(api.Handler{Pattern: "/os-quota-class-sets/", Func: f.QuotaClassSetsHandler})
+	f.registerHandler(api.Handler{Pattern: "/os-quota-sets/", Func: f.QuotaSetsHandler})
+	f.registerHandler(api.Handler{Pattern: "/servers/", Func: f.ServersHandler})
+	f.registerHandler(api.Handler{Pattern: "/os-hypervisors/", Func: f.HypervisorsHandler})
+	// placement is served by the same server next to the compute API
+	f.Server.AddHandler("/placement/resource_providers/", f.ResourceProvidersHandler)
 }
 
 func (f *NovaAPIFixture) ServicesHandler(w http.ResponseWriter, r *http.Request) {
@@ -205,6 +365,8 @@ func (f *NovaAPIFixture) ServicesHandler(w http.ResponseWriter, r *http.Request)
 	switch r.Method {
 	case "GET":
 		f.getServices(w, r)
+	case "PUT":
+		f.updateService(w, r)
 	case "DELETE":
 		f.deleteService(w, r)
 	default:
@@ -217,7 +379,9 @@ func (f *NovaAPIFixture) getServices(w http.ResponseWriter, r *http.Request) {
 	services := []Service{}
 	for _, service := range f.Services {
 		binaryFilter := r.URL.Query().Get("binary")
-		if binaryFilter == "" || service.Binary == binaryFilter {
+		hostFilter := r.URL.Query().Get("host")
+		if (binaryFilter == "" || service.Binary == binaryFilter) &&
+			(hostFilter == "" || service.Host == hostFilter) {
 			services = append(services, service)
 		}
 	}
@@ -254,8 +418,700 @@ func (f *NovaAPIFixture) deleteService(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(404)
 }
 
-// ResponseHandleToken responds with a valid keystone token and the computeURL in the catalog
-func ResponseHandleToken(keystoneURL string, computeURL string) string {
+func (f *NovaAPIFixture) updateService(w http.ResponseWriter, r *http.Request) {
+	items := strings.Split(r.URL.Path, "/")
+	id := items[len(items)-1]
+
+	var body struct {
+		Status         string `json:"status"`
+		DisabledReason string `json:"disabled_reason"`
+		ForcedDown     *bool  `json:"forced_down"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		f.InternalError(err, "Error during unmarshalling request", w, r)
+		return
+	}
+	for i := range f.Services {
+		service := &f.Services[i]
+		if service.ID == id {
+			if body.Status != "" {
+				service.Status = body.Status
+				service.DisabledReason = body.DisabledReason
+			}
+			if body.ForcedDown != nil {
+				service.ForcedDown = *body.ForcedDown
+				if service.ForcedDown {
+					service.State = "down"
+				}
+			}
+			// like nova, saving the service record bumps its update time
+			service.UpdatedAt = time.Now()
+			f.respondJSON(w, r, 200, map[string]interface{}{"service": service})
+			return
+		}
+	}
+	w.WriteHeader(404)
+}
+
+// FindFlavorByName returns the flavor with the given name or nil if no such
+// flavor exists
+func (f *NovaAPIFixture) FindFlavorByName(name string) *Flavor {
+	for _, flavor := range f.Flavors {
+		if flavor.Name == name {
+			return flavor
+		}
+	}
+	return nil
+}
+
+func (f *NovaAPIFixture) FlavorsHandler(w http.ResponseWriter, r *http.Request) {
+	f.LogRequest(r)
+	f.RecordRequest(r)
+
+	// the path is /compute/flavors[/<id>[/<subresource>[/<key>]]]
+	items := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, f.URLBase+"/flavors"), "/"), "/")
+	switch {
+	case items[0] == "" && r.Method == "POST":
+		f.createFlavor(w, r)
+	case len(items) == 1 && items[0] == "detail" && r.Method == "GET":
+		f.listFlavors(w, r)
+	case len(items) == 1 && r.Method == "GET":
+		f.withFlavor(w, items[0], func(flavor *Flavor) {
+			f.respondJSON(w, r, 200, map[string]interface{}{"flavor": flavor})
+		})
+	case len(items) == 1 && r.Method == "DELETE":
+		f.withFlavor(w, items[0], func(flavor *Flavor) {
+			delete(f.Flavors, flavor.ID)
+			w.WriteHeader(202)
+		})
+	case len(items) == 2 && items[1] == "os-extra_specs":
+		f.withFlavor(w, items[0], func(flavor *Flavor) {
+			f.flavorExtraSpecs(w, r, flavor)
+		})
+	case len(items) == 3 && items[1] == "os-extra_specs" && r.Method == "DELETE":
+		f.withFlavor(w, items[0], func(flavor *Flavor) {
+			delete(flavor.ExtraSpecs, items[2])
+			w.WriteHeader(200)
+		})
+	case len(items) == 2 && items[1] == "os-flavor-access" && r.Method == "GET":
+		f.withFlavor(w, items[0], func(flavor *Flavor) {
+			f.respondJSON(w, r, 200, map[string]interface{}{"flavor_access": flavorAccess(flavor)})
+		})
+	case len(items) == 2 && items[1] == "action" && r.Method == "POST":
+		f.withFlavor(w, items[0], func(flavor *Flavor) {
+			f.flavorAction(w, r, flavor)
+		})
+	default:
+		f.UnexpectedRequest(w, r)
+	}
+}
+
+func (f *NovaAPIFixture) respondJSON(w http.ResponseWriter, r *http.Request, status int, body interface{}) {
+	bytes, err := json.Marshal(body)
+	if err != nil {
+		f.InternalError(err, "Error during marshalling response", w, r)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprint(w, string(bytes))
+}
+
+func (f *NovaAPIFixture) withFlavor(w http.ResponseWriter, id string, handle func(flavor *Flavor)) {
+	flavor, ok := f.Flavors[id]
+	if !ok {
+		w.WriteHeader(404)
+		return
+	}
+	handle(flavor)
+}
+
+func (f *NovaAPIFixture) createFlavor(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Flavor struct {
+			Name     string `json:"name"`
+			VCPUs    int    `json:"vcpus"`
+			RAM      int    `json:"ram"`
+			Disk     int    `json:"disk"`
+			IsPublic *bool  `json:"os-flavor-access:is_public"`
+		} `json:"flavor"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		f.InternalError(err, "Error during unmarshalling request", w, r)
+		return
+	}
+	if f.FindFlavorByName(body.Flavor.Name) != nil {
+		w.WriteHeader(409)
+		return
+	}
+
+	flavor := &Flavor{
+		ID:         uuid.New().String(),
+		Name:       body.Flavor.Name,
+		VCPUs:      body.Flavor.VCPUs,
+		RAM:        body.Flavor.RAM,
+		Disk:       body.Flavor.Disk,
+		IsPublic:   body.Flavor.IsPublic == nil || *body.Flavor.IsPublic,
+		ExtraSpecs: map[string]string{},
+	}
+	f.Flavors[flavor.ID] = flavor
+	f.respondJSON(w, r, 200, map[string]interface{}{"flavor": flavor})
+}
+
+func (f *NovaAPIFixture) listFlavors(w http.ResponseWriter, r *http.Request) {
+	flavors := []*Flavor{}
+	for _, flavor := range f.Flavors {
+		flavors = append(flavors, flavor)
+	}
+	f.respondJSON(w, r, 200, map[string]interface{}{"flavors": flavors})
+}
+
+func (f *NovaAPIFixture) flavorExtraSpecs(w http.ResponseWriter, r *http.Request, flavor *Flavor) {
+	switch r.Method {
+	case "GET":
+		f.respondJSON(w, r, 200, map[string]interface{}{"extra_specs": flavor.ExtraSpecs})
+	case "POST":
+		var body struct {
+			ExtraSpecs map[string]string `json:"extra_specs"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			f.InternalError(err, "Error during unmarshalling request", w, r)
+			return
+		}
+		for key, value := range body.ExtraSpecs {
+			flavor.ExtraSpecs[key] = value
+		}
+		f.respondJSON(w, r, 200, map[string]interface{}{"extra_specs": flavor.ExtraSpecs})
+	default:
+		f.UnexpectedRequest(w, r)
+	}
+}
+
+func flavorAccess(flavor *Flavor) []map[string]string {
+	access := []map[string]string{}
+	for _, project := range flavor.ProjectAccess {
+		access = append(access, map[string]string{"flavor_id": flavor.ID, "tenant_id": project})
+	}
+	return access
+}
+
+func (f *NovaAPIFixture) flavorAction(w http.ResponseWriter, r *http.Request, flavor *Flavor) {
+	var body struct {
+		AddTenantAccess *struct {
+			Tenant string `json:"tenant"`
+		} `json:"addTenantAccess"`
+		RemoveTenantAccess *struct {
+			Tenant string `json:"tenant"`
+		} `json:"removeTenantAccess"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		f.InternalError(err, "Error during unmarshalling request", w, r)
+		return
+	}
+	if flavor.IsPublic {
+		w.WriteHeader(409)
+		return
+	}
+	switch {
+	case body.AddTenantAccess != nil:
+		flavor.ProjectAccess = append(flavor.ProjectAccess, body.AddTenantAccess.Tenant)
+	case body.RemoveTenantAccess != nil:
+		projects := []string{}
+		for _, project := range flavor.ProjectAccess {
+			if project != body.RemoveTenantAccess.Tenant {
+				projects = append(projects, project)
+			}
+		}
+		flavor.ProjectAccess = projects
+	default:
+		f.UnexpectedRequest(w, r)
+		return
+	}
+	f.respondJSON(w, r, 200, map[string]interface{}{"flavor_access": flavorAccess(flavor)})
+}
+
+// FindAggregateByName returns the aggregate with the given name or nil if no
+// such aggregate exists
+func (f *NovaAPIFixture) FindAggregateByName(name string) *Aggregate {
+	for _, aggregate := range f.Aggregates {
+		if aggregate.Name == name {
+			return aggregate
+		}
+	}
+	return nil
+}
+
+// AddAggregate adds an aggregate to the fixture state and returns it
+func (f *NovaAPIFixture) AddAggregate(name string, availabilityZone string, hosts ...string) *Aggregate {
+	f.lastAggregateID++
+	aggregate := &Aggregate{
+		ID:               f.lastAggregateID,
+		Name:             name,
+		AvailabilityZone: availabilityZone,
+		Hosts:            hosts,
+		Metadata:         map[string]string{},
+	}
+	if availabilityZone != "" {
+		aggregate.Metadata["availability_zone"] = availabilityZone
+	}
+	f.Aggregates[aggregate.ID] = aggregate
+	return aggregate
+}
+
+func (f *NovaAPIFixture) AggregatesHandler(w http.ResponseWriter, r *http.Request) {
+	f.LogRequest(r)
+	f.RecordRequest(r)
+
+	// the path is /compute/os-aggregates[/<id>[/action]]
+	items := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, f.URLBase+"/os-aggregates"), "/"), "/")
+	switch {
+	case items[0] == "" && r.Method == "GET":
+		aggregates := []*Aggregate{}
+		for _, aggregate := range f.Aggregates {
+			aggregates = append(aggregates, aggregate)
+		}
+		f.respondJSON(w, r, 200, map[string]interface{}{"aggregates": aggregates})
+	case items[0] == "" && r.Method == "POST":
+		f.createAggregate(w, r)
+	case len(items) == 1 && r.Method == "GET":
+		f.withAggregate(w, items[0], func(aggregate *Aggregate) {
+			f.respondJSON(w, r, 200, map[string]interface{}{"aggregate": aggregate})
+		})
+	case len(items) == 1 && r.Method == "PUT":
+		f.withAggregate(w, items[0], func(aggregate *Aggregate) {
+			f.updateAggregate(w, r, aggregate)
+		})
+	case len(items) == 1 && r.Method == "DELETE":
+		f.withAggregate(w, items[0], func(aggregate *Aggregate) {
+			if len(aggregate.Hosts) > 0 {
+				w.WriteHeader(400)
+				return
+			}
+			delete(f.Aggregates, aggregate.ID)
+			w.WriteHeader(200)
+		})
+	case len(items) == 2 && items[1] == "action" && r.Method == "POST":
+		f.withAggregate(w, items[0], func(aggregate *Aggregate) {
+			f.aggregateAction(w, r, aggregate)
+		})
+	default:
+		f.UnexpectedRequest(w, r)
+	}
+}
+
+func (f *NovaAPIFixture) withAggregate(w http.ResponseWriter, id string, handle func(aggregate *Aggregate)) {
+	for _, aggregate := range f.Aggregates {
+		if fmt.Sprint(aggregate.ID) == id {
+			handle(aggregate)
+			return
+		}
+	}
+	w.WriteHeader(404)
+}
+
+func (f *NovaAPIFixture) createAggregate(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Aggregate struct {
+			Name             string `json:"name"`
+			AvailabilityZone string `json:"availability_zone"`
+		} `json:"aggregate"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		f.InternalError(err, "Error during unmarshalling request", w, r)
+		return
+	}
+	if f.FindAggregateByName(body.Aggregate.Name) != nil {
+		w.WriteHeader(409)
+		return
+	}
+	aggregate := f.AddAggregate(body.Aggregate.Name, body.Aggregate.AvailabilityZone)
+	f.respondJSON(w, r, 200, map[string]interface{}{"aggregate": aggregate})
+}
+
+func (f *NovaAPIFixture) updateAggregate(w http.ResponseWriter, r *http.Request, aggregate *Aggregate) {
+	var body struct {
+		Aggregate struct {
+			Name             string `json:"name"`
+			AvailabilityZone string `json:"availability_zone"`
+		} `json:"aggregate"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		f.InternalError(err, "Error during unmarshalling request", w, r)
+		return
+	}
+	if body.Aggregate.Name != "" {
+		aggregate.Name = body.Aggregate.Name
+	}
+	if body.Aggregate.AvailabilityZone != "" {
+		aggregate.AvailabilityZone = body.Aggregate.AvailabilityZone
+		aggregate.Metadata["availability_zone"] = body.Aggregate.AvailabilityZone
+	}
+	f.respondJSON(w, r, 200, map[string]interface{}{"aggregate": aggregate})
+}
+
+func (f *NovaAPIFixture) aggregateAction(w http.ResponseWriter, r *http.Request, aggregate *Aggregate) {
+	var body struct {
+		AddHost *struct {
+			Host string `json:"host"`
+		} `json:"add_host"`
+		RemoveHost *struct {
+			Host string `json:"host"`
+		} `json:"remove_host"`
+		SetMetadata *struct {
+			Metadata map[string]*string `json:"metadata"`
+		} `json:"set_metadata"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		f.InternalError(err, "Error during unmarshalling request", w, r)
+		return
+	}
+	switch {
+	case body.AddHost != nil:
+		for _, host := range aggregate.Hosts {
+			if host == body.AddHost.Host {
+				w.WriteHeader(409)
+				return
+			}
+		}
+		// like nova a host cannot be in two availability zones
+		for _, other := range f.Aggregates {
+			if other.ID == aggregate.ID || other.AvailabilityZone == "" ||
+				aggregate.AvailabilityZone == "" || other.AvailabilityZone == aggregate.AvailabilityZone {
+				continue
+			}
+			for _, host := range other.Hosts {
+				if host == body.AddHost.Host {
+					f.respondJSON(w, r, 400, map[string]interface{}{
+						"badRequest": map[string]interface{}{
+							"code":    400,
+							"message": "Cannot add host to aggregate " + fmt.Sprint(aggregate.ID),
+						},
+					})
+					return
+				}
+			}
+		}
+		aggregate.Hosts = append(aggregate.Hosts, body.AddHost.Host)
+	case body.RemoveHost != nil:
+		hosts := []string{}
+		for _, host := range aggregate.Hosts {
+			if host != body.RemoveHost.Host {
+				hosts = append(hosts, host)
+			}
+		}
+		if len(hosts) == len(aggregate.Hosts) {
+			w.WriteHeader(404)
+			return
+		}
+		aggregate.Hosts = hosts
+	case body.SetMetadata != nil:
+		for key, value := range body.SetMetadata.Metadata {
+			if value == nil {
+				delete(aggregate.Metadata, key)
+			} else {
+				aggregate.Metadata[key] = *value
+			}
+		}
+		aggregate.AvailabilityZone = aggregate.Metadata["availability_zone"]
+	default:
+		f.UnexpectedRequest(w, r)
+		return
+	}
+	f.respondJSON(w, r, 200, map[string]interface{}{"aggregate": aggregate})
+}
+
+func (f *NovaAPIFixture) QuotaClassSetsHandler(w http.ResponseWriter, r *http.Request) {
+	f.LogRequest(r)
+	f.RecordRequest(r)
+
+	// the path is /compute/os-quota-class-sets/<name>
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, f.URLBase+"/os-quota-class-sets"), "/")
+	// like nova, return the defaults for unknown quota classes
+	limits := map[string]int{}
+	for key, value := range f.QuotaClasses["default"] {
+		limits[key] = value
+	}
+	for key, value := range f.QuotaClasses[name] {
+		limits[key] = value
+	}
+	switch r.Method {
+	case "GET":
+		f.respondJSON(w, r, 200, map[string]interface{}{"quota_class_set": limits})
+	case "PUT":
+		var body struct {
+			QuotaClassSet map[string]int `json:"quota_class_set"`
+		}
+		err := json.NewDecoder(r.Body).Decode(&body)
+		if err != nil {
+			f.InternalError(err, "Error during unmarshalling request", w, r)
+			return
+		}
+		for key, value := range body.QuotaClassSet {
+			limits[key] = value
+		}
+		f.QuotaClasses[name] = limits
+		f.respondJSON(w, r, 200, map[string]interface{}{"quota_class_set": limits})
+	default:
+		f.UnexpectedRequest(w, r)
+	}
+}
+
+// getProjectLimits returns the effective limits of the project
+func (f *NovaAPIFixture) getProjectLimits(projectID string) map[string]int {
+	limits := map[string]int{}
+	for key, value := range f.QuotaClasses["default"] {
+		limits[key] = value
+	}
+	for key, value := range f.ProjectQuotas[projectID] {
+		limits[key] = value
+	}
+	return limits
+}
+
+func (f *NovaAPIFixture) QuotaSetsHandler(w http.ResponseWriter, r *http.Request) {
+	f.LogRequest(r)
+	f.RecordRequest(r)
+
+	// the path is /compute/os-quota-sets/<project>[/detail]
+	items := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, f.URLBase+"/os-quota-sets"), "/"), "/")
+	projectID := items[0]
+	switch {
+	case len(items) == 1 && r.Method == "GET":
+		quotaSet := map[string]interface{}{"id": projectID}
+		for key, value := range f.getProjectLimits(projectID) {
+			quotaSet[key] = value
+		}
+		f.respondJSON(w, r, 200, map[string]interface{}{"quota_set": quotaSet})
+	case len(items) == 2 && items[1] == "detail" && r.Method == "GET":
+		quotaSet := map[string]interface{}{"id": projectID}
+		for key, value := range f.getProjectLimits(projectID) {
+			quotaSet[key] = map[string]int{
+				"limit":    value,
+				"in_use":   f.ProjectUsage[projectID][key],
+				"reserved": 0,
+			}
+		}
+		f.respondJSON(w, r, 200, map[string]interface{}{"quota_set": quotaSet})
+	case len(items) == 1 && r.Method == "PUT":
+		f.updateQuotaSet(w, r, projectID)
+	case len(items) == 1 && r.Method == "DELETE":
+		delete(f.ProjectQuotas, projectID)
+		w.WriteHeader(202)
+	default:
+		f.UnexpectedRequest(w, r)
+	}
+}
+
+func (f *NovaAPIFixture) updateQuotaSet(w http.ResponseWriter, r *http.Request, projectID string) {
+	var body struct {
+		QuotaSet map[string]interface{} `json:"quota_set"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		f.InternalError(err, "Error during unmarshalling request", w, r)
+		return
+	}
+	force, _ := body.QuotaSet["force"].(bool)
+	delete(body.QuotaSet, "force")
+
+	limits := map[string]int{}
+	for key, value := range body.QuotaSet {
+		limit := int(value.(float64))
+		// like nova, reject limits below the current usage unless forced
+		if !force && limit != -1 && limit < f.ProjectUsage[projectID][key] {
+			w.WriteHeader(400)
+			return
+		}
+		limits[key] = limit
+	}
+	if f.ProjectQuotas[projectID] == nil {
+		f.ProjectQuotas[projectID] = map[string]int{}
+	}
+	for key, value := range limits {
+		f.ProjectQuotas[projectID][key] = value
+	}
+	f.respondJSON(w, r, 200, map[string]interface{}{"quota_set": f.getProjectLimits(projectID)})
+}
+
+// SimulateServiceHeartbeat simulates that the service reports its state
+// so its update time is bumped
+func (f *NovaAPIFixture) SimulateServiceHeartbeat(id string) {
+	for i := range f.Services {
+		if f.Services[i].ID == id {
+			f.Services[i].UpdatedAt = time.Now().Add(time.Minute)
+		}
+	}
+}
+
+// AddServer adds an active server to the host
+func (f *NovaAPIFixture) AddServer(id string, name string, host string) *Server {
+	server := &Server{
+		ID:       id,
+		Name:     name,
+		Status:   "ACTIVE",
+		Host:     host,
+		Tags:     []string{},
+		Metadata: map[string]string{},
+	}
+	f.Servers[id] = server
+	return server
+}
+
+// CompleteLiveMigration simulates that the live migration of the server
+// finished successfully
+func (f *NovaAPIFixture) CompleteLiveMigration(id string, host string) {
+	server := f.Servers[id]
+	server.Host = host
+	server.TaskState = nil
+}
+
+// FailLiveMigration simulates that the live migration of the server failed
+// and the server stayed on its host
+func (f *NovaAPIFixture) FailLiveMigration(id string) {
+	f.Servers[id].TaskState = nil
+}
+
+// CompleteEvacuation simulates that the evacuation of the server finished
+// successfully
+func (f *NovaAPIFixture) CompleteEvacuation(id string, host string) {
+	f.CompleteLiveMigration(id, host)
+}
+
+// FailEvacuation simulates that the evacuation of the server failed and the
+// server is left in ERROR state on its host
+func (f *NovaAPIFixture) FailEvacuation(id string, message string) {
+	server := f.Servers[id]
+	server.Status = "ERROR"
+	server.TaskState = nil
+	server.Fault = map[string]string{"message": message}
+}
+
+// hasTags returns true if the server has all the tags
+func (s *Server) hasTags(tags []string) bool {
+	for _, tag := range tags {
+		found := false
+		for _, serverTag := range s.Tags {
+			found = found || serverTag == tag
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *NovaAPIFixture) ServersHandler(w http.ResponseWriter, r *http.Request) {
+	f.LogRequest(r)
+	f.RecordRequest(r)
+
+	// the path is /compute/servers/<id>[/action] or /compute/servers/detail
+	items := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, f.URLBase+"/servers"), "/"), "/")
+	switch {
+	case len(items) == 1 && items[0] == "detail" && r.Method == "GET":
+		hostFilter := r.URL.Query().Get("host")
+		tagsFilter := []string{}
+		if tags := r.URL.Query().Get("tags"); tags != "" {
+			tagsFilter = strings.Split(tags, ",")
+		}
+		servers := []*Server{}
+		for _, server := range f.Servers {
+			if (hostFilter == "" || server.Host == hostFilter) && server.hasTags(tagsFilter) {
+				servers = append(servers, server)
+			}
+		}
+		f.respondJSON(w, r, 200, map[string]interface{}{"servers": servers})
+	case len(items) == 2 && items[1] == "action" && r.Method == "POST":
+		server, ok := f.Servers[items[0]]
+		if !ok {
+			w.WriteHeader(404)
+			return
+		}
+		f.serverAction(w, r, server)
+	default:
+		f.UnexpectedRequest(w, r)
+	}
+}
+
+func (f *NovaAPIFixture) serverAction(w http.ResponseWriter, r *http.Request, server *Server) {
+	var body struct {
+		MigrateLive *struct {
+			Host           *string `json:"host"`
+			BlockMigration string  `json:"block_migration"`
+		} `json:"os-migrateLive"`
+		Evacuate *struct {
+			Host *string `json:"host"`
+		} `json:"evacuate"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&body)
+	if err != nil {
+		f.InternalError(err, "Error during unmarshalling request", w, r)
+		return
+	}
+	switch {
+	case body.MigrateLive != nil:
+		if server.Status != "ACTIVE" && server.Status != "PAUSED" {
+			w.WriteHeader(409)
+			return
+		}
+		migrating := "migrating"
+		server.TaskState = &migrating
+		w.WriteHeader(202)
+	case body.Evacuate != nil:
+		if server.Status != "ACTIVE" && server.Status != "SHUTOFF" && server.Status != "ERROR" {
+			w.WriteHeader(409)
+			return
+		}
+		// like nova, only allow evacuation if the compute service is down
+		for _, service := range f.Services {
+			if service.Binary == "nova-compute" && service.Host == server.Host && service.State == "up" {
+				w.WriteHeader(409)
+				return
+			}
+		}
+		rebuilding := "rebuilding"
+		server.TaskState = &rebuilding
+		w.WriteHeader(200)
+	default:
+		f.UnexpectedRequest(w, r)
+	}
+}
+
+func (f *NovaAPIFixture) HypervisorsHandler(w http.ResponseWriter, r *http.Request) {
+	f.LogRequest(r)
+	f.RecordRequest(r)
+	if r.Method != "GET" || r.URL.Path != f.URLBase+"/os-hypervisors/detail" {
+		f.UnexpectedRequest(w, r)
+		return
+	}
+	f.respondJSON(w, r, 200, map[string]interface{}{"hypervisors": f.Hypervisors})
+}
+
+func (f *NovaAPIFixture) ResourceProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	f.LogRequest(r)
+	f.RecordRequest(r)
+	if r.Method != "DELETE" {
+		f.UnexpectedRequest(w, r)
+		return
+	}
+	uuid := strings.TrimPrefix(r.URL.Path, "/placement/resource_providers/")
+	if !f.ResourceProviders[uuid] {
+		w.WriteHeader(404)
+		return
+	}
+	delete(f.ResourceProviders, uuid)
+	w.WriteHeader(204)
+}
+
+// ResponseHandleToken responds with a valid keystone token and the
+// computeURL and placementURL in the catalog
+func ResponseHandleToken(keystoneURL string, computeURL string, placementURL string) string {
 	return fmt.Sprintf(
 		`
 			{
@@ -297,11 +1153,26 @@ func ResponseHandleToken(keystoneURL string, computeURL string) string {
 						"id":"76086b1494bd497dbe7d45c53bd0cc70",
 						"type":"compute",
 						"name":"nova"
+					},
+					{
+						"endpoints":[
+							{
+								"name":"placement",
+								"id":"2f8d3a0e1b6c4a59a9f0c3e7d4b1a6c2",
+								"interface":"internal",
+								"region_id":"regionOne",
+								"url":"%s",
+								"region":"regionOne"
+							}
+						],
+						"id":"9c1e7b3a5d2f4e6a8b0c1d2e3f4a5b6c",
+						"type":"placement",
+						"name":"placement"
 					}
 				   	]
 				}
 			 }
-			`, keystoneURL, computeURL, computeURL)
+			`, keystoneURL, computeURL, computeURL, placementURL)
 }
 
 // SetupAPIFixture creates both keystone and nova API server simulators
@@ -321,7 +1192,9 @@ func SetupAPIFixtures(logger logr.Logger) (*keystone_helper.KeystoneAPIFixture,
 				w.Header().Add("Content-Type", "application/json")
 				w.WriteHeader(202)
 				// ensure keystone returns the simulator endpoints in its catalog
-				fmt.Fprint(w, ResponseHandleToken(keystone.Endpoint(), novaAPIServer.Endpoint()))
+				fmt.Fprint(w, ResponseHandleToken(
+					keystone.Endpoint(), novaAPIServer.Endpoint(),
+					novaAPIServer.Server.Endpoint()+"/placement"))
 			}
 		}})
 	DeferCleanup(keystone.Cleanup)
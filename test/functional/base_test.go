@@ -254,6 +254,30 @@ func CreateNovaCell(name types.NamespacedName, spec map[string]interface{}) clie
 	return th.CreateUnstructured(raw)
 }
 
+// CreateNovaCellOwnedByNova creates a NovaCell that is controlled by a Nova
+// CR named by novaNames without creating the Nova CR itself
+func CreateNovaCellOwnedByNova(name types.NamespacedName, spec map[string]interface{}, novaUID string) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaCell",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+			"ownerReferences": []interface{}{
+				map[string]interface{}{
+					"apiVersion": "nova.openstack.org/v1beta1",
+					"kind":       "Nova",
+					"name":       novaNames.NovaName.Name,
+					"uid":        novaUID,
+					"controller": true,
+				},
+			},
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
 func GetNovaCell(name types.NamespacedName) *novav1.NovaCell {
 	instance := &novav1.NovaCell{}
 	Eventually(func(g Gomega) {
@@ -358,6 +382,9 @@ type CellNames struct {
 	NovaComputeConfigDataName        types.NamespacedName
 	HostDiscoveryJobName             types.NamespacedName
 	DBPurgeCronJobName               types.NamespacedName
+	AuditCronJobName                 types.NamespacedName
+	HostsCronJobName                 types.NamespacedName
+	OnlineDataMigrationJobName       types.NamespacedName
 }
 
 func GetCellNames(novaName types.NamespacedName, cell string) CellNames {
@@ -403,6 +430,10 @@ func GetCellNames(novaName types.NamespacedName, cell string) CellNames {
 			Name:      cellConductor.Name + "-db-sync",
 		},
 		ConductorStatefulSetName: cellConductor,
+		OnlineDataMigrationJobName: types.NamespacedName{
+			Namespace: novaName.Namespace,
+			Name:      cellConductor.Name + "-online-data-migration",
+		},
 		TransportURLName: types.NamespacedName{
 			Namespace: novaName.Namespace,
 			Name:      cellName.Name + "-transport",
@@ -462,6 +493,14 @@ func GetCellNames(novaName types.NamespacedName, cell string) CellNames {
 			Namespace: novaName.Namespace,
 			Name:      cellName.Name + "-db-purge",
 		},
+		AuditCronJobName: types.NamespacedName{
+			Namespace: novaName.Namespace,
+			Name:      cellName.Name + "-audit",
+		},
+		HostsCronJobName: types.NamespacedName{
+			Namespace: novaName.Namespace,
+			Name:      cellName.Name + "-hosts",
+		},
 	}
 
 	if cell == "cell0" {
@@ -487,6 +526,7 @@ type NovaNames struct {
 	APIStatefulSetName             types.NamespacedName
 	APIKeystoneEndpointName        types.NamespacedName
 	APIConfigDataName              types.NamespacedName
+	APIKnownGoodConfigDataName     types.NamespacedName
 	InternalCertSecretName         types.NamespacedName
 	PublicCertSecretName           types.NamespacedName
 	CaBundleSecretName             types.NamespacedName
@@ -506,6 +546,8 @@ type NovaNames struct {
 	InternalNovaMetadataServiceName types.NamespacedName
 	InternalTopLevelSecretName      types.NamespacedName
 	MemcachedNamespace              types.NamespacedName
+	UpgradeCheckJobName             types.NamespacedName
+	AdoptionCheckJobName            types.NamespacedName
 	Cells                           map[string]CellNames
 	NovaTopologies                  []types.NamespacedName
 }
@@ -566,6 +608,10 @@ func GetNovaNames(novaName types.NamespacedName, cellNames []string) NovaNames {
 			Namespace: novaAPI.Namespace,
 			Name:      novaAPI.Name + "-config-data",
 		},
+		APIKnownGoodConfigDataName: types.NamespacedName{
+			Namespace: novaAPI.Namespace,
+			Name:      novaAPI.Name + "-config-data-known-good",
+		},
 		InternalCertSecretName: types.NamespacedName{
 			Namespace: novaAPI.Namespace,
 			Name:      "internal-tls-certs"},
@@ -619,6 +665,14 @@ func GetNovaNames(novaName types.NamespacedName, cellNames []string) NovaNames {
 			Name:      MemcachedInstance,
 			Namespace: novaName.Namespace,
 		},
+		UpgradeCheckJobName: types.NamespacedName{
+			Namespace: novaName.Namespace,
+			Name:      novaName.Name + "-upgrade-check",
+		},
+		AdoptionCheckJobName: types.NamespacedName{
+			Namespace: novaName.Namespace,
+			Name:      novaName.Name + "-adoption-check",
+		},
 		Cells: cells,
 		NovaTopologies: []types.NamespacedName{
 			{
@@ -914,3 +968,311 @@ func GetSampleTopologySpec(label string) (map[string]interface{}, []corev1.Topol
 	}
 	return topologySpec, topologySpecObj
 }
+
+func GetDefaultNovaFlavorSpec(novaNames NovaNames) map[string]interface{} {
+	return map[string]interface{}{
+		"novaInstance": novaNames.NovaName.Name,
+		"vcpus":        2,
+		"ram":          4096,
+		"disk":         20,
+	}
+}
+
+func CreateNovaFlavor(name types.NamespacedName, spec map[string]interface{}) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaFlavor",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetNovaFlavor(name types.NamespacedName) *novav1.NovaFlavor {
+	instance := &novav1.NovaFlavor{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func NovaFlavorConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetNovaFlavor(name)
+	return instance.Status.Conditions
+}
+
+func GetDefaultNovaAggregateSpec(novaNames NovaNames) map[string]interface{} {
+	return map[string]interface{}{
+		"novaInstance": novaNames.NovaName.Name,
+	}
+}
+
+func CreateNovaAggregate(name types.NamespacedName, spec map[string]interface{}) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaAggregate",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetNovaAggregate(name types.NamespacedName) *novav1.NovaAggregate {
+	instance := &novav1.NovaAggregate{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func NovaAggregateNotExists(name types.NamespacedName) {
+	Consistently(func(g Gomega) {
+		instance := &novav1.NovaAggregate{}
+		err := k8sClient.Get(ctx, name, instance)
+		g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+	}, consistencyTimeout, interval).Should(Succeed())
+}
+
+func NovaAggregateConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetNovaAggregate(name)
+	return instance.Status.Conditions
+}
+
+func GetDefaultNovaQuotaClassSpec(novaNames NovaNames) map[string]interface{} {
+	return map[string]interface{}{
+		"novaInstance": novaNames.NovaName.Name,
+		"instances":    20,
+	}
+}
+
+func CreateNovaQuotaClass(name types.NamespacedName, spec map[string]interface{}) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaQuotaClass",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetNovaQuotaClass(name types.NamespacedName) *novav1.NovaQuotaClass {
+	instance := &novav1.NovaQuotaClass{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func NovaQuotaClassConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetNovaQuotaClass(name)
+	return instance.Status.Conditions
+}
+
+func GetDefaultNovaProjectQuotaSpec(novaNames NovaNames, projectID string) map[string]interface{} {
+	return map[string]interface{}{
+		"novaInstance": novaNames.NovaName.Name,
+		"projectID":    projectID,
+		"instances":    5,
+	}
+}
+
+func CreateNovaProjectQuota(name types.NamespacedName, spec map[string]interface{}) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaProjectQuota",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetNovaProjectQuota(name types.NamespacedName) *novav1.NovaProjectQuota {
+	instance := &novav1.NovaProjectQuota{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func NovaProjectQuotaConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetNovaProjectQuota(name)
+	return instance.Status.Conditions
+}
+
+func GetDefaultNovaHostDrainSpec(novaNames NovaNames, host string) map[string]interface{} {
+	return map[string]interface{}{
+		"novaInstance": novaNames.NovaName.Name,
+		"host":         host,
+	}
+}
+
+func CreateNovaHostDrain(name types.NamespacedName, spec map[string]interface{}) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaHostDrain",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetNovaHostDrain(name types.NamespacedName) *novav1.NovaHostDrain {
+	instance := &novav1.NovaHostDrain{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func NovaHostDrainConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetNovaHostDrain(name)
+	return instance.Status.Conditions
+}
+
+func GetDefaultNovaManageCommandSpec(novaNames NovaNames, cellName string) map[string]interface{} {
+	return map[string]interface{}{
+		"novaInstance": novaNames.NovaName.Name,
+		"cellName":     cellName,
+		"command":      "placement heal_allocations",
+	}
+}
+
+func CreateNovaManageCommand(name types.NamespacedName, spec map[string]interface{}) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaManageCommand",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetNovaManageCommand(name types.NamespacedName) *novav1.NovaManageCommand {
+	instance := &novav1.NovaManageCommand{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func NovaManageCommandConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetNovaManageCommand(name)
+	return instance.Status.Conditions
+}
+
+func GetDefaultNovaDatabaseBackupSpec(novaNames NovaNames) map[string]interface{} {
+	return map[string]interface{}{
+		"novaInstance":   novaNames.NovaName.Name,
+		"storageRequest": "1G",
+	}
+}
+
+func CreateNovaDatabaseBackup(name types.NamespacedName, spec map[string]interface{}) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaDatabaseBackup",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetNovaDatabaseBackup(name types.NamespacedName) *novav1.NovaDatabaseBackup {
+	instance := &novav1.NovaDatabaseBackup{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func NovaDatabaseBackupConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetNovaDatabaseBackup(name)
+	return instance.Status.Conditions
+}
+
+func GetDefaultNovaDatabaseRestoreSpec(novaNames NovaNames, backupName string) map[string]interface{} {
+	return map[string]interface{}{
+		"novaInstance": novaNames.NovaName.Name,
+		"backupName":   backupName,
+	}
+}
+
+func CreateNovaDatabaseRestore(name types.NamespacedName, spec map[string]interface{}) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaDatabaseRestore",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetNovaDatabaseRestore(name types.NamespacedName) *novav1.NovaDatabaseRestore {
+	instance := &novav1.NovaDatabaseRestore{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func NovaDatabaseRestoreConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetNovaDatabaseRestore(name)
+	return instance.Status.Conditions
+}
+
+func GetDefaultNovaHostEvacuationSpec(novaNames NovaNames, host string) map[string]interface{} {
+	return map[string]interface{}{
+		"novaInstance": novaNames.NovaName.Name,
+		"host":         host,
+	}
+}
+
+func CreateNovaHostEvacuation(name types.NamespacedName, spec map[string]interface{}) client.Object {
+	raw := map[string]interface{}{
+		"apiVersion": "nova.openstack.org/v1beta1",
+		"kind":       "NovaHostEvacuation",
+		"metadata": map[string]interface{}{
+			"name":      name.Name,
+			"namespace": name.Namespace,
+		},
+		"spec": spec,
+	}
+	return th.CreateUnstructured(raw)
+}
+
+func GetNovaHostEvacuation(name types.NamespacedName) *novav1.NovaHostEvacuation {
+	instance := &novav1.NovaHostEvacuation{}
+	Eventually(func(g Gomega) {
+		g.Expect(k8sClient.Get(ctx, name, instance)).Should(Succeed())
+	}, timeout, interval).Should(Succeed())
+	return instance
+}
+
+func NovaHostEvacuationConditionGetter(name types.NamespacedName) condition.Conditions {
+	instance := GetNovaHostEvacuation(name)
+	return instance.Status.Conditions
+}
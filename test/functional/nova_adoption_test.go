@@ -0,0 +1,256 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const cell0UUID = "00000000-0000-0000-0000-000000000000"
+
+// CreateNovaForAdoption creates a Nova with cell0 and cell1 in adoption mode
+// and simulates that the DBs and the message buses are ready
+func CreateNovaForAdoption(novaNames NovaNames) {
+	cell0 := novaNames.Cells["cell0"]
+	cell1 := novaNames.Cells["cell1"]
+
+	DeferCleanup(k8sClient.Delete, ctx, CreateNovaSecret(novaNames.NovaName.Namespace, SecretName))
+	DeferCleanup(k8sClient.Delete, ctx, CreateNovaMessageBusSecret(cell0))
+	DeferCleanup(k8sClient.Delete, ctx, CreateNovaMessageBusSecret(cell1))
+
+	serviceSpec := corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 3306}}}
+	DeferCleanup(
+		mariadb.DeleteDBService,
+		mariadb.CreateDBService(novaNames.APIMariaDBDatabaseName.Namespace, novaNames.APIMariaDBDatabaseName.Name, serviceSpec))
+	DeferCleanup(mariadb.DeleteDBService, mariadb.CreateDBService(cell0.MariaDBDatabaseName.Namespace, cell0.MariaDBDatabaseName.Name, serviceSpec))
+	DeferCleanup(mariadb.DeleteDBService, mariadb.CreateDBService(cell1.MariaDBDatabaseName.Namespace, cell1.MariaDBDatabaseName.Name, serviceSpec))
+
+	apiMariaDBAccount, apiMariaDBSecret := mariadb.CreateMariaDBAccountAndSecret(
+		novaNames.APIMariaDBDatabaseAccount, mariadbv1.MariaDBAccountSpec{})
+	DeferCleanup(k8sClient.Delete, ctx, apiMariaDBAccount)
+	DeferCleanup(k8sClient.Delete, ctx, apiMariaDBSecret)
+
+	cell0Account, cell0Secret := mariadb.CreateMariaDBAccountAndSecret(
+		cell0.MariaDBAccountName, mariadbv1.MariaDBAccountSpec{})
+	DeferCleanup(k8sClient.Delete, ctx, cell0Account)
+	DeferCleanup(k8sClient.Delete, ctx, cell0Secret)
+
+	cell1Account, cell1Secret := mariadb.CreateMariaDBAccountAndSecret(
+		cell1.MariaDBAccountName, mariadbv1.MariaDBAccountSpec{})
+	DeferCleanup(k8sClient.Delete, ctx, cell1Account)
+	DeferCleanup(k8sClient.Delete, ctx, cell1Secret)
+
+	spec := GetDefaultNovaSpec()
+	cell0Template := GetDefaultNovaCellTemplate()
+	cell0Template["cellDatabaseInstance"] = cell0.MariaDBDatabaseName.Name
+	cell0Template["cellDatabaseAccount"] = cell0Account.Name
+
+	cell1Template := GetDefaultNovaCellTemplate()
+	cell1Template["cellDatabaseInstance"] = cell1.MariaDBDatabaseName.Name
+	cell1Template["cellDatabaseAccount"] = cell1Account.Name
+	cell1Template["cellMessageBusInstance"] = cell1.TransportURLName.Name
+
+	spec["cellTemplates"] = map[string]interface{}{
+		"cell0": cell0Template,
+		"cell1": cell1Template,
+	}
+	spec["apiDatabaseInstance"] = novaNames.APIMariaDBDatabaseName.Name
+	spec["apiMessageBusInstance"] = cell0.TransportURLName.Name
+	spec["adoption"] = true
+
+	DeferCleanup(th.DeleteInstance, CreateNova(novaNames.NovaName, spec))
+	DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(novaNames.NovaName.Namespace))
+	memcachedSpec := infra.GetDefaultMemcachedSpec()
+	DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(novaNames.NovaName.Namespace, MemcachedInstance, memcachedSpec))
+	infra.SimulateMemcachedReady(novaNames.MemcachedNamespace)
+	keystone.SimulateKeystoneServiceReady(novaNames.KeystoneServiceName)
+
+	mariadb.SimulateMariaDBDatabaseCompleted(novaNames.APIMariaDBDatabaseName)
+	mariadb.SimulateMariaDBDatabaseCompleted(cell0.MariaDBDatabaseName)
+	mariadb.SimulateMariaDBDatabaseCompleted(cell1.MariaDBDatabaseName)
+
+	mariadb.SimulateMariaDBAccountCompleted(novaNames.APIMariaDBDatabaseAccount)
+	mariadb.SimulateMariaDBAccountCompleted(cell0.MariaDBAccountName)
+	mariadb.SimulateMariaDBAccountCompleted(cell1.MariaDBAccountName)
+
+	infra.SimulateTransportURLReady(cell0.TransportURLName)
+	infra.SimulateTransportURLReady(cell1.TransportURLName)
+}
+
+var _ = Describe("Nova adoption", func() {
+	BeforeEach(func() {
+		CreateNovaForAdoption(novaNames)
+	})
+
+	It("checks the existing deployment before deploying the cells", func() {
+		job := th.GetJob(novaNames.AdoptionCheckJobName)
+		expectedCells := GetEnvVarValue(
+			job.Spec.Template.Spec.Containers[0].Env, "EXPECTED_CELLS", "")
+		Expect(expectedCells).To(ContainSubstring(`"name":"cell0"`))
+		Expect(expectedCells).To(ContainSubstring(`"name":"cell1","transportURLHost":"cell1"`))
+		Expect(expectedCells).To(ContainSubstring(`"databaseName":"nova_cell1"`))
+		Expect(expectedCells).To(ContainSubstring(`"databaseName":"nova_cell0"`))
+
+		th.ExpectConditionWithDetails(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaAdoptionReadyCondition,
+			corev1.ConditionFalse,
+			condition.RequestedReason,
+			novav1.NovaAdoptionReadyRunningMessage,
+		)
+		NovaCellNotExists(cell0.CellCRName)
+	})
+
+	It("reports the mismatches and does not deploy the cells", func() {
+		pod := CreateManageCommandPod(novaNames.AdoptionCheckJobName, 0,
+			`{"mismatches": ["cell1: no existing cell mapping"], "cellMappings": {}}`)
+		DeferCleanup(th.DeleteInstance, pod)
+		th.SimulateJobSuccess(novaNames.AdoptionCheckJobName)
+
+		th.ExpectConditionWithDetails(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaAdoptionReadyCondition,
+			corev1.ConditionFalse,
+			condition.ErrorReason,
+			"Adoption check found mismatches with the existing deployment: "+
+				"cell1: no existing cell mapping",
+		)
+		Expect(GetNova(novaNames.NovaName).Status.AdoptionMismatches).To(
+			Equal([]string{"cell1: no existing cell mapping"}))
+		NovaCellNotExists(cell0.CellCRName)
+	})
+
+	It("re-runs the check when the recheck annotation changes", func() {
+		pod := CreateManageCommandPod(novaNames.AdoptionCheckJobName, 0,
+			`{"mismatches": ["cell1: no existing cell mapping"], "cellMappings": {}}`)
+		th.SimulateJobSuccess(novaNames.AdoptionCheckJobName)
+		th.ExpectCondition(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaAdoptionReadyCondition,
+			corev1.ConditionFalse,
+		)
+		Eventually(func(g Gomega) {
+			g.Expect(GetNova(novaNames.NovaName).Status.AdoptionMismatches).NotTo(BeEmpty())
+		}, timeout, interval).Should(Succeed())
+		th.DeleteInstance(pod)
+
+		// the existing deployment is fixed
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			nova.Annotations = map[string]string{
+				novav1.AdoptionRecheckAnnotation: "1",
+			}
+			g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+		}, timeout, interval).Should(Succeed())
+		Eventually(func(g Gomega) {
+			job := th.GetJob(novaNames.AdoptionCheckJobName)
+			g.Expect(GetEnvVarValue(
+				job.Spec.Template.Spec.Containers[0].Env, "RECHECK", "")).To(Equal("1"))
+		}, timeout, interval).Should(Succeed())
+
+		pod = CreateManageCommandPod(novaNames.AdoptionCheckJobName, 0,
+			`{"mismatches": [], "cellMappings": {`+
+				`"cell0": {"uuid": "`+cell0UUID+`", "transportURLHost": "", "databaseHost": "openstack-cell0"}, `+
+				`"cell1": {"uuid": "`+cell1UUID+`", "transportURLHost": "cell1", "databaseHost": "openstack-cell1"}}}`)
+		DeferCleanup(th.DeleteInstance, pod)
+		th.SimulateJobSuccess(novaNames.AdoptionCheckJobName)
+
+		th.ExpectCondition(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaAdoptionReadyCondition,
+			corev1.ConditionTrue,
+		)
+		Expect(GetNova(novaNames.NovaName).Status.AdoptionMismatches).To(BeEmpty())
+	})
+
+	It("adopts the existing cell mappings when they match", func() {
+		pod := CreateManageCommandPod(novaNames.AdoptionCheckJobName, 0,
+			`{"mismatches": [], "cellMappings": {`+
+				`"cell0": {"uuid": "`+cell0UUID+`", "transportURLHost": "", "databaseHost": "openstack-cell0"}, `+
+				`"cell1": {"uuid": "`+cell1UUID+`", "transportURLHost": "cell1", "databaseHost": "openstack-cell1"}}}`)
+		DeferCleanup(th.DeleteInstance, pod)
+		th.SimulateJobSuccess(novaNames.AdoptionCheckJobName)
+
+		th.ExpectCondition(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaAdoptionReadyCondition,
+			corev1.ConditionTrue,
+		)
+		nova := GetNova(novaNames.NovaName)
+		Expect(nova.Status.AdoptionMismatches).To(BeEmpty())
+		Expect(nova.Status.CellMappings).To(
+			HaveKeyWithValue(cell1.CellCRName.Name, novav1.NovaCellMapping{
+				UUID:             cell1UUID,
+				TransportURLHost: "cell1",
+				DatabaseHost:     "openstack-cell1",
+			}))
+
+		GetNovaCell(cell0.CellCRName)
+		th.SimulateJobSuccess(cell0.DBSyncJobName)
+		th.SimulateStatefulSetReplicaReady(cell0.ConductorStatefulSetName)
+		Eventually(func(g Gomega) {
+			mappingJob := th.GetJob(cell0.CellMappingJobName)
+			g.Expect(GetEnvVarValue(
+				mappingJob.Spec.Template.Spec.Containers[0].Env, "CELL_UUID", "")).To(Equal(cell0UUID))
+		}, timeout, interval).Should(Succeed())
+	})
+
+	It("does not check the cells added after the adoption", func() {
+		pod := CreateManageCommandPod(novaNames.AdoptionCheckJobName, 0,
+			`{"mismatches": [], "cellMappings": {`+
+				`"cell0": {"uuid": "`+cell0UUID+`", "transportURLHost": "", "databaseHost": "openstack-cell0"}, `+
+				`"cell1": {"uuid": "`+cell1UUID+`", "transportURLHost": "cell1", "databaseHost": "openstack-cell1"}}}`)
+		DeferCleanup(th.DeleteInstance, pod)
+		th.SimulateJobSuccess(novaNames.AdoptionCheckJobName)
+		Eventually(func(g Gomega) {
+			g.Expect(GetNova(novaNames.NovaName).Status.AdoptionCompleted).To(BeTrue())
+		}, timeout, interval).Should(Succeed())
+		jobUID := th.GetJob(novaNames.AdoptionCheckJobName).UID
+
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			template := nova.Spec.CellTemplates[cell1.CellName]
+			template.CellDatabaseInstance = cell2.MariaDBDatabaseName.Name
+			template.CellDatabaseAccount = cell2.MariaDBAccountName.Name
+			template.CellMessageBusInstance = cell2.TransportURLName.Name
+			nova.Spec.CellTemplates[cell2.CellName] = template
+			g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+		}, timeout, interval).Should(Succeed())
+
+		// the existing cells are still deployed while the new cell waits
+		// for its DB
+		GetNovaCell(cell0.CellCRName)
+		Consistently(func(g Gomega) {
+			conditions := NovaConditionGetter(novaNames.NovaName)
+			g.Expect(conditions.IsTrue(novav1.NovaAdoptionReadyCondition)).To(BeTrue())
+			g.Expect(th.GetJob(novaNames.AdoptionCheckJobName).UID).To(Equal(jobUID))
+		}, consistencyTimeout, interval).Should(Succeed())
+	})
+})
@@ -0,0 +1,191 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+// UpdateCellAudit sets the audit of the template of the given cell
+func UpdateCellAudit(cell CellNames, audit novav1.NovaCellAudit) {
+	Eventually(func(g Gomega) {
+		nova := GetNova(novaNames.NovaName)
+		template := nova.Spec.CellTemplates[cell.CellName]
+		template.Audit = audit
+		nova.Spec.CellTemplates[cell.CellName] = template
+		g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+// SimulateCellAuditJob simulates that the audit CronJob of the cell started
+// a Job that completed with the given report
+func SimulateCellAuditJob(cell CellNames, name string, report string) {
+	cron := GetCronJob(cell.AuditCronJobName)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cell.AuditCronJobName.Namespace,
+			Labels:    cron.Spec.JobTemplate.Labels,
+		},
+		Spec: cron.Spec.JobTemplate.Spec,
+	}
+	Expect(k8sClient.Create(ctx, job)).To(Succeed())
+	DeferCleanup(th.DeleteInstance, job)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-pod",
+			Namespace: job.Namespace,
+			Labels: map[string]string{
+				"job-name": name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nova-manage", Image: "nova-conductor"},
+			},
+		},
+	}
+	Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+	DeferCleanup(th.DeleteInstance, pod)
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "nova-manage",
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					Message:    report,
+					FinishedAt: metav1.Now(),
+				},
+			},
+		},
+	}
+	Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.CompletionTime = &now
+	job.Status.Succeeded = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
+
+var _ = Describe("Nova cell consistency audit", func() {
+	BeforeEach(func() {
+		CreateNovaWith3CellsAndEnsureReady(novaNames)
+	})
+
+	It("does not audit the cell by default", func() {
+		Consistently(func(g Gomega) {
+			cron := &batchv1.CronJob{}
+			err := k8sClient.Get(ctx, cell1.AuditCronJobName, cron)
+			g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			g.Expect(GetNovaCell(cell1.CellCRName).Status.Audit).To(BeNil())
+		}, consistencyTimeout, interval).Should(Succeed())
+	})
+
+	It("reports the findings of the audit in the status of the cell", func() {
+		UpdateCellAudit(cell1, novav1.NovaCellAudit{
+			Enabled:  true,
+			Schedule: ptr.To("0 */6 * * *"),
+		})
+
+		cron := GetCronJob(cell1.AuditCronJobName)
+		Expect(cron.Spec.Schedule).To(Equal("0 */6 * * *"))
+		container := cron.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+		Expect(GetEnvVarValue(container.Env, "CELL_NAME", "")).To(Equal(cell1.CellName))
+		Expect(container.Image).To(Equal(GetNovaCell(cell1.CellCRName).Spec.ConductorContainerImageURL))
+		// the audit runs with the nova-manage config of the cell
+		Expect(cron.Spec.JobTemplate.Spec.Template.Spec.Volumes).To(
+			ContainElement(HaveField("VolumeSource.Secret.SecretName",
+				cell1.CellCRName.Name+"-manage-config-data")))
+
+		th.ExpectConditionWithDetails(
+			cell1.CellCRName,
+			ConditionGetterFunc(NovaCellConditionGetter),
+			novav1.NovaCellAuditCondition,
+			corev1.ConditionUnknown,
+			condition.InitReason,
+			novav1.NovaCellAuditInitMessage,
+		)
+
+		SimulateCellAuditJob(cell1, "audit-1",
+			`{"orphanedAllocations": 2, "unmappedHosts": ["compute-1"], `+
+				`"aggregatesInSync": true, "errors": []}`)
+
+		Eventually(func(g Gomega) {
+			audit := GetNovaCell(cell1.CellCRName).Status.Audit
+			g.Expect(audit).NotTo(BeNil())
+			g.Expect(audit.OrphanedAllocations).To(Equal(2))
+			g.Expect(audit.UnmappedHosts).To(Equal([]string{"compute-1"}))
+			g.Expect(audit.AggregatesInSync).To(BeTrue())
+		}, timeout, interval).Should(Succeed())
+		th.ExpectConditionWithDetails(
+			cell1.CellCRName,
+			ConditionGetterFunc(NovaCellConditionGetter),
+			novav1.NovaCellAuditCondition,
+			corev1.ConditionFalse,
+			condition.ErrorReason,
+			"Consistency audit found 2 orphaned allocations; unmapped hosts compute-1",
+		)
+		// the findings do not affect the readiness of the cell
+		th.ExpectCondition(
+			cell1.CellCRName,
+			ConditionGetterFunc(NovaCellConditionGetter),
+			condition.ReadyCondition,
+			corev1.ConditionTrue,
+		)
+
+		// disabling the audit removes the CronJob and the findings
+		UpdateCellAudit(cell1, novav1.NovaCellAudit{Enabled: false})
+		Eventually(func(g Gomega) {
+			cron := &batchv1.CronJob{}
+			err := k8sClient.Get(ctx, cell1.AuditCronJobName, cron)
+			g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			g.Expect(GetNovaCell(cell1.CellCRName).Status.Audit).To(BeNil())
+		}, timeout, interval).Should(Succeed())
+	})
+
+	It("reports a consistent cell", func() {
+		UpdateCellAudit(cell2, novav1.NovaCellAudit{Enabled: true})
+		Expect(GetCronJob(cell2.AuditCronJobName).Spec.Schedule).To(Equal("0 1 * * *"))
+
+		SimulateCellAuditJob(cell2, "audit-2",
+			`{"orphanedAllocations": 0, "unmappedHosts": [], "aggregatesInSync": true, "errors": []}`)
+
+		th.ExpectConditionWithDetails(
+			cell2.CellCRName,
+			ConditionGetterFunc(NovaCellConditionGetter),
+			novav1.NovaCellAuditCondition,
+			corev1.ConditionTrue,
+			condition.ReadyReason,
+			novav1.NovaCellAuditMessage,
+		)
+	})
+})
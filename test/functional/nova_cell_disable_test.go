@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+)
+
+// UpdateCellDisabled sets the disabled and the scaleDownServices fields of
+// the template of the given cell
+func UpdateCellDisabled(cell CellNames, disabled bool, scaleDownServices bool) {
+	Eventually(func(g Gomega) {
+		nova := GetNova(novaNames.NovaName)
+		template := nova.Spec.CellTemplates[cell.CellName]
+		template.Disabled = disabled
+		template.ScaleDownServices = scaleDownServices
+		nova.Spec.CellTemplates[cell.CellName] = template
+		g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+var _ = Describe("Nova cell disabling", func() {
+	BeforeEach(func() {
+		CreateNovaWith3CellsAndEnsureReady(novaNames)
+	})
+
+	It("disables the cell in its cell mapping and enables it again", func() {
+		UpdateCellDisabled(cell1, true, false)
+
+		Eventually(func(g Gomega) {
+			mappingJob := th.GetJob(cell1.CellMappingJobName)
+			g.Expect(GetEnvVarValue(
+				mappingJob.Spec.Template.Spec.Containers[0].Env, "CELL_DISABLED", "")).To(Equal("true"))
+		}, timeout, interval).Should(Succeed())
+		th.SimulateJobSuccess(cell1.CellMappingJobName)
+
+		Eventually(func(g Gomega) {
+			g.Expect(GetNova(novaNames.NovaName).Status.DisabledCells).To(
+				ConsistOf(cell1.CellName))
+			g.Expect(GetNovaCell(cell1.CellCRName).Status.Disabled).To(BeTrue())
+		}, timeout, interval).Should(Succeed())
+		// the services of the cell are kept running
+		Expect(*GetNovaConductor(cell1.ConductorName).Spec.Replicas).To(Equal(int32(1)))
+
+		UpdateCellDisabled(cell1, false, false)
+
+		Eventually(func(g Gomega) {
+			mappingJob := th.GetJob(cell1.CellMappingJobName)
+			g.Expect(GetEnvVarValue(
+				mappingJob.Spec.Template.Spec.Containers[0].Env, "CELL_DISABLED", "")).To(BeEmpty())
+		}, timeout, interval).Should(Succeed())
+		th.SimulateJobSuccess(cell1.CellMappingJobName)
+
+		Eventually(func(g Gomega) {
+			g.Expect(GetNova(novaNames.NovaName).Status.DisabledCells).To(BeEmpty())
+			g.Expect(GetNovaCell(cell1.CellCRName).Status.Disabled).To(BeFalse())
+		}, timeout, interval).Should(Succeed())
+	})
+
+	It("scales down the services of the disabled cell", func() {
+		UpdateCellDisabled(cell2, true, true)
+
+		Eventually(func(g Gomega) {
+			g.Expect(*GetNovaConductor(cell2.ConductorName).Spec.Replicas).To(Equal(int32(0)))
+			g.Expect(*GetNovaNoVNCProxy(cell2.NoVNCProxyName).Spec.Replicas).To(Equal(int32(0)))
+		}, timeout, interval).Should(Succeed())
+		th.SimulateStatefulSetReplicaReady(cell2.ConductorStatefulSetName)
+		th.SimulateStatefulSetReplicaReady(cell2.NoVNCProxyStatefulSetName)
+		th.SimulateJobSuccess(cell2.CellMappingJobName)
+
+		Eventually(func(g Gomega) {
+			g.Expect(GetNova(novaNames.NovaName).Status.DisabledCells).To(
+				ConsistOf(cell2.CellName))
+			g.Expect(GetNovaCell(cell2.CellCRName).Status.Disabled).To(BeTrue())
+		}, timeout, interval).Should(Succeed())
+		// the other cells are not affected
+		Expect(*GetNovaConductor(cell1.ConductorName).Spec.Replicas).To(Equal(int32(1)))
+
+		UpdateCellDisabled(cell2, false, true)
+		Eventually(func(g Gomega) {
+			g.Expect(*GetNovaConductor(cell2.ConductorName).Spec.Replicas).To(Equal(int32(1)))
+			g.Expect(*GetNovaNoVNCProxy(cell2.NoVNCProxyName).Spec.Replicas).To(Equal(int32(1)))
+		}, timeout, interval).Should(Succeed())
+	})
+})
@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SetNovaCellHostMappings simulates that the given hosts are reported as
+// mapped to the cell
+func SetNovaCellHostMappings(name types.NamespacedName, hosts ...string) {
+	Eventually(func(g Gomega) {
+		cell := GetNovaCell(name)
+		cell.Status.HostMappings = &novav1.NovaCellHostMappingsStatus{
+			LastReportTime: metav1.Now(),
+			Hosts:          hosts,
+		}
+		g.Expect(k8sClient.Status().Update(ctx, cell)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+// SimulateCellHostsJob simulates that the hosts CronJob of the cell started
+// a Job that reported the given hosts
+func SimulateCellHostsJob(cell CellNames, name string, hosts ...string) {
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	_, err := writer.Write([]byte(strings.Join(hosts, "\n")))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(writer.Close()).To(Succeed())
+
+	report := fmt.Sprintf(
+		`{"hostCount": %d, "hosts": "%s"}`,
+		len(hosts), base64.StdEncoding.EncodeToString(compressed.Bytes()))
+
+	cron := GetCronJob(cell.HostsCronJobName)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cell.HostsCronJobName.Namespace,
+			Labels:    cron.Spec.JobTemplate.Labels,
+		},
+		Spec: cron.Spec.JobTemplate.Spec,
+	}
+	Expect(k8sClient.Create(ctx, job)).To(Succeed())
+	DeferCleanup(th.DeleteInstance, job)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-pod",
+			Namespace: job.Namespace,
+			Labels: map[string]string{
+				"job-name": name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nova-manage", Image: "nova-conductor"},
+			},
+		},
+	}
+	Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+	DeferCleanup(th.DeleteInstance, pod)
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "nova-manage",
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					Message:    report,
+					FinishedAt: metav1.Now(),
+				},
+			},
+		},
+	}
+	Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.CompletionTime = &now
+	job.Status.Succeeded = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
+
+var _ = Describe("Nova cell host mappings", func() {
+	BeforeEach(func() {
+		CreateNovaWith3CellsAndEnsureReady(novaNames)
+	})
+
+	It("does not report the hosts of cell0", func() {
+		Consistently(func(g Gomega) {
+			err := k8sClient.Get(ctx, cell0.HostsCronJobName, &batchv1.CronJob{})
+			g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+		}, consistencyTimeout, interval).Should(Succeed())
+	})
+
+	It("records the hosts mapped to the cell", func() {
+		cron := GetCronJob(cell1.HostsCronJobName)
+		container := cron.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+		Expect(GetEnvVarValue(container.Env, "CELL_NAME", "")).To(Equal(cell1.CellName))
+
+		SimulateCellHostsJob(cell1, "hosts-1", "edpm-compute-0", "ironic-compute-0")
+		Eventually(func(g Gomega) {
+			hostMappings := GetNovaCell(cell1.CellCRName).Status.HostMappings
+			g.Expect(hostMappings).NotTo(BeNil())
+			g.Expect(hostMappings.Hosts).To(Equal([]string{"edpm-compute-0", "ironic-compute-0"}))
+		}, timeout, interval).Should(Succeed())
+	})
+})
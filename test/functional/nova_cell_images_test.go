@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+const hotfixConductorImage = "quay.io/podified-antelope-centos9/openstack-nova-conductor:hotfix"
+
+var _ = Describe("Nova per cell image overrides", func() {
+	BeforeEach(func() {
+		CreateNovaWith3CellsAndEnsureReady(novaNames)
+	})
+
+	It("deploys the overridden image only in the given cell", func() {
+		defaultConductorImage := GetNova(novaNames.NovaName).Spec.ConductorContainerImageURL
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			template := nova.Spec.CellTemplates[cell1.CellName]
+			template.Images = &novav1.NovaCellImageOverrides{
+				ConductorContainerImageURL: hotfixConductorImage,
+			}
+			nova.Spec.CellTemplates[cell1.CellName] = template
+			g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+		}, timeout, interval).Should(Succeed())
+
+		ExpectConductorImage(cell1, hotfixConductorImage)
+		Eventually(func(g Gomega) {
+			g.Expect(GetNovaCell(cell1.CellCRName).Spec.ConductorContainerImageURL).To(
+				Equal(hotfixConductorImage))
+		}, timeout, interval).Should(Succeed())
+		Consistently(func(g Gomega) {
+			g.Expect(GetNovaConductor(cell0.ConductorName).Spec.ContainerImage).To(Equal(defaultConductorImage))
+			g.Expect(GetNovaConductor(cell2.ConductorName).Spec.ContainerImage).To(Equal(defaultConductorImage))
+			// the override does not start an upgrade
+			g.Expect(GetNova(novaNames.NovaName).Status.UpgradePhase).To(BeEmpty())
+		}, consistencyTimeout, interval).Should(Succeed())
+
+		// removing the override deploys the common image again
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			template := nova.Spec.CellTemplates[cell1.CellName]
+			template.Images = nil
+			nova.Spec.CellTemplates[cell1.CellName] = template
+			g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+		}, timeout, interval).Should(Succeed())
+		ExpectConductorImage(cell1, defaultConductorImage)
+	})
+
+	It("warns when the cells run mixed images", func() {
+		nova := GetNova(novaNames.NovaName)
+		Expect(nova.Spec.ValidateCellImages(field.NewPath("spec"))).To(BeEmpty())
+
+		template := nova.Spec.CellTemplates[cell1.CellName]
+		template.Images = &novav1.NovaCellImageOverrides{
+			ConductorContainerImageURL: hotfixConductorImage,
+		}
+		nova.Spec.CellTemplates[cell1.CellName] = template
+		warnings := nova.Spec.ValidateCellImages(field.NewPath("spec"))
+		Expect(warnings).To(HaveLen(1))
+		Expect(warnings[0]).To(ContainSubstring(
+			"spec.cellTemplates[cell1].images.conductorContainerImageURL: " +
+				"the cell runs " + hotfixConductorImage))
+
+		// overriding the image in every cell is not a mix
+		for cellName, template := range nova.Spec.CellTemplates {
+			template.Images = &novav1.NovaCellImageOverrides{
+				ConductorContainerImageURL: hotfixConductorImage,
+			}
+			nova.Spec.CellTemplates[cellName] = template
+		}
+		Expect(nova.Spec.ValidateCellImages(field.NewPath("spec"))).To(BeEmpty())
+	})
+})
@@ -0,0 +1,116 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const cell1UUID = "4c0bce94-62b0-4e2c-9b8a-2a4c6e0e1c11"
+
+// CreateCellMappingPod simulates the pod of the cell mapping Job of the cell
+// that terminated with the given report
+func CreateCellMappingPod(cell CellNames, report string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cell.CellMappingJobName.Name + "-pod",
+			Namespace: cell.CellMappingJobName.Namespace,
+			Labels: map[string]string{
+				"job-name": cell.CellMappingJobName.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nova-manage", Image: "nova-conductor"},
+			},
+		},
+	}
+	Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "nova-manage",
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					Message:    report,
+					FinishedAt: metav1.Now(),
+				},
+			},
+		},
+	}
+	Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+	return pod
+}
+
+var _ = Describe("Nova cell mapping", func() {
+	BeforeEach(func() {
+		CreateNovaWith3CellsAndEnsureReady(novaNames)
+	})
+
+	It("records the reported cell mapping and deletes the cell by its UUID", func() {
+		// disabling the cell re-runs its cell mapping job
+		UpdateCellDisabled(cell1, true, false)
+		Eventually(func(g Gomega) {
+			mappingJob := th.GetJob(cell1.CellMappingJobName)
+			g.Expect(GetEnvVarValue(
+				mappingJob.Spec.Template.Spec.Containers[0].Env, "CELL_DISABLED", "")).To(Equal("true"))
+		}, timeout, interval).Should(Succeed())
+
+		pod := CreateCellMappingPod(cell1,
+			`{"uuid": "`+cell1UUID+`", "transportURLHost": "rabbitmq-cell1", "databaseHost": "openstack-cell1"}`)
+		DeferCleanup(th.DeleteInstance, pod)
+		th.SimulateJobSuccess(cell1.CellMappingJobName)
+
+		expectedMapping := novav1.NovaCellMapping{
+			UUID:             cell1UUID,
+			TransportURLHost: "rabbitmq-cell1",
+			DatabaseHost:     "openstack-cell1",
+		}
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			g.Expect(nova.Status.CellMappings).To(
+				HaveKeyWithValue(cell1.CellCRName.Name, expectedMapping))
+			g.Expect(GetNovaCell(cell1.CellCRName).Status.CellMapping).To(
+				Equal(&expectedMapping))
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			delete(nova.Spec.CellTemplates, cell1.CellName)
+			g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			deleteJob := th.GetJob(cell1.CellDeleteJobName)
+			g.Expect(GetEnvVarValue(
+				deleteJob.Spec.Template.Spec.Containers[0].Env, "CELL_UUID", "")).To(Equal(cell1UUID))
+		}, timeout, interval).Should(Succeed())
+		th.SimulateJobSuccess(cell1.CellDeleteJobName)
+
+		NovaCellNotExists(cell1.CellCRName)
+		Eventually(func(g Gomega) {
+			g.Expect(GetNova(novaNames.NovaName).Status.CellMappings).NotTo(
+				HaveKey(cell1.CellCRName.Name))
+		}, timeout, interval).Should(Succeed())
+	})
+})
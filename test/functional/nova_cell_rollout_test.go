@@ -0,0 +1,114 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+)
+
+// UpdateNovaWithCellRollout sets the rollout strategy and overrides the
+// conductor image of every cell in a single update
+func UpdateNovaWithCellRollout(strategy novav1.NovaRolloutStrategy, conductorImage string) {
+	Eventually(func(g Gomega) {
+		nova := GetNova(novaNames.NovaName)
+		nova.Spec.RolloutStrategy = &strategy
+		for cellName, template := range nova.Spec.CellTemplates {
+			template.Images = &novav1.NovaCellImageOverrides{
+				ConductorContainerImageURL: conductorImage,
+			}
+			nova.Spec.CellTemplates[cellName] = template
+		}
+		g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+// SimulateConductorRolledOut simulates that the cell DB is synced with the
+// given conductor image and the conductor is rolled out
+func SimulateConductorRolledOut(cell CellNames, image string) {
+	ExpectConductorImage(cell, image)
+	th.SimulateJobSuccess(cell.DBSyncJobName)
+	th.SimulateStatefulSetReplicaReady(cell.ConductorStatefulSetName)
+}
+
+var _ = Describe("Nova cell by cell rollout", func() {
+	var oldConductorImage string
+
+	BeforeEach(func() {
+		CreateNovaWith3CellsAndEnsureReady(novaNames)
+		oldConductorImage = GetNova(novaNames.NovaName).Spec.ConductorContainerImageURL
+	})
+
+	It("rolls out the change to the cells in the given order", func() {
+		UpdateNovaWithCellRollout(
+			novav1.NovaRolloutStrategy{
+				MaxUnavailableCells: 1,
+				CellOrder:           []string{cell2.CellName},
+			},
+			hotfixConductorImage)
+
+		ExpectConductorImage(cell0, hotfixConductorImage)
+		Consistently(func(g Gomega) {
+			g.Expect(GetNovaConductor(cell1.ConductorName).Spec.ContainerImage).To(Equal(oldConductorImage))
+			g.Expect(GetNovaConductor(cell2.ConductorName).Spec.ContainerImage).To(Equal(oldConductorImage))
+		}, consistencyTimeout, interval).Should(Succeed())
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			g.Expect(nova.Status.RolloutHeldCells).To(
+				ConsistOf(cell1.CellName, cell2.CellName))
+			g.Expect(nova.Status.RolloutPausedBy).To(BeEmpty())
+		}, timeout, interval).Should(Succeed())
+
+		SimulateConductorRolledOut(cell0, hotfixConductorImage)
+		ExpectConductorImage(cell2, hotfixConductorImage)
+		Consistently(func(g Gomega) {
+			g.Expect(GetNovaConductor(cell1.ConductorName).Spec.ContainerImage).To(Equal(oldConductorImage))
+		}, consistencyTimeout, interval).Should(Succeed())
+
+		SimulateConductorRolledOut(cell2, hotfixConductorImage)
+		SimulateConductorRolledOut(cell1, hotfixConductorImage)
+		Eventually(func(g Gomega) {
+			g.Expect(GetNova(novaNames.NovaName).Status.RolloutHeldCells).To(BeEmpty())
+		}, timeout, interval).Should(Succeed())
+	})
+
+	It("pauses the rollout when a cell fails", func() {
+		UpdateNovaWithCellRollout(
+			novav1.NovaRolloutStrategy{
+				MaxUnavailableCells: 2,
+				CellOrder:           []string{cell2.CellName},
+			},
+			hotfixConductorImage)
+
+		// two cells are rolled out at the same time
+		ExpectConductorImage(cell0, hotfixConductorImage)
+		ExpectConductorImage(cell2, hotfixConductorImage)
+
+		// the failed cell2 pauses the rollout even if cell0 becomes Ready
+		th.SimulateJobFailure(cell2.DBSyncJobName)
+		SimulateConductorRolledOut(cell0, hotfixConductorImage)
+		Consistently(func(g Gomega) {
+			g.Expect(GetNovaConductor(cell1.ConductorName).Spec.ContainerImage).To(Equal(oldConductorImage))
+		}, consistencyTimeout, interval).Should(Succeed())
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			g.Expect(nova.Status.RolloutHeldCells).To(Equal([]string{cell1.CellName}))
+			g.Expect(nova.Status.RolloutPausedBy).To(Equal(cell2.CellName))
+		}, timeout, interval).Should(Succeed())
+	})
+})
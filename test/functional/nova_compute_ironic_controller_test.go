@@ -18,6 +18,7 @@ package functional_test
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
 	. "github.com/onsi/gomega"    //revive:disable:dot-imports
@@ -621,3 +622,92 @@ var _ = Describe("NovaCompute with ironic diver controller", func() {
 		})
 	})
 })
+
+var _ = Describe("NovaCompute stale service cleanup", func() {
+	var novaAPIFixture *NovaAPIFixture
+	var staleHost string
+
+	BeforeEach(func() {
+		keystoneFixture, f := SetupAPIFixtures(logger)
+		novaAPIFixture = f
+		DeferCleanup(
+			k8sClient.Delete, ctx, CreateDefaultCellInternalSecret(cell1))
+
+		staleHost = fmt.Sprintf("%s-1", cell1.NovaComputeStatefulSetName.Name)
+		novaAPIFixture.Hypervisors = append(novaAPIFixture.Hypervisors, Hypervisor{
+			ID:       "3",
+			Hostname: staleHost,
+		})
+		novaAPIFixture.ResourceProviders["3"] = true
+
+		spec := GetDefaultNovaComputeSpec(cell1)
+		spec["keystoneAuthURL"] = keystoneFixture.Endpoint()
+		spec["staleServiceGracePeriod"] = 300
+		DeferCleanup(th.DeleteInstance, CreateNovaCompute(cell1.NovaComputeName, spec))
+	})
+
+	It("deletes the service and the resource provider of a removed pod", func() {
+		novaAPIFixture.Services = append(novaAPIFixture.Services,
+			Service{
+				ID:     "9",
+				Binary: "nova-compute",
+				Host:   staleHost,
+				State:  "down",
+				Status: "enabled",
+				// down for longer than the grace period
+				UpdatedAt: time.Now().Add(-10 * time.Minute),
+			},
+			Service{
+				ID:     "10",
+				Binary: "nova-compute",
+				// not a pod name of the StatefulSet
+				Host:   fmt.Sprintf("%s-01", cell1.NovaComputeStatefulSetName.Name),
+				State:  "down",
+				Status: "enabled",
+			},
+		)
+		th.SimulateStatefulSetReplicaReady(cell1.NovaComputeStatefulSetName)
+
+		Eventually(func(g Gomega) {
+			g.Expect(novaAPIFixture.HasRequest("DELETE", "/compute/os-services/9", "")).To(BeTrue())
+			g.Expect(novaAPIFixture.HasRequest("DELETE", "/placement/resource_providers/3", "")).To(BeTrue())
+		}, timeout, interval).Should(Succeed())
+		Expect(novaAPIFixture.ResourceProviders).NotTo(HaveKey("3"))
+		// the resource providers of other hosts are kept
+		Expect(novaAPIFixture.ResourceProviders).To(HaveKey("1"))
+		Expect(novaAPIFixture.ResourceProviders).To(HaveKey("2"))
+		Expect(novaAPIFixture.HasRequest("DELETE", "/compute/os-services/10", "")).To(BeFalse())
+		th.ExpectCondition(
+			cell1.NovaComputeName,
+			ConditionGetterFunc(NovaComputeConditionGetter),
+			condition.ReadyCondition,
+			corev1.ConditionTrue,
+		)
+	})
+
+	It("keeps the service of a removed pod during the grace period", func() {
+		novaAPIFixture.Services = append(novaAPIFixture.Services, Service{
+			ID:        "9",
+			Binary:    "nova-compute",
+			Host:      staleHost,
+			State:     "down",
+			Status:    "enabled",
+			UpdatedAt: time.Now(),
+		})
+		th.SimulateStatefulSetReplicaReady(cell1.NovaComputeStatefulSetName)
+
+		th.ExpectCondition(
+			cell1.NovaComputeName,
+			ConditionGetterFunc(NovaComputeConditionGetter),
+			condition.DeploymentReadyCondition,
+			corev1.ConditionTrue,
+		)
+		Eventually(func(g Gomega) {
+			g.Expect(novaAPIFixture.HasRequest("GET", "/compute/os-services/", "binary=nova-compute")).To(BeTrue())
+		}, timeout, interval).Should(Succeed())
+		Consistently(func(g Gomega) {
+			g.Expect(novaAPIFixture.HasRequest("DELETE", "/compute/os-services/9", "")).To(BeFalse())
+			g.Expect(novaAPIFixture.HasRequest("DELETE", "/placement/resource_providers/3", "")).To(BeFalse())
+		}, consistencyTimeout, interval).Should(Succeed())
+	})
+})
@@ -25,9 +25,11 @@ import (
 	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
 
 	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/ptr"
 
+	rabbitmqv1 "github.com/openstack-k8s-operators/infra-operator/apis/rabbitmq/v1beta1"
 	topologyv1 "github.com/openstack-k8s-operators/infra-operator/apis/topology/v1beta1"
 	mariadb_test "github.com/openstack-k8s-operators/mariadb-operator/api/test/helpers"
 	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
@@ -154,6 +156,10 @@ var _ = Describe("Nova controller", func() {
 			cell0Template := nova.Spec.CellTemplates["cell0"]
 			Expect(cell0Template.DBPurge.ArchiveAge).To(Equal(ptr.To(30)))
 			Expect(cell0Template.DBPurge.PurgeAge).To(Equal(ptr.To(90)))
+			Expect(nova.Spec.Region).To(Equal("regionOne"))
+			Expect(nova.Spec.ServiceUserDomain).To(Equal("Default"))
+			Expect(nova.Spec.ServiceProjectName).To(Equal("service"))
+			Expect(nova.Spec.ServiceProjectDomain).To(Equal("Default"))
 		})
 
 		It("registers nova service to keystone", func() {
@@ -210,6 +216,12 @@ var _ = Describe("Nova controller", func() {
 				novav1.NovaAPIMQReadyCondition,
 				corev1.ConditionTrue,
 			)
+			// notifications are not configured so no notification MQ is
+			// requested
+			Eventually(func(g Gomega) {
+				nova := GetNova(novaNames.NovaName)
+				g.Expect(nova.Status.Conditions.Has(novav1.NovaNotificationMQReadyCondition)).To(BeFalse())
+			}, timeout, interval).Should(Succeed())
 		})
 
 		It("creates nova_cell0 DB", func() {
@@ -790,6 +802,291 @@ var _ = Describe("Nova controller", func() {
 		})
 	})
 
+	When("Nova CR instance is created with custom keystone region, domains and project", func() {
+		BeforeEach(func() {
+			DeferCleanup(
+				k8sClient.Delete, ctx, CreateNovaSecret(novaNames.NovaName.Namespace, SecretName))
+			DeferCleanup(
+				k8sClient.Delete, ctx, CreateNovaMessageBusSecret(cell0))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					novaNames.NovaName.Namespace,
+					"openstack",
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			memcachedSpec := infra.GetDefaultMemcachedSpec()
+
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(novaNames.NovaName.Namespace, MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(novaNames.MemcachedNamespace)
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(novaNames.NovaName.Namespace))
+
+			spec := GetDefaultNovaSpec()
+			spec["cellTemplates"] = map[string]interface{}{"cell0": GetDefaultNovaCellTemplate()}
+			spec["region"] = "regionTwo"
+			spec["serviceUserDomain"] = "users"
+			spec["serviceProjectName"] = "services"
+			spec["serviceProjectDomain"] = "projects"
+
+			DeferCleanup(th.DeleteInstance, CreateNova(novaNames.NovaName, spec))
+		})
+
+		It("propagates them to the sub CRs and the generated config", func() {
+			expectedIdentity := novav1.KeystoneServiceIdentity{
+				Region:               "regionTwo",
+				ServiceUserDomain:    "users",
+				ServiceProjectName:   "services",
+				ServiceProjectDomain: "projects",
+			}
+
+			keystone.SimulateKeystoneServiceReady(novaNames.KeystoneServiceName)
+			mariadb.SimulateMariaDBDatabaseCompleted(novaNames.APIMariaDBDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(novaNames.APIMariaDBDatabaseAccount)
+			mariadb.SimulateMariaDBDatabaseCompleted(cell0.MariaDBDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(cell0.MariaDBAccountName)
+			infra.SimulateTransportURLReady(cell0.TransportURLName)
+
+			cell := GetNovaCell(cell0.CellCRName)
+			Expect(cell.Spec.KeystoneServiceIdentity).To(Equal(expectedIdentity))
+			conductor := GetNovaConductor(cell0.ConductorName)
+			Expect(conductor.Spec.KeystoneServiceIdentity).To(Equal(expectedIdentity))
+
+			configDataMap := th.GetSecret(cell0.ConductorConfigDataName)
+			Expect(configDataMap.Data).Should(HaveKey("01-nova.conf"))
+			configData := string(configDataMap.Data["01-nova.conf"])
+			Expect(configData).To(ContainSubstring("region_name = regionTwo"))
+			Expect(configData).To(ContainSubstring("user_domain_name = users"))
+			Expect(configData).To(ContainSubstring("project_name = services"))
+			Expect(configData).To(ContainSubstring("project_domain_name = projects"))
+			Expect(configData).NotTo(ContainSubstring("project_name = service\n"))
+
+			th.SimulateJobSuccess(cell0.DBSyncJobName)
+			th.SimulateStatefulSetReplicaReady(cell0.ConductorStatefulSetName)
+			th.SimulateJobSuccess(cell0.CellMappingJobName)
+			SimulateReadyOfNovaTopServices()
+
+			Expect(GetNovaAPI(novaNames.APIName).Spec.KeystoneServiceIdentity).To(Equal(expectedIdentity))
+			Expect(GetNovaScheduler(novaNames.SchedulerName).Spec.KeystoneServiceIdentity).To(Equal(expectedIdentity))
+			Expect(GetNovaMetadata(novaNames.MetadataName).Spec.KeystoneServiceIdentity).To(Equal(expectedIdentity))
+
+			configDataMap = th.GetSecret(novaNames.APIConfigDataName)
+			Expect(configDataMap.Data).Should(HaveKey("01-nova.conf"))
+			configData = string(configDataMap.Data["01-nova.conf"])
+			Expect(configData).To(ContainSubstring("region_name = regionTwo"))
+			Expect(configData).To(ContainSubstring("endpoint_region_name = regionTwo"))
+			Expect(configData).To(ContainSubstring("user_domain_name = users"))
+			Expect(configData).To(ContainSubstring("project_name = services"))
+			Expect(configData).To(ContainSubstring("project_domain_name = projects"))
+		})
+	})
+
+	When("Nova CR instance is created with telemetry but without a notifications bus", func() {
+		BeforeEach(func() {
+			DeferCleanup(
+				k8sClient.Delete, ctx, CreateNovaSecret(novaNames.NovaName.Namespace, SecretName))
+			DeferCleanup(
+				k8sClient.Delete, ctx, CreateNovaMessageBusSecret(cell0))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					novaNames.NovaName.Namespace,
+					"openstack",
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			memcachedSpec := infra.GetDefaultMemcachedSpec()
+
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(novaNames.NovaName.Namespace, MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(novaNames.MemcachedNamespace)
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(novaNames.NovaName.Namespace))
+
+			spec := GetDefaultNovaSpec()
+			spec["cellTemplates"] = map[string]interface{}{"cell0": GetDefaultNovaCellTemplate()}
+			spec["telemetry"] = map[string]interface{}{"enabled": true}
+
+			DeferCleanup(th.DeleteInstance, CreateNova(novaNames.NovaName, spec))
+		})
+
+		It("defaults the telemetry fields", func() {
+			nova := GetNova(novaNames.NovaName)
+			Expect(nova.Spec.Telemetry.InstanceUsageAuditPeriod).To(Equal("hour"))
+			Expect(nova.Spec.Telemetry.NotifyOnStateChange).To(Equal("vm_and_task_state"))
+		})
+
+		It("reports that notifications are missing for telemetry", func() {
+			keystone.SimulateKeystoneServiceReady(novaNames.KeystoneServiceName)
+			mariadb.SimulateMariaDBDatabaseCompleted(novaNames.APIMariaDBDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(novaNames.APIMariaDBDatabaseAccount)
+			mariadb.SimulateMariaDBDatabaseCompleted(cell0.MariaDBDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(cell0.MariaDBAccountName)
+			infra.SimulateTransportURLReady(cell0.TransportURLName)
+
+			th.ExpectConditionWithDetails(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaTelemetryReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Telemetry is enabled but no notifications bus is configured for top level,cell0",
+			)
+
+			// the services are still configured for telemetry
+			cell := GetNovaCell(cell0.CellCRName)
+			Expect(cell.Spec.Telemetry.Enabled).To(BeTrue())
+			configDataMap := th.GetSecret(cell0.ConductorConfigDataName)
+			Expect(configDataMap.Data).Should(HaveKey("01-nova.conf"))
+			configData := string(configDataMap.Data["01-nova.conf"])
+			Expect(configData).To(
+				ContainSubstring("[notifications]\nnotify_on_state_change = vm_and_task_state"))
+			Expect(configData).To(
+				ContainSubstring("[oslo_messaging_notifications]\ndriver = noop"))
+
+			// the missing notifications do not affect the readiness of Nova
+			SimulateReadyOfNovaTopServices()
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaTelemetryReadyCondition,
+				corev1.ConditionFalse,
+			)
+		})
+	})
+
+	When("Nova CR instance is created with a notifications bus", func() {
+		var notificationTransportURLName types.NamespacedName
+
+		BeforeEach(func() {
+			notificationTransportURLName = types.NamespacedName{
+				Namespace: novaNames.NovaName.Namespace,
+				Name:      novaNames.NovaName.Name + "-notification-transport",
+			}
+			DeferCleanup(
+				k8sClient.Delete, ctx, CreateNovaSecret(novaNames.NovaName.Namespace, SecretName))
+			DeferCleanup(
+				k8sClient.Delete, ctx, CreateNovaMessageBusSecret(cell0))
+			DeferCleanup(
+				k8sClient.Delete, ctx, th.CreateSecret(
+					types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "rabbitmq-notification-secret"},
+					map[string][]byte{
+						"transport_url": []byte("rabbit://notification/fake"),
+					},
+				))
+			DeferCleanup(
+				mariadb.DeleteDBService,
+				mariadb.CreateDBService(
+					novaNames.NovaName.Namespace,
+					"openstack",
+					corev1.ServiceSpec{
+						Ports: []corev1.ServicePort{{Port: 3306}},
+					},
+				),
+			)
+			memcachedSpec := infra.GetDefaultMemcachedSpec()
+
+			DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(novaNames.NovaName.Namespace, MemcachedInstance, memcachedSpec))
+			infra.SimulateMemcachedReady(novaNames.MemcachedNamespace)
+			DeferCleanup(keystone.DeleteKeystoneAPI, keystone.CreateKeystoneAPI(novaNames.NovaName.Namespace))
+
+			spec := GetDefaultNovaSpec()
+			spec["cellTemplates"] = map[string]interface{}{"cell0": GetDefaultNovaCellTemplate()}
+			spec["notificationsBusInstance"] = "rabbitmq-notification"
+			spec["telemetry"] = map[string]interface{}{"enabled": true}
+
+			DeferCleanup(th.DeleteInstance, CreateNova(novaNames.NovaName, spec))
+		})
+
+		It("creates a separate TransportURL for notifications", func() {
+			keystone.SimulateKeystoneServiceReady(novaNames.KeystoneServiceName)
+			infra.SimulateTransportURLReady(cell0.TransportURLName)
+
+			transportURL := infra.GetTransportURL(notificationTransportURLName)
+			Expect(transportURL.Spec.RabbitmqClusterName).To(Equal("rabbitmq-notification"))
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaNotificationMQReadyCondition,
+				corev1.ConditionFalse,
+			)
+
+			infra.SimulateTransportURLReady(notificationTransportURLName)
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaNotificationMQReadyCondition,
+				corev1.ConditionTrue,
+			)
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaTelemetryReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+
+		It("configures notifications in every service", func() {
+			keystone.SimulateKeystoneServiceReady(novaNames.KeystoneServiceName)
+			mariadb.SimulateMariaDBDatabaseCompleted(novaNames.APIMariaDBDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(novaNames.APIMariaDBDatabaseAccount)
+			mariadb.SimulateMariaDBDatabaseCompleted(cell0.MariaDBDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(cell0.MariaDBAccountName)
+			infra.SimulateTransportURLReady(cell0.TransportURLName)
+			infra.SimulateTransportURLReady(notificationTransportURLName)
+
+			internalCellSecret := th.GetSecret(cell0.InternalCellSecretName)
+			Expect(internalCellSecret.Data).To(
+				HaveKeyWithValue(controllers.NotificationTransportURLSelector, []byte("rabbit://notification/fake")))
+
+			configDataMap := th.GetSecret(cell0.ConductorConfigDataName)
+			Expect(configDataMap.Data).Should(HaveKey("01-nova.conf"))
+			configData := string(configDataMap.Data["01-nova.conf"])
+			Expect(configData).To(
+				ContainSubstring("[oslo_messaging_notifications]\ntransport_url =  rabbit://notification/fake\ndriver = messagingv2"))
+
+			th.SimulateJobSuccess(cell0.DBSyncJobName)
+			th.SimulateStatefulSetReplicaReady(cell0.ConductorStatefulSetName)
+			th.SimulateJobSuccess(cell0.CellMappingJobName)
+			SimulateReadyOfNovaTopServices()
+
+			internalTopLevelSecret := th.GetSecret(novaNames.InternalTopLevelSecretName)
+			Expect(internalTopLevelSecret.Data).To(
+				HaveKeyWithValue(controllers.NotificationTransportURLSelector, []byte("rabbit://notification/fake")))
+
+			configDataMap = th.GetSecret(novaNames.APIConfigDataName)
+			Expect(configDataMap.Data).Should(HaveKey("01-nova.conf"))
+			configData = string(configDataMap.Data["01-nova.conf"])
+			Expect(configData).To(
+				ContainSubstring("[oslo_messaging_notifications]\ntransport_url =  rabbit://notification/fake\ndriver = messagingv2"))
+			Expect(configData).To(ContainSubstring("notification_format=versioned"))
+		})
+
+		It("deletes the TransportURL when the notifications bus is removed", func() {
+			keystone.SimulateKeystoneServiceReady(novaNames.KeystoneServiceName)
+			infra.GetTransportURL(notificationTransportURLName)
+
+			Eventually(func(g Gomega) {
+				nova := GetNova(novaNames.NovaName)
+				nova.Spec.NotificationsBusInstance = nil
+				g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				err := k8sClient.Get(ctx, notificationTransportURLName, &rabbitmqv1.TransportURL{})
+				g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+		})
+	})
+
 	When("Nova CR instance is deleted", func() {
 		BeforeEach(func() {
 			DeferCleanup(
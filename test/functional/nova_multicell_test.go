@@ -88,6 +88,7 @@ var _ = Describe("Nova multi cell", func() {
 			}
 			cell1Memcached := "memcached1"
 			cell1Template["memcachedInstance"] = cell1Memcached
+			cell1Template["availabilityZone"] = "az1"
 
 			cell2Template := GetDefaultNovaCellTemplate()
 			cell2Template["cellDatabaseInstance"] = cell2.MariaDBDatabaseName.Name
@@ -264,6 +265,27 @@ var _ = Describe("Nova multi cell", func() {
 			)
 		})
 
+		It("creates a NovaAggregate for the cell with availability zone", func() {
+			mariadb.SimulateMariaDBDatabaseCompleted(novaNames.APIMariaDBDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(novaNames.APIMariaDBDatabaseAccount)
+			mariadb.SimulateMariaDBDatabaseCompleted(cell0.MariaDBDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(cell0.MariaDBAccountName)
+			infra.SimulateTransportURLReady(cell0.TransportURLName)
+			th.SimulateJobSuccess(cell0.DBSyncJobName)
+			th.SimulateStatefulSetReplicaReady(cell0.ConductorStatefulSetName)
+			th.SimulateJobSuccess(cell0.CellMappingJobName)
+
+			aggregate := GetNovaAggregate(cell1.CellCRName)
+			Expect(aggregate.Spec.NovaInstance).To(Equal(novaNames.NovaName.Name))
+			Expect(aggregate.Spec.AvailabilityZone).To(Equal("az1"))
+			Expect(aggregate.Spec.CellName).To(Equal("cell1"))
+			Expect(aggregate.OwnerReferences).To(HaveLen(1))
+			Expect(aggregate.OwnerReferences[0].Kind).To(Equal("Nova"))
+
+			// cell2 does not request an availability zone
+			NovaAggregateNotExists(cell2.CellCRName)
+		})
+
 		It("creates all cell DBs", func() {
 			mariadb.SimulateMariaDBDatabaseCompleted(novaNames.APIMariaDBDatabaseName)
 			mariadb.SimulateMariaDBAccountCompleted(novaNames.APIMariaDBDatabaseAccount)
@@ -596,6 +618,41 @@ var _ = Describe("Nova multi cell", func() {
 				corev1.ConditionFalse,
 			)
 		})
+		It("summarises the capacity reported by the cells", func() {
+			mariadb.SimulateMariaDBDatabaseCompleted(cell2.MariaDBDatabaseName)
+			mariadb.SimulateMariaDBAccountCompleted(cell2.MariaDBAccountName)
+			infra.SimulateTransportURLReady(cell2.TransportURLName)
+			GetNovaCell(cell2.CellCRName)
+
+			Eventually(func(g Gomega) {
+				cell := GetNovaCell(cell2.CellCRName)
+				cell.Status.Capacity = &novav1.NovaCapacity{
+					Instances:         3,
+					ComputeServicesUp: 2,
+					VCPUs:             16,
+					VCPUsUsed:         6,
+					MemoryMB:          4096,
+					MemoryMBUsed:      1024,
+					LocalDiskGB:       100,
+					LocalDiskGBUsed:   30,
+					CollectedAt:       metav1.Now(),
+				}
+				g.Expect(k8sClient.Status().Update(ctx, cell)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				capacity := GetNova(novaNames.NovaName).Status.Capacity
+				g.Expect(capacity).NotTo(BeNil())
+				g.Expect(capacity.Instances).To(Equal(3))
+				g.Expect(capacity.ComputeServicesUp).To(Equal(2))
+				g.Expect(capacity.VCPUs).To(Equal(16))
+				g.Expect(capacity.VCPUsUsed).To(Equal(6))
+				g.Expect(capacity.MemoryMB).To(Equal(4096))
+				g.Expect(capacity.MemoryMBUsed).To(Equal(1024))
+				g.Expect(capacity.LocalDiskGB).To(Equal(100))
+				g.Expect(capacity.LocalDiskGBUsed).To(Equal(30))
+			}, timeout, interval).Should(Succeed())
+		})
 		It("creates Nova API even if cell1 and cell2 fails", func() {
 			mariadb.SimulateMariaDBDatabaseCompleted(novaNames.APIMariaDBDatabaseName)
 			mariadb.SimulateMariaDBAccountCompleted(novaNames.APIMariaDBDatabaseAccount)
@@ -238,6 +238,202 @@ var _ = Describe("Nova reconfiguration", func() {
 				corev1.ConditionTrue,
 			)
 		})
+
+		It("deletes the notification TransportURL of the cell", func() {
+			DeferCleanup(
+				k8sClient.Delete, ctx, th.CreateSecret(
+					types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "rabbitmq-notification-secret"},
+					map[string][]byte{
+						"transport_url": []byte("rabbit://notification/fake"),
+					},
+				))
+			notificationTransportURLName := types.NamespacedName{
+				Namespace: novaNames.NovaName.Namespace,
+				Name:      novaNames.NovaName.Name + "-cell1-notification-transport",
+			}
+			setCell1NotificationsBus := func(busInstance *string) {
+				Eventually(func(g Gomega) {
+					nova := GetNova(novaNames.NovaName)
+					template := nova.Spec.CellTemplates["cell1"]
+					template.NotificationsBusInstance = busInstance
+					nova.Spec.CellTemplates["cell1"] = template
+					g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+				}, timeout, interval).Should(Succeed())
+			}
+			notificationTransportURLIsDeleted := func() {
+				Eventually(func(g Gomega) {
+					err := k8sClient.Get(ctx, notificationTransportURLName, &rabbitmqv1.TransportURL{})
+					g.Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+				}, timeout, interval).Should(Succeed())
+			}
+
+			// removing the override of the cell deletes the TransportURL
+			setCell1NotificationsBus(ptr.To("rabbitmq-notification"))
+			infra.GetTransportURL(notificationTransportURLName)
+			setCell1NotificationsBus(nil)
+			notificationTransportURLIsDeleted()
+
+			// so does deleting the cell
+			setCell1NotificationsBus(ptr.To("rabbitmq-notification"))
+			infra.SimulateTransportURLReady(notificationTransportURLName)
+			Eventually(func(g Gomega) {
+				nova := GetNova(novaNames.NovaName)
+				delete(nova.Spec.CellTemplates, "cell1")
+				g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			th.SimulateJobSuccess(cell1.CellDeleteJobName)
+			NovaCellNotExists(cell1.CellCRName)
+			notificationTransportURLIsDeleted()
+		})
+
+		It("blocks the deletion of a non empty cell until it is forced", func() {
+			Eventually(func(g Gomega) {
+				nova := GetNova(novaNames.NovaName)
+				delete(nova.Spec.CellTemplates, "cell1")
+				g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				deleteJob := th.GetJob(cell1.CellDeleteJobName)
+				g.Expect(GetEnvVarValue(
+					deleteJob.Spec.Template.Spec.Containers[0].Env, "FORCE", "")).To(Equal("false"))
+				g.Expect(deleteJob.Spec.PodFailurePolicy).NotTo(BeNil())
+			}, timeout, interval).Should(Succeed())
+
+			// the job refuses to delete the cell as it still has hosts
+			pod := CreateManageCommandPod(cell1.CellDeleteJobName, 2, "cell cell1 has hosts: compute-0\n")
+			DeferCleanup(th.DeleteInstance, pod)
+			th.SimulateJobFailure(cell1.CellDeleteJobName)
+			th.ExpectConditionWithDetails(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaCellsDeletionCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"NovaCells deletion blocked as the cells are not empty: "+
+					"cell1(cell cell1 has hosts: compute-0; Job "+cell1.CellDeleteJobName.Name+"). "+
+					"Delete the Job of a cell to retry its deletion after the cell is emptied. "+
+					"Add the cell names to the nova.openstack.org/force-cell-delete "+
+					"annotation to force the deletion of the cells without instances",
+			)
+			Consistently(func(g Gomega) {
+				g.Expect(GetNovaCell(cell1.CellCRName)).NotTo(BeNil())
+			}, consistencyTimeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				nova := GetNova(novaNames.NovaName)
+				nova.Annotations = map[string]string{
+					novav1.CellForceDeleteAnnotation: "cell1",
+				}
+				g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			// the forced deletion re-runs the job
+			Eventually(func(g Gomega) {
+				deleteJob := th.GetJob(cell1.CellDeleteJobName)
+				g.Expect(GetEnvVarValue(
+					deleteJob.Spec.Template.Spec.Containers[0].Env, "FORCE", "")).To(Equal("true"))
+			}, timeout, interval).Should(Succeed())
+			th.SimulateJobSuccess(cell1.CellDeleteJobName)
+
+			NovaCellNotExists(cell1.CellCRName)
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaCellsDeletionCondition,
+				corev1.ConditionTrue,
+			)
+		})
+
+		It("retries the deletion of a blocked cell when its Job is deleted", func() {
+			Eventually(func(g Gomega) {
+				nova := GetNova(novaNames.NovaName)
+				delete(nova.Spec.CellTemplates, "cell1")
+				g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			pod := CreateManageCommandPod(cell1.CellDeleteJobName, 2, "cell cell1 has hosts: compute-0\n")
+			th.SimulateJobFailure(cell1.CellDeleteJobName)
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaCellsDeletionCondition,
+				corev1.ConditionFalse,
+			)
+			failedJob := th.GetJob(cell1.CellDeleteJobName)
+
+			// the cell is emptied and the failed Job is deleted
+			th.DeleteInstance(pod)
+			th.DeleteInstance(failedJob)
+			Eventually(func(g Gomega) {
+				g.Expect(th.GetJob(cell1.CellDeleteJobName).UID).NotTo(Equal(failedJob.UID))
+			}, timeout, interval).Should(Succeed())
+			th.SimulateJobSuccess(cell1.CellDeleteJobName)
+
+			NovaCellNotExists(cell1.CellCRName)
+		})
+
+		It("does not delete a cell with instances even if forced", func() {
+			Eventually(func(g Gomega) {
+				nova := GetNova(novaNames.NovaName)
+				nova.Annotations = map[string]string{
+					novav1.CellForceDeleteAnnotation: "cell1",
+				}
+				delete(nova.Spec.CellTemplates, "cell1")
+				g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+
+			Eventually(func(g Gomega) {
+				deleteJob := th.GetJob(cell1.CellDeleteJobName)
+				g.Expect(GetEnvVarValue(
+					deleteJob.Spec.Template.Spec.Containers[0].Env, "FORCE", "")).To(Equal("true"))
+				g.Expect(deleteJob.Spec.PodFailurePolicy).NotTo(BeNil())
+				g.Expect(deleteJob.Spec.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+			}, timeout, interval).Should(Succeed())
+
+			pod := CreateManageCommandPod(cell1.CellDeleteJobName, 3, "cell cell1 has instances on hosts: compute-0\n")
+			DeferCleanup(th.DeleteInstance, pod)
+			th.SimulateJobFailure(cell1.CellDeleteJobName)
+			Eventually(func(g Gomega) {
+				conditions := NovaConditionGetter(novaNames.NovaName)
+				cond := conditions.Get(novav1.NovaCellsDeletionCondition)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+				g.Expect(cond.Message).To(HavePrefix(
+					"NovaCells deletion blocked as the cells are not empty: " +
+						"cell1(cell cell1 has instances on hosts: compute-0; Job " +
+						cell1.CellDeleteJobName.Name + ")"))
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				g.Expect(GetNovaCell(cell1.CellCRName)).NotTo(BeNil())
+			}, consistencyTimeout, interval).Should(Succeed())
+		})
+
+		It("reports other failures of the cell deletion as failed", func() {
+			Eventually(func(g Gomega) {
+				nova := GetNova(novaNames.NovaName)
+				delete(nova.Spec.CellTemplates, "cell1")
+				g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			th.GetJob(cell1.CellDeleteJobName)
+
+			// e.g. the DB is not reachable
+			pod := CreateManageCommandPod(cell1.CellDeleteJobName, 1, "Could not connect to the database\n")
+			DeferCleanup(th.DeleteInstance, pod)
+			th.SimulateJobFailure(cell1.CellDeleteJobName)
+
+			Eventually(func(g Gomega) {
+				conditions := NovaConditionGetter(novaNames.NovaName)
+				cond := conditions.Get(novav1.NovaCellsDeletionCondition)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+				g.Expect(cond.Reason).To(Equal(condition.ErrorReason))
+				g.Expect(cond.Message).To(HavePrefix("NovaCells deletion failed: "))
+			}, timeout, interval).Should(Succeed())
+			Consistently(func(g Gomega) {
+				g.Expect(GetNovaCell(cell1.CellCRName)).NotTo(BeNil())
+			}, consistencyTimeout, interval).Should(Succeed())
+		})
 	})
 	When("cell0 conductor replicas is set to 0", func() {
 		It("sets the deployment replicas to 0", func() {
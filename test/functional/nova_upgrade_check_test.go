@@ -0,0 +1,214 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const newAPIImage = "quay.io/podified-antelope-centos9/openstack-nova-api:new"
+
+func UpdateNovaAPIImage(image string) {
+	Eventually(func(g Gomega) {
+		nova := GetNova(novaNames.NovaName)
+		nova.Spec.APIContainerImageURL = image
+		g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+// CreateUpgradeCheckPod simulates the pod of the upgrade check Job that
+// terminated with the given report
+func CreateUpgradeCheckPod(report string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      novaNames.UpgradeCheckJobName.Name + "-pod",
+			Namespace: novaNames.UpgradeCheckJobName.Namespace,
+			Labels: map[string]string{
+				"job-name": novaNames.UpgradeCheckJobName.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nova-status", Image: newAPIImage},
+			},
+		},
+	}
+	Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "nova-status",
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   2,
+					Message:    report,
+					FinishedAt: metav1.Now(),
+				},
+			},
+		},
+	}
+	Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+	return pod
+}
+
+var _ = Describe("Nova upgrade check", func() {
+	BeforeEach(func() {
+		CreateNovaWith3CellsAndEnsureReady(novaNames)
+	})
+
+	It("deploys the initial images without upgrade check", func() {
+		th.ExpectCondition(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaUpgradeCheckReadyCondition,
+			corev1.ConditionTrue,
+		)
+		nova := GetNova(novaNames.NovaName)
+		Expect(nova.Status.DeployedImages).NotTo(BeNil())
+		Expect(*nova.Status.DeployedImages).To(Equal(nova.Spec.NovaImages))
+
+		err := k8sClient.Get(ctx, novaNames.UpgradeCheckJobName, &batchv1.Job{})
+		Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	When("the API image is changed", func() {
+		var oldAPIImage string
+
+		BeforeEach(func() {
+			oldAPIImage = GetNova(novaNames.NovaName).Spec.APIContainerImageURL
+			UpdateNovaAPIImage(newAPIImage)
+		})
+
+		It("runs the upgrade check with the new image and keeps the old one meanwhile", func() {
+			job := th.GetJob(novaNames.UpgradeCheckJobName)
+			Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(newAPIImage))
+			Expect(job.Spec.Template.Spec.Containers[0].TerminationMessagePolicy).To(
+				Equal(corev1.TerminationMessageFallbackToLogsOnError))
+
+			th.ExpectConditionWithDetails(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaUpgradeCheckReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				novav1.NovaUpgradeCheckReadyRunningMessage,
+			)
+			Consistently(func(g Gomega) {
+				api := GetNovaAPI(novaNames.APIName)
+				g.Expect(api.Spec.ContainerImage).To(Equal(oldAPIImage))
+			}, consistencyTimeout, interval).Should(Succeed())
+		})
+
+		It("deploys the new image after the upgrade check passed", func() {
+			th.SimulateJobSuccess(novaNames.UpgradeCheckJobName)
+			SimulateServicesRolledOut()
+
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaUpgradeCheckReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Eventually(func(g Gomega) {
+				api := GetNovaAPI(novaNames.APIName)
+				g.Expect(api.Spec.ContainerImage).To(Equal(newAPIImage))
+				nova := GetNova(novaNames.NovaName)
+				g.Expect(nova.Status.DeployedImages.APIContainerImageURL).To(Equal(newAPIImage))
+				g.Expect(nova.Status.Hash).To(HaveKey(novav1.UpgradeCheckHashKey))
+				g.Expect(nova.Status.UpgradeCheckFailure).To(BeNil())
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports the failed upgrade check and keeps the old image", func() {
+			pod := CreateUpgradeCheckPod("Check: Cells v2\nResult: Failure")
+			DeferCleanup(th.DeleteInstance, pod)
+			th.SimulateJobFailure(novaNames.UpgradeCheckJobName)
+
+			Eventually(func(g Gomega) {
+				conditions := NovaConditionGetter(novaNames.NovaName)
+				cond := conditions.Get(novav1.NovaUpgradeCheckReadyCondition)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+				g.Expect(cond.Reason).To(Equal(condition.ErrorReason))
+				g.Expect(cond.Message).To(ContainSubstring("Result: Failure"))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionFalse,
+			)
+			Eventually(func(g Gomega) {
+				failure := GetNova(novaNames.NovaName).Status.UpgradeCheckFailure
+				g.Expect(failure).NotTo(BeNil())
+				g.Expect(failure.Images.APIContainerImageURL).To(Equal(newAPIImage))
+				g.Expect(failure.Report).To(Equal("Check: Cells v2\nResult: Failure"))
+			}, timeout, interval).Should(Succeed())
+
+			// the report is kept after the pod of the Job is removed
+			th.DeleteInstance(pod)
+			Consistently(func(g Gomega) {
+				conditions := NovaConditionGetter(novaNames.NovaName)
+				cond := conditions.Get(novav1.NovaUpgradeCheckReadyCondition)
+				g.Expect(cond).NotTo(BeNil())
+				g.Expect(cond.Message).To(ContainSubstring("Result: Failure"))
+				g.Expect(GetNova(novaNames.NovaName).Status.UpgradeCheckFailure).NotTo(BeNil())
+			}, consistencyTimeout, interval).Should(Succeed())
+
+			Consistently(func(g Gomega) {
+				api := GetNovaAPI(novaNames.APIName)
+				g.Expect(api.Spec.ContainerImage).To(Equal(oldAPIImage))
+			}, consistencyTimeout, interval).Should(Succeed())
+		})
+	})
+
+	It("deploys the new image without upgrade check if overridden", func() {
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			nova.Annotations = map[string]string{
+				novav1.UpgradeCheckOverrideAnnotation: "true",
+			}
+			nova.Spec.APIContainerImageURL = newAPIImage
+			g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			api := GetNovaAPI(novaNames.APIName)
+			g.Expect(api.Spec.ContainerImage).To(Equal(newAPIImage))
+		}, timeout, interval).Should(Succeed())
+		th.ExpectConditionWithDetails(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaUpgradeCheckReadyCondition,
+			corev1.ConditionTrue,
+			condition.ReadyReason,
+			"Upgrade check is overridden by the nova.openstack.org/upgrade-check-override annotation",
+		)
+
+		err := k8sClient.Get(ctx, novaNames.UpgradeCheckJobName, &batchv1.Job{})
+		Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+	})
+})
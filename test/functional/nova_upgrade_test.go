@@ -0,0 +1,263 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const newConductorImage = "quay.io/podified-antelope-centos9/openstack-nova-conductor:new"
+
+func ExpectUpgradePhase(phase novav1.NovaUpgradePhase) {
+	Eventually(func(g Gomega) {
+		nova := GetNova(novaNames.NovaName)
+		g.Expect(nova.Status.UpgradePhase).To(Equal(phase))
+	}, timeout, interval).Should(Succeed())
+}
+
+// UpdateNovaConductorImage sets the conductor image of the Nova
+func UpdateNovaConductorImage(image string) {
+	Eventually(func(g Gomega) {
+		nova := GetNova(novaNames.NovaName)
+		nova.Spec.ConductorContainerImageURL = image
+		g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+func ExpectConductorImage(cell CellNames, image string) {
+	Eventually(func(g Gomega) {
+		conductor := GetNovaConductor(cell.ConductorName)
+		g.Expect(conductor.Spec.ContainerImage).To(Equal(image))
+	}, timeout, interval).Should(Succeed())
+}
+
+// SimulateConductorUpgraded simulates that the cell DB is synced with the
+// new conductor image and the conductor is rolled out
+func SimulateConductorUpgraded(cell CellNames) {
+	ExpectConductorImage(cell, newConductorImage)
+	th.SimulateJobSuccess(cell.DBSyncJobName)
+	th.SimulateStatefulSetReplicaReady(cell.ConductorStatefulSetName)
+}
+
+// SimulateServicesRolledOut simulates that every StatefulSet of the
+// deployment is rolled out until the upgrade is completed
+func SimulateServicesRolledOut() {
+	Eventually(func(g Gomega) {
+		for _, cell := range []CellNames{cell0, cell1, cell2} {
+			th.SimulateStatefulSetReplicaReady(cell.ConductorStatefulSetName)
+		}
+		th.SimulateStatefulSetReplicaReady(cell1.NoVNCProxyStatefulSetName)
+		th.SimulateStatefulSetReplicaReady(cell2.NoVNCProxyStatefulSetName)
+		th.SimulateStatefulSetReplicaReady(cell1.NovaComputeStatefulSetName)
+		th.SimulateStatefulSetReplicaReady(novaNames.SchedulerStatefulSetName)
+		th.SimulateStatefulSetReplicaReady(novaNames.APIStatefulSetName)
+		th.SimulateStatefulSetReplicaReady(novaNames.MetadataStatefulSetName)
+
+		nova := GetNova(novaNames.NovaName)
+		g.Expect(nova.Status.UpgradePhase).To(Equal(novav1.NovaUpgradePhaseCompleted))
+	}, timeout, interval).Should(Succeed())
+}
+
+var _ = Describe("Nova ordered upgrade", func() {
+	BeforeEach(func() {
+		CreateNovaWith3CellsAndEnsureReady(novaNames)
+	})
+
+	It("reports that no upgrade is in progress", func() {
+		th.ExpectCondition(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaUpgradeReadyCondition,
+			corev1.ConditionTrue,
+		)
+		nova := GetNova(novaNames.NovaName)
+		Expect(nova.Status.UpgradePhase).To(BeEmpty())
+		Expect(nova.Spec.ComputeRPCPin).To(Equal("auto"))
+		Expect(GetNovaCell(cell1.CellCRName).Spec.OnlineDataMigrationsDeferred).To(BeFalse())
+	})
+
+	It("upgrades the conductors cell by cell and migrates the data last", func() {
+		oldConductorImage := GetNova(novaNames.NovaName).Spec.ConductorContainerImageURL
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			nova.Spec.ConductorContainerImageURL = newConductorImage
+			g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+		}, timeout, interval).Should(Succeed())
+		th.SimulateJobSuccess(novaNames.UpgradeCheckJobName)
+
+		// cell0 syncs the API and the cell0 DB first
+		ExpectUpgradePhase(novav1.NovaUpgradePhaseDBSync)
+		nova := GetNova(novaNames.NovaName)
+		Expect(nova.Status.PreviousImages.ConductorContainerImageURL).To(Equal(oldConductorImage))
+		Expect(nova.Status.TargetImages.ConductorContainerImageURL).To(Equal(newConductorImage))
+		Expect(nova.Status.DeployedImages.ConductorContainerImageURL).To(Equal(oldConductorImage))
+		th.ExpectConditionWithDetails(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaUpgradeReadyCondition,
+			corev1.ConditionFalse,
+			condition.RequestedReason,
+			"Upgrade in progress in the DBSync phase",
+		)
+		ExpectConductorImage(cell0, newConductorImage)
+		Consistently(func(g Gomega) {
+			g.Expect(GetNovaConductor(cell1.ConductorName).Spec.ContainerImage).To(Equal(oldConductorImage))
+			g.Expect(GetNovaConductor(cell2.ConductorName).Spec.ContainerImage).To(Equal(oldConductorImage))
+		}, consistencyTimeout, interval).Should(Succeed())
+		Eventually(func(g Gomega) {
+			g.Expect(GetNovaCell(cell1.CellCRName).Spec.OnlineDataMigrationsDeferred).To(BeTrue())
+		}, timeout, interval).Should(Succeed())
+
+		// then the other cells one by one
+		SimulateConductorUpgraded(cell0)
+		ExpectUpgradePhase(novav1.NovaUpgradePhaseConductors)
+		ExpectConductorImage(cell1, newConductorImage)
+		Consistently(func(g Gomega) {
+			g.Expect(GetNovaConductor(cell2.ConductorName).Spec.ContainerImage).To(Equal(oldConductorImage))
+		}, consistencyTimeout, interval).Should(Succeed())
+
+		SimulateConductorUpgraded(cell1)
+		ExpectConductorImage(cell2, newConductorImage)
+		SimulateConductorUpgraded(cell2)
+
+		// the online data migrations are run after every service is
+		// upgraded
+		ExpectUpgradePhase(novav1.NovaUpgradePhaseOnlineDataMigration)
+		Eventually(func(g Gomega) {
+			g.Expect(GetNovaCell(cell1.CellCRName).Spec.OnlineDataMigrationsDeferred).To(BeFalse())
+		}, timeout, interval).Should(Succeed())
+		for _, cell := range []CellNames{cell0, cell1, cell2} {
+			th.SimulateJobSuccess(cell.OnlineDataMigrationJobName)
+		}
+
+		ExpectUpgradePhase(novav1.NovaUpgradePhaseCompleted)
+		nova = GetNova(novaNames.NovaName)
+		Expect(nova.Status.DeployedImages.ConductorContainerImageURL).To(Equal(newConductorImage))
+		th.ExpectCondition(
+			novaNames.NovaName,
+			ConditionGetterFunc(NovaConditionGetter),
+			novav1.NovaUpgradeReadyCondition,
+			corev1.ConditionTrue,
+		)
+	})
+
+	When("an upgrade is in progress", func() {
+		var oldConductorImage string
+
+		BeforeEach(func() {
+			oldConductorImage = GetNova(novaNames.NovaName).Spec.ConductorContainerImageURL
+			UpdateNovaConductorImage(newConductorImage)
+			th.SimulateJobSuccess(novaNames.UpgradeCheckJobName)
+			ExpectUpgradePhase(novav1.NovaUpgradePhaseDBSync)
+			ExpectConductorImage(cell0, newConductorImage)
+		})
+
+		It("reports the passed upgrade check during the upgrade", func() {
+			th.ExpectConditionWithDetails(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaUpgradeCheckReadyCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				novav1.NovaUpgradeCheckReadyMessage,
+			)
+		})
+
+		It("aborts the upgrade when the previous images are requested", func() {
+			UpdateNovaConductorImage(oldConductorImage)
+
+			ExpectUpgradePhase(novav1.NovaUpgradePhaseAborted)
+			ExpectConductorImage(cell0, oldConductorImage)
+			th.ExpectCondition(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaUpgradeReadyCondition,
+				corev1.ConditionTrue,
+			)
+			nova := GetNova(novaNames.NovaName)
+			Expect(nova.Status.DeployedImages.ConductorContainerImageURL).To(Equal(oldConductorImage))
+		})
+
+		It("restarts the upgrade with the images requested during it", func() {
+			UpdateNovaConductorImage(hotfixConductorImage)
+
+			// the new images are checked while the upgrade continues
+			th.ExpectConditionWithDetails(
+				novaNames.NovaName,
+				ConditionGetterFunc(NovaConditionGetter),
+				novav1.NovaUpgradeCheckReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				novav1.NovaUpgradeCheckReadyRunningMessage,
+			)
+			Expect(GetNova(novaNames.NovaName).Status.TargetImages.ConductorContainerImageURL).To(
+				Equal(newConductorImage))
+
+			th.SimulateJobSuccess(novaNames.UpgradeCheckJobName)
+			Eventually(func(g Gomega) {
+				nova := GetNova(novaNames.NovaName)
+				g.Expect(nova.Status.TargetImages.ConductorContainerImageURL).To(Equal(hotfixConductorImage))
+				g.Expect(nova.Status.PreviousImages.ConductorContainerImageURL).To(Equal(oldConductorImage))
+				g.Expect(nova.Status.UpgradePhase).To(Equal(novav1.NovaUpgradePhaseDBSync))
+			}, timeout, interval).Should(Succeed())
+			ExpectConductorImage(cell0, hotfixConductorImage)
+		})
+	})
+
+	It("pins the compute RPC version and upgrades the API after the scheduler", func() {
+		oldAPIImage := GetNova(novaNames.NovaName).Spec.APIContainerImageURL
+		Eventually(func(g Gomega) {
+			nova := GetNova(novaNames.NovaName)
+			nova.Annotations = map[string]string{
+				novav1.UpgradeCheckOverrideAnnotation: "true",
+			}
+			nova.Spec.ComputeRPCPin = "antelope"
+			nova.Spec.APIContainerImageURL = newAPIImage
+			g.Expect(k8sClient.Update(ctx, nova)).To(Succeed())
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			conductor := GetNovaConductor(cell0.ConductorName)
+			g.Expect(conductor.Spec.ComputeRPCPin).To(Equal("antelope"))
+			api := GetNovaAPI(novaNames.APIName)
+			g.Expect(api.Spec.ContainerImage).To(Equal(oldAPIImage))
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			configData := th.GetSecret(cell0.ConductorConfigDataName)
+			g.Expect(string(configData.Data["01-nova.conf"])).Should(
+				ContainSubstring("[upgrade_levels]\ncompute = antelope"))
+		}, timeout, interval).Should(Succeed())
+
+		SimulateServicesRolledOut()
+
+		Eventually(func(g Gomega) {
+			api := GetNovaAPI(novaNames.APIName)
+			g.Expect(api.Spec.ContainerImage).To(Equal(newAPIImage))
+			g.Expect(api.Spec.ComputeRPCPin).To(BeEmpty())
+			scheduler := GetNovaScheduler(novaNames.SchedulerName)
+			g.Expect(scheduler.Spec.ComputeRPCPin).To(BeEmpty())
+		}, timeout, interval).Should(Succeed())
+	})
+})
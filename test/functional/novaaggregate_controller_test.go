@@ -0,0 +1,247 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("NovaAggregate controller", func() {
+	var aggregateName types.NamespacedName
+
+	BeforeEach(func() {
+		aggregateName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "agg-test",
+		}
+	})
+
+	When("a NovaAggregate is created but the NovaAPI does not exist", func() {
+		BeforeEach(func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaAggregate(aggregateName, GetDefaultNovaAggregateSpec(novaNames)))
+		})
+
+		It("waits for the NovaAPI", func() {
+			th.ExpectConditionWithDetails(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				novav1.NovaAPIReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for NovaAPI "+novaNames.APIName.Name+" to become Ready",
+			)
+			th.ExpectCondition(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionFalse,
+			)
+		})
+	})
+
+	When("the NovaAPI is Ready", func() {
+		var novaAPIFixture *NovaAPIFixture
+
+		BeforeEach(func() {
+			keystoneFixture, f := SetupAPIFixtures(logger)
+			novaAPIFixture = f
+			SimulateReadyOfNovaAPI(keystoneFixture.Endpoint())
+		})
+
+		It("creates an aggregate with availability zone, metadata and hosts", func() {
+			spec := GetDefaultNovaAggregateSpec(novaNames)
+			spec["availabilityZone"] = "az1"
+			spec["metadata"] = map[string]interface{}{
+				"storage": "ssd",
+			}
+			spec["hosts"] = []string{"nova-compute-0", "nova-compute-1"}
+			DeferCleanup(th.DeleteInstance, CreateNovaAggregate(aggregateName, spec))
+
+			th.ExpectCondition(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			aggregate := novaAPIFixture.FindAggregateByName("agg-test")
+			Expect(aggregate).NotTo(BeNil())
+			Expect(aggregate.AvailabilityZone).To(Equal("az1"))
+			Expect(aggregate.Metadata).To(Equal(map[string]string{
+				"availability_zone": "az1",
+				"storage":           "ssd",
+			}))
+			Expect(aggregate.Hosts).To(ConsistOf("nova-compute-0", "nova-compute-1"))
+
+			instance := GetNovaAggregate(aggregateName)
+			Expect(instance.Status.AggregateID).To(Equal(aggregate.ID))
+			Expect(instance.Status.Hosts).To(Equal([]string{"nova-compute-0", "nova-compute-1"}))
+			Expect(instance.Status.MissingHosts).To(BeEmpty())
+			Expect(instance.Finalizers).To(ContainElement("openstack.org/novaaggregate"))
+		})
+
+		It("reports the hosts that are not registered in the compute API", func() {
+			spec := GetDefaultNovaAggregateSpec(novaNames)
+			spec["hosts"] = []string{"nova-compute-0", "not-yet-registered"}
+			DeferCleanup(th.DeleteInstance, CreateNovaAggregate(aggregateName, spec))
+
+			th.ExpectConditionWithDetails(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				novav1.NovaAggregateSyncedCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for hosts to be registered in the compute API: not-yet-registered",
+			)
+			instance := GetNovaAggregate(aggregateName)
+			Expect(instance.Status.Hosts).To(Equal([]string{"nova-compute-0"}))
+			Expect(instance.Status.MissingHosts).To(Equal([]string{"not-yet-registered"}))
+
+			// the host is added when the compute service registers itself
+			novaAPIFixture.Services = append(novaAPIFixture.Services, Service{
+				ID:     "9",
+				Binary: "nova-compute",
+				Host:   "not-yet-registered",
+				State:  "up",
+				Status: "enabled",
+			})
+			th.ExpectCondition(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(novaAPIFixture.FindAggregateByName("agg-test").Hosts).To(
+				ConsistOf("nova-compute-0", "not-yet-registered"))
+		})
+
+		It("adds the compute hosts mapped to the cell", func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaCell(cell1.CellCRName, GetDefaultNovaCellSpec(cell1)))
+			// e.g. an EDPM compute that is not deployed by a NovaCompute
+			edpmHost := "edpm-compute-0"
+			novaAPIFixture.Services = append(novaAPIFixture.Services, Service{
+				ID:     "9",
+				Binary: "nova-compute",
+				Host:   edpmHost,
+				State:  "up",
+				Status: "enabled",
+			})
+
+			spec := GetDefaultNovaAggregateSpec(novaNames)
+			spec["cellName"] = cell1.CellName
+			spec["hosts"] = []string{"nova-compute-0"}
+			DeferCleanup(th.DeleteInstance, CreateNovaAggregate(aggregateName, spec))
+
+			th.ExpectCondition(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(novaAPIFixture.FindAggregateByName("agg-test").Hosts).To(
+				ConsistOf("nova-compute-0"))
+
+			SetNovaCellHostMappings(cell1.CellCRName, edpmHost, "edpm-compute-1")
+
+			th.ExpectConditionWithDetails(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				novav1.NovaAggregateSyncedCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for hosts to be registered in the compute API: edpm-compute-1",
+			)
+			Expect(novaAPIFixture.FindAggregateByName("agg-test").Hosts).To(
+				ConsistOf("nova-compute-0", edpmHost))
+		})
+
+		It("reports the hosts the compute API refuses to add", func() {
+			novaAPIFixture.AddAggregate("other-az", "az2", "nova-compute-1")
+
+			spec := GetDefaultNovaAggregateSpec(novaNames)
+			spec["availabilityZone"] = "az1"
+			spec["hosts"] = []string{"nova-compute-0", "nova-compute-1"}
+			DeferCleanup(th.DeleteInstance, CreateNovaAggregate(aggregateName, spec))
+
+			Eventually(func(g Gomega) {
+				instance := GetNovaAggregate(aggregateName)
+				g.Expect(instance.Status.Hosts).To(Equal([]string{"nova-compute-0"}))
+				g.Expect(instance.Status.FailedHosts).To(HaveLen(1))
+				g.Expect(instance.Status.FailedHosts[0]).To(HavePrefix("nova-compute-1: "))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				novav1.NovaAggregateSyncedCondition,
+				corev1.ConditionFalse,
+			)
+			Expect(novaAPIFixture.FindAggregateByName("agg-test").Hosts).To(
+				ConsistOf("nova-compute-0"))
+		})
+
+		It("adopts an existing aggregate and syncs it", func() {
+			existing := novaAPIFixture.AddAggregate("agg-test", "old-az", "nova-compute-1")
+			existing.Metadata["stale"] = "true"
+
+			spec := GetDefaultNovaAggregateSpec(novaNames)
+			spec["availabilityZone"] = "az1"
+			spec["hosts"] = []string{"nova-compute-0"}
+			DeferCleanup(th.DeleteInstance, CreateNovaAggregate(aggregateName, spec))
+
+			th.ExpectCondition(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(novaAPIFixture.Aggregates).To(HaveLen(1))
+			Expect(GetNovaAggregate(aggregateName).Status.AggregateID).To(Equal(existing.ID))
+			Expect(existing.AvailabilityZone).To(Equal("az1"))
+			Expect(existing.Metadata).To(Equal(map[string]string{"availability_zone": "az1"}))
+			Expect(existing.Hosts).To(ConsistOf("nova-compute-0"))
+		})
+
+		It("deletes the aggregate when the NovaAggregate is deleted", func() {
+			spec := GetDefaultNovaAggregateSpec(novaNames)
+			spec["hosts"] = []string{"nova-compute-0"}
+			CreateNovaAggregate(aggregateName, spec)
+			th.ExpectCondition(
+				aggregateName,
+				ConditionGetterFunc(NovaAggregateConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			aggregateID := GetNovaAggregate(aggregateName).Status.AggregateID
+
+			th.DeleteInstance(GetNovaAggregate(aggregateName))
+
+			Expect(novaAPIFixture.HasRequest(
+				"DELETE", fmt.Sprintf("/compute/os-aggregates/%d", aggregateID), "")).To(BeTrue())
+			Expect(novaAPIFixture.Aggregates).To(BeEmpty())
+		})
+	})
+})
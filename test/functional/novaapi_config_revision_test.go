@@ -0,0 +1,171 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// GetNovaAPIConfigRevisions returns the config revision Secrets of the
+// NovaAPI
+func GetNovaAPIConfigRevisions(g Gomega) []corev1.Secret {
+	revisions := &corev1.SecretList{}
+	g.Expect(k8sClient.List(
+		ctx, revisions, client.InNamespace(novaNames.APIName.Namespace),
+		client.MatchingLabels{novav1.ConfigRevisionOfLabel: novaNames.APIName.Name},
+	)).To(Succeed())
+	return revisions.Items
+}
+
+func UpdateNovaAPICustomServiceConfig(customServiceConfig string) {
+	Eventually(func(g Gomega) {
+		api := GetNovaAPI(novaNames.APIName)
+		api.Spec.CustomServiceConfig = customServiceConfig
+		g.Expect(k8sClient.Update(ctx, api)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+// ExpectNovaAPIConfigRevision waits until the customServiceConfig is
+// deployed and returns the name of its config revision
+func ExpectNovaAPIConfigRevision(customServiceConfig string) string {
+	revision := ""
+	Eventually(func(g Gomega) {
+		configData := th.GetSecret(novaNames.APIConfigDataName)
+		g.Expect(string(configData.Data["02-nova-override.conf"])).To(ContainSubstring(customServiceConfig))
+		revision = GetNovaAPI(novaNames.APIName).Status.ConfigRevision
+		g.Expect(revision).NotTo(BeEmpty())
+		configRevision := th.GetSecret(types.NamespacedName{
+			Namespace: novaNames.APIName.Namespace, Name: revision})
+		g.Expect(configRevision.Data).To(Equal(configData.Data))
+	}, timeout, interval).Should(Succeed())
+	return revision
+}
+
+func SetNovaAPIConfigRevisionAnnotation(revision string) {
+	Eventually(func(g Gomega) {
+		api := GetNovaAPI(novaNames.APIName)
+		api.Annotations = map[string]string{
+			novav1.ConfigRevisionAnnotation: revision,
+		}
+		g.Expect(k8sClient.Update(ctx, api)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+var _ = Describe("NovaAPI config revisions", func() {
+	BeforeEach(func() {
+		mariadb.CreateMariaDBDatabase(novaNames.APIMariaDBDatabaseName.Namespace, novaNames.APIMariaDBDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+		DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(novaNames.APIMariaDBDatabaseName))
+
+		apiMariaDBAccount, apiMariaDBSecret := mariadb.CreateMariaDBAccountAndSecret(
+			novaNames.APIMariaDBDatabaseAccount, mariadbv1.MariaDBAccountSpec{})
+		DeferCleanup(k8sClient.Delete, ctx, apiMariaDBAccount)
+		DeferCleanup(k8sClient.Delete, ctx, apiMariaDBSecret)
+
+		cell0Account, cell0Secret := mariadb.CreateMariaDBAccountAndSecret(
+			cell0.MariaDBAccountName, mariadbv1.MariaDBAccountSpec{})
+		DeferCleanup(k8sClient.Delete, ctx, cell0Account)
+		DeferCleanup(k8sClient.Delete, ctx, cell0Secret)
+		memcachedSpec := infra.GetDefaultMemcachedSpec()
+		DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(novaNames.NovaName.Namespace, MemcachedInstance, memcachedSpec))
+		infra.SimulateMemcachedReady(novaNames.MemcachedNamespace)
+		DeferCleanup(
+			k8sClient.Delete, ctx, CreateInternalTopLevelSecret(novaNames))
+
+		spec := GetDefaultNovaAPISpec(novaNames)
+		spec["customServiceConfig"] = "foo=rev1"
+		spec["configRevisionHistoryLimit"] = 2
+		DeferCleanup(th.DeleteInstance, CreateNovaAPI(novaNames.APIName, spec))
+	})
+
+	It("stores the rendered config in an immutable revision", func() {
+		revision := ExpectNovaAPIConfigRevision("foo=rev1")
+
+		configRevision := th.GetSecret(types.NamespacedName{
+			Namespace: novaNames.APIName.Namespace, Name: revision})
+		Expect(configRevision.Immutable).NotTo(BeNil())
+		Expect(*configRevision.Immutable).To(BeTrue())
+		Expect(configRevision.Labels).To(HaveKeyWithValue(
+			novav1.ConfigRevisionOfLabel, novaNames.APIName.Name))
+		Expect(configRevision.Annotations).To(HaveKeyWithValue(
+			novav1.ConfigRevisionNumberAnnotation, "1"))
+		Expect(configRevision.OwnerReferences).To(HaveLen(1))
+	})
+
+	It("keeps the last revisions up to the history limit", func() {
+		rev1 := ExpectNovaAPIConfigRevision("foo=rev1")
+		UpdateNovaAPICustomServiceConfig("foo=rev2")
+		rev2 := ExpectNovaAPIConfigRevision("foo=rev2")
+		UpdateNovaAPICustomServiceConfig("foo=rev3")
+		rev3 := ExpectNovaAPIConfigRevision("foo=rev3")
+
+		Eventually(func(g Gomega) {
+			names := []string{}
+			for _, revision := range GetNovaAPIConfigRevisions(g) {
+				names = append(names, revision.Name)
+			}
+			g.Expect(names).To(ConsistOf(rev2, rev3))
+		}, timeout, interval).Should(Succeed())
+		Expect(rev1).NotTo(BeElementOf(rev2, rev3))
+	})
+
+	It("deploys the requested config revision", func() {
+		rev1 := ExpectNovaAPIConfigRevision("foo=rev1")
+		UpdateNovaAPICustomServiceConfig("foo=rev2")
+		ExpectNovaAPIConfigRevision("foo=rev2")
+		rev2InputHash := GetNovaAPI(novaNames.APIName).Status.Hash["input"]
+
+		SetNovaAPIConfigRevisionAnnotation(rev1)
+
+		Expect(ExpectNovaAPIConfigRevision("foo=rev1")).To(Equal(rev1))
+		Eventually(func(g Gomega) {
+			api := GetNovaAPI(novaNames.APIName)
+			g.Expect(api.Status.Hash["input"]).NotTo(Equal(rev2InputHash))
+			configRevision := th.GetSecret(types.NamespacedName{
+				Namespace: novaNames.APIName.Namespace, Name: rev1})
+			g.Expect(configRevision.Annotations).To(HaveKeyWithValue(
+				novav1.ConfigRevisionNumberAnnotation, "3"))
+		}, timeout, interval).Should(Succeed())
+
+		// removing the annotation deploys the config rendered from the Spec
+		SetNovaAPIConfigRevisionAnnotation("")
+		ExpectNovaAPIConfigRevision("foo=rev2")
+	})
+
+	It("reports if the requested config revision does not exist", func() {
+		ExpectNovaAPIConfigRevision("foo=rev1")
+		SetNovaAPIConfigRevisionAnnotation("nova-api-config-data-missing")
+
+		th.ExpectConditionWithDetails(
+			novaNames.APIName,
+			ConditionGetterFunc(NovaAPIConditionGetter),
+			condition.ServiceConfigReadyCondition,
+			corev1.ConditionFalse,
+			condition.ErrorReason,
+			"Service config create error occurred requested config revision nova-api-config-data-missing not found",
+		)
+	})
+})
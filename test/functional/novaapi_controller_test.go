@@ -207,6 +207,10 @@ var _ = Describe("NovaAPI controller", func() {
 				Expect(configData).Should(ContainSubstring("osapi_compute_workers=1"))
 				Expect(configData).Should(ContainSubstring("auth_url = keystone-internal-auth-url"))
 				Expect(configData).Should(ContainSubstring("www_authenticate_uri = keystone-public-auth-url"))
+				Expect(configData).Should(ContainSubstring("region_name = regionOne"))
+				Expect(configData).Should(ContainSubstring("project_name = service"))
+				Expect(configData).Should(ContainSubstring("project_domain_name = Default"))
+				Expect(configData).Should(ContainSubstring("user_domain_name = Default"))
 				Expect(configData).Should(
 					ContainSubstring("[upgrade_levels]\ncompute = auto"))
 				memcacheInstance := infra.GetMemcached(novaNames.MemcachedNamespace)
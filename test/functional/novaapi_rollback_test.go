@@ -0,0 +1,180 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const brokenAPIImage = "quay.io/podified-antelope-centos9/openstack-nova-api:broken"
+
+// UpdateNovaAPIRevision changes the container image and the config of the
+// NovaAPI
+func UpdateNovaAPIRevision(image string, customServiceConfig string) {
+	Eventually(func(g Gomega) {
+		api := GetNovaAPI(novaNames.APIName)
+		api.Spec.ContainerImage = image
+		api.Spec.CustomServiceConfig = customServiceConfig
+		g.Expect(k8sClient.Update(ctx, api)).To(Succeed())
+	}, timeout, interval).Should(Succeed())
+}
+
+func ExpectAPIStatefulSetImage(image string) {
+	Eventually(func(g Gomega) {
+		ss := th.GetStatefulSet(novaNames.APIStatefulSetName)
+		g.Expect(ss.Spec.Template.Spec.Containers[1].Image).To(Equal(image))
+	}, timeout, interval).Should(Succeed())
+}
+
+var _ = Describe("NovaAPI rollback", func() {
+	var spec map[string]interface{}
+
+	BeforeEach(func() {
+		mariadb.CreateMariaDBDatabase(novaNames.APIMariaDBDatabaseName.Namespace, novaNames.APIMariaDBDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+		DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(novaNames.APIMariaDBDatabaseName))
+
+		apiMariaDBAccount, apiMariaDBSecret := mariadb.CreateMariaDBAccountAndSecret(
+			novaNames.APIMariaDBDatabaseAccount, mariadbv1.MariaDBAccountSpec{})
+		DeferCleanup(k8sClient.Delete, ctx, apiMariaDBAccount)
+		DeferCleanup(k8sClient.Delete, ctx, apiMariaDBSecret)
+
+		cell0Account, cell0Secret := mariadb.CreateMariaDBAccountAndSecret(
+			cell0.MariaDBAccountName, mariadbv1.MariaDBAccountSpec{})
+		DeferCleanup(k8sClient.Delete, ctx, cell0Account)
+		DeferCleanup(k8sClient.Delete, ctx, cell0Secret)
+		memcachedSpec := infra.GetDefaultMemcachedSpec()
+		DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(novaNames.NovaName.Namespace, MemcachedInstance, memcachedSpec))
+		infra.SimulateMemcachedReady(novaNames.MemcachedNamespace)
+		DeferCleanup(
+			k8sClient.Delete, ctx, CreateInternalTopLevelSecret(novaNames))
+
+		spec = GetDefaultNovaAPISpec(novaNames)
+		spec["rolloutDeadline"] = 2
+		spec["customServiceConfig"] = "foo=good"
+	})
+
+	JustBeforeEach(func() {
+		DeferCleanup(th.DeleteInstance, CreateNovaAPI(novaNames.APIName, spec))
+		th.SimulateStatefulSetReplicaReady(novaNames.APIStatefulSetName)
+		th.ExpectCondition(
+			novaNames.APIName,
+			ConditionGetterFunc(NovaAPIConditionGetter),
+			condition.DeploymentReadyCondition,
+			corev1.ConditionTrue,
+		)
+	})
+
+	It("records the last known-good revision", func() {
+		th.ExpectCondition(
+			novaNames.APIName,
+			ConditionGetterFunc(NovaAPIConditionGetter),
+			novav1.NovaRolloutReadyCondition,
+			corev1.ConditionTrue,
+		)
+		Eventually(func(g Gomega) {
+			api := GetNovaAPI(novaNames.APIName)
+			g.Expect(api.Status.Rollout.LastKnownGood).NotTo(BeNil())
+			g.Expect(api.Status.Rollout.LastKnownGood.ContainerImage).To(Equal(ContainerImage))
+			g.Expect(api.Status.Rollout.LastKnownGood.ConfigHash).To(Equal(api.Status.Hash["input"]))
+			g.Expect(api.Status.Rollout.Current).To(BeNil())
+		}, timeout, interval).Should(Succeed())
+
+		knownGood := th.GetSecret(novaNames.APIKnownGoodConfigDataName)
+		Expect(knownGood.Data).To(Equal(th.GetSecret(novaNames.APIConfigDataName).Data))
+	})
+
+	It("rolls back a rollout that does not become ready in time", func() {
+		UpdateNovaAPIRevision(brokenAPIImage, "foo=broken")
+		ExpectAPIStatefulSetImage(brokenAPIImage)
+
+		Eventually(func(g Gomega) {
+			conditions := NovaAPIConditionGetter(novaNames.APIName)
+			cond := conditions.Get(novav1.NovaRolloutReadyCondition)
+			g.Expect(cond).NotTo(BeNil())
+			g.Expect(cond.Status).To(Equal(corev1.ConditionFalse))
+			g.Expect(cond.Reason).To(Equal(condition.ErrorReason))
+			g.Expect(cond.Message).To(ContainSubstring(
+				"Rolled back to image " + ContainerImage))
+			g.Expect(cond.Message).To(ContainSubstring(
+				"as the rollout of image " + brokenAPIImage))
+		}, timeout, interval).Should(Succeed())
+
+		ExpectAPIStatefulSetImage(ContainerImage)
+		Eventually(func(g Gomega) {
+			configData := th.GetSecret(novaNames.APIConfigDataName)
+			g.Expect(string(configData.Data["02-nova-override.conf"])).To(ContainSubstring("foo=good"))
+			api := GetNovaAPI(novaNames.APIName)
+			g.Expect(api.Status.Rollout.RolledBackGeneration).To(Equal(api.Generation))
+			g.Expect(api.Status.Hash["input"]).To(Equal(api.Status.Rollout.LastKnownGood.ConfigHash))
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			events := &corev1.EventList{}
+			g.Expect(k8sClient.List(
+				ctx, events, client.InNamespace(novaNames.APIName.Namespace))).To(Succeed())
+			reasons := []string{}
+			for _, event := range events.Items {
+				if event.InvolvedObject.Name == novaNames.APIName.Name {
+					reasons = append(reasons, event.Reason)
+				}
+			}
+			g.Expect(reasons).To(ContainElement("RolledBack"))
+		}, timeout, interval).Should(Succeed())
+
+		// a new Spec is rolled out again
+		UpdateNovaAPIRevision(newAPIImage, "foo=fixed")
+		ExpectAPIStatefulSetImage(newAPIImage)
+		th.SimulateStatefulSetReplicaReady(novaNames.APIStatefulSetName)
+		th.ExpectCondition(
+			novaNames.APIName,
+			ConditionGetterFunc(NovaAPIConditionGetter),
+			novav1.NovaRolloutReadyCondition,
+			corev1.ConditionTrue,
+		)
+		Eventually(func(g Gomega) {
+			api := GetNovaAPI(novaNames.APIName)
+			g.Expect(api.Status.Rollout.LastKnownGood.ContainerImage).To(Equal(newAPIImage))
+		}, timeout, interval).Should(Succeed())
+	})
+
+	When("the rollback is disabled", func() {
+		BeforeEach(func() {
+			spec["rolloutDeadline"] = 0
+		})
+
+		It("keeps the rollout that does not become ready", func() {
+			UpdateNovaAPIRevision(brokenAPIImage, "foo=broken")
+			ExpectAPIStatefulSetImage(brokenAPIImage)
+
+			Consistently(func(g Gomega) {
+				ss := th.GetStatefulSet(novaNames.APIStatefulSetName)
+				g.Expect(ss.Spec.Template.Spec.Containers[1].Image).To(Equal(brokenAPIImage))
+				conditions := NovaAPIConditionGetter(novaNames.APIName)
+				g.Expect(conditions.IsTrue(novav1.NovaRolloutReadyCondition)).To(BeTrue())
+			}, consistencyTimeout, interval).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,140 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+)
+
+var _ = Describe("NovaCell capacity reporting", func() {
+	var novaAPIFixture *NovaAPIFixture
+	const novaUID = "00000000-0000-0000-0000-000000000009"
+
+	BeforeEach(func() {
+		keystoneFixture, f := SetupAPIFixtures(logger)
+		novaAPIFixture = f
+		SimulateReadyOfNovaAPI(keystoneFixture.Endpoint())
+
+		cell2Account, cell2Secret := mariadb.CreateMariaDBAccountAndSecret(
+			cell2.MariaDBAccountName, mariadbv1.MariaDBAccountSpec{})
+		DeferCleanup(k8sClient.Delete, ctx, cell2Account)
+		DeferCleanup(k8sClient.Delete, ctx, cell2Secret)
+		mariadb.CreateMariaDBDatabase(cell2.MariaDBDatabaseName.Namespace, cell2.MariaDBDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+		DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(cell2.MariaDBDatabaseName))
+		DeferCleanup(k8sClient.Delete, ctx, CreateMetadataCellInternalSecret(cell2))
+	})
+
+	It("reports every compute host if the cell is the only compute cell", func() {
+		spec := GetDefaultNovaCellSpec(cell2)
+		spec["noVNCProxyServiceTemplate"] = map[string]interface{}{
+			"enabled": false,
+		}
+		DeferCleanup(th.DeleteInstance, CreateNovaCellOwnedByNova(cell2.CellCRName, spec, novaUID))
+
+		Eventually(func(g Gomega) {
+			capacity := GetNovaCell(cell2.CellCRName).Status.Capacity
+			g.Expect(capacity).NotTo(BeNil())
+			g.Expect(capacity.Instances).To(Equal(2))
+			g.Expect(capacity.ComputeServicesUp).To(Equal(1))
+			g.Expect(capacity.ComputeServicesDown).To(Equal(1))
+			g.Expect(capacity.ComputeServicesDisabled).To(Equal(2))
+			g.Expect(capacity.VCPUs).To(Equal(32))
+			g.Expect(capacity.VCPUsUsed).To(Equal(4))
+			g.Expect(capacity.MemoryMB).To(Equal(65536))
+			g.Expect(capacity.MemoryMBUsed).To(Equal(8192))
+			g.Expect(capacity.LocalDiskGB).To(Equal(1000))
+			g.Expect(capacity.LocalDiskGBUsed).To(Equal(40))
+			g.Expect(capacity.CollectedAt.IsZero()).To(BeFalse())
+		}, timeout, interval).Should(Succeed())
+
+		// the deprecated resource fields are only returned before 2.88
+		req := novaAPIFixture.FindRequest("GET", "/compute/os-hypervisors/detail", "")
+		Expect(req).NotTo(BeNil())
+		Expect(req.Header.Get("X-OpenStack-Nova-API-Version")).To(Equal("2.87"))
+	})
+
+	It("only reports the hosts mapped to the cell if there are multiple compute cells", func() {
+		// another compute cell of the same Nova deployment
+		DeferCleanup(
+			th.DeleteInstance,
+			CreateNovaCellOwnedByNova(cell3.CellCRName, GetDefaultNovaCellSpec(cell3), novaUID))
+
+		// an EDPM compute that is not deployed by a NovaCompute
+		host := "edpm-compute-0"
+		novaAPIFixture.Services = append(novaAPIFixture.Services, Service{
+			ID:     "9",
+			Binary: "nova-compute",
+			Host:   host,
+			State:  "up",
+			Status: "enabled",
+		})
+		novaAPIFixture.Hypervisors = append(novaAPIFixture.Hypervisors, Hypervisor{
+			ID:           "3",
+			Hostname:     host,
+			VCPUs:        8,
+			VCPUsUsed:    2,
+			MemoryMB:     16384,
+			MemoryMBUsed: 2048,
+			LocalGB:      100,
+			LocalGBUsed:  10,
+			RunningVMs:   1,
+		})
+
+		spec := GetDefaultNovaCellSpec(cell2)
+		spec["noVNCProxyServiceTemplate"] = map[string]interface{}{
+			"enabled": false,
+		}
+		DeferCleanup(th.DeleteInstance, CreateNovaCellOwnedByNova(cell2.CellCRName, spec, novaUID))
+
+		// the capacity is not collected until the hosts of the cell are known
+		Consistently(func(g Gomega) {
+			g.Expect(GetNovaCell(cell2.CellCRName).Status.Capacity).To(BeNil())
+		}, consistencyTimeout, interval).Should(Succeed())
+
+		SetNovaCellHostMappings(cell2.CellCRName, host)
+
+		Eventually(func(g Gomega) {
+			capacity := GetNovaCell(cell2.CellCRName).Status.Capacity
+			g.Expect(capacity).NotTo(BeNil())
+			g.Expect(capacity.Instances).To(Equal(1))
+			g.Expect(capacity.ComputeServicesUp).To(Equal(1))
+			g.Expect(capacity.ComputeServicesDown).To(Equal(0))
+			g.Expect(capacity.ComputeServicesDisabled).To(Equal(0))
+			g.Expect(capacity.VCPUs).To(Equal(8))
+			g.Expect(capacity.VCPUsUsed).To(Equal(2))
+			g.Expect(capacity.MemoryMB).To(Equal(16384))
+			g.Expect(capacity.MemoryMBUsed).To(Equal(2048))
+			g.Expect(capacity.LocalDiskGB).To(Equal(100))
+			g.Expect(capacity.LocalDiskGBUsed).To(Equal(10))
+		}, timeout, interval).Should(Succeed())
+	})
+
+	It("does not report capacity for a NovaCell without a Nova", func() {
+		spec := GetDefaultNovaCellSpec(cell2)
+		spec["noVNCProxyServiceTemplate"] = map[string]interface{}{
+			"enabled": false,
+		}
+		DeferCleanup(th.DeleteInstance, CreateNovaCell(cell2.CellCRName, spec))
+
+		Consistently(func(g Gomega) {
+			g.Expect(GetNovaCell(cell2.CellCRName).Status.Capacity).To(BeNil())
+		}, consistencyTimeout, interval).Should(Succeed())
+		Expect(novaAPIFixture.HasRequest("GET", "/compute/os-hypervisors/detail", "")).To(BeFalse())
+	})
+})
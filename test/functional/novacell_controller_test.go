@@ -29,7 +29,9 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/service"
 	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
 	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -174,6 +176,89 @@ var _ = Describe("NovaCell controller", func() {
 					corev1.ConditionTrue,
 				)
 			})
+
+			It("does not run online data migrations on the new cell DB", func() {
+				th.ExpectCondition(
+					cell0.CellCRName,
+					ConditionGetterFunc(NovaCellConditionGetter),
+					novav1.NovaOnlineDataMigrationReadyCondition,
+					corev1.ConditionTrue,
+				)
+				novaCell := GetNovaCell(cell0.CellCRName)
+				Expect(novaCell.Status.OnlineDataMigrationImage).To(
+					Equal(novaCell.Spec.ConductorContainerImageURL))
+				job := &batchv1.Job{}
+				err := k8sClient.Get(ctx, cell0.OnlineDataMigrationJobName, job)
+				Expect(k8s_errors.IsNotFound(err)).To(BeTrue())
+			})
+
+			It("runs online data migrations after the new conductor image is rolled out", func() {
+				th.ExpectCondition(
+					cell0.CellCRName,
+					ConditionGetterFunc(NovaCellConditionGetter),
+					condition.ReadyCondition,
+					corev1.ConditionTrue,
+				)
+
+				Eventually(func(g Gomega) {
+					novaCell := GetNovaCell(cell0.CellCRName)
+					novaCell.Spec.ConductorContainerImageURL = "new-conductor-image"
+					g.Expect(k8sClient.Update(ctx, novaCell)).To(Succeed())
+				}, timeout, interval).Should(Succeed())
+
+				// the migration waits until the conductor is rolled out
+				Eventually(func(g Gomega) {
+					dbSync := th.GetJob(cell0.DBSyncJobName)
+					g.Expect(dbSync.Spec.Template.Spec.Containers[0].Image).To(Equal("new-conductor-image"))
+				}, timeout, interval).Should(Succeed())
+				th.ExpectConditionWithDetails(
+					cell0.CellCRName,
+					ConditionGetterFunc(NovaCellConditionGetter),
+					novav1.NovaOnlineDataMigrationReadyCondition,
+					corev1.ConditionFalse,
+					condition.RequestedReason,
+					novav1.NovaOnlineDataMigrationReadyWaitingMessage,
+				)
+				th.SimulateJobSuccess(cell0.DBSyncJobName)
+				th.SimulateStatefulSetReplicaReady(cell0.ConductorStatefulSetName)
+
+				th.ExpectConditionWithDetails(
+					cell0.CellCRName,
+					ConditionGetterFunc(NovaCellConditionGetter),
+					novav1.NovaOnlineDataMigrationReadyCondition,
+					corev1.ConditionFalse,
+					condition.RequestedReason,
+					novav1.NovaOnlineDataMigrationReadyRunningMessage,
+				)
+				migrationJob := th.GetJob(cell0.OnlineDataMigrationJobName)
+				Expect(migrationJob.Spec.Template.Spec.Containers[0].Image).To(Equal("new-conductor-image"))
+				Expect(migrationJob.Spec.Template.Spec.Volumes).To(HaveLen(2))
+				Expect(migrationJob.Spec.Template.Spec.Containers[0].VolumeMounts).To(HaveLen(3))
+				Expect(GetNovaCell(cell0.CellCRName).Status.OnlineDataMigrationImage).NotTo(
+					Equal("new-conductor-image"))
+
+				th.SimulateJobSuccess(cell0.OnlineDataMigrationJobName)
+				th.ExpectCondition(
+					cell0.CellCRName,
+					ConditionGetterFunc(NovaCellConditionGetter),
+					novav1.NovaOnlineDataMigrationReadyCondition,
+					corev1.ConditionTrue,
+				)
+				th.ExpectCondition(
+					cell0.CellCRName,
+					ConditionGetterFunc(NovaCellConditionGetter),
+					condition.ReadyCondition,
+					corev1.ConditionTrue,
+				)
+				novaCell := GetNovaCell(cell0.CellCRName)
+				Expect(novaCell.Status.OnlineDataMigrationImage).To(Equal("new-conductor-image"))
+				Expect(novaCell.Status.Hash).To(HaveKey(novav1.OnlineDataMigrationHashKey))
+
+				scripts := th.GetSecret(cell0.ConductorScriptDataName)
+				Expect(scripts.Data).To(HaveKey("onlinedatamigration.sh"))
+				Expect(string(scripts.Data["onlinedatamigration.sh"])).To(
+					ContainSubstring("nova-manage db online_data_migrations"))
+			})
 		})
 	})
 	When("A NovaCell/cell1 CR instance is created", func() {
@@ -514,6 +599,65 @@ var _ = Describe("NovaCell controller", func() {
 
 		})
 	})
+	When("A NovaCell/cell2 CR instance is created with telemetry and notifications", func() {
+		BeforeEach(func() {
+			mariadb.CreateMariaDBDatabase(cell2.MariaDBDatabaseName.Namespace, cell2.MariaDBDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+			DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(cell2.MariaDBDatabaseName))
+
+			DeferCleanup(
+				k8sClient.Delete,
+				ctx,
+				CreateCellInternalSecret(cell2, map[string][]byte{
+					"MetadataSecret":             []byte("metadata-secret"),
+					"notification_transport_url": []byte("rabbit://notification/fake"),
+				}),
+			)
+			spec := GetDefaultNovaCellSpec(cell2)
+			spec["noVNCProxyServiceTemplate"] = map[string]interface{}{
+				"enabled": false,
+			}
+			spec["telemetry"] = map[string]interface{}{
+				"enabled":                  true,
+				"instanceUsageAuditPeriod": "month@15",
+				"notifyOnStateChange":      "vm_state",
+			}
+			DeferCleanup(th.DeleteInstance, CreateNovaCell(cell2.CellCRName, spec))
+		})
+
+		It("configures telemetry and notifications in the compute config secret", func() {
+			th.ExpectCondition(
+				cell2.CellCRName,
+				ConditionGetterFunc(NovaCellConditionGetter),
+				novav1.NovaComputeServiceConfigReady,
+				corev1.ConditionTrue,
+			)
+
+			computeConfigData := th.GetSecret(cell2.ComputeConfigSecretName)
+			Expect(computeConfigData.Data).Should(HaveKey("01-nova.conf"))
+			configData := string(computeConfigData.Data["01-nova.conf"])
+			Expect(configData).To(
+				ContainSubstring("instance_usage_audit = true\ninstance_usage_audit_period = month@15"))
+			Expect(configData).To(
+				ContainSubstring("[notifications]\nnotify_on_state_change = vm_state"))
+			Expect(configData).To(
+				ContainSubstring("[oslo_messaging_notifications]\ntransport_url =  rabbit://notification/fake\ndriver = messagingv2"))
+		})
+
+		It("passes telemetry to the NovaConductor", func() {
+			conductor := GetNovaConductor(cell2.ConductorName)
+			Expect(conductor.Spec.Telemetry.Enabled).To(BeTrue())
+			Expect(conductor.Spec.Telemetry.NotifyOnStateChange).To(Equal("vm_state"))
+
+			configDataMap := th.GetSecret(cell2.ConductorConfigDataName)
+			Expect(configDataMap.Data).Should(HaveKey("01-nova.conf"))
+			configData := string(configDataMap.Data["01-nova.conf"])
+			Expect(configData).To(
+				ContainSubstring("[notifications]\nnotify_on_state_change = vm_state"))
+			// the usage audit only runs on the computes
+			Expect(configData).NotTo(ContainSubstring("instance_usage_audit"))
+		})
+	})
+
 	When("A NovaCell/cell2 CR instance is created without VNCProxy", func() {
 		BeforeEach(func() {
 			mariadb.CreateMariaDBDatabase(cell2.MariaDBDatabaseName.Namespace, cell2.MariaDBDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
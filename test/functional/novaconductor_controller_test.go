@@ -30,11 +30,93 @@ import (
 	"github.com/openstack-k8s-operators/lib-common/modules/common/util"
 	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
 	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
 )
 
+// SimulateDBPurgeJob simulates that the DB purge CronJob started a Job that
+// succeeded or failed with the given termination message
+func SimulateDBPurgeJob(cronName types.NamespacedName, name string, succeeded bool, message string) {
+	cron := GetCronJob(cronName)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cronName.Namespace,
+			Labels:    cron.Spec.JobTemplate.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "batch/v1",
+					Kind:       "CronJob",
+					Name:       cron.Name,
+					UID:        cron.UID,
+					Controller: ptr.To(true),
+				},
+			},
+		},
+		Spec: cron.Spec.JobTemplate.Spec,
+	}
+	Expect(k8sClient.Create(ctx, job)).To(Succeed())
+	DeferCleanup(th.DeleteInstance, job)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name + "-pod",
+			Namespace: job.Namespace,
+			Labels: map[string]string{
+				"job-name": name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nova-manage", Image: "nova-conductor"},
+			},
+		},
+	}
+	Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+	DeferCleanup(th.DeleteInstance, pod)
+	exitCode := int32(0)
+	if !succeeded {
+		exitCode = 1
+	}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "nova-manage",
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   exitCode,
+					Message:    message,
+					FinishedAt: metav1.Now(),
+				},
+			},
+		},
+	}
+	Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	if succeeded {
+		job.Status.CompletionTime = &now
+		job.Status.Succeeded = 1
+		job.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobComplete, Status: corev1.ConditionTrue, LastTransitionTime: now},
+		}
+	} else {
+		job.Status.Failed = 1
+		job.Status.Conditions = []batchv1.JobCondition{
+			{
+				Type:               batchv1.JobFailed,
+				Status:             corev1.ConditionTrue,
+				LastTransitionTime: now,
+				Reason:             "BackoffLimitExceeded",
+				Message:            "Job has reached the specified backoff limit",
+			},
+		}
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
+
 var _ = Describe("NovaConductor controller", func() {
 	BeforeEach(func() {
 		mariadb.CreateMariaDBDatabase(cell0.MariaDBDatabaseName.Namespace, cell0.MariaDBDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
@@ -85,6 +167,9 @@ var _ = Describe("NovaConductor controller", func() {
 			Expect(instance.Spec.DBPurge.Schedule).To(Equal(ptr.To("0 0 * * *")))
 			Expect(instance.Spec.DBPurge.ArchiveAge).To(Equal(ptr.To(30)))
 			Expect(instance.Spec.DBPurge.PurgeAge).To(Equal(ptr.To(90)))
+			Expect(instance.Spec.DBPurge.MaxRows).To(Equal(ptr.To(1000)))
+			Expect(instance.Spec.DBPurge.DryRun).To(BeFalse())
+			Expect(instance.Spec.DBPurge.ArchiveTaskLog).To(Equal(ptr.To(true)))
 		})
 
 		It("is missing the secret", func() {
@@ -375,11 +460,16 @@ var _ = Describe("NovaConductor controller", func() {
 					Equal(fmt.Sprintf("%d", *conductor.Spec.DBPurge.ArchiveAge)))
 				Expect(GetEnvVarValue(jobEnv, "PURGE_AGE", "")).To(
 					Equal(fmt.Sprintf("%d", *conductor.Spec.DBPurge.PurgeAge)))
+				Expect(GetEnvVarValue(jobEnv, "MAX_ROWS", "")).To(Equal("1000"))
+				Expect(GetEnvVarValue(jobEnv, "DRY_RUN", "")).To(Equal("false"))
+				Expect(GetEnvVarValue(jobEnv, "ARCHIVE_TASK_LOG", "")).To(Equal("true"))
 				service := cron.Spec.JobTemplate.Labels["service"]
 				Expect(service).To(Equal("nova-conductor"))
 				nodeSelector := cron.Spec.JobTemplate.Spec.Template.Spec.NodeSelector
 				Expect(nodeSelector).NotTo(BeNil())
 				Expect(nodeSelector).To(Equal(map[string]string{"foo": "bar"}))
+				Expect(cron.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy).To(
+					Equal(corev1.RestartPolicyNever))
 
 				th.ExpectCondition(
 					cell0.ConductorName,
@@ -388,6 +478,36 @@ var _ = Describe("NovaConductor controller", func() {
 					corev1.ConditionTrue,
 				)
 			})
+
+			It("records the result of the DB purge runs", func() {
+				th.SimulateStatefulSetReplicaReady(cell0.ConductorStatefulSetName)
+				Expect(GetNovaConductor(cell0.ConductorName).Status.DBPurge).To(BeNil())
+
+				SimulateDBPurgeJob(cell0.DBPurgeCronJobName, "db-purge-1", true,
+					`{"archivedRows": 120, "purgedRows": 80, "dryRun": false}`)
+				Eventually(func(g Gomega) {
+					dbPurge := GetNovaConductor(cell0.ConductorName).Status.DBPurge
+					g.Expect(dbPurge).NotTo(BeNil())
+					g.Expect(dbPurge.LastSuccessTime).NotTo(BeNil())
+					g.Expect(dbPurge.ArchivedRows).To(Equal(120))
+					g.Expect(dbPurge.PurgedRows).To(Equal(80))
+					g.Expect(dbPurge.DryRun).To(BeFalse())
+					g.Expect(dbPurge.LastFailureTime).To(BeNil())
+				}, timeout, interval).Should(Succeed())
+
+				SimulateDBPurgeJob(cell0.DBPurgeCronJobName, "db-purge-2", false,
+					"ERROR: Lost connection to MySQL server")
+				Eventually(func(g Gomega) {
+					dbPurge := GetNovaConductor(cell0.ConductorName).Status.DBPurge
+					g.Expect(dbPurge).NotTo(BeNil())
+					g.Expect(dbPurge.LastFailureTime).NotTo(BeNil())
+					g.Expect(dbPurge.LastFailureReason).To(Equal(
+						"BackoffLimitExceeded: Job has reached the specified backoff limit: " +
+							"ERROR: Lost connection to MySQL server"))
+					// the result of the last successful run is kept
+					g.Expect(dbPurge.ArchivedRows).To(Equal(120))
+				}, timeout, interval).Should(Succeed())
+			})
 		})
 	})
 
@@ -0,0 +1,406 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+)
+
+const backupReport = `{"path": "/backup/20241018020000", "cells": ["cell0", "cell1", "cell2"],` +
+	` "schemaVersions": {"nova_api": "cdeec0c85668", "nova_cell0": "13863f4e1612"},` +
+	` "image": "nova-conductor"}`
+
+// CreateDatabaseJobPod simulates the pod of the backup or the restore Job
+// that terminated with the given termination message
+func CreateDatabaseJobPod(jobName types.NamespacedName, message string) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName.Name + "-pod",
+			Namespace: jobName.Namespace,
+			Labels: map[string]string{
+				"job-name": jobName.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "db-backup", Image: "nova-conductor"},
+			},
+		},
+	}
+	Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+	DeferCleanup(th.DeleteInstance, pod)
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "db-backup",
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					Message:    message,
+					FinishedAt: metav1.Now(),
+				},
+			},
+		},
+	}
+	Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+}
+
+// SimulateBackupCronJobRun simulates that the backup CronJob started a Job
+// that succeeded with the given termination message
+func SimulateBackupCronJobRun(cronName types.NamespacedName, name string, message string) {
+	cron := GetCronJob(cronName)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: cronName.Namespace,
+			Labels:    cron.Spec.JobTemplate.Labels,
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "batch/v1",
+					Kind:       "CronJob",
+					Name:       cron.Name,
+					UID:        cron.UID,
+					Controller: ptr.To(true),
+				},
+			},
+		},
+		Spec: cron.Spec.JobTemplate.Spec,
+	}
+	Expect(k8sClient.Create(ctx, job)).To(Succeed())
+	DeferCleanup(th.DeleteInstance, job)
+
+	CreateDatabaseJobPod(types.NamespacedName{Namespace: job.Namespace, Name: name}, message)
+
+	now := metav1.Now()
+	job.Status.StartTime = &now
+	job.Status.CompletionTime = &now
+	job.Status.Succeeded = 1
+	job.Status.Conditions = []batchv1.JobCondition{
+		{Type: batchv1.JobComplete, Status: corev1.ConditionTrue},
+	}
+	Expect(k8sClient.Status().Update(ctx, job)).To(Succeed())
+}
+
+// TakeDatabaseBackup creates a one-shot NovaDatabaseBackup and simulates that
+// its Job took the backup
+func TakeDatabaseBackup(backupName types.NamespacedName) {
+	DeferCleanup(th.DeleteInstance, CreateNovaDatabaseBackup(backupName, GetDefaultNovaDatabaseBackupSpec(novaNames)))
+	jobName := types.NamespacedName{
+		Namespace: backupName.Namespace,
+		Name:      backupName.Name + "-db-backup",
+	}
+	th.GetJob(jobName)
+	CreateDatabaseJobPod(jobName, backupReport)
+	th.SimulateJobSuccess(jobName)
+	th.ExpectCondition(
+		backupName,
+		ConditionGetterFunc(NovaDatabaseBackupConditionGetter),
+		condition.ReadyCondition,
+		corev1.ConditionTrue,
+	)
+}
+
+var _ = Describe("NovaDatabaseBackup controller", func() {
+	var backupName types.NamespacedName
+	var resourceName types.NamespacedName
+
+	BeforeEach(func() {
+		backupName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "nova-backup",
+		}
+		resourceName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      backupName.Name + "-db-backup",
+		}
+	})
+
+	When("the Nova CR does not exist", func() {
+		BeforeEach(func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaDatabaseBackup(backupName, GetDefaultNovaDatabaseBackupSpec(novaNames)))
+		})
+
+		It("waits for the Nova CR", func() {
+			th.ExpectConditionWithDetails(
+				backupName,
+				ConditionGetterFunc(NovaDatabaseBackupConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for Nova "+novaNames.NovaName.Name,
+			)
+		})
+	})
+
+	When("Nova with 3 cells is ready", func() {
+		BeforeEach(func() {
+			CreateNovaWith3CellsAndEnsureReady(novaNames)
+		})
+
+		It("takes a backup of every DB once and records it", func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaDatabaseBackup(backupName, GetDefaultNovaDatabaseBackupSpec(novaNames)))
+
+			Eventually(func(g Gomega) {
+				pvc := &corev1.PersistentVolumeClaim{}
+				g.Expect(k8sClient.Get(ctx, resourceName, pvc)).To(Succeed())
+				g.Expect(pvc.Spec.Resources.Requests[corev1.ResourceStorage]).To(
+					Equal(resource.MustParse("1G")))
+			}, timeout, interval).Should(Succeed())
+
+			configData := th.GetSecret(types.NamespacedName{
+				Namespace: backupName.Namespace,
+				Name:      resourceName.Name + "-config-data",
+			})
+			Expect(configData.Data).To(HaveKey("nova_api.cnf"))
+			Expect(configData.Data).To(HaveKey("nova_cell2.cnf"))
+			Expect(string(configData.Data["nova_api.cnf"])).To(
+				ContainSubstring("host=hostname-for-"))
+
+			backupJob := th.GetJob(resourceName)
+			env := backupJob.Spec.Template.Spec.Containers[0].Env
+			Expect(GetEnvVarValue(env, "DATABASES", "")).To(
+				Equal("nova_api nova_cell0 nova_cell1 nova_cell2"))
+			Expect(GetEnvVarValue(env, "CELLS", "")).To(Equal("cell0 cell1 cell2"))
+			Expect(GetEnvVarValue(env, "MAX_BACKUPS", "")).To(Equal("3"))
+			th.ExpectConditionWithDetails(
+				backupName,
+				ConditionGetterFunc(NovaDatabaseBackupConditionGetter),
+				novav1.NovaDatabaseBackupCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				novav1.NovaDatabaseBackupRunningMessage,
+			)
+
+			CreateDatabaseJobPod(resourceName, backupReport)
+			th.SimulateJobSuccess(resourceName)
+
+			th.ExpectCondition(
+				backupName,
+				ConditionGetterFunc(NovaDatabaseBackupConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			lastBackup := GetNovaDatabaseBackup(backupName).Status.LastBackup
+			Expect(lastBackup).NotTo(BeNil())
+			Expect(lastBackup.Path).To(Equal("/backup/20241018020000"))
+			Expect(lastBackup.Cells).To(Equal([]string{"cell0", "cell1", "cell2"}))
+			Expect(lastBackup.SchemaVersions).To(HaveKeyWithValue("nova_api", "cdeec0c85668"))
+			Expect(lastBackup.ContainerImage).To(Equal("nova-conductor"))
+		})
+
+		It("schedules the backups and records the last one", func() {
+			spec := GetDefaultNovaDatabaseBackupSpec(novaNames)
+			spec["schedule"] = "0 2 * * *"
+			spec["cells"] = []string{"cell1"}
+			DeferCleanup(th.DeleteInstance, CreateNovaDatabaseBackup(backupName, spec))
+
+			th.ExpectConditionWithDetails(
+				backupName,
+				ConditionGetterFunc(NovaDatabaseBackupConditionGetter),
+				novav1.NovaDatabaseBackupCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				"Database backups are scheduled with 0 2 * * *",
+			)
+			cron := GetCronJob(resourceName)
+			Expect(cron.Spec.Schedule).To(Equal("0 2 * * *"))
+			Expect(cron.Spec.ConcurrencyPolicy).To(Equal(batchv1.ForbidConcurrent))
+			Expect(GetEnvVarValue(
+				cron.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env, "DATABASES", "")).To(
+				Equal("nova_api nova_cell1"))
+			Expect(GetNovaDatabaseBackup(backupName).Status.LastBackup).To(BeNil())
+
+			SimulateBackupCronJobRun(resourceName, "nova-backup-1", backupReport)
+			Eventually(func(g Gomega) {
+				lastBackup := GetNovaDatabaseBackup(backupName).Status.LastBackup
+				g.Expect(lastBackup).NotTo(BeNil())
+				g.Expect(lastBackup.Path).To(Equal("/backup/20241018020000"))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports a cell that is not in the Nova deployment", func() {
+			spec := GetDefaultNovaDatabaseBackupSpec(novaNames)
+			spec["cells"] = []string{"cell3"}
+			DeferCleanup(th.DeleteInstance, CreateNovaDatabaseBackup(backupName, spec))
+
+			th.ExpectConditionWithDetails(
+				backupName,
+				ConditionGetterFunc(NovaDatabaseBackupConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Input data error occurred cell cell3 is not defined in Nova "+novaNames.NovaName.Name,
+			)
+		})
+	})
+})
+
+var _ = Describe("NovaDatabaseRestore controller", func() {
+	var backupName types.NamespacedName
+	var restoreName types.NamespacedName
+	var jobName types.NamespacedName
+
+	BeforeEach(func() {
+		backupName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "nova-backup",
+		}
+		restoreName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "nova-restore",
+		}
+		jobName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      restoreName.Name + "-db-restore",
+		}
+		CreateNovaWith3CellsAndEnsureReady(novaNames)
+	})
+
+	It("waits for a backup to be taken", func() {
+		DeferCleanup(th.DeleteInstance, CreateNovaDatabaseRestore(
+			restoreName, GetDefaultNovaDatabaseRestoreSpec(novaNames, backupName.Name)))
+
+		th.ExpectConditionWithDetails(
+			restoreName,
+			ConditionGetterFunc(NovaDatabaseRestoreConditionGetter),
+			condition.InputReadyCondition,
+			corev1.ConditionFalse,
+			condition.RequestedReason,
+			"Waiting for NovaDatabaseBackup "+backupName.Name,
+		)
+	})
+
+	When("a backup is taken", func() {
+		BeforeEach(func() {
+			TakeDatabaseBackup(backupName)
+		})
+
+		It("refuses to restore while the services are running", func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaDatabaseRestore(
+				restoreName, GetDefaultNovaDatabaseRestoreSpec(novaNames, backupName.Name)))
+
+			Eventually(func(g Gomega) {
+				conditions := NovaDatabaseRestoreConditionGetter(restoreName)
+				restoreCondition := conditions.Get(novav1.NovaDatabaseRestoreCondition)
+				g.Expect(restoreCondition).NotTo(BeNil())
+				g.Expect(restoreCondition.Status).To(Equal(corev1.ConditionFalse))
+				g.Expect(restoreCondition.Reason).To(Equal(condition.ErrorReason))
+				g.Expect(restoreCondition.Message).To(HavePrefix(
+					"Database restore refused while services are running: nova-api, nova-scheduler"))
+				g.Expect(restoreCondition.Message).To(ContainSubstring("cell0 nova-conductor"))
+				g.Expect(restoreCondition.Message).To(ContainSubstring("cell2 nova-novncproxy"))
+			}, timeout, interval).Should(Succeed())
+			th.AssertJobDoesNotExist(jobName)
+		})
+
+		It("refuses to restore the API DB while a cell with API DB access is running", func() {
+			spec := GetDefaultNovaDatabaseRestoreSpec(novaNames, backupName.Name)
+			spec["cells"] = []string{cell1.CellName}
+			spec["restoreAPIDatabase"] = true
+			DeferCleanup(th.DeleteInstance, CreateNovaDatabaseRestore(restoreName, spec))
+
+			Eventually(func(g Gomega) {
+				conditions := NovaDatabaseRestoreConditionGetter(restoreName)
+				restoreCondition := conditions.Get(novav1.NovaDatabaseRestoreCondition)
+				g.Expect(restoreCondition).NotTo(BeNil())
+				g.Expect(restoreCondition.Status).To(Equal(corev1.ConditionFalse))
+				// cell0 is not restored but its super conductor writes the
+				// API DB
+				g.Expect(restoreCondition.Message).To(ContainSubstring("cell0 nova-conductor"))
+				g.Expect(restoreCondition.Message).To(ContainSubstring("cell1 nova-conductor"))
+				// cell2 has no API DB access
+				g.Expect(restoreCondition.Message).NotTo(ContainSubstring("cell2"))
+			}, timeout, interval).Should(Succeed())
+			th.AssertJobDoesNotExist(jobName)
+		})
+
+		It("restores the DB of a cell after the cell is scaled down", func() {
+			spec := GetDefaultNovaDatabaseRestoreSpec(novaNames, backupName.Name)
+			spec["cells"] = []string{cell2.CellName}
+			spec["restoreAPIDatabase"] = false
+			DeferCleanup(th.DeleteInstance, CreateNovaDatabaseRestore(restoreName, spec))
+
+			th.ExpectConditionWithDetails(
+				restoreName,
+				ConditionGetterFunc(NovaDatabaseRestoreConditionGetter),
+				novav1.NovaDatabaseRestoreCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Database restore refused while services are running: "+
+					"cell2 nova-conductor, cell2 nova-novncproxy",
+			)
+			th.AssertJobDoesNotExist(jobName)
+
+			UpdateCellDisabled(cell2, true, true)
+			Eventually(func(g Gomega) {
+				g.Expect(*GetNovaConductor(cell2.ConductorName).Spec.Replicas).To(Equal(int32(0)))
+				g.Expect(*GetNovaNoVNCProxy(cell2.NoVNCProxyName).Spec.Replicas).To(Equal(int32(0)))
+			}, timeout, interval).Should(Succeed())
+			th.SimulateStatefulSetReplicaReady(cell2.ConductorStatefulSetName)
+			th.SimulateStatefulSetReplicaReady(cell2.NoVNCProxyStatefulSetName)
+
+			restoreJob := th.GetJob(jobName)
+			env := restoreJob.Spec.Template.Spec.Containers[0].Env
+			Expect(GetEnvVarValue(env, "DATABASES", "")).To(Equal("nova_cell2"))
+			Expect(GetEnvVarValue(env, "BACKUP_PATH", "")).To(Equal("/backup/20241018020000"))
+			configData := th.GetSecret(types.NamespacedName{
+				Namespace: restoreName.Namespace,
+				Name:      jobName.Name + "-config-data",
+			})
+			Expect(configData.Data).To(HaveKey("nova_cell2.cnf"))
+			Expect(configData.Data).NotTo(HaveKey("nova_api.cnf"))
+
+			th.SimulateJobSuccess(jobName)
+			th.ExpectCondition(
+				restoreName,
+				ConditionGetterFunc(NovaDatabaseRestoreConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			restore := GetNovaDatabaseRestore(restoreName)
+			Expect(restore.Status.Databases).To(Equal([]string{"nova_cell2"}))
+			Expect(restore.Status.BackupPath).To(Equal("/backup/20241018020000"))
+			Expect(restore.Status.CompletionTime).NotTo(BeNil())
+		})
+
+		It("reports a cell that is not in the backup", func() {
+			spec := GetDefaultNovaDatabaseRestoreSpec(novaNames, backupName.Name)
+			spec["cells"] = []string{"cell3"}
+			DeferCleanup(th.DeleteInstance, CreateNovaDatabaseRestore(restoreName, spec))
+
+			th.ExpectConditionWithDetails(
+				restoreName,
+				ConditionGetterFunc(NovaDatabaseRestoreConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Input data error occurred the DB of cell cell3 is not in the last "+
+					"backup of NovaDatabaseBackup "+backupName.Name,
+			)
+		})
+	})
+})
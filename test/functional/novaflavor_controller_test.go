@@ -0,0 +1,227 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	mariadbv1 "github.com/openstack-k8s-operators/mariadb-operator/api/v1beta1"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// SimulateReadyOfNovaAPI creates a NovaAPI that is Ready and uses the given
+// keystone URL so the compute API client of the operator is wired to the API
+// simulators
+func SimulateReadyOfNovaAPI(keystoneAuthURL string) {
+	mariadb.CreateMariaDBDatabase(novaNames.APIMariaDBDatabaseName.Namespace, novaNames.APIMariaDBDatabaseName.Name, mariadbv1.MariaDBDatabaseSpec{})
+	DeferCleanup(k8sClient.Delete, ctx, mariadb.GetMariaDBDatabase(novaNames.APIMariaDBDatabaseName))
+
+	apiMariaDBAccount, apiMariaDBSecret := mariadb.CreateMariaDBAccountAndSecret(
+		novaNames.APIMariaDBDatabaseAccount, mariadbv1.MariaDBAccountSpec{})
+	DeferCleanup(k8sClient.Delete, ctx, apiMariaDBAccount)
+	DeferCleanup(k8sClient.Delete, ctx, apiMariaDBSecret)
+
+	cell0Account, cell0Secret := mariadb.CreateMariaDBAccountAndSecret(
+		cell0.MariaDBAccountName, mariadbv1.MariaDBAccountSpec{})
+	DeferCleanup(k8sClient.Delete, ctx, cell0Account)
+	DeferCleanup(k8sClient.Delete, ctx, cell0Secret)
+
+	memcachedSpec := infra.GetDefaultMemcachedSpec()
+	DeferCleanup(infra.DeleteMemcached, infra.CreateMemcached(novaNames.NovaName.Namespace, MemcachedInstance, memcachedSpec))
+	infra.SimulateMemcachedReady(novaNames.MemcachedNamespace)
+
+	DeferCleanup(
+		k8sClient.Delete, ctx, CreateInternalTopLevelSecret(novaNames))
+	spec := GetDefaultNovaAPISpec(novaNames)
+	spec["keystoneAuthURL"] = keystoneAuthURL
+	DeferCleanup(th.DeleteInstance, CreateNovaAPI(novaNames.APIName, spec))
+
+	th.SimulateStatefulSetReplicaReady(novaNames.APIStatefulSetName)
+	keystone.SimulateKeystoneEndpointReady(novaNames.APIKeystoneEndpointName)
+	th.ExpectCondition(
+		novaNames.APIName,
+		ConditionGetterFunc(NovaAPIConditionGetter),
+		condition.ReadyCondition,
+		corev1.ConditionTrue,
+	)
+}
+
+var _ = Describe("NovaFlavor controller", func() {
+	var flavorName types.NamespacedName
+
+	BeforeEach(func() {
+		flavorName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "m1.test",
+		}
+	})
+
+	When("a NovaFlavor is created but the NovaAPI does not exist", func() {
+		BeforeEach(func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaFlavor(flavorName, GetDefaultNovaFlavorSpec(novaNames)))
+		})
+
+		It("waits for the NovaAPI", func() {
+			th.ExpectConditionWithDetails(
+				flavorName,
+				ConditionGetterFunc(NovaFlavorConditionGetter),
+				novav1.NovaAPIReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for NovaAPI "+novaNames.APIName.Name+" to become Ready",
+			)
+			th.ExpectCondition(
+				flavorName,
+				ConditionGetterFunc(NovaFlavorConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionFalse,
+			)
+		})
+	})
+
+	When("the NovaAPI is Ready", func() {
+		var novaAPIFixture *NovaAPIFixture
+
+		BeforeEach(func() {
+			keystoneFixture, f := SetupAPIFixtures(logger)
+			novaAPIFixture = f
+			SimulateReadyOfNovaAPI(keystoneFixture.Endpoint())
+		})
+
+		It("creates a public flavor with extra specs", func() {
+			spec := GetDefaultNovaFlavorSpec(novaNames)
+			spec["extraSpecs"] = map[string]interface{}{
+				"hw:cpu_policy": "dedicated",
+			}
+			DeferCleanup(th.DeleteInstance, CreateNovaFlavor(flavorName, spec))
+
+			th.ExpectCondition(
+				flavorName,
+				ConditionGetterFunc(NovaFlavorConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			flavor := novaAPIFixture.FindFlavorByName("m1.test")
+			Expect(flavor).NotTo(BeNil())
+			Expect(flavor.VCPUs).To(Equal(2))
+			Expect(flavor.RAM).To(Equal(4096))
+			Expect(flavor.Disk).To(Equal(20))
+			Expect(flavor.IsPublic).To(BeTrue())
+			Expect(flavor.ExtraSpecs).To(Equal(map[string]string{"hw:cpu_policy": "dedicated"}))
+
+			instance := GetNovaFlavor(flavorName)
+			Expect(instance.Status.FlavorID).To(Equal(flavor.ID))
+			Expect(instance.Status.DriftedFields).To(BeEmpty())
+			Expect(instance.Finalizers).To(ContainElement("openstack.org/novaflavor"))
+		})
+
+		It("creates a private flavor with project access", func() {
+			spec := GetDefaultNovaFlavorSpec(novaNames)
+			spec["flavorName"] = "private"
+			spec["isPublic"] = false
+			spec["projectAccess"] = []string{"project-a", "project-b"}
+			DeferCleanup(th.DeleteInstance, CreateNovaFlavor(flavorName, spec))
+
+			th.ExpectCondition(
+				flavorName,
+				ConditionGetterFunc(NovaFlavorConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			flavor := novaAPIFixture.FindFlavorByName("private")
+			Expect(flavor).NotTo(BeNil())
+			Expect(flavor.IsPublic).To(BeFalse())
+			Expect(flavor.ProjectAccess).To(ConsistOf("project-a", "project-b"))
+		})
+
+		It("rejects project access for a public flavor", func() {
+			spec := GetDefaultNovaFlavorSpec(novaNames)
+			spec["projectAccess"] = []string{"project-a"}
+			DeferCleanup(th.DeleteInstance, CreateNovaFlavor(flavorName, spec))
+
+			th.ExpectConditionWithDetails(
+				flavorName,
+				ConditionGetterFunc(NovaFlavorConditionGetter),
+				novav1.NovaFlavorSyncedCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Flavor synchronization error occurred projectAccess can only be defined for non public flavors",
+			)
+			Expect(novaAPIFixture.FindFlavorByName("m1.test")).To(BeNil())
+		})
+
+		It("adopts an existing flavor and reports the drift", func() {
+			novaAPIFixture.Flavors["existing-id"] = &Flavor{
+				ID:       "existing-id",
+				Name:     "m1.test",
+				VCPUs:    2,
+				RAM:      2048,
+				Disk:     20,
+				IsPublic: true,
+				ExtraSpecs: map[string]string{
+					"hw:mem_page_size": "large",
+				},
+			}
+			spec := GetDefaultNovaFlavorSpec(novaNames)
+			spec["extraSpecs"] = map[string]interface{}{
+				"hw:cpu_policy": "dedicated",
+			}
+			DeferCleanup(th.DeleteInstance, CreateNovaFlavor(flavorName, spec))
+
+			th.ExpectConditionWithDetails(
+				flavorName,
+				ConditionGetterFunc(NovaFlavorConditionGetter),
+				novav1.NovaFlavorSyncedCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Flavor differs from the spec in fields that cannot be updated in place: ram",
+			)
+			instance := GetNovaFlavor(flavorName)
+			Expect(instance.Status.FlavorID).To(Equal("existing-id"))
+			Expect(instance.Status.DriftedFields).To(Equal([]string{"ram"}))
+
+			// the mutable part of the flavor is still updated
+			Expect(novaAPIFixture.Flavors).To(HaveLen(1))
+			Expect(novaAPIFixture.Flavors["existing-id"].ExtraSpecs).To(
+				Equal(map[string]string{"hw:cpu_policy": "dedicated"}))
+		})
+
+		It("deletes the flavor when the NovaFlavor is deleted", func() {
+			CreateNovaFlavor(flavorName, GetDefaultNovaFlavorSpec(novaNames))
+			th.ExpectCondition(
+				flavorName,
+				ConditionGetterFunc(NovaFlavorConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			flavorID := GetNovaFlavor(flavorName).Status.FlavorID
+
+			th.DeleteInstance(GetNovaFlavor(flavorName))
+
+			Expect(novaAPIFixture.HasRequest("DELETE", "/compute/flavors/"+flavorID, "")).To(BeTrue())
+			Expect(novaAPIFixture.Flavors).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,241 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("NovaHostDrain controller", func() {
+	var drainName types.NamespacedName
+	var host string
+
+	BeforeEach(func() {
+		drainName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "drain-test",
+		}
+		host = "compute-to-drain"
+	})
+
+	When("a NovaHostDrain is created but the NovaAPI does not exist", func() {
+		BeforeEach(func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaHostDrain(drainName, GetDefaultNovaHostDrainSpec(novaNames, host)))
+		})
+
+		It("waits for the NovaAPI", func() {
+			th.ExpectConditionWithDetails(
+				drainName,
+				ConditionGetterFunc(NovaHostDrainConditionGetter),
+				novav1.NovaAPIReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for NovaAPI "+novaNames.APIName.Name+" to become Ready",
+			)
+		})
+	})
+
+	When("the NovaAPI is Ready", func() {
+		var novaAPIFixture *NovaAPIFixture
+
+		BeforeEach(func() {
+			keystoneFixture, f := SetupAPIFixtures(logger)
+			novaAPIFixture = f
+			SimulateReadyOfNovaAPI(keystoneFixture.Endpoint())
+			novaAPIFixture.Services = append(novaAPIFixture.Services, Service{
+				ID:     "10",
+				Binary: "nova-compute",
+				Host:   host,
+				State:  "up",
+				Status: "enabled",
+			})
+		})
+
+		getService := func() Service {
+			for _, service := range novaAPIFixture.Services {
+				if service.ID == "10" {
+					return service
+				}
+			}
+			return Service{}
+		}
+
+		It("reports if the compute service of the host does not exist", func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaHostDrain(
+				drainName, GetDefaultNovaHostDrainSpec(novaNames, "unknown-host")))
+
+			th.ExpectConditionWithDetails(
+				drainName,
+				ConditionGetterFunc(NovaHostDrainConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Input data error occurred nova-compute service of host unknown-host is not found",
+			)
+		})
+
+		It("disables the service and migrates the instances with limited concurrency", func() {
+			novaAPIFixture.AddServer("server-1", "vm1", host)
+			novaAPIFixture.AddServer("server-2", "vm2", host)
+			novaAPIFixture.AddServer("server-3", "vm3", host)
+			novaAPIFixture.AddServer("server-4", "vm4", "other-host")
+
+			DeferCleanup(th.DeleteInstance, CreateNovaHostDrain(drainName, GetDefaultNovaHostDrainSpec(novaNames, host)))
+
+			th.ExpectCondition(
+				drainName,
+				ConditionGetterFunc(NovaHostDrainConditionGetter),
+				novav1.NovaHostServiceDisabledCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(getService().Status).To(Equal("disabled"))
+			Expect(getService().DisabledReason).To(ContainSubstring("drain-test"))
+
+			countStates := func(state novav1.NovaHostDrainInstanceState) int {
+				count := 0
+				for _, server := range GetNovaHostDrain(drainName).Status.Instances {
+					if server.State == state {
+						count++
+					}
+				}
+				return count
+			}
+			Eventually(func(g Gomega) {
+				g.Expect(GetNovaHostDrain(drainName).Status.Instances).To(HaveLen(3))
+				g.Expect(countStates(novav1.NovaHostDrainInstanceMigrating)).To(Equal(2))
+				g.Expect(countStates(novav1.NovaHostDrainInstancePending)).To(Equal(1))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectConditionWithDetails(
+				drainName,
+				ConditionGetterFunc(NovaHostDrainConditionGetter),
+				novav1.NovaHostDrainedCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Host drain in progress: 0 of 3 instances migrated",
+			)
+
+			// finish the started migrations, the last one is started after
+			for _, server := range GetNovaHostDrain(drainName).Status.Instances {
+				if server.State == novav1.NovaHostDrainInstanceMigrating {
+					novaAPIFixture.CompleteLiveMigration(server.ID, "other-host")
+				}
+			}
+			Eventually(func(g Gomega) {
+				g.Expect(countStates(novav1.NovaHostDrainInstanceMigrated)).To(Equal(2))
+				g.Expect(countStates(novav1.NovaHostDrainInstanceMigrating)).To(Equal(1))
+			}, timeout, interval).Should(Succeed())
+
+			for _, server := range GetNovaHostDrain(drainName).Status.Instances {
+				if server.State == novav1.NovaHostDrainInstanceMigrating {
+					novaAPIFixture.CompleteLiveMigration(server.ID, "other-host")
+				}
+			}
+			th.ExpectCondition(
+				drainName,
+				ConditionGetterFunc(NovaHostDrainConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			instance := GetNovaHostDrain(drainName)
+			Expect(instance.Status.MigratedCount).To(Equal(3))
+			Expect(instance.Status.FailedCount).To(Equal(0))
+			Expect(instance.Status.ServiceID).To(Equal("10"))
+			Expect(novaAPIFixture.HasRequest("POST", "/compute/servers/server-4/action", "")).To(BeFalse())
+		})
+
+		It("reports the instances that cannot be migrated", func() {
+			novaAPIFixture.AddServer("server-1", "vm1", host)
+			novaAPIFixture.AddServer("server-2", "vm2", host).Status = "SHUTOFF"
+
+			DeferCleanup(th.DeleteInstance, CreateNovaHostDrain(drainName, GetDefaultNovaHostDrainSpec(novaNames, host)))
+
+			Eventually(func(g Gomega) {
+				instances := GetNovaHostDrain(drainName).Status.Instances
+				g.Expect(instances).To(ContainElement(novav1.NovaHostDrainInstanceStatus{
+					ID:    "server-1",
+					Name:  "vm1",
+					State: novav1.NovaHostDrainInstanceMigrating,
+				}))
+			}, timeout, interval).Should(Succeed())
+			novaAPIFixture.FailLiveMigration("server-1")
+
+			th.ExpectConditionWithDetails(
+				drainName,
+				ConditionGetterFunc(NovaHostDrainConditionGetter),
+				novav1.NovaHostDrainedCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Failed to migrate 2 instances off the host: server-1, server-2",
+			)
+			instance := GetNovaHostDrain(drainName)
+			Expect(instance.Status.FailedCount).To(Equal(2))
+			Expect(instance.Status.Instances).To(ConsistOf(
+				novav1.NovaHostDrainInstanceStatus{
+					ID:      "server-1",
+					Name:    "vm1",
+					State:   novav1.NovaHostDrainInstanceFailed,
+					Message: "live migration failed, the instance is still on the host",
+				},
+				novav1.NovaHostDrainInstanceStatus{
+					ID:      "server-2",
+					Name:    "vm2",
+					State:   novav1.NovaHostDrainInstanceFailed,
+					Message: "instance in SHUTOFF status cannot be live migrated",
+				},
+			))
+		})
+
+		It("enables the service on delete if requested", func() {
+			spec := GetDefaultNovaHostDrainSpec(novaNames, host)
+			spec["enableServiceOnDelete"] = true
+			CreateNovaHostDrain(drainName, spec)
+			th.ExpectCondition(
+				drainName,
+				ConditionGetterFunc(NovaHostDrainConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(getService().Status).To(Equal("disabled"))
+
+			th.DeleteInstance(GetNovaHostDrain(drainName))
+
+			Expect(getService().Status).To(Equal("enabled"))
+		})
+
+		It("keeps the service disabled on delete by default", func() {
+			CreateNovaHostDrain(drainName, GetDefaultNovaHostDrainSpec(novaNames, host))
+			th.ExpectCondition(
+				drainName,
+				ConditionGetterFunc(NovaHostDrainConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			th.DeleteInstance(GetNovaHostDrain(drainName))
+
+			Expect(getService().Status).To(Equal("disabled"))
+		})
+	})
+})
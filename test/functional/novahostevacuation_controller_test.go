@@ -0,0 +1,234 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("NovaHostEvacuation controller", func() {
+	var evacuationName types.NamespacedName
+	var host string
+
+	BeforeEach(func() {
+		evacuationName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "evacuation-test",
+		}
+		host = "failed-compute"
+	})
+
+	When("a NovaHostEvacuation is created but the NovaAPI does not exist", func() {
+		BeforeEach(func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaHostEvacuation(evacuationName, GetDefaultNovaHostEvacuationSpec(novaNames, host)))
+		})
+
+		It("waits for the NovaAPI", func() {
+			th.ExpectConditionWithDetails(
+				evacuationName,
+				ConditionGetterFunc(NovaHostEvacuationConditionGetter),
+				novav1.NovaAPIReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for NovaAPI "+novaNames.APIName.Name+" to become Ready",
+			)
+		})
+	})
+
+	When("the NovaAPI is Ready", func() {
+		var novaAPIFixture *NovaAPIFixture
+
+		BeforeEach(func() {
+			keystoneFixture, f := SetupAPIFixtures(logger)
+			novaAPIFixture = f
+			SimulateReadyOfNovaAPI(keystoneFixture.Endpoint())
+			novaAPIFixture.Services = append(novaAPIFixture.Services, Service{
+				ID:     "11",
+				Binary: "nova-compute",
+				Host:   host,
+				State:  "down",
+				Status: "enabled",
+			})
+		})
+
+		getService := func() Service {
+			for _, service := range novaAPIFixture.Services {
+				if service.ID == "11" {
+					return service
+				}
+			}
+			return Service{}
+		}
+
+		getInstanceState := func(id string) novav1.NovaHostEvacuationInstanceState {
+			for _, server := range GetNovaHostEvacuation(evacuationName).Status.Instances {
+				if server.ID == id {
+					return server.State
+				}
+			}
+			return ""
+		}
+
+		It("forces the service down, evacuates the instances and unsets force down when the host is back", func() {
+			novaAPIFixture.AddServer("server-1", "vm1", host)
+			novaAPIFixture.AddServer("server-2", "vm2", host).Status = "SHUTOFF"
+			novaAPIFixture.AddServer("server-3", "vm3", host).Status = "ERROR"
+			novaAPIFixture.AddServer("server-4", "vm4", "other-host")
+
+			spec := GetDefaultNovaHostEvacuationSpec(novaNames, host)
+			spec["maxConcurrentEvacuations"] = 3
+			DeferCleanup(th.DeleteInstance, CreateNovaHostEvacuation(evacuationName, spec))
+
+			th.ExpectCondition(
+				evacuationName,
+				ConditionGetterFunc(NovaHostEvacuationConditionGetter),
+				novav1.NovaHostServiceForcedDownCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(getService().ForcedDown).To(BeTrue())
+
+			Eventually(func(g Gomega) {
+				g.Expect(getInstanceState("server-1")).To(Equal(novav1.NovaHostEvacuationInstanceEvacuating))
+				g.Expect(getInstanceState("server-2")).To(Equal(novav1.NovaHostEvacuationInstanceEvacuating))
+				g.Expect(getInstanceState("server-3")).To(Equal(novav1.NovaHostEvacuationInstanceEvacuating))
+			}, timeout, interval).Should(Succeed())
+			Expect(novaAPIFixture.HasRequest("POST", "/compute/servers/server-4/action", "")).To(BeFalse())
+
+			novaAPIFixture.CompleteEvacuation("server-1", "other-host")
+			novaAPIFixture.CompleteEvacuation("server-2", "other-host")
+			novaAPIFixture.CompleteEvacuation("server-3", "other-host")
+
+			th.ExpectCondition(
+				evacuationName,
+				ConditionGetterFunc(NovaHostEvacuationConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			instance := GetNovaHostEvacuation(evacuationName)
+			Expect(instance.Status.EvacuatedCount).To(Equal(3))
+			Expect(instance.Status.Recovered).To(BeFalse())
+			Expect(getService().ForcedDown).To(BeTrue())
+
+			// the host comes back and the compute service reports again
+			novaAPIFixture.SimulateServiceHeartbeat("11")
+			// the next periodic check is far away so trigger a reconcile
+			Eventually(func(g Gomega) {
+				instance := GetNovaHostEvacuation(evacuationName)
+				instance.Annotations = map[string]string{"test": "recheck"}
+				g.Expect(k8sClient.Update(ctx, instance)).To(Succeed())
+			}, timeout, interval).Should(Succeed())
+			Eventually(func(g Gomega) {
+				g.Expect(GetNovaHostEvacuation(evacuationName).Status.Recovered).To(BeTrue())
+			}, timeout, interval).Should(Succeed())
+			Expect(getService().ForcedDown).To(BeFalse())
+		})
+
+		It("refuses to evacuate the host while its service is up", func() {
+			for i := range novaAPIFixture.Services {
+				if novaAPIFixture.Services[i].ID == "11" {
+					novaAPIFixture.Services[i].State = "up"
+				}
+			}
+			novaAPIFixture.AddServer("server-1", "vm1", host)
+
+			DeferCleanup(th.DeleteInstance, CreateNovaHostEvacuation(
+				evacuationName, GetDefaultNovaHostEvacuationSpec(novaNames, host)))
+
+			th.ExpectConditionWithDetails(
+				evacuationName,
+				ConditionGetterFunc(NovaHostEvacuationConditionGetter),
+				novav1.NovaHostServiceForcedDownCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				novav1.NovaHostServiceForcedDownServiceUpMessage,
+			)
+			Consistently(func(g Gomega) {
+				g.Expect(novaAPIFixture.HasRequest("PUT", "/compute/os-services/11", "")).To(BeFalse())
+				g.Expect(novaAPIFixture.HasRequest("POST", "/compute/servers/server-1/action", "")).To(BeFalse())
+			}, consistencyTimeout, interval).Should(Succeed())
+			Expect(getService().ForcedDown).To(BeFalse())
+			Expect(GetNovaHostEvacuation(evacuationName).Status.Instances).To(BeEmpty())
+		})
+
+		It("only evacuates the instances matching the tags and metadata", func() {
+			novaAPIFixture.AddServer("server-1", "vm1", host).Tags = []string{"evacuable", "prod"}
+			novaAPIFixture.AddServer("server-2", "vm2", host).Tags = []string{"prod"}
+			server3 := novaAPIFixture.AddServer("server-3", "vm3", host)
+			server3.Tags = []string{"evacuable"}
+			server3.Metadata["ha"] = "false"
+
+			spec := GetDefaultNovaHostEvacuationSpec(novaNames, host)
+			spec["serverTags"] = []string{"evacuable"}
+			spec["serverMetadata"] = map[string]interface{}{"ha": "true"}
+			novaAPIFixture.Servers["server-1"].Metadata["ha"] = "true"
+			DeferCleanup(th.DeleteInstance, CreateNovaHostEvacuation(evacuationName, spec))
+
+			Eventually(func(g Gomega) {
+				instances := GetNovaHostEvacuation(evacuationName).Status.Instances
+				g.Expect(instances).To(ConsistOf(novav1.NovaHostEvacuationInstanceStatus{
+					ID:    "server-1",
+					Name:  "vm1",
+					State: novav1.NovaHostEvacuationInstanceEvacuating,
+				}))
+			}, timeout, interval).Should(Succeed())
+		})
+
+		It("reports the instances failed to be evacuated", func() {
+			novaAPIFixture.AddServer("server-1", "vm1", host)
+			novaAPIFixture.AddServer("server-2", "vm2", host).Status = "PAUSED"
+
+			DeferCleanup(th.DeleteInstance, CreateNovaHostEvacuation(
+				evacuationName, GetDefaultNovaHostEvacuationSpec(novaNames, host)))
+
+			Eventually(func(g Gomega) {
+				g.Expect(getInstanceState("server-1")).To(Equal(novav1.NovaHostEvacuationInstanceEvacuating))
+			}, timeout, interval).Should(Succeed())
+			novaAPIFixture.FailEvacuation("server-1", "No valid host was found.")
+
+			th.ExpectConditionWithDetails(
+				evacuationName,
+				ConditionGetterFunc(NovaHostEvacuationConditionGetter),
+				novav1.NovaHostEvacuatedCondition,
+				corev1.ConditionFalse,
+				condition.ErrorReason,
+				"Failed to evacuate 2 instances off the host: server-1, server-2",
+			)
+			Expect(GetNovaHostEvacuation(evacuationName).Status.Instances).To(ConsistOf(
+				novav1.NovaHostEvacuationInstanceStatus{
+					ID:      "server-1",
+					Name:    "vm1",
+					State:   novav1.NovaHostEvacuationInstanceFailed,
+					Message: "No valid host was found.",
+				},
+				novav1.NovaHostEvacuationInstanceStatus{
+					ID:      "server-2",
+					Name:    "vm2",
+					State:   novav1.NovaHostEvacuationInstanceFailed,
+					Message: "instance in PAUSED status cannot be evacuated",
+				},
+			))
+		})
+	})
+})
@@ -0,0 +1,179 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// CreateManageCommandPod simulates the pod of the nova-manage Job that
+// terminated with the given exit code and output
+func CreateManageCommandPod(jobName types.NamespacedName, exitCode int32, output string) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName.Name + "-pod",
+			Namespace: jobName.Namespace,
+			Labels: map[string]string{
+				"job-name": jobName.Name,
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "nova-manage", Image: "nova-conductor"},
+			},
+		},
+	}
+	Expect(k8sClient.Create(ctx, pod)).To(Succeed())
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{
+			Name: "nova-manage",
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					ExitCode:   exitCode,
+					Message:    output,
+					FinishedAt: metav1.Now(),
+				},
+			},
+		},
+	}
+	Expect(k8sClient.Status().Update(ctx, pod)).To(Succeed())
+	return pod
+}
+
+var _ = Describe("NovaManageCommand controller", func() {
+	var commandName types.NamespacedName
+	var jobName types.NamespacedName
+
+	BeforeEach(func() {
+		commandName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "heal-allocations",
+		}
+		jobName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      commandName.Name + "-nova-manage",
+		}
+	})
+
+	When("a NovaManageCommand is created but the Nova CR does not exist", func() {
+		BeforeEach(func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaManageCommand(
+				commandName, GetDefaultNovaManageCommandSpec(novaNames, cell1.CellName)))
+		})
+
+		It("waits for the cell to be mapped", func() {
+			th.ExpectConditionWithDetails(
+				commandName,
+				ConditionGetterFunc(NovaManageCommandConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for cell "+cell1.CellName+" of Nova "+novaNames.NovaName.Name+" to be mapped",
+			)
+			th.ExpectCondition(
+				commandName,
+				ConditionGetterFunc(NovaManageCommandConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionFalse,
+			)
+		})
+	})
+
+	When("the cell of the NovaManageCommand is mapped", func() {
+		BeforeEach(func() {
+			CreateNovaWith3CellsAndEnsureReady(novaNames)
+			spec := GetDefaultNovaManageCommandSpec(novaNames, cell1.CellName)
+			spec["args"] = []interface{}{"--max-count", "100"}
+			DeferCleanup(th.DeleteInstance, CreateNovaManageCommand(commandName, spec))
+		})
+
+		It("runs the command in the cell and reports its exit code and output", func() {
+			th.ExpectCondition(
+				commandName,
+				ConditionGetterFunc(NovaManageCommandConditionGetter),
+				condition.InputReadyCondition,
+				corev1.ConditionTrue,
+			)
+			th.ExpectConditionWithDetails(
+				commandName,
+				ConditionGetterFunc(NovaManageCommandConditionGetter),
+				novav1.NovaManageCommandCompletedCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				novav1.NovaManageCommandCompletedRunningMessage,
+			)
+
+			job := th.GetJob(jobName)
+			envs := job.Spec.Template.Spec.Containers[0].Env
+			Expect(GetEnvVarValue(envs, "NOVA_MANAGE_COMMAND", "")).To(Equal("placement heal_allocations"))
+			Expect(GetEnvVarValue(envs, "NOVA_MANAGE_ARGS", "")).To(Equal(`["--max-count","100"]`))
+			Expect(job.Spec.Template.Spec.Containers[0].Image).To(
+				Equal(GetNovaCell(cell1.CellCRName).Spec.ConductorContainerImageURL))
+
+			// heal_allocations reports with exit code 4 that nothing was healed
+			CreateManageCommandPod(jobName, 4, "Processed 0 instances.")
+			th.SimulateJobSuccess(jobName)
+
+			th.ExpectConditionWithDetails(
+				commandName,
+				ConditionGetterFunc(NovaManageCommandConditionGetter),
+				novav1.NovaManageCommandCompletedCondition,
+				corev1.ConditionTrue,
+				condition.ReadyReason,
+				"nova-manage command exited with code 4",
+			)
+			th.ExpectCondition(
+				commandName,
+				ConditionGetterFunc(NovaManageCommandConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			command := GetNovaManageCommand(commandName)
+			Expect(command.Status.ExitCode).NotTo(BeNil())
+			Expect(*command.Status.ExitCode).To(Equal(int32(4)))
+			Expect(command.Status.Output).To(Equal("Processed 0 instances."))
+		})
+
+		It("reports the exit code of a failed command", func() {
+			th.GetJob(jobName)
+			CreateManageCommandPod(jobName, 1, "Compute host compute-0 not found.")
+			th.SimulateJobFailure(jobName)
+
+			Eventually(func(g Gomega) {
+				command := GetNovaManageCommand(commandName)
+				g.Expect(command.Status.ExitCode).NotTo(BeNil())
+				g.Expect(*command.Status.ExitCode).To(Equal(int32(1)))
+				g.Expect(command.Status.Output).To(Equal("Compute host compute-0 not found."))
+			}, timeout, interval).Should(Succeed())
+			th.ExpectCondition(
+				commandName,
+				ConditionGetterFunc(NovaManageCommandConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+		})
+	})
+})
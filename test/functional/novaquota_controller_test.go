@@ -0,0 +1,241 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package functional_test
+
+import (
+	. "github.com/onsi/ginkgo/v2" //revive:disable:dot-imports
+	. "github.com/onsi/gomega"    //revive:disable:dot-imports
+
+	//revive:disable-next-line:dot-imports
+	. "github.com/openstack-k8s-operators/lib-common/modules/common/test/helpers"
+
+	"github.com/gophercloud/gophercloud"
+	condition "github.com/openstack-k8s-operators/lib-common/modules/common/condition"
+	novav1 "github.com/openstack-k8s-operators/nova-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("NovaQuotaClass controller", func() {
+	var quotaClassName types.NamespacedName
+
+	BeforeEach(func() {
+		quotaClassName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "default",
+		}
+	})
+
+	When("a NovaQuotaClass is created but the NovaAPI does not exist", func() {
+		BeforeEach(func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaQuotaClass(quotaClassName, GetDefaultNovaQuotaClassSpec(novaNames)))
+		})
+
+		It("waits for the NovaAPI", func() {
+			th.ExpectConditionWithDetails(
+				quotaClassName,
+				ConditionGetterFunc(NovaQuotaClassConditionGetter),
+				novav1.NovaAPIReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for NovaAPI "+novaNames.APIName.Name+" to become Ready",
+			)
+			th.ExpectCondition(
+				quotaClassName,
+				ConditionGetterFunc(NovaQuotaClassConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionFalse,
+			)
+		})
+	})
+
+	When("the NovaAPI is Ready", func() {
+		var novaAPIFixture *NovaAPIFixture
+
+		BeforeEach(func() {
+			keystoneFixture, f := SetupAPIFixtures(logger)
+			novaAPIFixture = f
+			SimulateReadyOfNovaAPI(keystoneFixture.Endpoint())
+		})
+
+		It("updates the default quota class and reports the applied limits", func() {
+			spec := GetDefaultNovaQuotaClassSpec(novaNames)
+			spec["cores"] = 40
+			spec["ram"] = -1
+			DeferCleanup(th.DeleteInstance, CreateNovaQuotaClass(quotaClassName, spec))
+
+			th.ExpectCondition(
+				quotaClassName,
+				ConditionGetterFunc(NovaQuotaClassConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			Expect(novaAPIFixture.HasRequest("PUT", "/compute/os-quota-class-sets/default", "")).To(BeTrue())
+			Expect(novaAPIFixture.QuotaClasses["default"]).To(Equal(map[string]int{
+				"instances":            20,
+				"cores":                40,
+				"ram":                  -1,
+				"key_pairs":            100,
+				"metadata_items":       128,
+				"server_groups":        10,
+				"server_group_members": 10,
+			}))
+
+			instance := GetNovaQuotaClass(quotaClassName)
+			Expect(instance.Status.AppliedLimits).To(Equal(novav1.NovaQuotaLimits{
+				Instances:          gophercloud.IntToPointer(20),
+				Cores:              gophercloud.IntToPointer(40),
+				RAM:                gophercloud.IntToPointer(-1),
+				KeyPairs:           gophercloud.IntToPointer(100),
+				MetadataItems:      gophercloud.IntToPointer(128),
+				ServerGroups:       gophercloud.IntToPointer(10),
+				ServerGroupMembers: gophercloud.IntToPointer(10),
+			}))
+		})
+
+		It("does not update the quota class if it is already in sync", func() {
+			spec := GetDefaultNovaQuotaClassSpec(novaNames)
+			spec["instances"] = 10
+			DeferCleanup(th.DeleteInstance, CreateNovaQuotaClass(quotaClassName, spec))
+
+			th.ExpectCondition(
+				quotaClassName,
+				ConditionGetterFunc(NovaQuotaClassConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(novaAPIFixture.HasRequest("PUT", "/compute/os-quota-class-sets/default", "")).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("NovaProjectQuota controller", func() {
+	var projectQuotaName types.NamespacedName
+	var projectID string
+
+	BeforeEach(func() {
+		projectQuotaName = types.NamespacedName{
+			Namespace: novaNames.NovaName.Namespace,
+			Name:      "project-quota-test",
+		}
+		projectID = "4e8a7d3f1c2b4a6e9f0d5c8b7a6e5d4c"
+	})
+
+	When("a NovaProjectQuota is created but the NovaAPI does not exist", func() {
+		BeforeEach(func() {
+			DeferCleanup(th.DeleteInstance, CreateNovaProjectQuota(projectQuotaName, GetDefaultNovaProjectQuotaSpec(novaNames, projectID)))
+		})
+
+		It("waits for the NovaAPI", func() {
+			th.ExpectConditionWithDetails(
+				projectQuotaName,
+				ConditionGetterFunc(NovaProjectQuotaConditionGetter),
+				novav1.NovaAPIReadyCondition,
+				corev1.ConditionFalse,
+				condition.RequestedReason,
+				"Waiting for NovaAPI "+novaNames.APIName.Name+" to become Ready",
+			)
+		})
+	})
+
+	When("the NovaAPI is Ready", func() {
+		var novaAPIFixture *NovaAPIFixture
+
+		BeforeEach(func() {
+			keystoneFixture, f := SetupAPIFixtures(logger)
+			novaAPIFixture = f
+			SimulateReadyOfNovaAPI(keystoneFixture.Endpoint())
+			novaAPIFixture.ProjectUsage[projectID] = map[string]int{
+				"instances": 3,
+				"cores":     6,
+				"ram":       6144,
+			}
+		})
+
+		It("sets the project quota and reports the applied and in use values", func() {
+			spec := GetDefaultNovaProjectQuotaSpec(novaNames, projectID)
+			spec["cores"] = 10
+			DeferCleanup(th.DeleteInstance, CreateNovaProjectQuota(projectQuotaName, spec))
+
+			th.ExpectCondition(
+				projectQuotaName,
+				ConditionGetterFunc(NovaProjectQuotaConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			Expect(novaAPIFixture.ProjectQuotas[projectID]).To(Equal(map[string]int{
+				"instances": 5,
+				"cores":     10,
+			}))
+
+			instance := GetNovaProjectQuota(projectQuotaName)
+			Expect(instance.Finalizers).To(ContainElement("openstack.org/novaprojectquota"))
+			Expect(*instance.Status.AppliedLimits.Instances).To(Equal(5))
+			Expect(*instance.Status.AppliedLimits.Cores).To(Equal(10))
+			// not managed limits are inherited from the default quota class
+			Expect(*instance.Status.AppliedLimits.RAM).To(Equal(51200))
+			Expect(*instance.Status.InUse.Instances).To(Equal(3))
+			Expect(*instance.Status.InUse.Cores).To(Equal(6))
+			Expect(*instance.Status.InUse.RAM).To(Equal(6144))
+		})
+
+		It("reports an error if the limit is below the usage without force", func() {
+			spec := GetDefaultNovaProjectQuotaSpec(novaNames, projectID)
+			spec["instances"] = 2
+			DeferCleanup(th.DeleteInstance, CreateNovaProjectQuota(projectQuotaName, spec))
+
+			th.ExpectCondition(
+				projectQuotaName,
+				ConditionGetterFunc(NovaProjectQuotaConditionGetter),
+				novav1.NovaQuotaSyncedCondition,
+				corev1.ConditionFalse,
+			)
+			Expect(novaAPIFixture.ProjectQuotas).NotTo(HaveKey(projectID))
+		})
+
+		It("applies the limit below the usage with force", func() {
+			spec := GetDefaultNovaProjectQuotaSpec(novaNames, projectID)
+			spec["instances"] = 2
+			spec["force"] = true
+			DeferCleanup(th.DeleteInstance, CreateNovaProjectQuota(projectQuotaName, spec))
+
+			th.ExpectCondition(
+				projectQuotaName,
+				ConditionGetterFunc(NovaProjectQuotaConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+			Expect(novaAPIFixture.ProjectQuotas[projectID]).To(HaveKeyWithValue("instances", 2))
+		})
+
+		It("resets the project quota when the NovaProjectQuota is deleted", func() {
+			CreateNovaProjectQuota(projectQuotaName, GetDefaultNovaProjectQuotaSpec(novaNames, projectID))
+			th.ExpectCondition(
+				projectQuotaName,
+				ConditionGetterFunc(NovaProjectQuotaConditionGetter),
+				condition.ReadyCondition,
+				corev1.ConditionTrue,
+			)
+
+			th.DeleteInstance(GetNovaProjectQuota(projectQuotaName))
+
+			Expect(novaAPIFixture.HasRequest("DELETE", "/compute/os-quota-sets/"+projectID, "")).To(BeTrue())
+			Expect(novaAPIFixture.ProjectQuotas).NotTo(HaveKey(projectID))
+		})
+	})
+})
@@ -68,6 +68,69 @@ func CreateNovaConductorFromSample(sampleFileName string, name types.NamespacedN
 	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
 }
 
+func CreateNovaFlavorFromSample(sampleFileName string, name types.NamespacedName) types.NamespacedName {
+	raw := ReadSample(sampleFileName)
+	instance := CreateNovaFlavor(name, raw["spec"].(map[string]interface{}))
+	DeferCleanup(th.DeleteInstance, instance)
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
+
+func CreateNovaAggregateFromSample(sampleFileName string, name types.NamespacedName) types.NamespacedName {
+	raw := ReadSample(sampleFileName)
+	instance := CreateNovaAggregate(name, raw["spec"].(map[string]interface{}))
+	DeferCleanup(th.DeleteInstance, instance)
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
+
+func CreateNovaQuotaClassFromSample(sampleFileName string, name types.NamespacedName) types.NamespacedName {
+	raw := ReadSample(sampleFileName)
+	instance := CreateNovaQuotaClass(name, raw["spec"].(map[string]interface{}))
+	DeferCleanup(th.DeleteInstance, instance)
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
+
+func CreateNovaProjectQuotaFromSample(sampleFileName string, name types.NamespacedName) types.NamespacedName {
+	raw := ReadSample(sampleFileName)
+	instance := CreateNovaProjectQuota(name, raw["spec"].(map[string]interface{}))
+	DeferCleanup(th.DeleteInstance, instance)
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
+
+func CreateNovaHostDrainFromSample(sampleFileName string, name types.NamespacedName) types.NamespacedName {
+	raw := ReadSample(sampleFileName)
+	instance := CreateNovaHostDrain(name, raw["spec"].(map[string]interface{}))
+	DeferCleanup(th.DeleteInstance, instance)
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
+
+func CreateNovaManageCommandFromSample(sampleFileName string, name types.NamespacedName) types.NamespacedName {
+	raw := ReadSample(sampleFileName)
+	instance := CreateNovaManageCommand(name, raw["spec"].(map[string]interface{}))
+	DeferCleanup(th.DeleteInstance, instance)
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
+
+func CreateNovaDatabaseBackupFromSample(sampleFileName string, name types.NamespacedName) types.NamespacedName {
+	raw := ReadSample(sampleFileName)
+	instance := CreateNovaDatabaseBackup(name, raw["spec"].(map[string]interface{}))
+	DeferCleanup(th.DeleteInstance, instance)
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
+
+func CreateNovaDatabaseRestoreFromSample(sampleFileName string, name types.NamespacedName) types.NamespacedName {
+	raw := ReadSample(sampleFileName)
+	instance := CreateNovaDatabaseRestore(name, raw["spec"].(map[string]interface{}))
+	DeferCleanup(th.DeleteInstance, instance)
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
+
+func CreateNovaHostEvacuationFromSample(sampleFileName string, name types.NamespacedName) types.NamespacedName {
+	raw := ReadSample(sampleFileName)
+	instance := CreateNovaHostEvacuation(name, raw["spec"].(map[string]interface{}))
+	DeferCleanup(th.DeleteInstance, instance)
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}
+}
+
 // This is a set of test for our samples. It only validates that the sample
 // file has all the required field with proper types. But it does not
 // validate that using a sample file will result in a working deployment.
@@ -142,4 +205,76 @@ var _ = Describe("Samples", func() {
 			GetNovaConductor(name)
 		})
 	})
+	When("nova_v1beta1_novaflavor.yaml sample is applied", func() {
+		It("NovaFlavor is created", func() {
+			name := CreateNovaFlavorFromSample(
+				"nova_v1beta1_novaflavor.yaml",
+				types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "m1.small"})
+			GetNovaFlavor(name)
+		})
+	})
+	When("nova_v1beta1_novaaggregate.yaml sample is applied", func() {
+		It("NovaAggregate is created", func() {
+			name := CreateNovaAggregateFromSample(
+				"nova_v1beta1_novaaggregate.yaml",
+				types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "fast-storage"})
+			GetNovaAggregate(name)
+		})
+	})
+	When("nova_v1beta1_novaquotaclass.yaml sample is applied", func() {
+		It("NovaQuotaClass is created", func() {
+			name := CreateNovaQuotaClassFromSample(
+				"nova_v1beta1_novaquotaclass.yaml",
+				types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "default"})
+			GetNovaQuotaClass(name)
+		})
+	})
+	When("nova_v1beta1_novaprojectquota.yaml sample is applied", func() {
+		It("NovaProjectQuota is created", func() {
+			name := CreateNovaProjectQuotaFromSample(
+				"nova_v1beta1_novaprojectquota.yaml",
+				types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "demo-project"})
+			GetNovaProjectQuota(name)
+		})
+	})
+	When("nova_v1beta1_novahostdrain.yaml sample is applied", func() {
+		It("NovaHostDrain is created", func() {
+			name := CreateNovaHostDrainFromSample(
+				"nova_v1beta1_novahostdrain.yaml",
+				types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "edpm-compute-0"})
+			GetNovaHostDrain(name)
+		})
+	})
+	When("nova_v1beta1_novahostevacuation.yaml sample is applied", func() {
+		It("NovaHostEvacuation is created", func() {
+			name := CreateNovaHostEvacuationFromSample(
+				"nova_v1beta1_novahostevacuation.yaml",
+				types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "edpm-compute-1"})
+			GetNovaHostEvacuation(name)
+		})
+	})
+	When("nova_v1beta1_novamanagecommand.yaml sample is applied", func() {
+		It("NovaManageCommand is created", func() {
+			name := CreateNovaManageCommandFromSample(
+				"nova_v1beta1_novamanagecommand.yaml",
+				types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "heal-allocations"})
+			GetNovaManageCommand(name)
+		})
+	})
+	When("nova_v1beta1_novadatabasebackup.yaml sample is applied", func() {
+		It("NovaDatabaseBackup is created", func() {
+			name := CreateNovaDatabaseBackupFromSample(
+				"nova_v1beta1_novadatabasebackup.yaml",
+				types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "nova-backup"})
+			GetNovaDatabaseBackup(name)
+		})
+	})
+	When("nova_v1beta1_novadatabaserestore.yaml sample is applied", func() {
+		It("NovaDatabaseRestore is created", func() {
+			name := CreateNovaDatabaseRestoreFromSample(
+				"nova_v1beta1_novadatabaserestore.yaml",
+				types.NamespacedName{Namespace: novaNames.NovaName.Namespace, Name: "nova-restore"})
+			GetNovaDatabaseRestore(name)
+		})
+	})
 })
@@ -27,6 +27,7 @@ import (
 	k8s_errors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // Entries used to test topology validation webhook at different levels
@@ -187,6 +188,119 @@ var _ = Describe("Nova validation", func() {
 					"Invalid value: true: should be false for cell0"),
 		)
 	})
+	It("rejects Nova with availabilityZone in cell0", func() {
+		spec := GetDefaultNovaSpec()
+		cell0Template := GetDefaultNovaCellTemplate()
+		cell0Template["availabilityZone"] = "az0"
+
+		spec["cellTemplates"] = map[string]interface{}{
+			"cell0": cell0Template,
+			// note that this is intentional to test that availabilityZone is
+			// allowed in cell1 but not in cell0
+			"cell1": cell0Template,
+		}
+		raw := map[string]interface{}{
+			"apiVersion": "nova.openstack.org/v1beta1",
+			"kind":       "Nova",
+			"metadata": map[string]interface{}{
+				"name":      novaNames.NovaName.Name,
+				"namespace": novaNames.Namespace,
+			},
+			"spec": spec,
+		}
+
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+
+		Expect(err).Should(HaveOccurred())
+		var statusError *k8s_errors.StatusError
+		Expect(errors.As(err, &statusError)).To(BeTrue())
+		Expect(statusError.ErrStatus.Details.Kind).To(Equal("Nova"))
+		Expect(statusError.ErrStatus.Message).To(
+			ContainSubstring(
+				"invalid: spec.cellTemplates[cell0].availabilityZone: " +
+					"Invalid value: \"az0\": should not be defined for cell0 as it has no computes"),
+		)
+		Expect(statusError.ErrStatus.Message).NotTo(
+			ContainSubstring("spec.cellTemplates[cell1].availabilityZone"))
+	})
+	It("rejects Nova with disabled cell0", func() {
+		spec := GetDefaultNovaSpec()
+		cell0Template := GetDefaultNovaCellTemplate()
+		cell0Template["disabled"] = true
+
+		spec["cellTemplates"] = map[string]interface{}{
+			"cell0": cell0Template,
+			// note that this is intentional to test that disabling is
+			// allowed in cell1 but not in cell0
+			"cell1": cell0Template,
+		}
+		raw := map[string]interface{}{
+			"apiVersion": "nova.openstack.org/v1beta1",
+			"kind":       "Nova",
+			"metadata": map[string]interface{}{
+				"name":      novaNames.NovaName.Name,
+				"namespace": novaNames.Namespace,
+			},
+			"spec": spec,
+		}
+
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+
+		Expect(err).Should(HaveOccurred())
+		var statusError *k8s_errors.StatusError
+		Expect(errors.As(err, &statusError)).To(BeTrue())
+		Expect(statusError.ErrStatus.Details.Kind).To(Equal("Nova"))
+		Expect(statusError.ErrStatus.Message).To(
+			ContainSubstring(
+				"invalid: spec.cellTemplates[cell0].disabled: " +
+					"Invalid value: true: cell0 cannot be disabled as it is not used for scheduling"),
+		)
+		Expect(statusError.ErrStatus.Message).NotTo(
+			ContainSubstring("spec.cellTemplates[cell1].disabled"))
+	})
+	It("rejects Nova with audit enabled in cell0", func() {
+		spec := GetDefaultNovaSpec()
+		cell0Template := GetDefaultNovaCellTemplate()
+		cell0Template["audit"] = map[string]interface{}{
+			"enabled": true,
+		}
+
+		spec["cellTemplates"] = map[string]interface{}{
+			"cell0": cell0Template,
+			// note that this is intentional to test that the audit is
+			// allowed in cell1 but not in cell0
+			"cell1": cell0Template,
+		}
+		raw := map[string]interface{}{
+			"apiVersion": "nova.openstack.org/v1beta1",
+			"kind":       "Nova",
+			"metadata": map[string]interface{}{
+				"name":      novaNames.NovaName.Name,
+				"namespace": novaNames.Namespace,
+			},
+			"spec": spec,
+		}
+
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+
+		Expect(err).Should(HaveOccurred())
+		var statusError *k8s_errors.StatusError
+		Expect(errors.As(err, &statusError)).To(BeTrue())
+		Expect(statusError.ErrStatus.Details.Kind).To(Equal("Nova"))
+		Expect(statusError.ErrStatus.Message).To(
+			ContainSubstring(
+				"invalid: spec.cellTemplates[cell0].audit.enabled: " +
+					"Invalid value: true: should not be enabled for cell0 as it has no computes"),
+		)
+		Expect(statusError.ErrStatus.Message).NotTo(
+			ContainSubstring("spec.cellTemplates[cell1].audit"))
+	})
 	It("rejects NovaCell with NoVNCProxy in cell0", func() {
 		spec := GetDefaultNovaCellSpec(cell0)
 		spec["noVNCProxyServiceTemplate"] = map[string]interface{}{
@@ -1076,6 +1190,41 @@ var _ = Describe("Nova validation", func() {
 			),
 		)
 	})
+	It("rejects Nova with wrong audit.Schedule in cellTemplate", func() {
+		spec := GetDefaultNovaSpec()
+		cell0 := GetDefaultNovaCellTemplate()
+		cell1 := GetDefaultNovaCellTemplate()
+		cell1["audit"] = map[string]interface{}{
+			"enabled":  true,
+			"schedule": "0 1 * *",
+		}
+		spec["cellTemplates"] = map[string]interface{}{"cell0": cell0, "cell1": cell1}
+		raw := map[string]interface{}{
+			"apiVersion": "nova.openstack.org/v1beta1",
+			"kind":       "Nova",
+			"metadata": map[string]interface{}{
+				"name":      novaNames.NovaName.Name,
+				"namespace": novaNames.Namespace,
+			},
+			"spec": spec,
+		}
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+
+		Expect(err).Should(HaveOccurred())
+		var statusError *k8s_errors.StatusError
+		Expect(errors.As(err, &statusError)).To(BeTrue())
+		Expect(statusError.ErrStatus.Details.Kind).To(Equal("Nova"))
+		Expect(statusError.ErrStatus.Message).To(
+			ContainSubstring(
+				"invalid: spec.cellTemplates[cell1]." +
+					"audit.schedule: " +
+					"Invalid value: \"0 1 * *\": " +
+					"expected exactly 5 fields, found 4: [0 1 * *]",
+			),
+		)
+	})
 
 	It("rejects NovaAPI wrong service override endpoint type", func() {
 		spec := GetDefaultNovaAPISpec(novaNames)
@@ -1178,3 +1327,112 @@ var _ = Describe("Nova validation", func() {
 		cell0ComputeEntry,
 	)
 })
+
+var _ = Describe("NovaQuotaClass and NovaProjectQuota validation", func() {
+	var quotaName types.NamespacedName
+
+	BeforeEach(func() {
+		quotaName = types.NamespacedName{
+			Namespace: novaNames.Namespace,
+			Name:      "quota-test",
+		}
+	})
+
+	It("rejects NovaQuotaClass with negative limit", func() {
+		spec := GetDefaultNovaQuotaClassSpec(novaNames)
+		spec["ram"] = -2
+		raw := map[string]interface{}{
+			"apiVersion": "nova.openstack.org/v1beta1",
+			"kind":       "NovaQuotaClass",
+			"metadata": map[string]interface{}{
+				"name":      quotaName.Name,
+				"namespace": quotaName.Namespace,
+			},
+			"spec": spec,
+		}
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.ram"))
+	})
+
+	It("rejects NovaQuotaClass without limits", func() {
+		spec := GetDefaultNovaQuotaClassSpec(novaNames)
+		delete(spec, "instances")
+		raw := map[string]interface{}{
+			"apiVersion": "nova.openstack.org/v1beta1",
+			"kind":       "NovaQuotaClass",
+			"metadata": map[string]interface{}{
+				"name":      quotaName.Name,
+				"namespace": quotaName.Namespace,
+			},
+			"spec": spec,
+		}
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+		Expect(err).To(HaveOccurred())
+		var statusError *k8s_errors.StatusError
+		Expect(errors.As(err, &statusError)).To(BeTrue())
+		Expect(statusError.ErrStatus.Details.Kind).To(Equal("NovaQuotaClass"))
+		Expect(statusError.ErrStatus.Message).To(
+			ContainSubstring(
+				"spec: Required value: at least one quota limit needs to be defined"),
+		)
+	})
+
+	It("rejects NovaProjectQuota with inconsistent limits", func() {
+		spec := GetDefaultNovaProjectQuotaSpec(novaNames, "project-1")
+		spec["instances"] = 10
+		spec["cores"] = 4
+		spec["serverGroups"] = 0
+		spec["serverGroupMembers"] = 5
+		raw := map[string]interface{}{
+			"apiVersion": "nova.openstack.org/v1beta1",
+			"kind":       "NovaProjectQuota",
+			"metadata": map[string]interface{}{
+				"name":      quotaName.Name,
+				"namespace": quotaName.Namespace,
+			},
+			"spec": spec,
+		}
+		unstructuredObj := &unstructured.Unstructured{Object: raw}
+		_, err := controllerutil.CreateOrPatch(
+			ctx, k8sClient, unstructuredObj, func() error { return nil })
+		Expect(err).To(HaveOccurred())
+		var statusError *k8s_errors.StatusError
+		Expect(errors.As(err, &statusError)).To(BeTrue())
+		Expect(statusError.ErrStatus.Details.Kind).To(Equal("NovaProjectQuota"))
+		Expect(statusError.ErrStatus.Message).To(
+			ContainSubstring(
+				"spec.cores: Invalid value: 4: should not be less than the instances limit 10"),
+		)
+		Expect(statusError.ErrStatus.Message).To(
+			ContainSubstring(
+				"spec.serverGroupMembers: Invalid value: 5: should be 0 if serverGroups is 0"),
+		)
+	})
+
+	It("accepts NovaProjectQuota with unlimited cores", func() {
+		spec := GetDefaultNovaProjectQuotaSpec(novaNames, "project-1")
+		spec["instances"] = 10
+		spec["cores"] = -1
+		DeferCleanup(th.DeleteInstance, CreateNovaProjectQuota(quotaName, spec))
+	})
+
+	It("rejects NovaProjectQuota projectID change", func() {
+		DeferCleanup(
+			th.DeleteInstance,
+			CreateNovaProjectQuota(quotaName, GetDefaultNovaProjectQuotaSpec(novaNames, "project-1")))
+
+		Eventually(func(g Gomega) {
+			instance := GetNovaProjectQuota(quotaName)
+			instance.Spec.ProjectID = "project-2"
+			err := k8sClient.Update(ctx, instance)
+			g.Expect(err).To(HaveOccurred())
+			g.Expect(err.Error()).To(
+				ContainSubstring("spec.projectID: Invalid value: \"project-2\": is immutable"))
+		}, timeout, interval).Should(Succeed())
+	})
+})